@@ -0,0 +1,64 @@
+package controller
+
+import (
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"renotech.com.my/internal/enum"
+	"renotech.com.my/internal/middleware"
+	"renotech.com.my/internal/model"
+	"renotech.com.my/internal/service"
+	"renotech.com.my/internal/utils"
+)
+
+// Tenant handlers
+func documentNumberListHandler(c *gin.Context) {
+	systemContext := utils.GetSystemContextFromGin(c)
+
+	result, err := service.DocumentNumberConfigList(systemContext)
+	if err != nil {
+		utils.SendErrorResponse(c, err)
+		return
+	}
+
+	utils.SendSuccessResponse(c, result)
+}
+
+func documentNumberUpdateHandler(c *gin.Context) {
+	systemContext := utils.GetSystemContextFromGin(c)
+	systemContext.Logger.Info("Document numbering update started", zap.String("endpoint", "/api/v1/document-number"))
+	defer systemContext.Logger.Info("Document numbering update completed")
+
+	var input model.DocumentNumberConfigRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.SendErrorResponse(c, utils.SystemError(
+			enum.ErrorCodeValidation,
+			"Invalid request data",
+			map[string]interface{}{"details": err.Error()},
+		))
+		return
+	}
+
+	result, err := service.DocumentNumberConfigUpdate(&input, systemContext)
+	if err != nil {
+		systemContext.Logger.Error("Document numbering update failed", zap.Error(err))
+		utils.SendErrorResponse(c, err)
+		return
+	}
+
+	systemContext.Logger.Info("Document numbering update successful",
+		zap.String("type", string(result.Type)),
+		zap.String("pattern", result.Pattern),
+	)
+
+	utils.SendSuccessResponse(c, result)
+}
+
+func DocumentNumberAPIInit(r *gin.Engine) {
+	// Document numbering routes - Protected with tenant auth middleware
+	documentNumberGroup := r.Group("/api/v1/document-number")
+	documentNumberGroup.Use(middleware.JWTAuthMiddleware())
+	{
+		documentNumberGroup.GET("", documentNumberListHandler)
+		documentNumberGroup.PUT("", documentNumberUpdateHandler)
+	}
+}
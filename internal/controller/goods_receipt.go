@@ -0,0 +1,117 @@
+package controller
+
+import (
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"renotech.com.my/internal/enum"
+	"renotech.com.my/internal/middleware"
+	"renotech.com.my/internal/model"
+	"renotech.com.my/internal/service"
+	"renotech.com.my/internal/utils"
+)
+
+// Tenant handlers
+func goodsReceiptCreateHandler(c *gin.Context) {
+	systemContext := utils.GetSystemContextFromGin(c)
+	systemContext.Logger.Info("Goods receipt creation started", zap.String("endpoint", "/api/v1/goods-receipt"))
+	defer systemContext.Logger.Info("Goods receipt creation completed")
+
+	var input model.GoodsReceiptCreateRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.SendErrorResponse(c, utils.SystemError(
+			enum.ErrorCodeValidation,
+			"Invalid request data",
+			map[string]interface{}{"details": err.Error()},
+		))
+		return
+	}
+
+	result, err := service.GoodsReceiptCreate(&input, systemContext)
+	if err != nil {
+		systemContext.Logger.Error("Goods receipt creation failed", zap.Error(err))
+		utils.SendErrorResponse(c, err)
+		return
+	}
+
+	systemContext.Logger.Info("Goods receipt creation successful",
+		zap.String("receiptID", result.Receipt.ID.Hex()),
+		zap.String("orderID", input.Order.Hex()),
+		zap.String("orderStatus", string(result.Order.Status)),
+	)
+
+	utils.SendSuccessResponse(c, result)
+}
+
+func goodsReceiptGetHandler(c *gin.Context) {
+	systemContext := utils.GetSystemContextFromGin(c)
+
+	receiptID, err := utils.ValidateObjectID(c.Param("id"))
+	if err != nil {
+		utils.SendErrorResponse(c, err)
+		return
+	}
+
+	result, err := service.GoodsReceiptGetByID(receiptID, systemContext)
+	if err != nil {
+		utils.SendErrorResponse(c, err)
+		return
+	}
+
+	utils.SendSuccessResponse(c, result)
+}
+
+func goodsReceiptListHandler(c *gin.Context) {
+	systemContext := utils.GetSystemContextFromGin(c)
+
+	var input model.GoodsReceiptListRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.SendErrorResponse(c, utils.SystemError(
+			enum.ErrorCodeValidation,
+			"Invalid request data",
+			map[string]interface{}{"details": err.Error()},
+		))
+		return
+	}
+
+	result, err := service.GoodsReceiptList(input, systemContext)
+	if err != nil {
+		utils.SendErrorResponse(c, err)
+		return
+	}
+
+	utils.SendSuccessResponse(c, result)
+}
+
+func goodsReceiptOutstandingHandler(c *gin.Context) {
+	systemContext := utils.GetSystemContextFromGin(c)
+
+	var input model.OrderOutstandingRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.SendErrorResponse(c, utils.SystemError(
+			enum.ErrorCodeValidation,
+			"Invalid request data",
+			map[string]interface{}{"details": err.Error()},
+		))
+		return
+	}
+
+	result, err := service.OrderOutstandingReport(input, systemContext)
+	if err != nil {
+		utils.SendErrorResponse(c, err)
+		return
+	}
+
+	utils.SendSuccessResponse(c, result)
+}
+
+func GoodsReceiptAPIInit(r *gin.Engine) {
+	// Goods receipt routes - Protected with tenant auth middleware
+	goodsReceiptGroup := r.Group("/api/v1/goods-receipt")
+	goodsReceiptGroup.Use(middleware.JWTAuthMiddleware())
+	{
+		goodsReceiptGroup.POST("", goodsReceiptCreateHandler)
+		goodsReceiptGroup.GET("/:id", goodsReceiptGetHandler)
+		goodsReceiptGroup.POST("/list", goodsReceiptListHandler)
+		goodsReceiptGroup.POST("/outstanding", goodsReceiptOutstandingHandler)
+	}
+}
@@ -0,0 +1,220 @@
+package controller
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"renotech.com.my/internal/enum"
+	"renotech.com.my/internal/middleware"
+	"renotech.com.my/internal/model"
+	"renotech.com.my/internal/service"
+	"renotech.com.my/internal/utils"
+)
+
+func invoiceCreateHandler(c *gin.Context) {
+	systemContext := utils.GetSystemContextFromGin(c)
+	systemContext.Logger.Info("Invoice creation started", zap.String("endpoint", "/api/v1/invoice"))
+	defer systemContext.Logger.Info("Invoice creation completed")
+
+	var input model.InvoiceCreateRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.SendErrorResponse(c, utils.SystemError(
+			enum.ErrorCodeValidation,
+			"Invalid request data",
+			map[string]interface{}{"details": err.Error()},
+		))
+		return
+	}
+
+	result, err := service.InvoiceCreate(&input, systemContext)
+	if err != nil {
+		systemContext.Logger.Error("Invoice creation failed", zap.Error(err))
+		utils.SendErrorResponse(c, err)
+		return
+	}
+
+	systemContext.Logger.Info("Invoice creation successful",
+		zap.String("invoiceID", result.ID.Hex()),
+		zap.String("invoiceNumber", result.InvoiceNumber),
+		zap.Stringer("total", result.Total),
+	)
+
+	utils.SendSuccessResponse(c, result)
+}
+
+func invoiceGetHandler(c *gin.Context) {
+	systemContext := utils.GetSystemContextFromGin(c)
+
+	invoiceID, err := utils.ValidateObjectID(c.Param("id"))
+	if err != nil {
+		utils.SendErrorResponse(c, err)
+		return
+	}
+
+	result, err := service.InvoiceGetByID(invoiceID, systemContext)
+	if err != nil {
+		utils.SendErrorResponse(c, err)
+		return
+	}
+
+	utils.SendSuccessResponse(c, result)
+}
+
+func invoiceListHandler(c *gin.Context) {
+	systemContext := utils.GetSystemContextFromGin(c)
+
+	var input model.InvoiceListRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.SendErrorResponse(c, utils.SystemError(
+			enum.ErrorCodeValidation,
+			"Invalid request data",
+			map[string]interface{}{"details": err.Error()},
+		))
+		return
+	}
+
+	result, err := service.InvoiceList(input, systemContext)
+	if err != nil {
+		utils.SendErrorResponse(c, err)
+		return
+	}
+
+	utils.SendSuccessResponse(c, result)
+}
+
+func invoiceUpdateHandler(c *gin.Context) {
+	systemContext := utils.GetSystemContextFromGin(c)
+	systemContext.Logger.Info("Invoice update started", zap.String("endpoint", "/api/v1/invoice"))
+	defer systemContext.Logger.Info("Invoice update completed")
+
+	var input model.InvoiceUpdateRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.SendErrorResponse(c, utils.SystemError(
+			enum.ErrorCodeValidation,
+			"Invalid request data",
+			map[string]interface{}{"details": err.Error()},
+		))
+		return
+	}
+
+	result, err := service.InvoiceUpdate(&input, systemContext)
+	if err != nil {
+		systemContext.Logger.Error("Invoice update failed", zap.Error(err))
+		utils.SendErrorResponse(c, err)
+		return
+	}
+
+	systemContext.Logger.Info("Invoice update successful",
+		zap.String("invoiceID", result.ID.Hex()),
+	)
+
+	utils.SendSuccessResponse(c, result)
+}
+
+func invoiceDeleteHandler(c *gin.Context) {
+	systemContext := utils.GetSystemContextFromGin(c)
+	systemContext.Logger.Info("Invoice deletion started", zap.String("endpoint", "/api/v1/invoice/:id"))
+	defer systemContext.Logger.Info("Invoice deletion completed")
+
+	invoiceID, err := utils.ValidateObjectID(c.Param("id"))
+	if err != nil {
+		utils.SendErrorResponse(c, err)
+		return
+	}
+
+	if err := service.InvoiceDelete(invoiceID, systemContext); err != nil {
+		systemContext.Logger.Error("Invoice deletion failed", zap.Error(err))
+		utils.SendErrorResponse(c, err)
+		return
+	}
+
+	systemContext.Logger.Info("Invoice deletion successful",
+		zap.String("invoiceID", invoiceID.Hex()),
+	)
+
+	utils.SendSuccessMessageResponse(c, "Invoice deleted successfully")
+}
+
+func invoiceStatusUpdateHandler(c *gin.Context) {
+	systemContext := utils.GetSystemContextFromGin(c)
+	systemContext.Logger.Info("Invoice status update started", zap.String("endpoint", "/api/v1/invoice/:id/status"))
+	defer systemContext.Logger.Info("Invoice status update completed")
+
+	invoiceID, err := utils.ValidateObjectID(c.Param("id"))
+	if err != nil {
+		utils.SendErrorResponse(c, err)
+		return
+	}
+
+	var input model.InvoiceStatusUpdateRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.SendErrorResponse(c, utils.SystemError(
+			enum.ErrorCodeValidation,
+			"Invalid request data",
+			map[string]interface{}{"details": err.Error()},
+		))
+		return
+	}
+
+	result, err := service.InvoiceStatusUpdate(invoiceID, &input, systemContext)
+	if err != nil {
+		systemContext.Logger.Error("Invoice status update failed", zap.Error(err))
+		utils.SendErrorResponse(c, err)
+		return
+	}
+
+	systemContext.Logger.Info("Invoice status update successful",
+		zap.String("invoiceID", invoiceID.Hex()),
+		zap.String("status", string(result.Status)),
+	)
+
+	utils.SendSuccessResponse(c, result)
+}
+
+func invoicePDFHandler(c *gin.Context) {
+	systemContext := utils.GetSystemContextFromGin(c)
+	systemContext.Logger.Info("Invoice PDF generation started", zap.String("endpoint", "/api/v1/invoice/:id/pdf"))
+	defer systemContext.Logger.Info("Invoice PDF generation completed")
+
+	invoiceID, err := utils.ValidateObjectID(c.Param("id"))
+	if err != nil {
+		utils.SendErrorResponse(c, err)
+		return
+	}
+
+	pdfBuffer, filename, err := service.InvoiceGeneratePDF(invoiceID, systemContext)
+	if err != nil {
+		systemContext.Logger.Error("Invoice PDF generation failed", zap.Error(err))
+		utils.SendErrorResponse(c, err)
+		return
+	}
+
+	systemContext.Logger.Info("Invoice PDF generation successful",
+		zap.String("invoiceID", invoiceID.Hex()),
+		zap.String("filename", filename),
+		zap.Int("pdfSize", len(pdfBuffer)),
+	)
+
+	c.Header("Content-Type", "application/pdf")
+	c.Header("Content-Disposition", "attachment; filename=\""+filename+"\"")
+	c.Header("Content-Length", strconv.Itoa(len(pdfBuffer)))
+
+	c.Data(http.StatusOK, "application/pdf", pdfBuffer)
+}
+
+func InvoiceAPIInit(r *gin.Engine) {
+	// Invoice routes - Protected with tenant auth middleware
+	invoiceGroup := r.Group("/api/v1/invoice")
+	invoiceGroup.Use(middleware.JWTAuthMiddleware())
+	{
+		invoiceGroup.POST("", invoiceCreateHandler)
+		invoiceGroup.GET("/:id", invoiceGetHandler)
+		invoiceGroup.GET("/:id/pdf", invoicePDFHandler)
+		invoiceGroup.POST("/list", invoiceListHandler)
+		invoiceGroup.PUT("", invoiceUpdateHandler)
+		invoiceGroup.PATCH("/:id/status", invoiceStatusUpdateHandler)
+		invoiceGroup.DELETE("/:id", invoiceDeleteHandler)
+	}
+}
@@ -0,0 +1,451 @@
+package controller
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"renotech.com.my/internal/enum"
+	"renotech.com.my/internal/middleware"
+	"renotech.com.my/internal/model"
+	"renotech.com.my/internal/service"
+	"renotech.com.my/internal/utils"
+)
+
+// Tenant handlers
+func orderCreateHandler(c *gin.Context) {
+	ctx := utils.GetSystemContextFromGin(c)
+	ctx.Logger.Info("Order creation started", zap.String("endpoint", "/api/v1/order"))
+	defer ctx.Logger.Info("Order creation completed")
+
+	var input model.OrderCreateRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.SendErrorResponse(c, utils.SystemError(
+			enum.ErrorCodeValidation,
+			"Invalid request data",
+			map[string]interface{}{"details": err.Error()},
+		))
+		return
+	}
+
+	result, err := service.OrderCreate(&input, ctx)
+	if err != nil {
+		ctx.Logger.Error("Order creation failed", zap.Error(err))
+		utils.SendErrorResponse(c, err)
+		return
+	}
+
+	ctx.Logger.Info("Order creation successful",
+		zap.String("orderID", result.ID.Hex()),
+		zap.String("supplier", result.Supplier.Name),
+	)
+
+	utils.SendSuccessResponse(c, result)
+}
+
+func orderGetHandler(c *gin.Context) {
+	ctx := utils.GetSystemContextFromGin(c)
+
+	orderID, err := utils.ValidateObjectID(c.Param("id"))
+	if err != nil {
+		utils.SendErrorResponse(c, err)
+		return
+	}
+
+	result, err := service.OrderGetByID(orderID, ctx)
+	if err != nil {
+		utils.SendErrorResponse(c, err)
+		return
+	}
+
+	utils.SendSuccessResponse(c, result)
+}
+
+func orderListHandler(c *gin.Context) {
+	systemContext := utils.GetSystemContextFromGin(c)
+
+	var input model.OrderListRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.SendErrorResponse(c, utils.SystemError(
+			enum.ErrorCodeValidation,
+			"Invalid request data",
+			map[string]interface{}{"details": err.Error()},
+		))
+		return
+	}
+
+	result, err := service.OrderList(input, systemContext)
+	if err != nil {
+		utils.SendErrorResponse(c, err)
+		return
+	}
+
+	utils.SendSuccessResponse(c, result)
+}
+
+func orderUpdateHandler(c *gin.Context) {
+	systemContext := utils.GetSystemContextFromGin(c)
+	systemContext.Logger.Info("Order update started", zap.String("endpoint", "/api/v1/order"))
+	defer systemContext.Logger.Info("Order update completed")
+
+	var input model.OrderUpdateRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.SendErrorResponse(c, utils.SystemError(
+			enum.ErrorCodeValidation,
+			"Invalid request data",
+			map[string]interface{}{"details": err.Error()},
+		))
+		return
+	}
+
+	result, err := service.OrderUpdate(&input, systemContext)
+	if err != nil {
+		systemContext.Logger.Error("Order update failed", zap.Error(err))
+		utils.SendErrorResponse(c, err)
+		return
+	}
+
+	systemContext.Logger.Info("Order update successful",
+		zap.String("orderID", result.ID.Hex()),
+		zap.String("supplier", result.Supplier.Name),
+	)
+
+	utils.SendSuccessResponse(c, result)
+}
+
+func orderStatusUpdateHandler(c *gin.Context) {
+	systemContext := utils.GetSystemContextFromGin(c)
+	systemContext.Logger.Info("Order status update started", zap.String("endpoint", "/api/v1/order/:id/status"))
+	defer systemContext.Logger.Info("Order status update completed")
+
+	orderID, err := utils.ValidateObjectID(c.Param("id"))
+	if err != nil {
+		utils.SendErrorResponse(c, err)
+		return
+	}
+
+	var input model.OrderStatusUpdateRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.SendErrorResponse(c, utils.SystemError(
+			enum.ErrorCodeValidation,
+			"Invalid request data",
+			map[string]interface{}{"details": err.Error()},
+		))
+		return
+	}
+
+	result, err := service.OrderStatusUpdate(orderID, &input, systemContext)
+	if err != nil {
+		systemContext.Logger.Error("Order status update failed", zap.Error(err))
+		utils.SendErrorResponse(c, err)
+		return
+	}
+
+	systemContext.Logger.Info("Order status update successful",
+		zap.String("orderID", orderID.Hex()),
+		zap.String("status", string(result.Status)),
+	)
+
+	utils.SendSuccessResponse(c, result)
+}
+
+func orderDeliveryProposalReviewHandler(c *gin.Context) {
+	systemContext := utils.GetSystemContextFromGin(c)
+	systemContext.Logger.Info("Order delivery proposal review started", zap.String("endpoint", "/api/v1/order/:id/delivery-proposal"))
+	defer systemContext.Logger.Info("Order delivery proposal review completed")
+
+	orderID, err := utils.ValidateObjectID(c.Param("id"))
+	if err != nil {
+		utils.SendErrorResponse(c, err)
+		return
+	}
+
+	var input model.OrderDeliveryProposalReviewRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.SendErrorResponse(c, utils.SystemError(
+			enum.ErrorCodeValidation,
+			"Invalid request data",
+			map[string]interface{}{"details": err.Error()},
+		))
+		return
+	}
+
+	result, err := service.OrderDeliveryProposalReview(orderID, &input, systemContext)
+	if err != nil {
+		systemContext.Logger.Error("Order delivery proposal review failed", zap.Error(err))
+		utils.SendErrorResponse(c, err)
+		return
+	}
+
+	systemContext.Logger.Info("Order delivery proposal review successful",
+		zap.String("orderID", orderID.Hex()),
+		zap.String("proposalStatus", string(input.Status)),
+	)
+
+	utils.SendSuccessResponse(c, result)
+}
+
+func orderPDFHandler(c *gin.Context) {
+	systemContext := utils.GetSystemContextFromGin(c)
+	systemContext.Logger.Info("Order PDF generation started", zap.String("endpoint", "/api/v1/order/:id/pdf"))
+	defer systemContext.Logger.Info("Order PDF generation completed")
+
+	orderID, err := utils.ValidateObjectID(c.Param("id"))
+	if err != nil {
+		utils.SendErrorResponse(c, err)
+		return
+	}
+
+	pdfBuffer, filename, err := service.OrderGeneratePDF(orderID, systemContext)
+	if err != nil {
+		systemContext.Logger.Error("Order PDF generation failed", zap.Error(err))
+		utils.SendErrorResponse(c, err)
+		return
+	}
+
+	systemContext.Logger.Info("Order PDF generation successful",
+		zap.String("orderID", orderID.Hex()),
+		zap.String("filename", filename),
+		zap.Int("pdfSize", len(pdfBuffer)),
+	)
+
+	c.Header("Content-Type", "application/pdf")
+	c.Header("Content-Disposition", "attachment; filename=\""+filename+"\"")
+	c.Header("Content-Length", strconv.Itoa(len(pdfBuffer)))
+
+	c.Data(http.StatusOK, "application/pdf", pdfBuffer)
+}
+
+func orderEmailHandler(c *gin.Context) {
+	systemContext := utils.GetSystemContextFromGin(c)
+	systemContext.Logger.Info("Order email started", zap.String("endpoint", "/api/v1/order/:id/email"))
+	defer systemContext.Logger.Info("Order email completed")
+
+	orderID, err := utils.ValidateObjectID(c.Param("id"))
+	if err != nil {
+		utils.SendErrorResponse(c, err)
+		return
+	}
+
+	var input model.DocumentEmailRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.SendErrorResponse(c, utils.SystemError(
+			enum.ErrorCodeValidation,
+			"Invalid request data",
+			map[string]interface{}{"details": err.Error()},
+		))
+		return
+	}
+
+	result, err := service.OrderSendEmail(orderID, &input, systemContext)
+	if err != nil {
+		systemContext.Logger.Error("Order email failed", zap.Error(err))
+		utils.SendErrorResponse(c, err)
+		return
+	}
+
+	systemContext.Logger.Info("Order email successful",
+		zap.String("orderID", orderID.Hex()),
+		zap.Strings("to", result.To),
+	)
+
+	utils.SendSuccessResponse(c, result)
+}
+
+func orderDeleteHandler(c *gin.Context) {
+	systemContext := utils.GetSystemContextFromGin(c)
+	systemContext.Logger.Info("Order deletion started", zap.String("endpoint", "/api/v1/order/:id"))
+	defer systemContext.Logger.Info("Order deletion completed")
+
+	orderID, err := utils.ValidateObjectID(c.Param("id"))
+	if err != nil {
+		utils.SendErrorResponse(c, err)
+		return
+	}
+
+	err = service.OrderDelete(orderID, systemContext)
+	if err != nil {
+		systemContext.Logger.Error("Order deletion failed", zap.Error(err))
+		utils.SendErrorResponse(c, err)
+		return
+	}
+
+	systemContext.Logger.Info("Order deletion successful",
+		zap.String("orderID", orderID.Hex()),
+	)
+
+	utils.SendSuccessMessageResponse(c, "Order deleted successfully")
+}
+
+func orderInitHandler(c *gin.Context) {
+	systemContext := utils.GetSystemContextFromGin(c)
+	systemContext.Logger.Info("Order initialisation started", zap.String("endpoint", "/api/v1/order/init"))
+	defer systemContext.Logger.Info("Order initialisation completed")
+
+	var input model.OrderInitRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.SendErrorResponse(c, utils.SystemError(
+			enum.ErrorCodeValidation,
+			"Invalid request data",
+			map[string]interface{}{"details": err.Error()},
+		))
+		return
+	}
+
+	result, err := service.OrderInitFromProject(&input, systemContext)
+	if err != nil {
+		systemContext.Logger.Error("Order initialisation failed", zap.Error(err))
+		utils.SendErrorResponse(c, err)
+		return
+	}
+
+	systemContext.Logger.Info("Order initialisation successful",
+		zap.String("projectID", input.ProjectID.Hex()),
+		zap.Int("orders", result.Summary.TotalOrders),
+		zap.Bool("preview", input.Preview),
+	)
+
+	utils.SendSuccessResponse(c, result)
+}
+
+func orderShareCreateHandler(c *gin.Context) {
+	systemContext := utils.GetSystemContextFromGin(c)
+	systemContext.Logger.Info("Order share link creation started", zap.String("endpoint", "/api/v1/order/:id/share"))
+	defer systemContext.Logger.Info("Order share link creation completed")
+
+	orderID, err := utils.ValidateObjectID(c.Param("id"))
+	if err != nil {
+		utils.SendErrorResponse(c, err)
+		return
+	}
+
+	var input model.DocumentShareCreateRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.SendErrorResponse(c, utils.SystemError(
+			enum.ErrorCodeValidation,
+			"Invalid request data",
+			map[string]interface{}{"details": err.Error()},
+		))
+		return
+	}
+
+	result, err := service.OrderShareCreate(orderID, &input, systemContext)
+	if err != nil {
+		systemContext.Logger.Error("Order share link creation failed", zap.Error(err))
+		utils.SendErrorResponse(c, err)
+		return
+	}
+
+	systemContext.Logger.Info("Order share link creation successful",
+		zap.String("orderID", orderID.Hex()),
+		zap.String("shareID", result.ID.Hex()),
+	)
+
+	utils.SendSuccessResponse(c, result)
+}
+
+func orderShareRevokeHandler(c *gin.Context) {
+	systemContext := utils.GetSystemContextFromGin(c)
+	systemContext.Logger.Info("Order share link revocation started", zap.String("endpoint", "/api/v1/order/:id/share"))
+	defer systemContext.Logger.Info("Order share link revocation completed")
+
+	orderID, err := utils.ValidateObjectID(c.Param("id"))
+	if err != nil {
+		utils.SendErrorResponse(c, err)
+		return
+	}
+
+	err = service.OrderShareRevoke(orderID, systemContext)
+	if err != nil {
+		systemContext.Logger.Error("Order share link revocation failed", zap.Error(err))
+		utils.SendErrorResponse(c, err)
+		return
+	}
+
+	utils.SendSuccessMessageResponse(c, "Supplier links revoked successfully")
+}
+
+// Public handlers, authorised by share token
+func orderSupplierGetHandler(c *gin.Context) {
+	systemContext := utils.GetSystemContextFromGin(c)
+
+	result, err := service.OrderSupplierGet(c.Param("token"), systemContext)
+	if err != nil {
+		utils.SendErrorResponse(c, err)
+		return
+	}
+
+	utils.SendSuccessResponse(c, result)
+}
+
+func orderSupplierPreviewHandler(c *gin.Context) {
+	systemContext := utils.GetSystemContextFromGin(c)
+
+	html, err := service.OrderSupplierPreview(c.Param("token"), systemContext)
+	if err != nil {
+		utils.SendErrorResponse(c, err)
+		return
+	}
+
+	c.Header("Content-Type", "text/html; charset=utf-8")
+	c.String(http.StatusOK, html)
+}
+
+func orderSupplierRespondHandler(c *gin.Context) {
+	systemContext := utils.GetSystemContextFromGin(c)
+	systemContext.Logger.Info("Supplier order response started", zap.String("endpoint", "/api/v1/public/order/:token/respond"))
+	defer systemContext.Logger.Info("Supplier order response completed")
+
+	var input model.OrderSupplierRespondRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.SendErrorResponse(c, utils.SystemError(
+			enum.ErrorCodeValidation,
+			"Invalid request data",
+			map[string]interface{}{"details": err.Error()},
+		))
+		return
+	}
+
+	result, err := service.OrderSupplierRespond(c.Param("token"), &input, systemContext)
+	if err != nil {
+		systemContext.Logger.Error("Supplier order response failed", zap.Error(err))
+		utils.SendErrorResponse(c, err)
+		return
+	}
+
+	systemContext.Logger.Info("Supplier order response successful",
+		zap.String("poNumber", result.PONumber),
+		zap.String("action", string(input.Action)),
+	)
+
+	utils.SendSuccessResponse(c, result)
+}
+
+func OrderAPIInit(r *gin.Engine) {
+	// Order routes - Protected with tenant auth middleware
+	orderGroup := r.Group("/api/v1/order")
+	orderGroup.Use(middleware.JWTAuthMiddleware())
+	{
+		orderGroup.POST("", orderCreateHandler)
+		orderGroup.POST("/init", orderInitHandler)
+		orderGroup.GET("/:id", orderGetHandler)
+		orderGroup.GET("/:id/pdf", orderPDFHandler)
+		orderGroup.POST("/:id/email", orderEmailHandler)
+		orderGroup.POST("/list", orderListHandler)
+		orderGroup.PUT("", orderUpdateHandler)
+		orderGroup.PATCH("/:id/status", orderStatusUpdateHandler)
+		orderGroup.POST("/:id/delivery-proposal", orderDeliveryProposalReviewHandler)
+		orderGroup.DELETE("/:id", orderDeleteHandler)
+		orderGroup.POST("/:id/share", orderShareCreateHandler)
+		orderGroup.DELETE("/:id/share", orderShareRevokeHandler)
+	}
+
+	// Supplier self-service routes - Public, authorised by share token
+	publicOrderGroup := r.Group("/api/v1/public/order")
+	{
+		publicOrderGroup.GET("/:token", orderSupplierGetHandler)
+		publicOrderGroup.GET("/:token/preview", orderSupplierPreviewHandler)
+		publicOrderGroup.POST("/:token/respond", orderSupplierRespondHandler)
+	}
+}
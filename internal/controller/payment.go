@@ -0,0 +1,249 @@
+package controller
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"renotech.com.my/internal/enum"
+	"renotech.com.my/internal/middleware"
+	"renotech.com.my/internal/model"
+	"renotech.com.my/internal/service"
+	"renotech.com.my/internal/utils"
+)
+
+func paymentCreateHandler(c *gin.Context) {
+	systemContext := utils.GetSystemContextFromGin(c)
+	systemContext.Logger.Info("Payment recording started", zap.String("endpoint", "/api/v1/payment"))
+	defer systemContext.Logger.Info("Payment recording completed")
+
+	var input model.PaymentCreateRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.SendErrorResponse(c, utils.SystemError(
+			enum.ErrorCodeValidation,
+			"Invalid request data",
+			map[string]interface{}{"details": err.Error()},
+		))
+		return
+	}
+
+	result, err := service.PaymentCreate(&input, systemContext)
+	if err != nil {
+		systemContext.Logger.Error("Payment recording failed", zap.Error(err))
+		utils.SendErrorResponse(c, err)
+		return
+	}
+
+	systemContext.Logger.Info("Payment recording successful",
+		zap.String("paymentID", result.ID.Hex()),
+		zap.String("receiptNumber", result.ReceiptNumber),
+		zap.Stringer("amount", result.Amount),
+		zap.Stringer("unallocatedAmount", result.UnallocatedAmount),
+	)
+
+	utils.SendSuccessResponse(c, result)
+}
+
+func paymentGetHandler(c *gin.Context) {
+	systemContext := utils.GetSystemContextFromGin(c)
+
+	paymentID, err := utils.ValidateObjectID(c.Param("id"))
+	if err != nil {
+		utils.SendErrorResponse(c, err)
+		return
+	}
+
+	result, err := service.PaymentGetByID(paymentID, systemContext)
+	if err != nil {
+		utils.SendErrorResponse(c, err)
+		return
+	}
+
+	utils.SendSuccessResponse(c, result)
+}
+
+func paymentListHandler(c *gin.Context) {
+	systemContext := utils.GetSystemContextFromGin(c)
+
+	var input model.PaymentListRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.SendErrorResponse(c, utils.SystemError(
+			enum.ErrorCodeValidation,
+			"Invalid request data",
+			map[string]interface{}{"details": err.Error()},
+		))
+		return
+	}
+
+	result, err := service.PaymentList(input, systemContext)
+	if err != nil {
+		utils.SendErrorResponse(c, err)
+		return
+	}
+
+	utils.SendSuccessResponse(c, result)
+}
+
+func paymentAllocateHandler(c *gin.Context) {
+	systemContext := utils.GetSystemContextFromGin(c)
+	systemContext.Logger.Info("Payment allocation started", zap.String("endpoint", "/api/v1/payment/:id/allocate"))
+	defer systemContext.Logger.Info("Payment allocation completed")
+
+	paymentID, err := utils.ValidateObjectID(c.Param("id"))
+	if err != nil {
+		utils.SendErrorResponse(c, err)
+		return
+	}
+
+	var input model.PaymentAllocateRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.SendErrorResponse(c, utils.SystemError(
+			enum.ErrorCodeValidation,
+			"Invalid request data",
+			map[string]interface{}{"details": err.Error()},
+		))
+		return
+	}
+
+	result, err := service.PaymentAllocate(paymentID, &input, systemContext)
+	if err != nil {
+		systemContext.Logger.Error("Payment allocation failed", zap.Error(err))
+		utils.SendErrorResponse(c, err)
+		return
+	}
+
+	systemContext.Logger.Info("Payment allocation successful",
+		zap.String("paymentID", paymentID.Hex()),
+		zap.Stringer("unallocatedAmount", result.UnallocatedAmount),
+	)
+
+	utils.SendSuccessResponse(c, result)
+}
+
+func paymentVoidHandler(c *gin.Context) {
+	systemContext := utils.GetSystemContextFromGin(c)
+	systemContext.Logger.Info("Payment void started", zap.String("endpoint", "/api/v1/payment/:id/void"))
+	defer systemContext.Logger.Info("Payment void completed")
+
+	paymentID, err := utils.ValidateObjectID(c.Param("id"))
+	if err != nil {
+		utils.SendErrorResponse(c, err)
+		return
+	}
+
+	var input model.PaymentVoidRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.SendErrorResponse(c, utils.SystemError(
+			enum.ErrorCodeValidation,
+			"Invalid request data",
+			map[string]interface{}{"details": err.Error()},
+		))
+		return
+	}
+
+	result, err := service.PaymentVoid(paymentID, &input, systemContext)
+	if err != nil {
+		systemContext.Logger.Error("Payment void failed", zap.Error(err))
+		utils.SendErrorResponse(c, err)
+		return
+	}
+
+	systemContext.Logger.Info("Payment void successful",
+		zap.String("paymentID", paymentID.Hex()),
+		zap.String("receiptNumber", result.ReceiptNumber),
+	)
+
+	utils.SendSuccessResponse(c, result)
+}
+
+func paymentBalanceHandler(c *gin.Context) {
+	systemContext := utils.GetSystemContextFromGin(c)
+
+	var input model.PaymentBalanceRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.SendErrorResponse(c, utils.SystemError(
+			enum.ErrorCodeValidation,
+			"Invalid request data",
+			map[string]interface{}{"details": err.Error()},
+		))
+		return
+	}
+
+	result, err := service.PaymentBalance(&input, systemContext)
+	if err != nil {
+		utils.SendErrorResponse(c, err)
+		return
+	}
+
+	utils.SendSuccessResponse(c, result)
+}
+
+func paymentAgingHandler(c *gin.Context) {
+	systemContext := utils.GetSystemContextFromGin(c)
+
+	var input model.PaymentAgingRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.SendErrorResponse(c, utils.SystemError(
+			enum.ErrorCodeValidation,
+			"Invalid request data",
+			map[string]interface{}{"details": err.Error()},
+		))
+		return
+	}
+
+	result, err := service.PaymentAging(&input, systemContext)
+	if err != nil {
+		utils.SendErrorResponse(c, err)
+		return
+	}
+
+	utils.SendSuccessResponse(c, result)
+}
+
+func paymentReceiptHandler(c *gin.Context) {
+	systemContext := utils.GetSystemContextFromGin(c)
+	systemContext.Logger.Info("Payment receipt generation started", zap.String("endpoint", "/api/v1/payment/:id/receipt"))
+	defer systemContext.Logger.Info("Payment receipt generation completed")
+
+	paymentID, err := utils.ValidateObjectID(c.Param("id"))
+	if err != nil {
+		utils.SendErrorResponse(c, err)
+		return
+	}
+
+	pdfBuffer, filename, err := service.PaymentGenerateReceipt(paymentID, systemContext)
+	if err != nil {
+		systemContext.Logger.Error("Payment receipt generation failed", zap.Error(err))
+		utils.SendErrorResponse(c, err)
+		return
+	}
+
+	systemContext.Logger.Info("Payment receipt generation successful",
+		zap.String("paymentID", paymentID.Hex()),
+		zap.String("filename", filename),
+		zap.Int("pdfSize", len(pdfBuffer)),
+	)
+
+	c.Header("Content-Type", "application/pdf")
+	c.Header("Content-Disposition", "attachment; filename=\""+filename+"\"")
+	c.Header("Content-Length", strconv.Itoa(len(pdfBuffer)))
+
+	c.Data(http.StatusOK, "application/pdf", pdfBuffer)
+}
+
+func PaymentAPIInit(r *gin.Engine) {
+	// Payment routes - Protected with tenant auth middleware
+	paymentGroup := r.Group("/api/v1/payment")
+	paymentGroup.Use(middleware.JWTAuthMiddleware())
+	{
+		paymentGroup.POST("", paymentCreateHandler)
+		paymentGroup.GET("/:id", paymentGetHandler)
+		paymentGroup.GET("/:id/receipt", paymentReceiptHandler)
+		paymentGroup.POST("/list", paymentListHandler)
+		paymentGroup.POST("/balance", paymentBalanceHandler)
+		paymentGroup.POST("/aging", paymentAgingHandler)
+		paymentGroup.PATCH("/:id/allocate", paymentAllocateHandler)
+		paymentGroup.PATCH("/:id/void", paymentVoidHandler)
+	}
+}
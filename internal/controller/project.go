@@ -0,0 +1,514 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"renotech.com.my/internal/enum"
+	"renotech.com.my/internal/middleware"
+	"renotech.com.my/internal/model"
+	"renotech.com.my/internal/service"
+	"renotech.com.my/internal/utils"
+)
+
+func projectCreateFromQuotationHandler(c *gin.Context) {
+	systemContext := utils.GetSystemContextFromGin(c)
+	systemContext.Logger.Info("Project creation started", zap.String("endpoint", "/api/v1/project/from-quotation"))
+	defer systemContext.Logger.Info("Project creation completed")
+
+	var input model.ProjectCreateRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.SendErrorResponse(c, utils.SystemError(
+			enum.ErrorCodeValidation,
+			"Invalid request data",
+			map[string]interface{}{"details": err.Error()},
+		))
+		return
+	}
+
+	result, err := service.ProjectCreateFromQuotation(&input, systemContext)
+	if err != nil {
+		systemContext.Logger.Error("Project creation failed", zap.Error(err))
+		utils.SendErrorResponse(c, err)
+		return
+	}
+
+	systemContext.Logger.Info("Project creation successful",
+		zap.String("projectID", result.ID.Hex()),
+		zap.String("quotationID", result.Quotation.Hex()),
+	)
+
+	utils.SendSuccessResponse(c, result)
+}
+
+func projectGetHandler(c *gin.Context) {
+	systemContext := utils.GetSystemContextFromGin(c)
+
+	projectID, err := utils.ValidateObjectID(c.Param("id"))
+	if err != nil {
+		utils.SendErrorResponse(c, err)
+		return
+	}
+
+	result, err := service.ProjectGetByID(projectID, systemContext)
+	if err != nil {
+		utils.SendErrorResponse(c, err)
+		return
+	}
+
+	utils.SendSuccessResponse(c, result)
+}
+
+func projectListHandler(c *gin.Context) {
+	systemContext := utils.GetSystemContextFromGin(c)
+
+	var input model.ProjectListRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.SendErrorResponse(c, utils.SystemError(
+			enum.ErrorCodeValidation,
+			"Invalid request data",
+			map[string]interface{}{"details": err.Error()},
+		))
+		return
+	}
+
+	result, err := service.ProjectList(input, systemContext)
+	if err != nil {
+		utils.SendErrorResponse(c, err)
+		return
+	}
+
+	utils.SendSuccessResponse(c, result)
+}
+
+func projectListMineHandler(c *gin.Context) {
+	systemContext := utils.GetSystemContextFromGin(c)
+
+	var input model.ProjectListRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.SendErrorResponse(c, utils.SystemError(
+			enum.ErrorCodeValidation,
+			"Invalid request data",
+			map[string]interface{}{"details": err.Error()},
+		))
+		return
+	}
+
+	result, err := service.ProjectListMine(input, systemContext)
+	if err != nil {
+		utils.SendErrorResponse(c, err)
+		return
+	}
+
+	utils.SendSuccessResponse(c, result)
+}
+
+func projectAssignPICHandler(c *gin.Context) {
+	systemContext := utils.GetSystemContextFromGin(c)
+	systemContext.Logger.Info("Project PIC assignment started", zap.String("endpoint", "/api/v1/project/:id/pic/assign"))
+	defer systemContext.Logger.Info("Project PIC assignment completed")
+
+	projectID, err := utils.ValidateObjectID(c.Param("id"))
+	if err != nil {
+		utils.SendErrorResponse(c, err)
+		return
+	}
+
+	var input model.ProjectPICRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.SendErrorResponse(c, utils.SystemError(
+			enum.ErrorCodeValidation,
+			"Invalid request data",
+			map[string]interface{}{"details": err.Error()},
+		))
+		return
+	}
+
+	result, err := service.ProjectAssignPIC(projectID, &input, systemContext)
+	if err != nil {
+		systemContext.Logger.Error("Project PIC assignment failed", zap.Error(err))
+		utils.SendErrorResponse(c, err)
+		return
+	}
+
+	systemContext.Logger.Info("Project PIC assignment successful",
+		zap.String("projectID", projectID.Hex()),
+		zap.Int("picCount", len(result.PIC)),
+	)
+
+	utils.SendSuccessResponse(c, result)
+}
+
+func projectUnassignPICHandler(c *gin.Context) {
+	systemContext := utils.GetSystemContextFromGin(c)
+	systemContext.Logger.Info("Project PIC unassignment started", zap.String("endpoint", "/api/v1/project/:id/pic/unassign"))
+	defer systemContext.Logger.Info("Project PIC unassignment completed")
+
+	projectID, err := utils.ValidateObjectID(c.Param("id"))
+	if err != nil {
+		utils.SendErrorResponse(c, err)
+		return
+	}
+
+	var input model.ProjectPICRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.SendErrorResponse(c, utils.SystemError(
+			enum.ErrorCodeValidation,
+			"Invalid request data",
+			map[string]interface{}{"details": err.Error()},
+		))
+		return
+	}
+
+	result, err := service.ProjectUnassignPIC(projectID, &input, systemContext)
+	if err != nil {
+		systemContext.Logger.Error("Project PIC unassignment failed", zap.Error(err))
+		utils.SendErrorResponse(c, err)
+		return
+	}
+
+	systemContext.Logger.Info("Project PIC unassignment successful",
+		zap.String("projectID", projectID.Hex()),
+		zap.Int("picCount", len(result.PIC)),
+	)
+
+	utils.SendSuccessResponse(c, result)
+}
+
+func projectUpdateHandler(c *gin.Context) {
+	systemContext := utils.GetSystemContextFromGin(c)
+	systemContext.Logger.Info("Project update started", zap.String("endpoint", "/api/v1/project"))
+	defer systemContext.Logger.Info("Project update completed")
+
+	var input model.ProjectUpdateRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.SendErrorResponse(c, utils.SystemError(
+			enum.ErrorCodeValidation,
+			"Invalid request data",
+			map[string]interface{}{"details": err.Error()},
+		))
+		return
+	}
+
+	result, err := service.ProjectUpdate(&input, systemContext)
+	if err != nil {
+		systemContext.Logger.Error("Project update failed", zap.Error(err))
+		utils.SendErrorResponse(c, err)
+		return
+	}
+
+	systemContext.Logger.Info("Project update successful",
+		zap.String("projectID", result.ID.Hex()),
+		zap.String("name", result.Name),
+	)
+
+	utils.SendSuccessResponse(c, result)
+}
+
+func projectDeleteHandler(c *gin.Context) {
+	systemContext := utils.GetSystemContextFromGin(c)
+	systemContext.Logger.Info("Project deletion started", zap.String("endpoint", "/api/v1/project/:id"))
+	defer systemContext.Logger.Info("Project deletion completed")
+
+	projectID, err := utils.ValidateObjectID(c.Param("id"))
+	if err != nil {
+		utils.SendErrorResponse(c, err)
+		return
+	}
+
+	if err := service.ProjectDelete(projectID, systemContext); err != nil {
+		systemContext.Logger.Error("Project deletion failed", zap.Error(err))
+		utils.SendErrorResponse(c, err)
+		return
+	}
+
+	systemContext.Logger.Info("Project deletion successful",
+		zap.String("projectID", projectID.Hex()),
+	)
+
+	utils.SendSuccessMessageResponse(c, "Project deleted successfully")
+}
+
+func projectToggleStarHandler(c *gin.Context) {
+	systemContext := utils.GetSystemContextFromGin(c)
+	systemContext.Logger.Info("Project star toggle started", zap.String("endpoint", "/api/v1/project/:id/star"))
+	defer systemContext.Logger.Info("Project star toggle completed")
+
+	projectID, err := utils.ValidateObjectID(c.Param("id"))
+	if err != nil {
+		utils.SendErrorResponse(c, err)
+		return
+	}
+
+	var input model.ProjectToggleStarRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.SendErrorResponse(c, utils.SystemError(
+			enum.ErrorCodeValidation,
+			"Invalid request data",
+			map[string]interface{}{"details": err.Error()},
+		))
+		return
+	}
+
+	result, err := service.ProjectToggleStar(projectID, input.IsStared, systemContext)
+	if err != nil {
+		systemContext.Logger.Error("Project star toggle failed", zap.Error(err))
+		utils.SendErrorResponse(c, err)
+		return
+	}
+
+	systemContext.Logger.Info("Project star toggle successful",
+		zap.String("projectID", projectID.Hex()),
+		zap.Bool("isStared", result.IsStared),
+	)
+
+	utils.SendSuccessResponse(c, result)
+}
+
+func projectCostReportHandler(c *gin.Context) {
+	systemContext := utils.GetSystemContextFromGin(c)
+
+	projectID, err := utils.ValidateObjectID(c.Param("id"))
+	if err != nil {
+		utils.SendErrorResponse(c, err)
+		return
+	}
+
+	result, err := service.ProjectCostReport(projectID, systemContext)
+	if err != nil {
+		utils.SendErrorResponse(c, err)
+		return
+	}
+
+	utils.SendSuccessResponse(c, result)
+}
+
+func projectRetentionGetHandler(c *gin.Context) {
+	systemContext := utils.GetSystemContextFromGin(c)
+
+	projectID, err := utils.ValidateObjectID(c.Param("id"))
+	if err != nil {
+		utils.SendErrorResponse(c, err)
+		return
+	}
+
+	result, err := service.ProjectRetentionLedger(projectID, systemContext)
+	if err != nil {
+		utils.SendErrorResponse(c, err)
+		return
+	}
+
+	utils.SendSuccessResponse(c, result)
+}
+
+func projectRetentionUpdateHandler(c *gin.Context) {
+	systemContext := utils.GetSystemContextFromGin(c)
+	systemContext.Logger.Info("Project retention update started", zap.String("endpoint", "/api/v1/project/:id/retention"))
+	defer systemContext.Logger.Info("Project retention update completed")
+
+	projectID, err := utils.ValidateObjectID(c.Param("id"))
+	if err != nil {
+		utils.SendErrorResponse(c, err)
+		return
+	}
+
+	var input model.ProjectRetentionUpdateRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.SendErrorResponse(c, utils.SystemError(
+			enum.ErrorCodeValidation,
+			"Invalid request data",
+			map[string]interface{}{"details": err.Error()},
+		))
+		return
+	}
+
+	result, err := service.ProjectRetentionUpdate(projectID, &input, systemContext)
+	if err != nil {
+		systemContext.Logger.Error("Project retention update failed", zap.Error(err))
+		utils.SendErrorResponse(c, err)
+		return
+	}
+
+	systemContext.Logger.Info("Project retention update successful",
+		zap.String("projectID", projectID.Hex()),
+		zap.Float64("retentionPercent", result.RetentionPercent),
+	)
+
+	utils.SendSuccessResponse(c, result)
+}
+
+func projectCompletionHandler(c *gin.Context) {
+	systemContext := utils.GetSystemContextFromGin(c)
+	systemContext.Logger.Info("Project completion started", zap.String("endpoint", "/api/v1/project/:id/completion"))
+	defer systemContext.Logger.Info("Project completion completed")
+
+	projectID, err := utils.ValidateObjectID(c.Param("id"))
+	if err != nil {
+		utils.SendErrorResponse(c, err)
+		return
+	}
+
+	var input model.ProjectCompletionRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.SendErrorResponse(c, utils.SystemError(
+			enum.ErrorCodeValidation,
+			"Invalid request data",
+			map[string]interface{}{"details": err.Error()},
+		))
+		return
+	}
+
+	result, err := service.ProjectCompletionRecord(projectID, &input, systemContext)
+	if err != nil {
+		systemContext.Logger.Error("Project completion failed", zap.Error(err))
+		utils.SendErrorResponse(c, err)
+		return
+	}
+
+	systemContext.Logger.Info("Project completion successful",
+		zap.String("projectID", projectID.Hex()),
+		zap.Time("completedAt", *result.CompletedAt),
+	)
+
+	utils.SendSuccessResponse(c, result)
+}
+
+func projectGanttHandler(c *gin.Context) {
+	systemContext := utils.GetSystemContextFromGin(c)
+
+	projectID, err := utils.ValidateObjectID(c.Param("id"))
+	if err != nil {
+		utils.SendErrorResponse(c, err)
+		return
+	}
+
+	result, err := service.ProjectGantt(projectID, systemContext)
+	if err != nil {
+		utils.SendErrorResponse(c, err)
+		return
+	}
+
+	utils.SendSuccessResponse(c, result)
+}
+
+func projectScheduleICSHandler(c *gin.Context) {
+	systemContext := utils.GetSystemContextFromGin(c)
+
+	projectID, err := utils.ValidateObjectID(c.Param("id"))
+	if err != nil {
+		utils.SendErrorResponse(c, err)
+		return
+	}
+
+	calendar, filename, err := service.ProjectScheduleICS(projectID, systemContext)
+	if err != nil {
+		utils.SendErrorResponse(c, err)
+		return
+	}
+
+	c.Header("Content-Disposition", "attachment; filename=\""+filename+"\"")
+	c.Data(http.StatusOK, "text/calendar; charset=utf-8", calendar)
+}
+
+func projectCalendarShareCreateHandler(c *gin.Context) {
+	systemContext := utils.GetSystemContextFromGin(c)
+	systemContext.Logger.Info("Project calendar link creation started", zap.String("endpoint", "/api/v1/project/:id/calendar-share"))
+	defer systemContext.Logger.Info("Project calendar link creation completed")
+
+	projectID, err := utils.ValidateObjectID(c.Param("id"))
+	if err != nil {
+		utils.SendErrorResponse(c, err)
+		return
+	}
+
+	var input model.DocumentShareCreateRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.SendErrorResponse(c, utils.SystemError(
+			enum.ErrorCodeValidation,
+			"Invalid request data",
+			map[string]interface{}{"details": err.Error()},
+		))
+		return
+	}
+
+	result, err := service.ProjectCalendarShareCreate(projectID, &input, systemContext)
+	if err != nil {
+		systemContext.Logger.Error("Project calendar link creation failed", zap.Error(err))
+		utils.SendErrorResponse(c, err)
+		return
+	}
+
+	systemContext.Logger.Info("Project calendar link creation successful",
+		zap.String("projectID", projectID.Hex()),
+		zap.String("shareID", result.ID.Hex()),
+	)
+
+	utils.SendSuccessResponse(c, result)
+}
+
+func projectCalendarShareRevokeHandler(c *gin.Context) {
+	systemContext := utils.GetSystemContextFromGin(c)
+	systemContext.Logger.Info("Project calendar link revocation started", zap.String("endpoint", "/api/v1/project/:id/calendar-share"))
+	defer systemContext.Logger.Info("Project calendar link revocation completed")
+
+	projectID, err := utils.ValidateObjectID(c.Param("id"))
+	if err != nil {
+		utils.SendErrorResponse(c, err)
+		return
+	}
+
+	err = service.ProjectCalendarShareRevoke(projectID, systemContext)
+	if err != nil {
+		systemContext.Logger.Error("Project calendar link revocation failed", zap.Error(err))
+		utils.SendErrorResponse(c, err)
+		return
+	}
+
+	utils.SendSuccessMessageResponse(c, "Calendar links revoked successfully")
+}
+
+// Public handlers, authorised by share token
+func projectCalendarFeedHandler(c *gin.Context) {
+	systemContext := utils.GetSystemContextFromGin(c)
+
+	calendar, filename, err := service.ProjectCalendarFeed(c.Param("token"), systemContext)
+	if err != nil {
+		utils.SendErrorResponse(c, err)
+		return
+	}
+
+	c.Header("Content-Disposition", "inline; filename=\""+filename+"\"")
+	c.Data(http.StatusOK, "text/calendar; charset=utf-8", calendar)
+}
+
+func ProjectAPIInit(r *gin.Engine) {
+	// Project routes - Protected with tenant auth middleware
+	projectGroup := r.Group("/api/v1/project")
+	projectGroup.Use(middleware.JWTAuthMiddleware())
+	{
+		projectGroup.POST("/from-quotation", projectCreateFromQuotationHandler)
+		projectGroup.GET("/:id", projectGetHandler)
+		projectGroup.GET("/:id/cost-report", projectCostReportHandler)
+		projectGroup.GET("/:id/retention", projectRetentionGetHandler)
+		projectGroup.GET("/:id/gantt", projectGanttHandler)
+		projectGroup.GET("/:id/schedule.ics", projectScheduleICSHandler)
+		projectGroup.POST("/:id/calendar-share", projectCalendarShareCreateHandler)
+		projectGroup.DELETE("/:id/calendar-share", projectCalendarShareRevokeHandler)
+		projectGroup.POST("/list", projectListHandler)
+		projectGroup.POST("/mine", projectListMineHandler)
+		projectGroup.PUT("", projectUpdateHandler)
+		projectGroup.DELETE("/:id", projectDeleteHandler)
+		projectGroup.PATCH("/:id/star", projectToggleStarHandler)
+		projectGroup.PATCH("/:id/pic/assign", projectAssignPICHandler)
+		projectGroup.PATCH("/:id/pic/unassign", projectUnassignPICHandler)
+		projectGroup.PATCH("/:id/retention", projectRetentionUpdateHandler)
+		projectGroup.PATCH("/:id/completion", projectCompletionHandler)
+	}
+
+	// Calendar feed routes - Public, authorised by share token
+	publicProjectGroup := r.Group("/api/v1/public/project")
+	{
+		publicProjectGroup.GET("/:token/schedule.ics", projectCalendarFeedHandler)
+	}
+}
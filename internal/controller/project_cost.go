@@ -0,0 +1,148 @@
+package controller
+
+import (
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"renotech.com.my/internal/enum"
+	"renotech.com.my/internal/middleware"
+	"renotech.com.my/internal/model"
+	"renotech.com.my/internal/service"
+	"renotech.com.my/internal/utils"
+)
+
+func projectCostCreateHandler(c *gin.Context) {
+	systemContext := utils.GetSystemContextFromGin(c)
+	systemContext.Logger.Info("Project cost creation started", zap.String("endpoint", "/api/v1/project-cost"))
+	defer systemContext.Logger.Info("Project cost creation completed")
+
+	var input model.ProjectCostCreateRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.SendErrorResponse(c, utils.SystemError(
+			enum.ErrorCodeValidation,
+			"Invalid request data",
+			map[string]interface{}{"details": err.Error()},
+		))
+		return
+	}
+
+	result, err := service.ProjectCostCreate(&input, systemContext)
+	if err != nil {
+		systemContext.Logger.Error("Project cost creation failed", zap.Error(err))
+		utils.SendErrorResponse(c, err)
+		return
+	}
+
+	systemContext.Logger.Info("Project cost creation successful",
+		zap.String("projectCostID", result.ID.Hex()),
+		zap.String("projectID", result.Project.Hex()),
+		zap.Stringer("amount", result.Amount),
+	)
+
+	utils.SendSuccessResponse(c, result)
+}
+
+func projectCostGetHandler(c *gin.Context) {
+	systemContext := utils.GetSystemContextFromGin(c)
+
+	costID, err := utils.ValidateObjectID(c.Param("id"))
+	if err != nil {
+		utils.SendErrorResponse(c, err)
+		return
+	}
+
+	result, err := service.ProjectCostGetByID(costID, systemContext)
+	if err != nil {
+		utils.SendErrorResponse(c, err)
+		return
+	}
+
+	utils.SendSuccessResponse(c, result)
+}
+
+func projectCostListHandler(c *gin.Context) {
+	systemContext := utils.GetSystemContextFromGin(c)
+
+	var input model.ProjectCostListRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.SendErrorResponse(c, utils.SystemError(
+			enum.ErrorCodeValidation,
+			"Invalid request data",
+			map[string]interface{}{"details": err.Error()},
+		))
+		return
+	}
+
+	result, err := service.ProjectCostList(input, systemContext)
+	if err != nil {
+		utils.SendErrorResponse(c, err)
+		return
+	}
+
+	utils.SendSuccessResponse(c, result)
+}
+
+func projectCostUpdateHandler(c *gin.Context) {
+	systemContext := utils.GetSystemContextFromGin(c)
+	systemContext.Logger.Info("Project cost update started", zap.String("endpoint", "/api/v1/project-cost"))
+	defer systemContext.Logger.Info("Project cost update completed")
+
+	var input model.ProjectCostUpdateRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.SendErrorResponse(c, utils.SystemError(
+			enum.ErrorCodeValidation,
+			"Invalid request data",
+			map[string]interface{}{"details": err.Error()},
+		))
+		return
+	}
+
+	result, err := service.ProjectCostUpdate(&input, systemContext)
+	if err != nil {
+		systemContext.Logger.Error("Project cost update failed", zap.Error(err))
+		utils.SendErrorResponse(c, err)
+		return
+	}
+
+	systemContext.Logger.Info("Project cost update successful",
+		zap.String("projectCostID", result.ID.Hex()),
+	)
+
+	utils.SendSuccessResponse(c, result)
+}
+
+func projectCostDeleteHandler(c *gin.Context) {
+	systemContext := utils.GetSystemContextFromGin(c)
+	systemContext.Logger.Info("Project cost deletion started", zap.String("endpoint", "/api/v1/project-cost/:id"))
+	defer systemContext.Logger.Info("Project cost deletion completed")
+
+	costID, err := utils.ValidateObjectID(c.Param("id"))
+	if err != nil {
+		utils.SendErrorResponse(c, err)
+		return
+	}
+
+	if err := service.ProjectCostDelete(costID, systemContext); err != nil {
+		systemContext.Logger.Error("Project cost deletion failed", zap.Error(err))
+		utils.SendErrorResponse(c, err)
+		return
+	}
+
+	systemContext.Logger.Info("Project cost deletion successful",
+		zap.String("projectCostID", costID.Hex()),
+	)
+
+	utils.SendSuccessMessageResponse(c, "Project cost deleted successfully")
+}
+
+func ProjectCostAPIInit(r *gin.Engine) {
+	// Project cost routes - Protected with tenant auth middleware
+	projectCostGroup := r.Group("/api/v1/project-cost")
+	projectCostGroup.Use(middleware.JWTAuthMiddleware())
+	{
+		projectCostGroup.POST("", projectCostCreateHandler)
+		projectCostGroup.GET("/:id", projectCostGetHandler)
+		projectCostGroup.POST("/list", projectCostListHandler)
+		projectCostGroup.PUT("", projectCostUpdateHandler)
+		projectCostGroup.DELETE("/:id", projectCostDeleteHandler)
+	}
+}
@@ -0,0 +1,148 @@
+package controller
+
+import (
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"renotech.com.my/internal/enum"
+	"renotech.com.my/internal/middleware"
+	"renotech.com.my/internal/model"
+	"renotech.com.my/internal/service"
+	"renotech.com.my/internal/utils"
+)
+
+func projectTaskCreateHandler(c *gin.Context) {
+	systemContext := utils.GetSystemContextFromGin(c)
+	systemContext.Logger.Info("Project task creation started", zap.String("endpoint", "/api/v1/project-task"))
+	defer systemContext.Logger.Info("Project task creation completed")
+
+	var input model.ProjectTaskCreateRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.SendErrorResponse(c, utils.SystemError(
+			enum.ErrorCodeValidation,
+			"Invalid request data",
+			map[string]interface{}{"details": err.Error()},
+		))
+		return
+	}
+
+	result, err := service.ProjectTaskCreate(&input, systemContext)
+	if err != nil {
+		systemContext.Logger.Error("Project task creation failed", zap.Error(err))
+		utils.SendErrorResponse(c, err)
+		return
+	}
+
+	systemContext.Logger.Info("Project task creation successful",
+		zap.String("projectTaskID", result.ID.Hex()),
+		zap.String("projectID", result.Project.Hex()),
+		zap.String("name", result.Name),
+	)
+
+	utils.SendSuccessResponse(c, result)
+}
+
+func projectTaskGetHandler(c *gin.Context) {
+	systemContext := utils.GetSystemContextFromGin(c)
+
+	taskID, err := utils.ValidateObjectID(c.Param("id"))
+	if err != nil {
+		utils.SendErrorResponse(c, err)
+		return
+	}
+
+	result, err := service.ProjectTaskGetByID(taskID, systemContext)
+	if err != nil {
+		utils.SendErrorResponse(c, err)
+		return
+	}
+
+	utils.SendSuccessResponse(c, result)
+}
+
+func projectTaskListHandler(c *gin.Context) {
+	systemContext := utils.GetSystemContextFromGin(c)
+
+	var input model.ProjectTaskListRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.SendErrorResponse(c, utils.SystemError(
+			enum.ErrorCodeValidation,
+			"Invalid request data",
+			map[string]interface{}{"details": err.Error()},
+		))
+		return
+	}
+
+	result, err := service.ProjectTaskList(input, systemContext)
+	if err != nil {
+		utils.SendErrorResponse(c, err)
+		return
+	}
+
+	utils.SendSuccessResponse(c, result)
+}
+
+func projectTaskUpdateHandler(c *gin.Context) {
+	systemContext := utils.GetSystemContextFromGin(c)
+	systemContext.Logger.Info("Project task update started", zap.String("endpoint", "/api/v1/project-task"))
+	defer systemContext.Logger.Info("Project task update completed")
+
+	var input model.ProjectTaskUpdateRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.SendErrorResponse(c, utils.SystemError(
+			enum.ErrorCodeValidation,
+			"Invalid request data",
+			map[string]interface{}{"details": err.Error()},
+		))
+		return
+	}
+
+	result, err := service.ProjectTaskUpdate(&input, systemContext)
+	if err != nil {
+		systemContext.Logger.Error("Project task update failed", zap.Error(err))
+		utils.SendErrorResponse(c, err)
+		return
+	}
+
+	systemContext.Logger.Info("Project task update successful",
+		zap.String("projectTaskID", result.ID.Hex()),
+	)
+
+	utils.SendSuccessResponse(c, result)
+}
+
+func projectTaskDeleteHandler(c *gin.Context) {
+	systemContext := utils.GetSystemContextFromGin(c)
+	systemContext.Logger.Info("Project task deletion started", zap.String("endpoint", "/api/v1/project-task/:id"))
+	defer systemContext.Logger.Info("Project task deletion completed")
+
+	taskID, err := utils.ValidateObjectID(c.Param("id"))
+	if err != nil {
+		utils.SendErrorResponse(c, err)
+		return
+	}
+
+	if err := service.ProjectTaskDelete(taskID, systemContext); err != nil {
+		systemContext.Logger.Error("Project task deletion failed", zap.Error(err))
+		utils.SendErrorResponse(c, err)
+		return
+	}
+
+	systemContext.Logger.Info("Project task deletion successful",
+		zap.String("projectTaskID", taskID.Hex()),
+	)
+
+	utils.SendSuccessMessageResponse(c, "Project task deleted successfully")
+}
+
+func ProjectTaskAPIInit(r *gin.Engine) {
+	// Project task routes - Protected with tenant auth middleware
+	projectTaskGroup := r.Group("/api/v1/project-task")
+	projectTaskGroup.Use(middleware.JWTAuthMiddleware())
+	{
+		projectTaskGroup.POST("", projectTaskCreateHandler)
+		projectTaskGroup.GET("/:id", projectTaskGetHandler)
+		projectTaskGroup.POST("/list", projectTaskListHandler)
+		projectTaskGroup.PUT("", projectTaskUpdateHandler)
+		projectTaskGroup.DELETE("/:id", projectTaskDeleteHandler)
+	}
+}
@@ -1,6 +1,11 @@
 package controller
 
 import (
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+
 	"github.com/gin-gonic/gin"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.uber.org/zap"
@@ -37,6 +42,7 @@ func quotationCreateHandler(c *gin.Context) {
 	ctx.Logger.Info("Quotation creation successful",
 		zap.String("quotationID", result.ID.Hex()),
 		zap.String("name", result.Name),
+		zap.Int("pricingIssues", len(result.PricingReport.Issues)),
 	)
 
 	utils.SendSuccessResponse(c, result)
@@ -107,6 +113,7 @@ func quotationUpdateHandler(c *gin.Context) {
 	systemContext.Logger.Info("Quotation update successful",
 		zap.String("quotationID", result.ID.Hex()),
 		zap.String("name", result.Name),
+		zap.Int("pricingIssues", len(result.PricingReport.Issues)),
 	)
 
 	utils.SendSuccessResponse(c, result)
@@ -264,6 +271,493 @@ func quotationDuplicateHandler(c *gin.Context) {
 	utils.SendSuccessResponse(c, result)
 }
 
+func quotationImportHandler(c *gin.Context) {
+	systemContext := utils.GetSystemContextFromGin(c)
+	systemContext.Logger.Info("Quotation import started", zap.String("endpoint", "/api/v1/quotation/import/upload"))
+	defer systemContext.Logger.Info("Quotation import completed")
+
+	file, err := c.FormFile("file")
+	if err != nil {
+		utils.SendErrorResponse(c, utils.SystemError(
+			enum.ErrorCodeValidation,
+			"No file provided or invalid file",
+			nil,
+		))
+		return
+	}
+
+	if !middleware.IsFileTypeAllowed(file.Filename) {
+		utils.SendErrorResponse(c, utils.SystemError(
+			enum.ErrorCodeValidation,
+			"File type not allowed",
+			map[string]interface{}{"filename": file.Filename},
+		))
+		return
+	}
+
+	maxSize := int64(utils.GetEnvInt("MAX_FILE_SIZE", 5242880)) // 5MB default
+	if file.Size > maxSize {
+		utils.SendErrorResponse(c, utils.SystemError(
+			enum.ErrorCodeTooLarge,
+			fmt.Sprintf("File size (%d bytes) exceeds maximum allowed size (%d bytes)", file.Size, maxSize),
+			nil,
+		))
+		return
+	}
+
+	input := model.QuotationImportRequest{
+		FileName: file.Filename,
+		Name:     c.PostForm("name"),
+	}
+	if folder := c.PostForm("folder"); folder != "" {
+		folderID, err := utils.ValidateObjectID(folder)
+		if err != nil {
+			utils.SendErrorResponse(c, err)
+			return
+		}
+		input.Folder = &folderID
+	}
+
+	reader, err := file.Open()
+	if err != nil {
+		utils.SendErrorResponse(c, utils.SystemError(enum.ErrorCodeInternal, "Failed to read uploaded file", nil))
+		return
+	}
+	defer reader.Close()
+
+	content, err := io.ReadAll(io.LimitReader(reader, maxSize))
+	if err != nil {
+		utils.SendErrorResponse(c, utils.SystemError(enum.ErrorCodeInternal, "Failed to read uploaded file", nil))
+		return
+	}
+
+	result, err := service.QuotationImport(&input, content, systemContext)
+	if err != nil {
+		systemContext.Logger.Error("Quotation import failed", zap.Error(err))
+		utils.SendErrorResponse(c, err)
+		return
+	}
+
+	systemContext.Logger.Info("Quotation import successful",
+		zap.String("quotationID", result.Quotation.ID.Hex()),
+		zap.String("filename", file.Filename),
+		zap.Int("matched", result.Summary.Matched),
+		zap.Int("unmatched", result.Summary.Unmatched),
+		zap.Int("invalid", result.Summary.Invalid),
+	)
+
+	utils.SendSuccessResponse(c, result)
+}
+
+func quotationStatusUpdateHandler(c *gin.Context) {
+	systemContext := utils.GetSystemContextFromGin(c)
+	systemContext.Logger.Info("Quotation status update started", zap.String("endpoint", "/api/v1/quotation/:id/status"))
+	defer systemContext.Logger.Info("Quotation status update completed")
+
+	quotationID, err := utils.ValidateObjectID(c.Param("id"))
+	if err != nil {
+		utils.SendErrorResponse(c, err)
+		return
+	}
+
+	var input model.QuotationStatusUpdateRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.SendErrorResponse(c, utils.SystemError(
+			enum.ErrorCodeValidation,
+			"Invalid request data",
+			map[string]interface{}{"details": err.Error()},
+		))
+		return
+	}
+
+	result, err := service.QuotationStatusUpdate(quotationID, &input, systemContext)
+	if err != nil {
+		systemContext.Logger.Error("Quotation status update failed", zap.Error(err))
+		utils.SendErrorResponse(c, err)
+		return
+	}
+
+	systemContext.Logger.Info("Quotation status update successful",
+		zap.String("quotationID", quotationID.Hex()),
+		zap.String("status", string(result.Status)),
+	)
+
+	utils.SendSuccessResponse(c, result)
+}
+
+func quotationPDFHandler(c *gin.Context) {
+	systemContext := utils.GetSystemContextFromGin(c)
+	systemContext.Logger.Info("Quotation PDF generation started", zap.String("endpoint", "/api/v1/quotation/:id/pdf"))
+	defer systemContext.Logger.Info("Quotation PDF generation completed")
+
+	quotationID, err := utils.ValidateObjectID(c.Param("id"))
+	if err != nil {
+		utils.SendErrorResponse(c, err)
+		return
+	}
+
+	templateID, err := quotationTemplateParam(c.Query("template"))
+	if err != nil {
+		utils.SendErrorResponse(c, err)
+		return
+	}
+
+	pdfBuffer, filename, err := service.QuotationGeneratePDF(quotationID, templateID, systemContext)
+	if err != nil {
+		systemContext.Logger.Error("Quotation PDF generation failed", zap.Error(err))
+		utils.SendErrorResponse(c, err)
+		return
+	}
+
+	systemContext.Logger.Info("Quotation PDF generation successful",
+		zap.String("quotationID", quotationID.Hex()),
+		zap.String("filename", filename),
+		zap.Int("pdfSize", len(pdfBuffer)),
+	)
+
+	c.Header("Content-Type", "application/pdf")
+	c.Header("Content-Disposition", "attachment; filename=\""+filename+"\"")
+	c.Header("Content-Length", strconv.Itoa(len(pdfBuffer)))
+
+	c.Data(http.StatusOK, "application/pdf", pdfBuffer)
+}
+
+func quotationPreviewHandler(c *gin.Context) {
+	systemContext := utils.GetSystemContextFromGin(c)
+
+	quotationID, err := utils.ValidateObjectID(c.Param("id"))
+	if err != nil {
+		utils.SendErrorResponse(c, err)
+		return
+	}
+
+	templateID, err := quotationTemplateParam(c.Query("template"))
+	if err != nil {
+		utils.SendErrorResponse(c, err)
+		return
+	}
+
+	html, err := service.QuotationPreview(quotationID, templateID, systemContext)
+	if err != nil {
+		utils.SendErrorResponse(c, err)
+		return
+	}
+
+	c.Header("Content-Type", "text/html; charset=utf-8")
+	c.String(http.StatusOK, html)
+}
+
+func quotationAnalysisHandler(c *gin.Context) {
+	systemContext := utils.GetSystemContextFromGin(c)
+
+	quotationID, err := utils.ValidateObjectID(c.Param("id"))
+	if err != nil {
+		utils.SendErrorResponse(c, err)
+		return
+	}
+
+	result, err := service.QuotationAnalysis(quotationID, systemContext)
+	if err != nil {
+		utils.SendErrorResponse(c, err)
+		return
+	}
+
+	utils.SendSuccessResponse(c, result)
+}
+
+func quotationEmailHandler(c *gin.Context) {
+	systemContext := utils.GetSystemContextFromGin(c)
+	systemContext.Logger.Info("Quotation email started", zap.String("endpoint", "/api/v1/quotation/:id/email"))
+	defer systemContext.Logger.Info("Quotation email completed")
+
+	quotationID, err := utils.ValidateObjectID(c.Param("id"))
+	if err != nil {
+		utils.SendErrorResponse(c, err)
+		return
+	}
+
+	var input model.DocumentEmailRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.SendErrorResponse(c, utils.SystemError(
+			enum.ErrorCodeValidation,
+			"Invalid request data",
+			map[string]interface{}{"details": err.Error()},
+		))
+		return
+	}
+
+	result, err := service.QuotationSendEmail(quotationID, &input, systemContext)
+	if err != nil {
+		systemContext.Logger.Error("Quotation email failed", zap.Error(err))
+		utils.SendErrorResponse(c, err)
+		return
+	}
+
+	systemContext.Logger.Info("Quotation email successful",
+		zap.String("quotationID", quotationID.Hex()),
+		zap.Strings("to", result.To),
+	)
+
+	utils.SendSuccessResponse(c, result)
+}
+
+func quotationRevisionListHandler(c *gin.Context) {
+	systemContext := utils.GetSystemContextFromGin(c)
+
+	quotationID, err := utils.ValidateObjectID(c.Param("id"))
+	if err != nil {
+		utils.SendErrorResponse(c, err)
+		return
+	}
+
+	result, err := service.QuotationRevisionList(quotationID, systemContext)
+	if err != nil {
+		utils.SendErrorResponse(c, err)
+		return
+	}
+
+	utils.SendSuccessResponse(c, result)
+}
+
+func quotationRevisionGetHandler(c *gin.Context) {
+	systemContext := utils.GetSystemContextFromGin(c)
+
+	quotationID, err := utils.ValidateObjectID(c.Param("id"))
+	if err != nil {
+		utils.SendErrorResponse(c, err)
+		return
+	}
+
+	revision, err := quotationRevisionParam(c.Param("revision"))
+	if err != nil {
+		utils.SendErrorResponse(c, err)
+		return
+	}
+
+	result, err := service.QuotationRevisionGet(quotationID, revision, systemContext)
+	if err != nil {
+		utils.SendErrorResponse(c, err)
+		return
+	}
+
+	utils.SendSuccessResponse(c, result)
+}
+
+func quotationRevisionDiffHandler(c *gin.Context) {
+	systemContext := utils.GetSystemContextFromGin(c)
+
+	quotationID, err := utils.ValidateObjectID(c.Param("id"))
+	if err != nil {
+		utils.SendErrorResponse(c, err)
+		return
+	}
+
+	from, err := quotationRevisionParam(c.Query("from"))
+	if err != nil {
+		utils.SendErrorResponse(c, err)
+		return
+	}
+
+	// Compare against the current revision when no to revision is given
+	to := 0
+	if c.Query("to") != "" {
+		if to, err = quotationRevisionParam(c.Query("to")); err != nil {
+			utils.SendErrorResponse(c, err)
+			return
+		}
+	}
+
+	result, err := service.QuotationRevisionDiff(quotationID, from, to, systemContext)
+	if err != nil {
+		utils.SendErrorResponse(c, err)
+		return
+	}
+
+	utils.SendSuccessResponse(c, result)
+}
+
+func quotationRevisionRestoreHandler(c *gin.Context) {
+	systemContext := utils.GetSystemContextFromGin(c)
+	systemContext.Logger.Info("Quotation revision restore started", zap.String("endpoint", "/api/v1/quotation/:id/revision/:revision/restore"))
+	defer systemContext.Logger.Info("Quotation revision restore completed")
+
+	quotationID, err := utils.ValidateObjectID(c.Param("id"))
+	if err != nil {
+		utils.SendErrorResponse(c, err)
+		return
+	}
+
+	revision, err := quotationRevisionParam(c.Param("revision"))
+	if err != nil {
+		utils.SendErrorResponse(c, err)
+		return
+	}
+
+	var input model.QuotationRevisionRestoreRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.SendErrorResponse(c, utils.SystemError(
+			enum.ErrorCodeValidation,
+			"Invalid request data",
+			map[string]interface{}{"details": err.Error()},
+		))
+		return
+	}
+
+	result, err := service.QuotationRevisionRestore(quotationID, revision, &input, systemContext)
+	if err != nil {
+		systemContext.Logger.Error("Quotation revision restore failed", zap.Error(err))
+		utils.SendErrorResponse(c, err)
+		return
+	}
+
+	systemContext.Logger.Info("Quotation revision restore successful",
+		zap.String("quotationID", quotationID.Hex()),
+		zap.Int("restoredRevision", revision),
+		zap.Int("revision", result.Revision),
+	)
+
+	utils.SendSuccessResponse(c, result)
+}
+
+// quotationTemplateParam parses the optional quotation template ID from the query
+func quotationTemplateParam(value string) (*primitive.ObjectID, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	templateID, err := utils.ValidateObjectID(value)
+	if err != nil {
+		return nil, err
+	}
+	return &templateID, nil
+}
+
+// quotationRevisionParam parses a revision number from the path or query
+func quotationRevisionParam(value string) (int, error) {
+	revision, err := strconv.Atoi(value)
+	if err != nil || revision <= 0 {
+		return 0, utils.SystemError(
+			enum.ErrorCodeValidation,
+			"Invalid revision",
+			map[string]interface{}{"revision": value},
+		)
+	}
+	return revision, nil
+}
+
+func quotationShareCreateHandler(c *gin.Context) {
+	systemContext := utils.GetSystemContextFromGin(c)
+	systemContext.Logger.Info("Quotation share link creation started", zap.String("endpoint", "/api/v1/quotation/:id/share"))
+	defer systemContext.Logger.Info("Quotation share link creation completed")
+
+	quotationID, err := utils.ValidateObjectID(c.Param("id"))
+	if err != nil {
+		utils.SendErrorResponse(c, err)
+		return
+	}
+
+	var input model.DocumentShareCreateRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.SendErrorResponse(c, utils.SystemError(
+			enum.ErrorCodeValidation,
+			"Invalid request data",
+			map[string]interface{}{"details": err.Error()},
+		))
+		return
+	}
+
+	result, err := service.QuotationShareCreate(quotationID, &input, systemContext)
+	if err != nil {
+		systemContext.Logger.Error("Quotation share link creation failed", zap.Error(err))
+		utils.SendErrorResponse(c, err)
+		return
+	}
+
+	systemContext.Logger.Info("Quotation share link creation successful",
+		zap.String("quotationID", quotationID.Hex()),
+		zap.String("shareID", result.ID.Hex()),
+	)
+
+	utils.SendSuccessResponse(c, result)
+}
+
+func quotationShareRevokeHandler(c *gin.Context) {
+	systemContext := utils.GetSystemContextFromGin(c)
+	systemContext.Logger.Info("Quotation share link revocation started", zap.String("endpoint", "/api/v1/quotation/:id/share"))
+	defer systemContext.Logger.Info("Quotation share link revocation completed")
+
+	quotationID, err := utils.ValidateObjectID(c.Param("id"))
+	if err != nil {
+		utils.SendErrorResponse(c, err)
+		return
+	}
+
+	err = service.QuotationShareRevoke(quotationID, systemContext)
+	if err != nil {
+		systemContext.Logger.Error("Quotation share link revocation failed", zap.Error(err))
+		utils.SendErrorResponse(c, err)
+		return
+	}
+
+	utils.SendSuccessMessageResponse(c, "Client links revoked successfully")
+}
+
+// Public handlers, authorised by share token
+func quotationClientGetHandler(c *gin.Context) {
+	systemContext := utils.GetSystemContextFromGin(c)
+
+	result, err := service.QuotationClientGet(c.Param("token"), systemContext)
+	if err != nil {
+		utils.SendErrorResponse(c, err)
+		return
+	}
+
+	utils.SendSuccessResponse(c, result)
+}
+
+func quotationClientPreviewHandler(c *gin.Context) {
+	systemContext := utils.GetSystemContextFromGin(c)
+
+	html, err := service.QuotationClientPreview(c.Param("token"), systemContext)
+	if err != nil {
+		utils.SendErrorResponse(c, err)
+		return
+	}
+
+	c.Header("Content-Type", "text/html; charset=utf-8")
+	c.String(http.StatusOK, html)
+}
+
+func quotationClientRespondHandler(c *gin.Context) {
+	systemContext := utils.GetSystemContextFromGin(c)
+	systemContext.Logger.Info("Client quotation response started", zap.String("endpoint", "/api/v1/public/quotation/:token/respond"))
+	defer systemContext.Logger.Info("Client quotation response completed")
+
+	var input model.QuotationClientRespondRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.SendErrorResponse(c, utils.SystemError(
+			enum.ErrorCodeValidation,
+			"Invalid request data",
+			map[string]interface{}{"details": err.Error()},
+		))
+		return
+	}
+
+	result, err := service.QuotationClientRespond(c.Param("token"), &input, c.ClientIP(), systemContext)
+	if err != nil {
+		systemContext.Logger.Error("Client quotation response failed", zap.Error(err))
+		utils.SendErrorResponse(c, err)
+		return
+	}
+
+	systemContext.Logger.Info("Client quotation response successful",
+		zap.String("quotationNumber", result.QuotationNumber),
+		zap.String("action", string(input.Action)),
+	)
+
+	utils.SendSuccessResponse(c, result)
+}
+
 func QuotationAPIInit(r *gin.Engine) {
 	// Quotation routes - Protected with tenant auth middleware
 	quotationGroup := r.Group("/api/v1/quotation")
@@ -275,8 +769,28 @@ func QuotationAPIInit(r *gin.Engine) {
 		quotationGroup.PUT("", quotationUpdateHandler)
 		quotationGroup.DELETE("/:id", quotationDeleteHandler)
 		quotationGroup.PATCH("/:id/star", quotationToggleStarHandler)
+		quotationGroup.PATCH("/:id/status", quotationStatusUpdateHandler)
+		quotationGroup.GET("/:id/pdf", quotationPDFHandler)
+		quotationGroup.GET("/:id/preview", quotationPreviewHandler)
+		quotationGroup.GET("/:id/analysis", quotationAnalysisHandler)
+		quotationGroup.POST("/:id/email", quotationEmailHandler)
 		quotationGroup.POST("/folder/create", quotationCreateFolderHandler)
 		quotationGroup.PATCH("/move", quotationMoveHandler)
 		quotationGroup.POST("/duplicate", quotationDuplicateHandler)
+		quotationGroup.POST("/import/upload", quotationImportHandler) // Multipart, the /upload suffix lets file uploads through request validation
+		quotationGroup.GET("/:id/revision", quotationRevisionListHandler)
+		quotationGroup.GET("/:id/revision/diff", quotationRevisionDiffHandler)
+		quotationGroup.GET("/:id/revision/:revision", quotationRevisionGetHandler)
+		quotationGroup.POST("/:id/revision/:revision/restore", quotationRevisionRestoreHandler)
+		quotationGroup.POST("/:id/share", quotationShareCreateHandler)
+		quotationGroup.DELETE("/:id/share", quotationShareRevokeHandler)
+	}
+
+	// Client self-service routes - Public, authorised by share token
+	publicQuotationGroup := r.Group("/api/v1/public/quotation")
+	{
+		publicQuotationGroup.GET("/:token", quotationClientGetHandler)
+		publicQuotationGroup.GET("/:token/preview", quotationClientPreviewHandler)
+		publicQuotationGroup.POST("/:token/respond", quotationClientRespondHandler)
 	}
 }
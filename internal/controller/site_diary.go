@@ -0,0 +1,148 @@
+package controller
+
+import (
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"renotech.com.my/internal/enum"
+	"renotech.com.my/internal/middleware"
+	"renotech.com.my/internal/model"
+	"renotech.com.my/internal/service"
+	"renotech.com.my/internal/utils"
+)
+
+func siteDiaryCreateHandler(c *gin.Context) {
+	systemContext := utils.GetSystemContextFromGin(c)
+	systemContext.Logger.Info("Site diary creation started", zap.String("endpoint", "/api/v1/site-diary"))
+	defer systemContext.Logger.Info("Site diary creation completed")
+
+	var input model.SiteDiaryCreateRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.SendErrorResponse(c, utils.SystemError(
+			enum.ErrorCodeValidation,
+			"Invalid request data",
+			map[string]interface{}{"details": err.Error()},
+		))
+		return
+	}
+
+	result, err := service.SiteDiaryCreate(&input, systemContext)
+	if err != nil {
+		systemContext.Logger.Error("Site diary creation failed", zap.Error(err))
+		utils.SendErrorResponse(c, err)
+		return
+	}
+
+	systemContext.Logger.Info("Site diary creation successful",
+		zap.String("siteDiaryID", result.ID.Hex()),
+		zap.String("projectID", result.Project.Hex()),
+		zap.Time("date", result.Date),
+	)
+
+	utils.SendSuccessResponse(c, result)
+}
+
+func siteDiaryGetHandler(c *gin.Context) {
+	systemContext := utils.GetSystemContextFromGin(c)
+
+	diaryID, err := utils.ValidateObjectID(c.Param("id"))
+	if err != nil {
+		utils.SendErrorResponse(c, err)
+		return
+	}
+
+	result, err := service.SiteDiaryGetByID(diaryID, systemContext)
+	if err != nil {
+		utils.SendErrorResponse(c, err)
+		return
+	}
+
+	utils.SendSuccessResponse(c, result)
+}
+
+func siteDiaryFeedHandler(c *gin.Context) {
+	systemContext := utils.GetSystemContextFromGin(c)
+
+	var input model.SiteDiaryFeedRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.SendErrorResponse(c, utils.SystemError(
+			enum.ErrorCodeValidation,
+			"Invalid request data",
+			map[string]interface{}{"details": err.Error()},
+		))
+		return
+	}
+
+	result, err := service.SiteDiaryFeed(input, systemContext)
+	if err != nil {
+		utils.SendErrorResponse(c, err)
+		return
+	}
+
+	utils.SendSuccessResponse(c, result)
+}
+
+func siteDiaryUpdateHandler(c *gin.Context) {
+	systemContext := utils.GetSystemContextFromGin(c)
+	systemContext.Logger.Info("Site diary update started", zap.String("endpoint", "/api/v1/site-diary"))
+	defer systemContext.Logger.Info("Site diary update completed")
+
+	var input model.SiteDiaryUpdateRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.SendErrorResponse(c, utils.SystemError(
+			enum.ErrorCodeValidation,
+			"Invalid request data",
+			map[string]interface{}{"details": err.Error()},
+		))
+		return
+	}
+
+	result, err := service.SiteDiaryUpdate(&input, systemContext)
+	if err != nil {
+		systemContext.Logger.Error("Site diary update failed", zap.Error(err))
+		utils.SendErrorResponse(c, err)
+		return
+	}
+
+	systemContext.Logger.Info("Site diary update successful",
+		zap.String("siteDiaryID", result.ID.Hex()),
+	)
+
+	utils.SendSuccessResponse(c, result)
+}
+
+func siteDiaryDeleteHandler(c *gin.Context) {
+	systemContext := utils.GetSystemContextFromGin(c)
+	systemContext.Logger.Info("Site diary deletion started", zap.String("endpoint", "/api/v1/site-diary/:id"))
+	defer systemContext.Logger.Info("Site diary deletion completed")
+
+	diaryID, err := utils.ValidateObjectID(c.Param("id"))
+	if err != nil {
+		utils.SendErrorResponse(c, err)
+		return
+	}
+
+	if err := service.SiteDiaryDelete(diaryID, systemContext); err != nil {
+		systemContext.Logger.Error("Site diary deletion failed", zap.Error(err))
+		utils.SendErrorResponse(c, err)
+		return
+	}
+
+	systemContext.Logger.Info("Site diary deletion successful",
+		zap.String("siteDiaryID", diaryID.Hex()),
+	)
+
+	utils.SendSuccessMessageResponse(c, "Site diary deleted successfully")
+}
+
+func SiteDiaryAPIInit(r *gin.Engine) {
+	// Site diary routes - Protected with tenant auth middleware
+	siteDiaryGroup := r.Group("/api/v1/site-diary")
+	siteDiaryGroup.Use(middleware.JWTAuthMiddleware())
+	{
+		siteDiaryGroup.POST("", siteDiaryCreateHandler)
+		siteDiaryGroup.GET("/:id", siteDiaryGetHandler)
+		siteDiaryGroup.POST("/feed", siteDiaryFeedHandler)
+		siteDiaryGroup.PUT("", siteDiaryUpdateHandler)
+		siteDiaryGroup.DELETE("/:id", siteDiaryDeleteHandler)
+	}
+}
@@ -0,0 +1,220 @@
+package controller
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"renotech.com.my/internal/enum"
+	"renotech.com.my/internal/middleware"
+	"renotech.com.my/internal/model"
+	"renotech.com.my/internal/service"
+	"renotech.com.my/internal/utils"
+)
+
+func variationOrderCreateHandler(c *gin.Context) {
+	systemContext := utils.GetSystemContextFromGin(c)
+	systemContext.Logger.Info("Variation order creation started", zap.String("endpoint", "/api/v1/variation-order"))
+	defer systemContext.Logger.Info("Variation order creation completed")
+
+	var input model.VariationOrderCreateRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.SendErrorResponse(c, utils.SystemError(
+			enum.ErrorCodeValidation,
+			"Invalid request data",
+			map[string]interface{}{"details": err.Error()},
+		))
+		return
+	}
+
+	result, err := service.VariationOrderCreate(&input, systemContext)
+	if err != nil {
+		systemContext.Logger.Error("Variation order creation failed", zap.Error(err))
+		utils.SendErrorResponse(c, err)
+		return
+	}
+
+	systemContext.Logger.Info("Variation order creation successful",
+		zap.String("variationOrderID", result.ID.Hex()),
+		zap.String("voNumber", result.VONumber),
+		zap.Stringer("netAmount", result.NetAmount),
+	)
+
+	utils.SendSuccessResponse(c, result)
+}
+
+func variationOrderGetHandler(c *gin.Context) {
+	systemContext := utils.GetSystemContextFromGin(c)
+
+	variationOrderID, err := utils.ValidateObjectID(c.Param("id"))
+	if err != nil {
+		utils.SendErrorResponse(c, err)
+		return
+	}
+
+	result, err := service.VariationOrderGetByID(variationOrderID, systemContext)
+	if err != nil {
+		utils.SendErrorResponse(c, err)
+		return
+	}
+
+	utils.SendSuccessResponse(c, result)
+}
+
+func variationOrderListHandler(c *gin.Context) {
+	systemContext := utils.GetSystemContextFromGin(c)
+
+	var input model.VariationOrderListRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.SendErrorResponse(c, utils.SystemError(
+			enum.ErrorCodeValidation,
+			"Invalid request data",
+			map[string]interface{}{"details": err.Error()},
+		))
+		return
+	}
+
+	result, err := service.VariationOrderList(input, systemContext)
+	if err != nil {
+		utils.SendErrorResponse(c, err)
+		return
+	}
+
+	utils.SendSuccessResponse(c, result)
+}
+
+func variationOrderUpdateHandler(c *gin.Context) {
+	systemContext := utils.GetSystemContextFromGin(c)
+	systemContext.Logger.Info("Variation order update started", zap.String("endpoint", "/api/v1/variation-order"))
+	defer systemContext.Logger.Info("Variation order update completed")
+
+	var input model.VariationOrderUpdateRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.SendErrorResponse(c, utils.SystemError(
+			enum.ErrorCodeValidation,
+			"Invalid request data",
+			map[string]interface{}{"details": err.Error()},
+		))
+		return
+	}
+
+	result, err := service.VariationOrderUpdate(&input, systemContext)
+	if err != nil {
+		systemContext.Logger.Error("Variation order update failed", zap.Error(err))
+		utils.SendErrorResponse(c, err)
+		return
+	}
+
+	systemContext.Logger.Info("Variation order update successful",
+		zap.String("variationOrderID", result.ID.Hex()),
+	)
+
+	utils.SendSuccessResponse(c, result)
+}
+
+func variationOrderDeleteHandler(c *gin.Context) {
+	systemContext := utils.GetSystemContextFromGin(c)
+	systemContext.Logger.Info("Variation order deletion started", zap.String("endpoint", "/api/v1/variation-order/:id"))
+	defer systemContext.Logger.Info("Variation order deletion completed")
+
+	variationOrderID, err := utils.ValidateObjectID(c.Param("id"))
+	if err != nil {
+		utils.SendErrorResponse(c, err)
+		return
+	}
+
+	if err := service.VariationOrderDelete(variationOrderID, systemContext); err != nil {
+		systemContext.Logger.Error("Variation order deletion failed", zap.Error(err))
+		utils.SendErrorResponse(c, err)
+		return
+	}
+
+	systemContext.Logger.Info("Variation order deletion successful",
+		zap.String("variationOrderID", variationOrderID.Hex()),
+	)
+
+	utils.SendSuccessMessageResponse(c, "Variation order deleted successfully")
+}
+
+func variationOrderStatusUpdateHandler(c *gin.Context) {
+	systemContext := utils.GetSystemContextFromGin(c)
+	systemContext.Logger.Info("Variation order status update started", zap.String("endpoint", "/api/v1/variation-order/:id/status"))
+	defer systemContext.Logger.Info("Variation order status update completed")
+
+	variationOrderID, err := utils.ValidateObjectID(c.Param("id"))
+	if err != nil {
+		utils.SendErrorResponse(c, err)
+		return
+	}
+
+	var input model.VariationOrderStatusUpdateRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		utils.SendErrorResponse(c, utils.SystemError(
+			enum.ErrorCodeValidation,
+			"Invalid request data",
+			map[string]interface{}{"details": err.Error()},
+		))
+		return
+	}
+
+	result, err := service.VariationOrderStatusUpdate(variationOrderID, &input, systemContext)
+	if err != nil {
+		systemContext.Logger.Error("Variation order status update failed", zap.Error(err))
+		utils.SendErrorResponse(c, err)
+		return
+	}
+
+	systemContext.Logger.Info("Variation order status update successful",
+		zap.String("variationOrderID", variationOrderID.Hex()),
+		zap.String("status", string(result.Status)),
+	)
+
+	utils.SendSuccessResponse(c, result)
+}
+
+func variationOrderPDFHandler(c *gin.Context) {
+	systemContext := utils.GetSystemContextFromGin(c)
+	systemContext.Logger.Info("Variation order PDF generation started", zap.String("endpoint", "/api/v1/variation-order/:id/pdf"))
+	defer systemContext.Logger.Info("Variation order PDF generation completed")
+
+	variationOrderID, err := utils.ValidateObjectID(c.Param("id"))
+	if err != nil {
+		utils.SendErrorResponse(c, err)
+		return
+	}
+
+	pdfBuffer, filename, err := service.VariationOrderGeneratePDF(variationOrderID, systemContext)
+	if err != nil {
+		systemContext.Logger.Error("Variation order PDF generation failed", zap.Error(err))
+		utils.SendErrorResponse(c, err)
+		return
+	}
+
+	systemContext.Logger.Info("Variation order PDF generation successful",
+		zap.String("variationOrderID", variationOrderID.Hex()),
+		zap.String("filename", filename),
+		zap.Int("pdfSize", len(pdfBuffer)),
+	)
+
+	c.Header("Content-Type", "application/pdf")
+	c.Header("Content-Disposition", "attachment; filename=\""+filename+"\"")
+	c.Header("Content-Length", strconv.Itoa(len(pdfBuffer)))
+
+	c.Data(http.StatusOK, "application/pdf", pdfBuffer)
+}
+
+func VariationOrderAPIInit(r *gin.Engine) {
+	// Variation order routes - Protected with tenant auth middleware
+	variationOrderGroup := r.Group("/api/v1/variation-order")
+	variationOrderGroup.Use(middleware.JWTAuthMiddleware())
+	{
+		variationOrderGroup.POST("", variationOrderCreateHandler)
+		variationOrderGroup.GET("/:id", variationOrderGetHandler)
+		variationOrderGroup.GET("/:id/pdf", variationOrderPDFHandler)
+		variationOrderGroup.POST("/list", variationOrderListHandler)
+		variationOrderGroup.PUT("", variationOrderUpdateHandler)
+		variationOrderGroup.PATCH("/:id/status", variationOrderStatusUpdateHandler)
+		variationOrderGroup.DELETE("/:id", variationOrderDeleteHandler)
+	}
+}
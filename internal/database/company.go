@@ -4,26 +4,29 @@ import (
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson/primitive"
+	"renotech.com.my/internal/enum"
 )
 
 type Company struct {
-	ID                  *primitive.ObjectID `bson:"_id,omitempty" json:"_id,omitempty"`
-	Name                string              `bson:"name" json:"name"`
-	ClientDisplayName   string              `bson:"clientDisplayName" json:"clientDisplayName"`
-	SupplierDisplayName string              `bson:"supplierDisplayName" json:"supplierDisplayName"`
-	Address             string              `bson:"address" json:"address"`
-	Website             string              `bson:"website" json:"website"`
-	Email               string              `bson:"email" json:"email"`
-	Description         string              `bson:"description" json:"description"`
-	Owner               *primitive.ObjectID `bson:"owner,omitempty" json:"owner,omitempty"`
-	Logo                string              `bson:"logo" json:"logo"`
-	RegistrationNo      string              `bson:"registrationNo" json:"registrationNo"`
-	Contact             string              `bson:"contact" json:"contact"`
-	TermCondition       []string            `bson:"termCondition" json:"termCondition"`
-	IsDeleted           bool                `bson:"isDeleted" json:"isDeleted"`
-	IsEnabled           bool                `bson:"isEnabled" json:"isEnabled"`
-	CreatedAt           time.Time           `bson:"createdAt" json:"createdAt"`
-	CreatedBy           *primitive.ObjectID `bson:"createdBy,omitempty" json:"createdBy,omitempty"`
-	UpdatedAt           time.Time           `bson:"updatedAt" json:"updatedAt"`
-	UpdatedBy           *primitive.ObjectID `bson:"updatedBy,omitempty" json:"updatedBy,omitempty"`
+	ID                   *primitive.ObjectID `bson:"_id,omitempty" json:"_id,omitempty"`
+	Name                 string              `bson:"name" json:"name"`
+	ClientDisplayName    string              `bson:"clientDisplayName" json:"clientDisplayName"`
+	SupplierDisplayName  string              `bson:"supplierDisplayName" json:"supplierDisplayName"`
+	Address              string              `bson:"address" json:"address"`
+	Website              string              `bson:"website" json:"website"`
+	Email                string              `bson:"email" json:"email"`
+	Description          string              `bson:"description" json:"description"`
+	Owner                *primitive.ObjectID `bson:"owner,omitempty" json:"owner,omitempty"`
+	Logo                 string              `bson:"logo" json:"logo"`
+	RegistrationNo       string              `bson:"registrationNo" json:"registrationNo"`
+	Contact              string              `bson:"contact" json:"contact"`
+	TermCondition        []string            `bson:"termCondition" json:"termCondition"`
+	MoneyRounding        enum.MoneyRounding  `bson:"moneyRounding" json:"moneyRounding"`               // Defaults to line rounding
+	MinimumMarginPercent float64             `bson:"minimumMarginPercent" json:"minimumMarginPercent"` // Quotation lines below this margin are flagged, 0 turns the check off
+	IsDeleted            bool                `bson:"isDeleted" json:"isDeleted"`
+	IsEnabled            bool                `bson:"isEnabled" json:"isEnabled"`
+	CreatedAt            time.Time           `bson:"createdAt" json:"createdAt"`
+	CreatedBy            *primitive.ObjectID `bson:"createdBy,omitempty" json:"createdBy,omitempty"`
+	UpdatedAt            time.Time           `bson:"updatedAt" json:"updatedAt"`
+	UpdatedBy            *primitive.ObjectID `bson:"updatedBy,omitempty" json:"updatedBy,omitempty"`
 }
@@ -0,0 +1,31 @@
+package database
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"renotech.com.my/internal/enum"
+)
+
+// DocumentNumberConfig is a company's numbering pattern for one document type
+type DocumentNumberConfig struct {
+	ID        *primitive.ObjectID      `bson:"_id,omitempty" json:"_id,omitempty"`
+	Company   *primitive.ObjectID      `bson:"company" json:"company"`
+	Type      enum.DocumentNumberType  `bson:"type" json:"type"`
+	Pattern   string                   `bson:"pattern" json:"pattern"` // e.g. PO-{YYYY}-{seq:5}
+	Reset     enum.DocumentNumberReset `bson:"reset" json:"reset"`
+	CreatedAt time.Time                `bson:"createdAt" json:"createdAt"`
+	CreatedBy primitive.ObjectID       `bson:"createdBy" json:"createdBy"`
+	UpdatedAt time.Time                `bson:"updatedAt" json:"updatedAt"`
+	UpdatedBy *primitive.ObjectID      `bson:"updatedBy" json:"updatedBy"`
+}
+
+// DocumentCounter holds the last issued sequence for a company, document type and reset period
+type DocumentCounter struct {
+	ID        *primitive.ObjectID     `bson:"_id,omitempty" json:"_id,omitempty"`
+	Company   *primitive.ObjectID     `bson:"company" json:"company"`
+	Type      enum.DocumentNumberType `bson:"type" json:"type"`
+	Period    string                  `bson:"period" json:"period"` // "" (never), "2025" (yearly) or "2025-01" (monthly)
+	Sequence  int64                   `bson:"sequence" json:"sequence"`
+	UpdatedAt time.Time               `bson:"updatedAt" json:"updatedAt"`
+}
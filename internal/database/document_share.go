@@ -0,0 +1,23 @@
+package database
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"renotech.com.my/internal/enum"
+)
+
+// DocumentShare is a revocable public link to a single document, used without logging in
+type DocumentShare struct {
+	ID           *primitive.ObjectID    `bson:"_id,omitempty" json:"_id,omitempty"`
+	Type         enum.DocumentShareType `bson:"type" json:"type"`
+	Document     primitive.ObjectID     `bson:"document" json:"document"` // Order or quotation ID
+	Company      *primitive.ObjectID    `bson:"company" json:"company"`
+	ExpiresAt    time.Time              `bson:"expiresAt" json:"expiresAt"`
+	IsRevoked    bool                   `bson:"isRevoked" json:"isRevoked"`
+	RevokedAt    *time.Time             `bson:"revokedAt,omitempty" json:"revokedAt,omitempty"`
+	ViewCount    int                    `bson:"viewCount" json:"viewCount"`
+	LastViewedAt *time.Time             `bson:"lastViewedAt,omitempty" json:"lastViewedAt,omitempty"`
+	CreatedAt    time.Time              `bson:"createdAt" json:"createdAt"`
+	CreatedBy    primitive.ObjectID     `bson:"createdBy" json:"createdBy"`
+}
@@ -10,7 +10,7 @@ type Folder struct {
 	ID          primitive.ObjectID  `bson:"_id,omitempty" json:"_id,omitempty"`
 	Name        string              `bson:"name" json:"name"`
 	Client      SystemClient        `bson:"client" json:"client"`
-	Budget      float64             `bson:"budget" json:"budget"`
+	Budget      Money               `bson:"budget" json:"budget"`
 	Address     SystemAddress       `bson:"address" json:"address"`
 	Description string              `bson:"description" json:"description"`
 	Remark      string              `bson:"remark" json:"remark"`
@@ -0,0 +1,36 @@
+package database
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// GoodsReceipt records what actually arrived on site against a purchase order
+type GoodsReceipt struct {
+	ID           *primitive.ObjectID `bson:"_id,omitempty" json:"_id,omitempty"`
+	Order        primitive.ObjectID  `bson:"order" json:"order"`
+	Company      *primitive.ObjectID `bson:"company" json:"company"`   // Tenant isolation
+	PONumber     string              `bson:"poNumber" json:"poNumber"` // Copied from order for reporting
+	Supplier     OrderSupplier       `bson:"supplier" json:"supplier"` // Copied from order for reporting
+	ReceivedDate time.Time           `bson:"receivedDate" json:"receivedDate"`
+	ReceivedBy   string              `bson:"receivedBy" json:"receivedBy"` // Person who received the goods on site
+	Items        []GoodsReceiptItem  `bson:"items" json:"items"`
+	Media        []SystemMedia       `bson:"media" json:"media"` // Delivery photos
+	Remark       string              `bson:"remark" json:"remark"`
+	CreatedAt    time.Time           `bson:"createdAt" json:"createdAt"`
+	CreatedBy    primitive.ObjectID  `bson:"createdBy" json:"createdBy"`
+	UpdatedAt    time.Time           `bson:"updatedAt" json:"updatedAt"`
+	UpdatedBy    *primitive.ObjectID `bson:"updatedBy" json:"updatedBy"`
+	IsDeleted    bool                `bson:"isDeleted" json:"isDeleted"`
+}
+
+type GoodsReceiptItem struct {
+	ItemIndex        int                 `bson:"itemIndex" json:"itemIndex"` // Index into Order.Items
+	Material         *primitive.ObjectID `bson:"material" json:"material"`
+	Name             string              `bson:"name" json:"name"`
+	Unit             string              `bson:"unit" json:"unit"`
+	ReceivedQuantity float64             `bson:"receivedQuantity" json:"receivedQuantity"` // Total delivered, including rejected
+	RejectedQuantity float64             `bson:"rejectedQuantity" json:"rejectedQuantity"`
+	Remark           string              `bson:"remark" json:"remark"`
+}
@@ -0,0 +1,62 @@
+package database
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"renotech.com.my/internal/enum"
+)
+
+// Invoice is a progress claim billed to the client of a project
+type Invoice struct {
+	ID            *primitive.ObjectID     `bson:"_id,omitempty" json:"_id,omitempty"`
+	Project       primitive.ObjectID      `bson:"project" json:"project"`
+	Company       *primitive.ObjectID     `bson:"company" json:"company"` // Tenant isolation
+	InvoiceNumber string                  `bson:"invoiceNumber" json:"invoiceNumber"`
+	BillingType   enum.InvoiceBillingType `bson:"billingType" json:"billingType"`
+	Title         string                  `bson:"title" json:"title"` // e.g. "Progress claim 2 - tiling completed"
+
+	// Client snapshot taken from the project's quotation
+	Client  SystemClient  `bson:"client" json:"client"`
+	Address SystemAddress `bson:"address" json:"address"`
+
+	IssueDate time.Time `bson:"issueDate" json:"issueDate"`
+	DueDate   time.Time `bson:"dueDate" json:"dueDate"`
+
+	Lines            []InvoiceLine `bson:"lines" json:"lines"`
+	SubTotal         Money         `bson:"subTotal" json:"subTotal"`
+	RetentionPercent float64       `bson:"retentionPercent" json:"retentionPercent"`
+	RetentionAmount  Money         `bson:"retentionAmount" json:"retentionAmount"` // Withheld from the sub total before tax
+	TaxRate          float64       `bson:"taxRate" json:"taxRate"`                 // Tax percentage
+	TaxAmount        Money         `bson:"taxAmount" json:"taxAmount"`
+	Total            Money         `bson:"total" json:"total"`
+	PaidAmount       Money         `bson:"paidAmount" json:"paidAmount"`
+	BalanceDue       Money         `bson:"balanceDue" json:"balanceDue"`
+	AllocatingAmount Money         `bson:"allocatingAmount" json:"allocatingAmount"` // Held by payment allocations still being recorded
+
+	Status     enum.InvoiceStatus `bson:"status" json:"status"`
+	IssuedAt   *time.Time         `bson:"issuedAt,omitempty" json:"issuedAt,omitempty"`
+	VoidedAt   *time.Time         `bson:"voidedAt,omitempty" json:"voidedAt,omitempty"`
+	VoidReason string             `bson:"voidReason" json:"voidReason"`
+	Remark     string             `bson:"remark" json:"remark"`
+
+	ActionLogs []SystemActionLog   `bson:"actionLogs" json:"actionLogs"`
+	CreatedAt  time.Time           `bson:"createdAt" json:"createdAt"`
+	CreatedBy  primitive.ObjectID  `bson:"createdBy" json:"createdBy"`
+	UpdatedAt  time.Time           `bson:"updatedAt" json:"updatedAt"`
+	UpdatedBy  *primitive.ObjectID `bson:"updatedBy" json:"updatedBy"`
+	IsDeleted  bool                `bson:"isDeleted" json:"isDeleted"`
+}
+
+type InvoiceLine struct {
+	Description      string                     `bson:"description" json:"description"`
+	Area             string                     `bson:"area" json:"area"`
+	ItemIndex        *int                       `bson:"itemIndex,omitempty" json:"itemIndex,omitempty"`               // Items billing: index into the area's materials
+	StagePercent     float64                    `bson:"stagePercent,omitempty" json:"stagePercent,omitempty"`         // Stage billing: percentage of the original contract sum
+	VariationOrder   *primitive.ObjectID        `bson:"variationOrder,omitempty" json:"variationOrder,omitempty"`     // Variation billing
+	RetentionRelease enum.RetentionReleaseStage `bson:"retentionRelease,omitempty" json:"retentionRelease,omitempty"` // Retention billing
+	Unit             string                     `bson:"unit" json:"unit"`
+	Quantity         float64                    `bson:"quantity" json:"quantity"`
+	UnitPrice        Money                      `bson:"unitPrice" json:"unitPrice"`
+	Amount           Money                      `bson:"amount" json:"amount"`
+}
@@ -17,8 +17,8 @@ type Material struct {
 	Supplier            *primitive.ObjectID `bson:"supplier" json:"supplier"`
 	Brand               string              `bson:"brand" json:"brand"`
 	Unit                string              `bson:"unit" json:"unit"`
-	CostPerUnit         float64             `bson:"costPerUnit" json:"costPerUnit"`
-	PricePerUnit        float64             `bson:"pricePerUnit" json:"pricePerUnit"`
+	CostPerUnit         Money               `bson:"costPerUnit" json:"costPerUnit"`
+	PricePerUnit        Money               `bson:"pricePerUnit" json:"pricePerUnit"`
 	Tags                []string            `bson:"tags" json:"tags"`
 	Media               []SystemMedia       `bson:"media" json:"media"`
 	Company             primitive.ObjectID  `bson:"company" json:"company"`
@@ -0,0 +1,262 @@
+package database
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/big"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/x/bsonx/bsoncore"
+)
+
+// Money is an amount in sen. Arithmetic on it is exact; anything that multiplies by a quantity or a
+// rate goes through Times, Percent or Scale, which round half up to the nearest sen.
+//
+// It is stored in MongoDB as a Decimal128 with two decimal places and written to JSON as a decimal
+// number of ringgit, so API clients keep sending and receiving values like 12.50.
+type Money int64
+
+var (
+	bigHundred = big.NewRat(100, 1)
+	bigTwo     = big.NewInt(2)
+
+	moneyPattern   = regexp.MustCompile(`^-?[0-9]+(\.[0-9]{1,2})?$`)
+	decimalPattern = regexp.MustCompile(`^-?[0-9]+(\.[0-9]+)?$`)
+)
+
+// MoneyFromFloat converts a ringgit amount using its shortest decimal form, so 1.005 becomes 1.01
+// rather than 1.00 as binary floating point rounding would give.
+func MoneyFromFloat(amount float64) Money {
+	return RoundMoney(new(big.Rat).Mul(DecimalRat(amount), bigHundred))
+}
+
+// ParseMoney parses a decimal ringgit amount such as "1234.5" or "-0.05". Only plain decimals with at
+// most two decimal places are accepted, since this is how amounts arrive from API clients.
+func ParseMoney(value string) (Money, error) {
+	value = strings.TrimSpace(value)
+	if !moneyPattern.MatchString(value) {
+		return 0, fmt.Errorf("invalid money amount %q", value)
+	}
+	return parseDecimalMoney(value)
+}
+
+// ParseDecimalMoney parses a plain decimal ringgit amount with any number of decimal places, rounding
+// half up to the sen. It is meant for imported figures such as spreadsheet cells.
+func ParseDecimalMoney(value string) (Money, error) {
+	value = strings.TrimSpace(value)
+	if !decimalPattern.MatchString(value) {
+		return 0, fmt.Errorf("invalid money amount %q", value)
+	}
+	return parseDecimalMoney(value)
+}
+
+// MoneyFromDecimal128 converts a stored Decimal128 in ringgit exactly, rounding half up to the sen
+func MoneyFromDecimal128(decimal primitive.Decimal128) (Money, error) {
+	coefficient, exponent, err := decimal.BigInt()
+	if err != nil {
+		return 0, fmt.Errorf("invalid money amount %s: %w", decimal.String(), err)
+	}
+
+	// Decimal128 exponents are at most a few thousand, so the power of ten stays manageable
+	sen := new(big.Rat).Mul(new(big.Rat).SetInt(coefficient), bigHundred)
+	power := new(big.Rat).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(absInt(exponent))), nil))
+	if exponent < 0 {
+		sen.Quo(sen, power)
+	} else {
+		sen.Mul(sen, power)
+	}
+
+	return roundMoneyChecked(sen)
+}
+
+// RoundMoney rounds an exact amount in sen half up, away from zero, to a whole sen. Amounts beyond the
+// range of Money are clamped to its limits rather than wrapping around.
+func RoundMoney(sen *big.Rat) Money {
+	rounded, err := roundMoneyChecked(sen)
+	if err != nil {
+		if sen.Sign() < 0 {
+			return Money(math.MinInt64)
+		}
+		return Money(math.MaxInt64)
+	}
+	return rounded
+}
+
+// DecimalRat returns the exact value of a float's shortest decimal form, used for quantities and rates
+func DecimalRat(value float64) *big.Rat {
+	rat, ok := new(big.Rat).SetString(strconv.FormatFloat(value, 'f', -1, 64))
+	if !ok {
+		return new(big.Rat)
+	}
+	return rat
+}
+
+// Rat returns the amount in sen as an exact rational
+func (m Money) Rat() *big.Rat {
+	return new(big.Rat).SetInt64(int64(m))
+}
+
+// Times multiplies by a quantity, rounding to the sen
+func (m Money) Times(factor float64) Money {
+	return RoundMoney(m.TimesExact(factor))
+}
+
+// TimesExact multiplies by a quantity without rounding, for callers that round a whole document at once
+func (m Money) TimesExact(factor float64) *big.Rat {
+	return new(big.Rat).Mul(m.Rat(), DecimalRat(factor))
+}
+
+// Percent returns rate percent of the amount, rounding to the sen
+func (m Money) Percent(rate float64) Money {
+	exact := new(big.Rat).Mul(m.Rat(), DecimalRat(rate))
+	return RoundMoney(exact.Quo(exact, bigHundred))
+}
+
+// Scale returns the amount multiplied by numerator / denominator, rounding to the sen. A zero
+// denominator gives zero.
+func (m Money) Scale(numerator Money, denominator Money) Money {
+	if denominator == 0 {
+		return 0
+	}
+	exact := new(big.Rat).Mul(m.Rat(), numerator.Rat())
+	return RoundMoney(exact.Quo(exact, denominator.Rat()))
+}
+
+// Float64 returns the amount in ringgit for ratios and logging, never for further money arithmetic
+func (m Money) Float64() float64 {
+	return float64(m) / 100
+}
+
+// String formats the amount in ringgit with two decimal places, e.g. "-1234.50"
+func (m Money) String() string {
+	sign := ""
+	value := int64(m)
+	if value < 0 {
+		sign = "-"
+		value = -value
+	}
+	return fmt.Sprintf("%s%d.%02d", sign, value/100, value%100)
+}
+
+func (m Money) MarshalJSON() ([]byte, error) {
+	return []byte(m.String()), nil
+}
+
+// UnmarshalJSON accepts a JSON number or a numeric string in ringgit
+func (m *Money) UnmarshalJSON(data []byte) error {
+	value := strings.TrimSpace(string(data))
+	if value == "null" {
+		return nil
+	}
+
+	if strings.HasPrefix(value, `"`) {
+		var text string
+		if err := json.Unmarshal(data, &text); err != nil {
+			return err
+		}
+		value = text
+	}
+
+	parsed, err := ParseMoney(value)
+	if err != nil {
+		return err
+	}
+	*m = parsed
+	return nil
+}
+
+func (m Money) MarshalBSONValue() (bsontype.Type, []byte, error) {
+	decimal, err := primitive.ParseDecimal128(m.String())
+	if err != nil {
+		return 0, nil, err
+	}
+	return bsontype.Decimal128, bsoncore.AppendDecimal128(nil, decimal), nil
+}
+
+// UnmarshalBSONValue reads a Decimal128 in ringgit. Numbers stored as doubles or integers before
+// money was stored as decimals are read as ringgit too.
+func (m *Money) UnmarshalBSONValue(t bsontype.Type, data []byte) error {
+	value := bsoncore.Value{Type: t, Data: data}
+
+	switch t {
+	case bsontype.Decimal128:
+		parsed, err := MoneyFromDecimal128(value.Decimal128())
+		if err != nil {
+			return err
+		}
+		*m = parsed
+	case bsontype.Double:
+		*m = MoneyFromFloat(value.Double())
+	case bsontype.Int32:
+		*m = Money(int64(value.Int32()) * 100)
+	case bsontype.Int64:
+		*m = Money(value.Int64() * 100)
+	case bsontype.Null, bsontype.Undefined:
+		*m = 0
+	default:
+		return fmt.Errorf("cannot decode %s into Money", t)
+	}
+
+	return nil
+}
+
+func parseDecimalMoney(value string) (Money, error) {
+	rat, ok := new(big.Rat).SetString(value)
+	if !ok {
+		return 0, fmt.Errorf("invalid money amount %q", value)
+	}
+
+	amount, err := roundMoneyChecked(rat.Mul(rat, bigHundred))
+	if err != nil {
+		return 0, fmt.Errorf("money amount %q is out of range", value)
+	}
+	return amount, nil
+}
+
+// roundMoneyChecked rounds like RoundMoney and fails when the result does not fit in Money
+func roundMoneyChecked(sen *big.Rat) (Money, error) {
+	num := new(big.Int).Abs(sen.Num())
+	den := sen.Denom()
+
+	// floor((2 * num + den) / (2 * den)) is num / den rounded half up
+	rounded := new(big.Int).Mul(num, bigTwo)
+	rounded.Add(rounded, den)
+	rounded.Quo(rounded, new(big.Int).Mul(den, bigTwo))
+
+	if sen.Sign() < 0 {
+		rounded.Neg(rounded)
+	}
+
+	if !rounded.IsInt64() {
+		return 0, fmt.Errorf("money amount out of range")
+	}
+	return Money(rounded.Int64()), nil
+}
+
+func absInt(value int) int {
+	if value < 0 {
+		return -value
+	}
+	return value
+}
+
+// MinMoney returns the smaller amount
+func MinMoney(a Money, b Money) Money {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+// MaxMoney returns the larger amount
+func MaxMoney(a Money, b Money) Money {
+	if a > b {
+		return a
+	}
+	return b
+}
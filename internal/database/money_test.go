@@ -0,0 +1,276 @@
+package database
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/x/bsonx/bsoncore"
+)
+
+func TestRoundMoney(t *testing.T) {
+	tests := []struct {
+		name string
+		sen  *big.Rat
+		want Money
+	}{
+		{"whole sen", big.NewRat(125, 1), 125},
+		{"below half rounds down", big.NewRat(1249, 10), 125},
+		{"just below half rounds down", big.NewRat(12449, 100), 124},
+		{"half rounds up", big.NewRat(1245, 10), 125},
+		{"above half rounds up", big.NewRat(1246, 10), 125},
+		{"negative half rounds away from zero", big.NewRat(-1245, 10), -125},
+		{"negative below half rounds towards zero", big.NewRat(-1244, 10), -124},
+		{"third of a sen", big.NewRat(1, 3), 0},
+		{"two thirds of a sen", big.NewRat(2, 3), 1},
+		{"zero", big.NewRat(0, 1), 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := RoundMoney(tt.sen); got != tt.want {
+				t.Errorf("RoundMoney(%s) = %d, want %d", tt.sen.RatString(), got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMoneyFromFloat(t *testing.T) {
+	tests := []struct {
+		amount float64
+		want   Money
+	}{
+		{0, 0},
+		{12.5, 1250},
+		{1.005, 101},
+		{2.675, 268},
+		{0.1 + 0.2, 30},
+		{-1.005, -101},
+		{1234567.89, 123456789},
+	}
+
+	for _, tt := range tests {
+		if got := MoneyFromFloat(tt.amount); got != tt.want {
+			t.Errorf("MoneyFromFloat(%v) = %d, want %d", tt.amount, got, tt.want)
+		}
+	}
+}
+
+func TestParseMoney(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    Money
+		wantErr bool
+	}{
+		{"1234.5", 123450, false},
+		{"-0.05", -5, false},
+		{" 12.50 ", 1250, false},
+		{"0", 0, false},
+		{"1.5", 150, false},
+		{"1.005", 0, true},
+		{"1e3", 0, true},
+		{"1/3", 0, true},
+		{".5", 0, true},
+		{"99999999999999999999", 0, true},
+		{"", 0, true},
+		{"abc", 0, true},
+		{"12,50", 0, true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseMoney(tt.value)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseMoney(%q) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseMoney(%q) = %d, want %d", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestParseDecimalMoney(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    Money
+		wantErr bool
+	}{
+		{"1234.5", 123450, false},
+		{"1.005", 101, false},
+		{"1.004", 100, false},
+		{"-1.005", -101, false},
+		{"1e3", 0, true},
+		{"99999999999999999999", 0, true},
+		{"", 0, true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseDecimalMoney(tt.value)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseDecimalMoney(%q) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseDecimalMoney(%q) = %d, want %d", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestMoneyArithmetic(t *testing.T) {
+	tests := []struct {
+		name string
+		got  Money
+		want Money
+	}{
+		{"times whole quantity", Money(1250).Times(3), 3750},
+		{"times fractional quantity rounds half up", Money(333).Times(1.5), 500},
+		{"times float noise", Money(10).Times(0.1 + 0.2), 3},
+		{"percent", Money(10000).Percent(6), 600},
+		{"percent rounds half up", Money(25).Percent(10), 3},
+		{"percent of negative rounds away from zero", Money(-25).Percent(10), -3},
+		{"scale", Money(1000).Scale(1, 3), 333},
+		{"scale rounds half up", Money(1).Scale(1, 2), 1},
+		{"scale by zero denominator", Money(1000).Scale(1, 0), 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.got != tt.want {
+				t.Errorf("got %d, want %d", tt.got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMoneyString(t *testing.T) {
+	tests := []struct {
+		amount Money
+		want   string
+	}{
+		{0, "0.00"},
+		{5, "0.05"},
+		{-5, "-0.05"},
+		{123450, "1234.50"},
+		{-123450, "-1234.50"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.amount.String(); got != tt.want {
+			t.Errorf("Money(%d).String() = %q, want %q", tt.amount, got, tt.want)
+		}
+	}
+}
+
+func TestMoneyJSON(t *testing.T) {
+	tests := []struct {
+		data    string
+		want    Money
+		wantErr bool
+	}{
+		{`12.5`, 1250, false},
+		{`"12.50"`, 1250, false},
+		{`-0.05`, -5, false},
+		{`1.005`, 0, true},
+		{`1e3`, 0, true},
+		{`null`, 0, false},
+		{`"abc"`, 0, true},
+		{`true`, 0, true},
+	}
+
+	for _, tt := range tests {
+		var got Money
+		err := json.Unmarshal([]byte(tt.data), &got)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("unmarshal %s error = %v, wantErr %v", tt.data, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("unmarshal %s = %d, want %d", tt.data, got, tt.want)
+		}
+	}
+
+	data, err := json.Marshal(struct {
+		Amount Money `json:"amount"`
+	}{Amount: 123450})
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	if string(data) != `{"amount":1234.50}` {
+		t.Errorf("marshal = %s, want {\"amount\":1234.50}", data)
+	}
+}
+
+func TestMoneyBSON(t *testing.T) {
+	decimal := func(value string) primitive.Decimal128 {
+		d, err := primitive.ParseDecimal128(value)
+		if err != nil {
+			t.Fatalf("ParseDecimal128(%q): %v", value, err)
+		}
+		return d
+	}
+
+	tests := []struct {
+		name    string
+		value   interface{}
+		want    Money
+		wantErr bool
+	}{
+		{"decimal", decimal("1234.50"), 123450, false},
+		{"decimal with more places rounds half up", decimal("1.005"), 101, false},
+		{"negative decimal", decimal("-0.05"), -5, false},
+		{"legacy double", 12.5, 1250, false},
+		{"legacy double rounds half up", 1.005, 101, false},
+		{"legacy int32 in ringgit", int32(12), 1200, false},
+		{"legacy int64 in ringgit", int64(-3), -300, false},
+		{"null", nil, 0, false},
+		{"string", "12.50", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := bson.Marshal(bson.M{"amount": tt.value})
+			if err != nil {
+				t.Fatalf("marshal: %v", err)
+			}
+
+			var got struct {
+				Amount Money `bson:"amount"`
+			}
+			err = bson.Unmarshal(data, &got)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("unmarshal error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got.Amount != tt.want {
+				t.Errorf("unmarshal = %d, want %d", got.Amount, tt.want)
+			}
+		})
+	}
+}
+
+func TestMoneyBSONRoundTrip(t *testing.T) {
+	for _, amount := range []Money{0, 1, -1, 1250, -123450, 999999999999} {
+		bsonType, data, err := amount.MarshalBSONValue()
+		if err != nil {
+			t.Fatalf("MarshalBSONValue(%d): %v", amount, err)
+		}
+		if bsonType != bsontype.Decimal128 {
+			t.Fatalf("MarshalBSONValue(%d) type = %s, want decimal", amount, bsonType)
+		}
+
+		stored := bsoncore.Value{Type: bsonType, Data: data}.Decimal128().String()
+		if stored != amount.String() {
+			t.Errorf("MarshalBSONValue(%d) stored %s, want %s", amount, stored, amount.String())
+		}
+
+		var got Money
+		if err := got.UnmarshalBSONValue(bsonType, data); err != nil {
+			t.Fatalf("UnmarshalBSONValue(%d): %v", amount, err)
+		}
+		if got != amount {
+			t.Errorf("round trip %d = %d", amount, got)
+		}
+	}
+}
@@ -31,10 +31,10 @@ type Order struct {
 
 	// Order Items & Pricing
 	Items       []OrderItem `bson:"items" json:"items"`
-	SubTotal    float64     `bson:"subTotal" json:"subTotal"`
+	SubTotal    Money       `bson:"subTotal" json:"subTotal"`
 	TaxRate     float64     `bson:"taxRate" json:"taxRate"`         // Tax percentage
-	TaxAmount   float64     `bson:"taxAmount" json:"taxAmount"`     // Calculated tax
-	TotalCharge float64     `bson:"totalCharge" json:"totalCharge"` // Final total
+	TaxAmount   Money       `bson:"taxAmount" json:"taxAmount"`     // Calculated tax
+	TotalCharge Money       `bson:"totalCharge" json:"totalCharge"` // Final total
 
 	// Status & Tracking
 	Status   enum.OrderStatus   `bson:"status" json:"status"`     // Draft, Sent, Confirmed, Delivered, etc.
@@ -44,6 +44,9 @@ type Order struct {
 	Remark        string `bson:"remark" json:"remark"`
 	InternalNotes string `bson:"internalNotes" json:"internalNotes"` // Not visible in PO
 
+	// Latest response submitted by the supplier through the self-service link
+	SupplierResponse *OrderSupplierResponse `bson:"supplierResponse,omitempty" json:"supplierResponse,omitempty"`
+
 	// Audit Fields
 	ActionLogs []SystemActionLog   `bson:"actionLogs" json:"actionLogs"`
 	CreatedAt  time.Time           `bson:"createdAt" json:"createdAt"`
@@ -69,7 +72,26 @@ type OrderItem struct {
 	Brand       string              `bson:"brand" json:"brand"`
 	Unit        string              `bson:"unit" json:"unit"` // pcs, kg, m², etc.
 	Quantity    float64             `bson:"quantity" json:"quantity"`
-	UnitPrice   float64             `bson:"unitPrice" json:"unitPrice"`   // Cost per unit
-	TotalPrice  float64             `bson:"totalPrice" json:"totalPrice"` // quantity × unitPrice
+	UnitPrice   Money               `bson:"unitPrice" json:"unitPrice"`   // Cost per unit
+	TotalPrice  Money               `bson:"totalPrice" json:"totalPrice"` // quantity × unitPrice
 	Remark      string              `bson:"remark" json:"remark"`
+	Area        string              `bson:"area" json:"area"` // Optional: project area the item is costed against
+
+	// Delivery tracking, maintained by goods receipts
+	ReceivedQuantity float64 `bson:"receivedQuantity" json:"receivedQuantity"` // Total delivered, including rejected
+	RejectedQuantity float64 `bson:"rejectedQuantity" json:"rejectedQuantity"` // Delivered but not accepted
+}
+
+type OrderSupplierResponse struct {
+	Action           enum.SupplierResponseAction `bson:"action" json:"action"`
+	Reason           string                      `bson:"reason" json:"reason"`
+	ProposedDelivery *time.Time                  `bson:"proposedDelivery,omitempty" json:"proposedDelivery,omitempty"`
+	RespondedBy      string                      `bson:"respondedBy" json:"respondedBy"`
+	RespondedAt      time.Time                   `bson:"respondedAt" json:"respondedAt"`
+
+	// Staff review of a proposed delivery date
+	ProposalStatus     enum.DeliveryProposalStatus `bson:"proposalStatus,omitempty" json:"proposalStatus,omitempty"`
+	ProposalReviewedBy *primitive.ObjectID         `bson:"proposalReviewedBy,omitempty" json:"proposalReviewedBy,omitempty"`
+	ProposalReviewedAt *time.Time                  `bson:"proposalReviewedAt,omitempty" json:"proposalReviewedAt,omitempty"`
+	ProposalRemark     string                      `bson:"proposalRemark,omitempty" json:"proposalRemark,omitempty"`
 }
@@ -0,0 +1,49 @@
+package database
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"renotech.com.my/internal/enum"
+)
+
+// Payment is money received from a client. It is recorded against a project or, for deposits taken
+// before the project exists, against a quotation. The part not allocated to invoices is a credit balance.
+type Payment struct {
+	ID            *primitive.ObjectID `bson:"_id,omitempty" json:"_id,omitempty"`
+	Project       *primitive.ObjectID `bson:"project" json:"project"`
+	Quotation     *primitive.ObjectID `bson:"quotation" json:"quotation"`
+	Company       *primitive.ObjectID `bson:"company" json:"company"` // Tenant isolation
+	ReceiptNumber string              `bson:"receiptNumber" json:"receiptNumber"`
+
+	// Client snapshot taken from the quotation
+	Client SystemClient `bson:"client" json:"client"`
+
+	PaymentDate time.Time          `bson:"paymentDate" json:"paymentDate"`
+	Method      enum.PaymentMethod `bson:"method" json:"method"`
+	Reference   string             `bson:"reference" json:"reference"` // Bank reference, cheque number, etc.
+	Amount      Money              `bson:"amount" json:"amount"`
+
+	Allocations       []PaymentAllocation `bson:"allocations" json:"allocations"`
+	AllocatedAmount   Money               `bson:"allocatedAmount" json:"allocatedAmount"`
+	UnallocatedAmount Money               `bson:"unallocatedAmount" json:"unallocatedAmount"` // Credit balance
+
+	Status     enum.PaymentStatus `bson:"status" json:"status"`
+	VoidedAt   *time.Time         `bson:"voidedAt,omitempty" json:"voidedAt,omitempty"`
+	VoidReason string             `bson:"voidReason" json:"voidReason"`
+	Remark     string             `bson:"remark" json:"remark"`
+
+	ActionLogs []SystemActionLog   `bson:"actionLogs" json:"actionLogs"`
+	CreatedAt  time.Time           `bson:"createdAt" json:"createdAt"`
+	CreatedBy  primitive.ObjectID  `bson:"createdBy" json:"createdBy"`
+	UpdatedAt  time.Time           `bson:"updatedAt" json:"updatedAt"`
+	UpdatedBy  *primitive.ObjectID `bson:"updatedBy" json:"updatedBy"`
+	IsDeleted  bool                `bson:"isDeleted" json:"isDeleted"`
+}
+
+type PaymentAllocation struct {
+	Invoice       primitive.ObjectID `bson:"invoice" json:"invoice"`
+	InvoiceNumber string             `bson:"invoiceNumber" json:"invoiceNumber"`
+	Amount        Money              `bson:"amount" json:"amount"`
+	AllocatedAt   time.Time          `bson:"allocatedAt" json:"allocatedAt"`
+}
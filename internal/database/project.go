@@ -7,24 +7,38 @@ import (
 )
 
 type Project struct {
-	ID                  *primitive.ObjectID  `bson:"_id,omitempty" json:"_id,omitempty"`
-	Folder              primitive.ObjectID   `bson:"folder" json:"folder"`
-	Quotation           primitive.ObjectID   `bson:"quotation" json:"quotation"`
-	Description         string               `bson:"description" json:"description"`
-	Remark              string               `bson:"remark" json:"remark"`
-	AreaMaterials       []SystemAreaMaterial `bson:"areaMaterials" json:"areaMaterials"`
-	Discount            SystemDiscount       `bson:"discount" json:"discount"`
-	TotalDiscount       float64              `bson:"totalDiscount" json:"totalDiscount"`
-	TotalCharge         float64              `bson:"totalCharge" json:"totalCharge"`
-	TotalNettCharge     float64              `bson:"totalNettCharge" json:"totalNettCharge"`
-	TotalCost           float64              `bson:"totalCost" json:"totalCost"`
-	CreatedAt           time.Time            `bson:"createdAt" json:"createdAt"`
-	CreatedBy           primitive.ObjectID   `bson:"createdBy" json:"createdBy"`
-	UpdatedAt           time.Time            `bson:"updatedAt" json:"updatedAt"`
-	UpdatedBy           *primitive.ObjectID  `bson:"updatedBy" json:"updatedBy"`
-	EstimatedCompleteAt time.Time            `bson:"estimatedCompleteAt" json:"estimatedCompleteAt"`
-	ActionLogs          []SystemActionLog    `bson:"actionLogs" json:"actionLogs"`
-	PIC                 []primitive.ObjectID `bson:"pic" json:"pic"`
-	Company             *primitive.ObjectID  `bson:"company" json:"company"`
-	IsDeleted           bool                 `bson:"isDeleted" json:"isDeleted"`
+	ID                     *primitive.ObjectID      `bson:"_id,omitempty" json:"_id,omitempty"`
+	Folder                 primitive.ObjectID       `bson:"folder" json:"folder"`
+	Quotation              primitive.ObjectID       `bson:"quotation" json:"quotation"`
+	Name                   string                   `bson:"name" json:"name"`
+	Description            string                   `bson:"description" json:"description"`
+	Remark                 string                   `bson:"remark" json:"remark"`
+	AreaMaterials          []SystemAreaMaterial     `bson:"areaMaterials" json:"areaMaterials"`
+	Discounts              []SystemDiscount         `bson:"discounts" json:"discounts"`
+	AdditionalCharges      []SystemAdditionalCharge `bson:"additionalCharges" json:"additionalCharges"`
+	TotalDiscount          Money                    `bson:"totalDiscount" json:"totalDiscount"`
+	TotalAdditionalCharge  Money                    `bson:"totalAdditionalCharge" json:"totalAdditionalCharge"`
+	TotalCharge            Money                    `bson:"totalCharge" json:"totalCharge"`
+	TotalNettCharge        Money                    `bson:"totalNettCharge" json:"totalNettCharge"`
+	TotalCost              Money                    `bson:"totalCost" json:"totalCost"`                     // Sum of material CostPerUnit x quantity
+	ApprovedVariation      Money                    `bson:"approvedVariation" json:"approvedVariation"`     // Net of approved variation orders
+	RevisedContractSum     Money                    `bson:"revisedContractSum" json:"revisedContractSum"`   // TotalNettCharge plus approved variations
+	BilledAmount           Money                    `bson:"billedAmount" json:"billedAmount"`               // Stage, item and variation billing on invoices that are not void
+	BilledStagePercent     float64                  `bson:"billedStagePercent" json:"billedStagePercent"`   // Stage percentages on invoices that are not void
+	RetentionPercent       float64                  `bson:"retentionPercent" json:"retentionPercent"`       // Withheld from each progress claim
+	RetentionCapPercent    float64                  `bson:"retentionCapPercent" json:"retentionCapPercent"` // Limit of retention as a percentage of the contract sum, 0 for no limit
+	DefectsLiabilityMonths int                      `bson:"defectsLiabilityMonths" json:"defectsLiabilityMonths"`
+	CompletedAt            *time.Time               `bson:"completedAt,omitempty" json:"completedAt,omitempty"`                       // Practical completion
+	DefectsLiabilityEndsAt *time.Time               `bson:"defectsLiabilityEndsAt,omitempty" json:"defectsLiabilityEndsAt,omitempty"` // CompletedAt plus DefectsLiabilityMonths
+	OrdersInitialisedAt    *time.Time               `bson:"ordersInitialisedAt,omitempty" json:"ordersInitialisedAt,omitempty"`       // Set when purchase orders are created from the project materials
+	IsStared               bool                     `bson:"isStared" json:"isStared"`
+	CreatedAt              time.Time                `bson:"createdAt" json:"createdAt"`
+	CreatedBy              primitive.ObjectID       `bson:"createdBy" json:"createdBy"`
+	UpdatedAt              time.Time                `bson:"updatedAt" json:"updatedAt"`
+	UpdatedBy              *primitive.ObjectID      `bson:"updatedBy" json:"updatedBy"`
+	EstimatedCompleteAt    time.Time                `bson:"estimatedCompleteAt" json:"estimatedCompleteAt"`
+	ActionLogs             []SystemActionLog        `bson:"actionLogs" json:"actionLogs"`
+	PIC                    []primitive.ObjectID     `bson:"pic" json:"pic"`
+	Company                *primitive.ObjectID      `bson:"company" json:"company"`
+	IsDeleted              bool                     `bson:"isDeleted" json:"isDeleted"`
 }
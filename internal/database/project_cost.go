@@ -0,0 +1,27 @@
+package database
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"renotech.com.my/internal/enum"
+)
+
+// ProjectCost is a manually recorded cost against a project that does not come from a purchase order
+type ProjectCost struct {
+	ID          *primitive.ObjectID  `bson:"_id,omitempty" json:"_id,omitempty"`
+	Project     primitive.ObjectID   `bson:"project" json:"project"`
+	Company     *primitive.ObjectID  `bson:"company" json:"company"` // Tenant isolation
+	Type        enum.ProjectCostType `bson:"type" json:"type"`
+	Area        string               `bson:"area" json:"area"` // Optional: project area the cost belongs to
+	Description string               `bson:"description" json:"description"`
+	Amount      Money                `bson:"amount" json:"amount"`
+	IncurredAt  time.Time            `bson:"incurredAt" json:"incurredAt"`
+	Reference   string               `bson:"reference" json:"reference"` // Supplier invoice, payslip, etc.
+	Media       []SystemMedia        `bson:"media" json:"media"`
+	CreatedAt   time.Time            `bson:"createdAt" json:"createdAt"`
+	CreatedBy   primitive.ObjectID   `bson:"createdBy" json:"createdBy"`
+	UpdatedAt   time.Time            `bson:"updatedAt" json:"updatedAt"`
+	UpdatedBy   *primitive.ObjectID  `bson:"updatedBy" json:"updatedBy"`
+	IsDeleted   bool                 `bson:"isDeleted" json:"isDeleted"`
+}
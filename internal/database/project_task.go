@@ -0,0 +1,30 @@
+package database
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ProjectTask is a scheduled piece of work or milestone within a project
+type ProjectTask struct {
+	ID              *primitive.ObjectID  `bson:"_id,omitempty" json:"_id,omitempty"`
+	Project         primitive.ObjectID   `bson:"project" json:"project"`
+	Company         *primitive.ObjectID  `bson:"company" json:"company"` // Tenant isolation
+	Name            string               `bson:"name" json:"name"`
+	Description     string               `bson:"description" json:"description"`
+	Area            string               `bson:"area" json:"area"`               // Optional: project area the task is carried out in
+	IsMilestone     bool                 `bson:"isMilestone" json:"isMilestone"` // Milestones are shown as a single point on the schedule
+	PlannedStart    time.Time            `bson:"plannedStart" json:"plannedStart"`
+	PlannedEnd      time.Time            `bson:"plannedEnd" json:"plannedEnd"`
+	ActualStart     *time.Time           `bson:"actualStart" json:"actualStart"`
+	ActualEnd       *time.Time           `bson:"actualEnd" json:"actualEnd"`
+	Dependencies    []primitive.ObjectID `bson:"dependencies" json:"dependencies"` // Tasks that must finish before this one starts
+	PercentComplete float64              `bson:"percentComplete" json:"percentComplete"`
+	AssignedTo      *primitive.ObjectID  `bson:"assignedTo" json:"assignedTo"`
+	CreatedAt       time.Time            `bson:"createdAt" json:"createdAt"`
+	CreatedBy       primitive.ObjectID   `bson:"createdBy" json:"createdBy"`
+	UpdatedAt       time.Time            `bson:"updatedAt" json:"updatedAt"`
+	UpdatedBy       *primitive.ObjectID  `bson:"updatedBy" json:"updatedBy"`
+	IsDeleted       bool                 `bson:"isDeleted" json:"isDeleted"`
+}
@@ -4,14 +4,16 @@ import (
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson/primitive"
+	"renotech.com.my/internal/enum"
 )
 
 type Quotation struct {
 	ID                    *primitive.ObjectID      `bson:"_id,omitempty" json:"_id,omitempty"`
 	Folder                *primitive.ObjectID      `bson:"folder" json:"folder"`
 	Name                  string                   `bson:"name" json:"name"`
+	QuotationNumber       string                   `bson:"quotationNumber" json:"quotationNumber"` // Auto-generated from company numbering
 	Client                SystemClient             `bson:"client" json:"client"`
-	Budget                float64                  `bson:"budget" json:"budget"`
+	Budget                Money                    `bson:"budget" json:"budget"`
 	Address               SystemAddress            `bson:"address" json:"address"`
 	ExpiredAt             time.Time                `bson:"expiredAt" json:"expiredAt"`
 	Description           string                   `bson:"description" json:"description"`
@@ -20,11 +22,21 @@ type Quotation struct {
 	Discounts             []SystemDiscount         `bson:"discounts" json:"discounts"`
 	AdditionalCharges     []SystemAdditionalCharge `bson:"additionalCharges" json:"additionalCharges"`
 	IsStared              bool                     `bson:"isStared" json:"isStared"`
-	TotalCharge           float64                  `bson:"totalCharge" json:"totalCharge"`
-	TotalDiscount         float64                  `bson:"totalDiscount" json:"totalDiscount"`
-	TotalAdditionalCharge float64                  `bson:"totalAdditionalCharge" json:"totalAdditionalCharge"`
-	TotalNettCharge       float64                  `bson:"totalNettCharge" json:"totalNettCharge"`
+	Status                enum.QuotationStatus     `bson:"status" json:"status"`
+	SentAt                *time.Time               `bson:"sentAt" json:"sentAt"`
+	AcceptedAt            *time.Time               `bson:"acceptedAt" json:"acceptedAt"`
+	RejectedAt            *time.Time               `bson:"rejectedAt" json:"rejectedAt"`
+	LapsedAt              *time.Time               `bson:"lapsedAt" json:"lapsedAt"`                         // When the quotation was marked expired
+	Acceptance            *QuotationAcceptance     `bson:"acceptance,omitempty" json:"acceptance,omitempty"` // Online acceptance by the client, never changed once set
+	ChangeRequests        []QuotationChangeRequest `bson:"changeRequests" json:"changeRequests"`
+	TotalCharge           Money                    `bson:"totalCharge" json:"totalCharge"`
+	TotalDiscount         Money                    `bson:"totalDiscount" json:"totalDiscount"`
+	TotalAdditionalCharge Money                    `bson:"totalAdditionalCharge" json:"totalAdditionalCharge"`
+	TotalNettCharge       Money                    `bson:"totalNettCharge" json:"totalNettCharge"`
+	Revision              int                      `bson:"revision" json:"revision"`             // Current revision, increased by every saved change
+	RevisionReason        string                   `bson:"revisionReason" json:"revisionReason"` // Reason given for the current revision
 	Media                 []SystemMedia            `bson:"media" json:"media"`
+	ActionLogs            []SystemActionLog        `bson:"actionLogs" json:"actionLogs"`
 	Company               *primitive.ObjectID      `bson:"company" json:"company"`
 	CreatedAt             time.Time                `bson:"createdAt" json:"createdAt"`
 	CreatedBy             primitive.ObjectID       `bson:"createdBy" json:"createdBy"`
@@ -32,3 +44,24 @@ type Quotation struct {
 	UpdatedBy             *primitive.ObjectID      `bson:"updatedBy" json:"updatedBy"`
 	IsDeleted             bool                     `bson:"isDeleted" json:"isDeleted"`
 }
+
+// QuotationAcceptance is the client's acceptance through a share link
+type QuotationAcceptance struct {
+	Name            string             `bson:"name" json:"name"`
+	IPAddress       string             `bson:"ipAddress" json:"ipAddress"`
+	Signature       string             `bson:"signature" json:"signature"` // Media path of the drawn signature
+	Revision        int                `bson:"revision" json:"revision"`   // Revision the client accepted
+	TotalNettCharge Money              `bson:"totalNettCharge" json:"totalNettCharge"`
+	Share           primitive.ObjectID `bson:"share" json:"share"`
+	AcceptedAt      time.Time          `bson:"acceptedAt" json:"acceptedAt"`
+}
+
+// QuotationChangeRequest is a change asked for by the client through a share link
+type QuotationChangeRequest struct {
+	Name        string             `bson:"name" json:"name"`
+	Message     string             `bson:"message" json:"message"`
+	IPAddress   string             `bson:"ipAddress" json:"ipAddress"`
+	Revision    int                `bson:"revision" json:"revision"`
+	Share       primitive.ObjectID `bson:"share" json:"share"`
+	RequestedAt time.Time          `bson:"requestedAt" json:"requestedAt"`
+}
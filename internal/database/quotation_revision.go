@@ -0,0 +1,22 @@
+package database
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// QuotationRevision is an immutable snapshot of a quotation as saved by one revision. Revisions are
+// only ever inserted, never updated or deleted.
+type QuotationRevision struct {
+	ID            *primitive.ObjectID `bson:"_id,omitempty" json:"_id,omitempty"`
+	Quotation     primitive.ObjectID  `bson:"quotation" json:"quotation"`
+	Company       *primitive.ObjectID `bson:"company" json:"company"`
+	Revision      int                 `bson:"revision" json:"revision"`
+	Label         string              `bson:"label" json:"label"` // Printable label, A for revision 1
+	Reason        string              `bson:"reason" json:"reason"`
+	Snapshot      Quotation           `bson:"snapshot" json:"snapshot"` // Quotation as saved, without action logs
+	CreatedAt     time.Time           `bson:"createdAt" json:"createdAt"`
+	CreatedBy     primitive.ObjectID  `bson:"createdBy" json:"createdBy"`
+	CreatedByName string              `bson:"createdByName" json:"createdByName"`
+}
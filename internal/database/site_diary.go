@@ -0,0 +1,34 @@
+package database
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"renotech.com.my/internal/enum"
+)
+
+// SiteDiary is a dated record of what happened on site for a project
+type SiteDiary struct {
+	ID            *primitive.ObjectID   `bson:"_id,omitempty" json:"_id,omitempty"`
+	Project       primitive.ObjectID    `bson:"project" json:"project"`
+	Company       *primitive.ObjectID   `bson:"company" json:"company"` // Tenant isolation
+	Date          time.Time             `bson:"date" json:"date"`
+	Weather       enum.SiteDiaryWeather `bson:"weather" json:"weather"`
+	Manpower      int                   `bson:"manpower" json:"manpower"` // Workers on site
+	WorkDone      []SiteDiaryWork       `bson:"workDone" json:"workDone"`
+	Issues        []string              `bson:"issues" json:"issues"`
+	Media         []SystemMedia         `bson:"media" json:"media"` // General site photos
+	Remark        string                `bson:"remark" json:"remark"`
+	CreatedAt     time.Time             `bson:"createdAt" json:"createdAt"`
+	CreatedBy     primitive.ObjectID    `bson:"createdBy" json:"createdBy"`
+	CreatedByName string                `bson:"createdByName" json:"createdByName"`
+	UpdatedAt     time.Time             `bson:"updatedAt" json:"updatedAt"`
+	UpdatedBy     *primitive.ObjectID   `bson:"updatedBy" json:"updatedBy"`
+	IsDeleted     bool                  `bson:"isDeleted" json:"isDeleted"`
+}
+
+type SiteDiaryWork struct {
+	Area        SystemArea    `bson:"area" json:"area"`
+	Description string        `bson:"description" json:"description"`
+	Media       []SystemMedia `bson:"media" json:"media"` // Progress photos for the area
+}
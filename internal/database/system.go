@@ -29,7 +29,7 @@ type SystemArea struct {
 type SystemAreaMaterial struct {
 	Area      SystemArea                 `bson:"area" json:"area"`
 	Materials []SystemAreaMaterialDetail `bson:"materials" json:"materials"`
-	SubTotal  float64                    `bson:"subTotal" json:"subTotal"`
+	SubTotal  Money                      `bson:"subTotal" json:"subTotal"`
 }
 
 type SystemAreaMaterialDetail struct {
@@ -39,23 +39,24 @@ type SystemAreaMaterialDetail struct {
 	Type         enum.MaterialType          `bson:"type" json:"type"`
 	Brand        string                     `bson:"brand" json:"brand"`
 	Unit         string                     `bson:"unit" json:"unit"`
-	PricePerUnit float64                    `bson:"pricePerUnit" json:"pricePerUnit"`
+	PricePerUnit Money                      `bson:"pricePerUnit" json:"pricePerUnit"`
+	CostPerUnit  Money                      `bson:"costPerUnit" json:"costPerUnit"` // Catalogue cost when the line was quoted, internal only
 	Quantity     float64                    `bson:"quantity" json:"quantity"`
-	SubTotal     float64                    `bson:"subTotal" json:"subTotal"`
+	SubTotal     Money                      `bson:"subTotal" json:"subTotal"`
 	Remark       string                     `bson:"remark" json:"remark"`
 	Description  string                     `bson:"description" json:"description"`
 }
 
 type SystemDiscount struct {
 	Name        string            `bson:"name" json:"name"`
-	Value       float64           `bson:"value" json:"value"`
+	Value       Money             `bson:"value" json:"value"` // Percentage for rate discounts, ringgit for amount discounts, both to two decimal places
 	Type        enum.DiscountType `bson:"type" json:"type"`
 	Description string            `bson:"description" json:"description"`
 }
 
 type SystemAdditionalCharge struct {
 	Name        string                    `bson:"name" json:"name"`
-	Value       float64                   `bson:"value" json:"value"`
+	Value       Money                     `bson:"value" json:"value"` // Percentage for rate charges, ringgit for amount charges, both to two decimal places
 	Type        enum.AdditionalChargeType `bson:"type" json:"type"`
 	Description string                    `bson:"description" json:"description"`
 }
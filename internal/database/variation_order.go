@@ -0,0 +1,40 @@
+package database
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"renotech.com.my/internal/enum"
+)
+
+// VariationOrder records extra or omitted work agreed after a project has started.
+// Approved variation orders adjust the project's revised contract sum; the original stays untouched.
+type VariationOrder struct {
+	ID            *primitive.ObjectID       `bson:"_id,omitempty" json:"_id,omitempty"`
+	Project       primitive.ObjectID        `bson:"project" json:"project"`
+	Company       *primitive.ObjectID       `bson:"company" json:"company"` // Tenant isolation
+	VONumber      string                    `bson:"voNumber" json:"voNumber"`
+	Title         string                    `bson:"title" json:"title"`
+	Description   string                    `bson:"description" json:"description"`
+	Reason        string                    `bson:"reason" json:"reason"` // Why the variation was requested
+	Lines         []VariationOrderLine      `bson:"lines" json:"lines"`
+	TotalAddition Money                     `bson:"totalAddition" json:"totalAddition"`
+	TotalOmission Money                     `bson:"totalOmission" json:"totalOmission"` // Positive value of omitted work
+	NetAmount     Money                     `bson:"netAmount" json:"netAmount"`         // Addition minus omission
+	Status        enum.VariationOrderStatus `bson:"status" json:"status"`
+	ApprovedAt    *time.Time                `bson:"approvedAt,omitempty" json:"approvedAt,omitempty"`
+	ApprovedBy    string                    `bson:"approvedBy" json:"approvedBy"` // Client or staff who approved
+	ActionLogs    []SystemActionLog         `bson:"actionLogs" json:"actionLogs"`
+	CreatedAt     time.Time                 `bson:"createdAt" json:"createdAt"`
+	CreatedBy     primitive.ObjectID        `bson:"createdBy" json:"createdBy"`
+	UpdatedAt     time.Time                 `bson:"updatedAt" json:"updatedAt"`
+	UpdatedBy     *primitive.ObjectID       `bson:"updatedBy" json:"updatedBy"`
+	IsDeleted     bool                      `bson:"isDeleted" json:"isDeleted"`
+}
+
+type VariationOrderLine struct {
+	Type   enum.VariationLineType   `bson:"type" json:"type"`
+	Area   SystemArea               `bson:"area" json:"area"`
+	Item   SystemAreaMaterialDetail `bson:"item" json:"item"`
+	Amount Money                    `bson:"amount" json:"amount"` // Signed: positive for additions, negative for omissions
+}
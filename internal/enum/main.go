@@ -9,6 +9,26 @@ type UserType string
 type ErrorCode string
 type OrderStatus string
 type OrderPriority string
+type DocumentNumberType string
+type DocumentNumberReset string
+type DocumentShareType string
+type SupplierResponseAction string
+type DeliveryProposalStatus string
+type ProjectCostType string
+type ProjectTaskStatus string
+type SiteDiaryWeather string
+type VariationOrderStatus string
+type VariationLineType string
+type InvoiceStatus string
+type InvoiceBillingType string
+type RetentionReleaseStage string
+type PaymentMethod string
+type MoneyRounding string
+type PaymentStatus string
+type QuotationStatus string
+type QuotationClientAction string
+type QuotationMarginWarningType string
+type QuotationImportRowStatus string
 
 const (
 	ErrorCodeValidation   ErrorCode = "VALIDATION_ERROR"
@@ -70,3 +90,130 @@ const (
 	OrderPriorityHigh   OrderPriority = "high"
 	OrderPriorityUrgent OrderPriority = "urgent"
 )
+
+const (
+	DocumentNumberTypePurchaseOrder  DocumentNumberType = "purchase_order"
+	DocumentNumberTypeQuotation      DocumentNumberType = "quotation"
+	DocumentNumberTypeInvoice        DocumentNumberType = "invoice"
+	DocumentNumberTypeVariationOrder DocumentNumberType = "variation_order"
+	DocumentNumberTypeReceipt        DocumentNumberType = "receipt"
+)
+
+const (
+	DocumentNumberResetNever   DocumentNumberReset = "never"
+	DocumentNumberResetYearly  DocumentNumberReset = "yearly"
+	DocumentNumberResetMonthly DocumentNumberReset = "monthly"
+)
+
+const (
+	DocumentShareTypePurchaseOrder   DocumentShareType = "purchase_order"
+	DocumentShareTypeQuotation       DocumentShareType = "quotation"
+	DocumentShareTypeProjectSchedule DocumentShareType = "project_schedule"
+)
+
+const (
+	SupplierResponseActionConfirm         SupplierResponseAction = "confirm"
+	SupplierResponseActionReject          SupplierResponseAction = "reject"
+	SupplierResponseActionProposeDelivery SupplierResponseAction = "propose_delivery"
+)
+
+const (
+	DeliveryProposalStatusPending  DeliveryProposalStatus = "pending"
+	DeliveryProposalStatusAccepted DeliveryProposalStatus = "accepted"
+	DeliveryProposalStatusRejected DeliveryProposalStatus = "rejected"
+)
+
+const (
+	ProjectCostTypeLabour      ProjectCostType = "labour"
+	ProjectCostTypeTransport   ProjectCostType = "transport"
+	ProjectCostTypeSubcontract ProjectCostType = "subcontract"
+)
+
+const (
+	ProjectTaskStatusNotStarted ProjectTaskStatus = "not_started"
+	ProjectTaskStatusInProgress ProjectTaskStatus = "in_progress"
+	ProjectTaskStatusCompleted  ProjectTaskStatus = "completed"
+)
+
+const (
+	SiteDiaryWeatherSunny  SiteDiaryWeather = "sunny"
+	SiteDiaryWeatherCloudy SiteDiaryWeather = "cloudy"
+	SiteDiaryWeatherRainy  SiteDiaryWeather = "rainy"
+	SiteDiaryWeatherStormy SiteDiaryWeather = "stormy"
+)
+
+const (
+	VariationOrderStatusDraft     VariationOrderStatus = "draft"
+	VariationOrderStatusSubmitted VariationOrderStatus = "submitted"
+	VariationOrderStatusApproved  VariationOrderStatus = "approved"
+	VariationOrderStatusRejected  VariationOrderStatus = "rejected"
+	VariationOrderStatusCancelled VariationOrderStatus = "cancelled"
+)
+
+const (
+	VariationLineTypeAddition VariationLineType = "addition"
+	VariationLineTypeOmission VariationLineType = "omission"
+)
+
+const (
+	InvoiceStatusDraft         InvoiceStatus = "draft"
+	InvoiceStatusIssued        InvoiceStatus = "issued"
+	InvoiceStatusPartiallyPaid InvoiceStatus = "partially_paid"
+	InvoiceStatusPaid          InvoiceStatus = "paid"
+	InvoiceStatusVoid          InvoiceStatus = "void"
+)
+
+const (
+	InvoiceBillingTypeStage     InvoiceBillingType = "stage"     // Percentage of the original contract sum
+	InvoiceBillingTypeItems     InvoiceBillingType = "items"     // Selected area line items
+	InvoiceBillingTypeVariation InvoiceBillingType = "variation" // Approved variation orders
+	InvoiceBillingTypeRetention InvoiceBillingType = "retention" // Release of retention
+)
+
+const (
+	RetentionReleaseStageCompletion       RetentionReleaseStage = "completion"        // First half upon practical completion
+	RetentionReleaseStageDefectsLiability RetentionReleaseStage = "defects_liability" // Balance after the defects liability period
+)
+
+const (
+	PaymentMethodCash         PaymentMethod = "cash"
+	PaymentMethodBankTransfer PaymentMethod = "bank_transfer"
+	PaymentMethodCheque       PaymentMethod = "cheque"
+	PaymentMethodCard         PaymentMethod = "card"
+	PaymentMethodOnline       PaymentMethod = "online"
+)
+
+const (
+	PaymentStatusReceived PaymentStatus = "received"
+	PaymentStatusVoid     PaymentStatus = "void"
+)
+
+const (
+	MoneyRoundingLine     MoneyRounding = "line"     // Each line amount is rounded to the sen before it is added up
+	MoneyRoundingDocument MoneyRounding = "document" // Line amounts are added up exactly and only the totals are rounded
+)
+
+const (
+	QuotationStatusDraft    QuotationStatus = "draft"
+	QuotationStatusSent     QuotationStatus = "sent"
+	QuotationStatusAccepted QuotationStatus = "accepted"
+	QuotationStatusRejected QuotationStatus = "rejected"
+	QuotationStatusExpired  QuotationStatus = "expired" // Set by the expiry sweeper once ExpiredAt has passed
+)
+
+const (
+	QuotationClientActionAccept         QuotationClientAction = "accept"
+	QuotationClientActionRequestChanges QuotationClientAction = "request_changes"
+)
+
+const (
+	QuotationMarginWarningMissingCost  QuotationMarginWarningType = "missing_cost"  // No cost recorded, so the margin is unknown
+	QuotationMarginWarningBelowCost    QuotationMarginWarningType = "below_cost"    // Priced below cost
+	QuotationMarginWarningBelowMinimum QuotationMarginWarningType = "below_minimum" // Margin below the company minimum
+)
+
+const (
+	QuotationImportRowMatched   QuotationImportRowStatus = "matched"   // Imported and linked to a catalogue material
+	QuotationImportRowUnmatched QuotationImportRowStatus = "unmatched" // Imported as a free-text line
+	QuotationImportRowInvalid   QuotationImportRowStatus = "invalid"   // Not imported
+)
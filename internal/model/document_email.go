@@ -0,0 +1,20 @@
+package model
+
+import "time"
+
+type DocumentEmailRequest struct {
+	To      []string `json:"to"` // Defaults to the client or supplier email
+	Cc      []string `json:"cc"`
+	Bcc     []string `json:"bcc"`
+	Subject string   `json:"subject"` // Defaults to the document number
+	Message string   `json:"message"` // Plain text, shown above the signature
+}
+
+type DocumentEmailResponse struct {
+	To       []string  `json:"to"`
+	Cc       []string  `json:"cc"`
+	Bcc      []string  `json:"bcc"`
+	Subject  string    `json:"subject"`
+	Filename string    `json:"filename"`
+	SentAt   time.Time `json:"sentAt"`
+}
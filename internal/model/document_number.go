@@ -0,0 +1,19 @@
+package model
+
+import (
+	"renotech.com.my/internal/enum"
+)
+
+type DocumentNumberConfigRequest struct {
+	Type    enum.DocumentNumberType  `json:"type" binding:"required"`
+	Pattern string                   `json:"pattern" binding:"required"`
+	Reset   enum.DocumentNumberReset `json:"reset"`
+}
+
+type DocumentNumberConfigResponse struct {
+	Type      enum.DocumentNumberType  `json:"type"`
+	Pattern   string                   `json:"pattern"`
+	Reset     enum.DocumentNumberReset `json:"reset"`
+	IsDefault bool                     `json:"isDefault"` // True when the company has not configured this type
+	Preview   string                   `json:"preview"`   // Next number, without consuming the sequence
+}
@@ -0,0 +1,18 @@
+package model
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type DocumentShareCreateRequest struct {
+	ExpiresInDays int `json:"expiresInDays"` // Defaults to 14, maximum 90
+}
+
+type DocumentShareResponse struct {
+	ID        primitive.ObjectID `json:"_id"`
+	Token     string             `json:"token"`
+	URL       string             `json:"url"`
+	ExpiresAt time.Time          `json:"expiresAt"`
+}
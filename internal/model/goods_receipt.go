@@ -0,0 +1,80 @@
+package model
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"renotech.com.my/internal/database"
+)
+
+type GoodsReceiptCreateRequest struct {
+	Order        primitive.ObjectID     `json:"order" binding:"required"`
+	ReceivedDate time.Time              `json:"receivedDate" binding:"required"`
+	ReceivedBy   string                 `json:"receivedBy"` // Defaults to the current user
+	Items        []GoodsReceiptItemLine `json:"items" binding:"required,min=1"`
+	Media        []database.SystemMedia `json:"media"`
+	Remark       string                 `json:"remark"`
+}
+
+type GoodsReceiptItemLine struct {
+	ItemIndex        int     `json:"itemIndex"` // Index into Order.Items
+	ReceivedQuantity float64 `json:"receivedQuantity"`
+	RejectedQuantity float64 `json:"rejectedQuantity"`
+	Remark           string  `json:"remark"`
+}
+
+type GoodsReceiptCreateResponse struct {
+	Receipt database.GoodsReceipt `json:"receipt"`
+	Order   database.Order        `json:"order"`
+}
+
+type GoodsReceiptListRequest struct {
+	Page       int                 `json:"page"`
+	Limit      int                 `json:"limit"`
+	Sort       bson.M              `json:"sort"`
+	Order      *primitive.ObjectID `json:"order"`
+	SupplierID *primitive.ObjectID `json:"supplierId"`
+	DateFrom   *time.Time          `json:"dateFrom"` // Received date range
+	DateTo     *time.Time          `json:"dateTo"`
+}
+
+type GoodsReceiptListResponse struct {
+	Data       []bson.M `json:"data"`
+	Page       int      `json:"page"`
+	Limit      int      `json:"limit"`
+	Total      int64    `json:"total"`
+	TotalPages int      `json:"totalPages"`
+}
+
+// Outstanding quantity report models
+type OrderOutstandingRequest struct {
+	SupplierID *primitive.ObjectID `json:"supplierId"`
+	Project    *primitive.ObjectID `json:"project"`
+}
+
+type OrderOutstandingResponse struct {
+	Suppliers        []OrderOutstandingSupplier `json:"suppliers"`
+	OutstandingValue database.Money             `json:"outstandingValue"`
+}
+
+type OrderOutstandingSupplier struct {
+	SupplierID       *primitive.ObjectID    `json:"supplierId"`
+	SupplierName     string                 `json:"supplierName"`
+	OrderCount       int                    `json:"orderCount"`
+	OutstandingValue database.Money         `json:"outstandingValue"`
+	Items            []OrderOutstandingItem `json:"items"`
+}
+
+type OrderOutstandingItem struct {
+	Order               primitive.ObjectID `json:"order"`
+	PONumber            string             `json:"poNumber"`
+	ExpectedDelivery    time.Time          `json:"expectedDelivery"`
+	ItemIndex           int                `json:"itemIndex"`
+	Name                string             `json:"name"`
+	Unit                string             `json:"unit"`
+	OrderedQuantity     float64            `json:"orderedQuantity"`
+	AcceptedQuantity    float64            `json:"acceptedQuantity"` // Received minus rejected
+	OutstandingQuantity float64            `json:"outstandingQuantity"`
+	OutstandingValue    database.Money     `json:"outstandingValue"`
+}
@@ -0,0 +1,66 @@
+package model
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"renotech.com.my/internal/enum"
+)
+
+type InvoiceCreateRequest struct {
+	Project primitive.ObjectID `json:"project" binding:"required"`
+	InvoiceBillingRequest
+}
+
+type InvoiceUpdateRequest struct {
+	ID primitive.ObjectID `json:"_id" binding:"required"`
+	InvoiceBillingRequest
+}
+
+// InvoiceBillingRequest describes what an invoice bills. Only the selection matching
+// BillingType is used: StagePercent, Items, VariationOrders or RetentionRelease.
+type InvoiceBillingRequest struct {
+	BillingType      enum.InvoiceBillingType    `json:"billingType" binding:"required"`
+	Title            string                     `json:"title"`
+	StagePercent     float64                    `json:"stagePercent"`
+	Items            []InvoiceItemSelection     `json:"items"`
+	VariationOrders  []primitive.ObjectID       `json:"variationOrders"`
+	RetentionRelease enum.RetentionReleaseStage `json:"retentionRelease"`
+	IssueDate        time.Time                  `json:"issueDate" binding:"required"`
+	DueDate          *time.Time                 `json:"dueDate"`         // Defaults to issue date plus PaymentTermDays
+	PaymentTermDays  int                        `json:"paymentTermDays"` // Defaults to 30
+	TaxRate          float64                    `json:"taxRate"`
+	Remark           string                     `json:"remark"`
+}
+
+type InvoiceItemSelection struct {
+	Area      string  `json:"area" binding:"required"`
+	ItemIndex int     `json:"itemIndex"` // Index into the area's materials
+	Quantity  float64 `json:"quantity"`  // Defaults to the unbilled quantity
+}
+
+type InvoiceStatusUpdateRequest struct {
+	Status enum.InvoiceStatus `json:"status" binding:"required"`
+	Remark string             `json:"remark"` // Required when voiding, recorded in the action log
+}
+
+type InvoiceListRequest struct {
+	Page      int                 `json:"page"`
+	Limit     int                 `json:"limit"`
+	Sort      bson.M              `json:"sort"`
+	Project   *primitive.ObjectID `json:"project"`
+	Status    enum.InvoiceStatus  `json:"status"`
+	Search    string              `json:"search"`
+	IsOverdue *bool               `json:"isOverdue"`
+	DateFrom  *time.Time          `json:"dateFrom"` // Issue date range
+	DateTo    *time.Time          `json:"dateTo"`
+}
+
+type InvoiceListResponse struct {
+	Data       []bson.M `json:"data"`
+	Page       int      `json:"page"`
+	Limit      int      `json:"limit"`
+	Total      int64    `json:"total"`
+	TotalPages int      `json:"totalPages"`
+}
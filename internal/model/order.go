@@ -11,7 +11,9 @@ import (
 
 // Order CRUD request/response models
 type OrderInitRequest struct {
-	ProjectID primitive.ObjectID `json:"projectId" binding:"required"`
+	ProjectID   primitive.ObjectID            `json:"projectId" binding:"required"`
+	Preview     bool                          `json:"preview"`     // Group items without creating orders
+	Assignments map[string]primitive.ObjectID `json:"assignments"` // Unassigned item key -> supplier ID
 }
 
 type OrderCreateRequest struct {
@@ -73,20 +75,66 @@ type OrderListResponse struct {
 
 type OrderStatusUpdateRequest struct {
 	Status enum.OrderStatus `json:"status" binding:"required"`
+	Remark string           `json:"remark"` // Optional reason, recorded in the action log
+}
+
+// OrderDeliveryProposalReviewRequest accepts or rejects the delivery date proposed by the supplier
+type OrderDeliveryProposalReviewRequest struct {
+	Status enum.DeliveryProposalStatus `json:"status" binding:"required"` // accepted or rejected
+	Remark string                      `json:"remark"`                    // Optional reason, recorded in the action log
 }
 
 type OrderInitResponse struct {
-	Orders  []database.Order `json:"orders"`
-	Summary struct {
+	Orders     []database.Order      `json:"orders"`
+	Unassigned []OrderUnassignedItem `json:"unassigned"` // Items with no supplier, not included in any order
+	Warnings   []string              `json:"warnings"`   // Template components skipped because they left the catalogue
+	Summary    struct {
 		TotalOrders   int                             `json:"totalOrders"`
 		SupplierCount int                             `json:"supplierCount"`
-		TotalValue    float64                         `json:"totalValue"`
+		TotalValue    database.Money                  `json:"totalValue"`
 		BySupplier    map[string]OrderSupplierSummary `json:"bySupplier"`
 	} `json:"summary"`
 }
 
 type OrderSupplierSummary struct {
-	SupplierName string  `json:"supplierName"`
-	ItemCount    int     `json:"itemCount"`
-	TotalValue   float64 `json:"totalValue"`
+	SupplierName string         `json:"supplierName"`
+	ItemCount    int            `json:"itemCount"`
+	TotalValue   database.Money `json:"totalValue"`
+}
+
+type OrderUnassignedItem struct {
+	Key  string             `json:"key"` // Use as assignments key to resolve the supplier
+	Item database.OrderItem `json:"item"`
+}
+
+// Supplier self-service models
+type OrderSupplierRespondRequest struct {
+	Action           enum.SupplierResponseAction `json:"action" binding:"required"`
+	Reason           string                      `json:"reason"`           // Required when rejecting
+	ExpectedDelivery *time.Time                  `json:"expectedDelivery"` // Required when proposing a delivery date
+	RespondentName   string                      `json:"respondentName"`
+}
+
+// OrderSupplierView is the read-only order shown to suppliers, without internal notes or action logs
+type OrderSupplierView struct {
+	PONumber         string                          `json:"poNumber"`
+	CompanyName      string                          `json:"companyName"`
+	Supplier         database.OrderSupplier          `json:"supplier"`
+	OrderDate        time.Time                       `json:"orderDate"`
+	ExpectedDelivery time.Time                       `json:"expectedDelivery"`
+	DeliveryAddress  database.SystemAddress          `json:"deliveryAddress"`
+	DeliveryContact  string                          `json:"deliveryContact"`
+	DeliveryPhone    string                          `json:"deliveryPhone"`
+	DeliveryRemark   string                          `json:"deliveryRemark"`
+	TermConditions   []string                        `json:"termConditions"`
+	Items            []database.OrderItem            `json:"items"`
+	SubTotal         database.Money                  `json:"subTotal"`
+	TaxRate          float64                         `json:"taxRate"`
+	TaxAmount        database.Money                  `json:"taxAmount"`
+	TotalCharge      database.Money                  `json:"totalCharge"`
+	Status           enum.OrderStatus                `json:"status"`
+	Remark           string                          `json:"remark"`
+	SupplierResponse *database.OrderSupplierResponse `json:"supplierResponse,omitempty"`
+	CanRespond       bool                            `json:"canRespond"`
+	LinkExpiresAt    time.Time                       `json:"linkExpiresAt"`
 }
@@ -0,0 +1,116 @@
+package model
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"renotech.com.my/internal/database"
+	"renotech.com.my/internal/enum"
+)
+
+// PaymentCreateRequest records money received. Either Project or Quotation is required.
+// Without explicit Allocations a project payment settles its oldest outstanding invoices first;
+// whatever is left over is kept as credit.
+type PaymentCreateRequest struct {
+	Project      *primitive.ObjectID        `json:"project"`
+	Quotation    *primitive.ObjectID        `json:"quotation"`
+	PaymentDate  time.Time                  `json:"paymentDate" binding:"required"`
+	Method       enum.PaymentMethod         `json:"method" binding:"required"`
+	Reference    string                     `json:"reference"`
+	Amount       database.Money             `json:"amount" binding:"required"`
+	Allocations  []PaymentAllocationRequest `json:"allocations"`
+	KeepAsCredit bool                       `json:"keepAsCredit"` // Skip automatic allocation
+	Remark       string                     `json:"remark"`
+}
+
+type PaymentAllocationRequest struct {
+	Invoice primitive.ObjectID `json:"invoice" binding:"required"`
+	Amount  database.Money     `json:"amount"` // Defaults to the lower of the invoice balance and the remaining credit
+}
+
+// PaymentAllocateRequest applies a payment's credit balance to invoices, oldest first when no allocations are given
+type PaymentAllocateRequest struct {
+	Allocations []PaymentAllocationRequest `json:"allocations"`
+}
+
+type PaymentVoidRequest struct {
+	Reason string `json:"reason" binding:"required"`
+}
+
+type PaymentListRequest struct {
+	Page      int                 `json:"page"`
+	Limit     int                 `json:"limit"`
+	Sort      bson.M              `json:"sort"`
+	Project   *primitive.ObjectID `json:"project"`
+	Quotation *primitive.ObjectID `json:"quotation"`
+	Method    enum.PaymentMethod  `json:"method"`
+	Status    enum.PaymentStatus  `json:"status"`
+	Search    string              `json:"search"`
+	HasCredit *bool               `json:"hasCredit"`
+	DateFrom  *time.Time          `json:"dateFrom"` // Payment date range
+	DateTo    *time.Time          `json:"dateTo"`
+}
+
+type PaymentListResponse struct {
+	Data       []bson.M `json:"data"`
+	Page       int      `json:"page"`
+	Limit      int      `json:"limit"`
+	Total      int64    `json:"total"`
+	TotalPages int      `json:"totalPages"`
+}
+
+// PaymentBalanceRequest summarises the account of a project or a quotation; one of the two is required
+type PaymentBalanceRequest struct {
+	Project   *primitive.ObjectID `json:"project"`
+	Quotation *primitive.ObjectID `json:"quotation"`
+}
+
+type PaymentBalanceResponse struct {
+	TotalInvoiced database.Money `json:"totalInvoiced"` // Issued, partially paid and paid invoices
+	TotalReceived database.Money `json:"totalReceived"` // All payments that are not void
+	TotalPaid     database.Money `json:"totalPaid"`     // Payments allocated to invoices
+	Outstanding   database.Money `json:"outstanding"`   // Invoiced but not yet paid
+	CreditBalance database.Money `json:"creditBalance"` // Received but not allocated to any invoice
+}
+
+type PaymentAgingRequest struct {
+	AsOf    *time.Time          `json:"asOf"` // Defaults to now
+	Project *primitive.ObjectID `json:"project"`
+	Client  string              `json:"client"` // Client name search
+}
+
+type PaymentAgingResponse struct {
+	AsOf    time.Time            `json:"asOf"`
+	Company PaymentAgingBuckets  `json:"company"` // Totals across all clients
+	Clients []PaymentAgingClient `json:"clients"`
+}
+
+// PaymentAgingBuckets groups outstanding balances by days past the due date
+type PaymentAgingBuckets struct {
+	Current        database.Money `json:"current"` // Not yet due
+	Days1To30      database.Money `json:"days1To30"`
+	Days31To60     database.Money `json:"days31To60"`
+	Days61To90     database.Money `json:"days61To90"`
+	Over90         database.Money `json:"over90"`
+	Total          database.Money `json:"total"`
+	Credit         database.Money `json:"credit"`         // Unallocated payments
+	NetOutstanding database.Money `json:"netOutstanding"` // Total less credit
+}
+
+type PaymentAgingClient struct {
+	Client   database.SystemClient `json:"client"`
+	Buckets  PaymentAgingBuckets   `json:"buckets"`
+	Invoices []PaymentAgingInvoice `json:"invoices"`
+}
+
+type PaymentAgingInvoice struct {
+	Invoice       primitive.ObjectID `json:"invoice"`
+	InvoiceNumber string             `json:"invoiceNumber"`
+	Project       primitive.ObjectID `json:"project"`
+	IssueDate     time.Time          `json:"issueDate"`
+	DueDate       time.Time          `json:"dueDate"`
+	DaysOverdue   int                `json:"daysOverdue"`
+	Bucket        string             `json:"bucket"`
+	BalanceDue    database.Money     `json:"balanceDue"`
+}
@@ -17,13 +17,14 @@ type ProjectCreateRequest struct {
 
 type ProjectUpdateRequest struct {
 	ID                  primitive.ObjectID                `json:"_id" binding:"required"`
+	Name                string                            `json:"name"`
 	Description         string                            `json:"description"`
 	Remark              string                            `json:"remark"`
-	AreaMaterials       []database.SystemAreaMaterial    `json:"areaMaterials"`
-	Discount            database.SystemDiscount          `json:"discount"`
-	Quotation           primitive.ObjectID               `json:"quotation"`
-	PIC                 []primitive.ObjectID             `json:"pic" binding:"required,min=1"`
-	EstimatedCompleteAt time.Time                        `json:"estimatedCompleteAt" binding:"required"`
+	AreaMaterials       []database.SystemAreaMaterial     `json:"areaMaterials"`     // Optional, must match the project; scope changes need a variation order
+	Discounts           []database.SystemDiscount         `json:"discounts"`         // Optional, must match the project
+	AdditionalCharges   []database.SystemAdditionalCharge `json:"additionalCharges"` // Optional, must match the project
+	PIC                 []primitive.ObjectID              `json:"pic" binding:"required,min=1"`
+	EstimatedCompleteAt time.Time                         `json:"estimatedCompleteAt" binding:"required"`
 }
 
 type ProjectListRequest struct {
@@ -31,8 +32,10 @@ type ProjectListRequest struct {
 	Limit       int                 `json:"limit"`
 	Sort        bson.M              `json:"sort"`
 	Search      string              `json:"search"`
+	Name        string              `json:"name"`
 	Description string              `json:"description"`
 	Folder      *primitive.ObjectID `json:"folder"`
+	Quotation   *primitive.ObjectID `json:"quotation"`
 	IsStared    *bool               `json:"isStared"`
 }
 
@@ -46,4 +49,7 @@ type ProjectListResponse struct {
 
 type ProjectToggleStarRequest struct {
 	IsStared bool `json:"isStared"`
-}
\ No newline at end of file
+}
+type ProjectPICRequest struct {
+	PIC []primitive.ObjectID `json:"pic" binding:"required,min=1"`
+}
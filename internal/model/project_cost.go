@@ -0,0 +1,93 @@
+package model
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"renotech.com.my/internal/database"
+	"renotech.com.my/internal/enum"
+)
+
+type ProjectCostCreateRequest struct {
+	Project     primitive.ObjectID     `json:"project" binding:"required"`
+	Type        enum.ProjectCostType   `json:"type" binding:"required"`
+	Area        string                 `json:"area"`
+	Description string                 `json:"description"`
+	Amount      database.Money         `json:"amount" binding:"required"`
+	IncurredAt  time.Time              `json:"incurredAt" binding:"required"`
+	Reference   string                 `json:"reference"`
+	Media       []database.SystemMedia `json:"media"`
+}
+
+type ProjectCostUpdateRequest struct {
+	ID          primitive.ObjectID     `json:"_id" binding:"required"`
+	Type        enum.ProjectCostType   `json:"type" binding:"required"`
+	Area        string                 `json:"area"`
+	Description string                 `json:"description"`
+	Amount      database.Money         `json:"amount" binding:"required"`
+	IncurredAt  time.Time              `json:"incurredAt" binding:"required"`
+	Reference   string                 `json:"reference"`
+	Media       []database.SystemMedia `json:"media"`
+}
+
+type ProjectCostListRequest struct {
+	Page     int                  `json:"page"`
+	Limit    int                  `json:"limit"`
+	Sort     bson.M               `json:"sort"`
+	Project  primitive.ObjectID   `json:"project" binding:"required"`
+	Type     enum.ProjectCostType `json:"type"`
+	Area     string               `json:"area"`
+	DateFrom *time.Time           `json:"dateFrom"` // Incurred date range
+	DateTo   *time.Time           `json:"dateTo"`
+}
+
+type ProjectCostListResponse struct {
+	Data       []bson.M `json:"data"`
+	Page       int      `json:"page"`
+	Limit      int      `json:"limit"`
+	Total      int64    `json:"total"`
+	TotalPages int      `json:"totalPages"`
+}
+
+// Project cost report models
+type ProjectCostReportResponse struct {
+	Project            primitive.ObjectID        `json:"project"`
+	Name               string                    `json:"name"`
+	QuotedAmount       database.Money            `json:"quotedAmount"`       // Project TotalNettCharge
+	ApprovedVariation  database.Money            `json:"approvedVariation"`  // Net of approved variation orders
+	RevisedContractSum database.Money            `json:"revisedContractSum"` // Quoted amount plus approved variations
+	BudgetCost         database.Money            `json:"budgetCost"`         // Project TotalCost from material cost prices
+	CommittedCost      database.Money            `json:"committedCost"`      // Confirmed, partial and delivered orders plus manual costs
+	ActualCost         database.Money            `json:"actualCost"`         // Accepted deliveries plus manual costs
+	GrossMargin        database.Money            `json:"grossMargin"`        // Revised contract sum minus committed cost
+	MarginPercent      float64                   `json:"marginPercent"`
+	OrderCost          ProjectCostBreakdown      `json:"orderCost"`
+	ManualCost         map[string]database.Money `json:"manualCost"` // Keyed by cost type
+	Areas              []ProjectCostAreaReport   `json:"areas"`
+	Orders             []ProjectCostOrderSummary `json:"orders"`
+}
+
+type ProjectCostBreakdown struct {
+	Committed database.Money `json:"committed"`
+	Actual    database.Money `json:"actual"`
+}
+
+type ProjectCostAreaReport struct {
+	Area          string         `json:"area"`         // Empty for costs not allocated to an area
+	QuotedAmount  database.Money `json:"quotedAmount"` // Includes approved variations in the area
+	BudgetCost    database.Money `json:"budgetCost"`
+	CommittedCost database.Money `json:"committedCost"`
+	ActualCost    database.Money `json:"actualCost"`
+	GrossMargin   database.Money `json:"grossMargin"`
+	MarginPercent float64        `json:"marginPercent"`
+}
+
+type ProjectCostOrderSummary struct {
+	Order         primitive.ObjectID `json:"order"`
+	PONumber      string             `json:"poNumber"`
+	SupplierName  string             `json:"supplierName"`
+	Status        enum.OrderStatus   `json:"status"`
+	CommittedCost database.Money     `json:"committedCost"`
+	ActualCost    database.Money     `json:"actualCost"`
+}
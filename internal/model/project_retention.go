@@ -0,0 +1,47 @@
+package model
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"renotech.com.my/internal/database"
+	"renotech.com.my/internal/enum"
+)
+
+type ProjectRetentionUpdateRequest struct {
+	RetentionPercent       float64 `json:"retentionPercent"`
+	RetentionCapPercent    float64 `json:"retentionCapPercent"` // Percentage of the contract sum, 0 for no limit
+	DefectsLiabilityMonths int     `json:"defectsLiabilityMonths"`
+}
+
+type ProjectCompletionRequest struct {
+	CompletedAt time.Time `json:"completedAt" binding:"required"` // Date of practical completion
+}
+
+// ProjectRetentionResponse is the retention ledger of a project, built from its non-void invoices
+type ProjectRetentionResponse struct {
+	Project                primitive.ObjectID         `json:"project"`
+	RetentionPercent       float64                    `json:"retentionPercent"`
+	RetentionCapPercent    float64                    `json:"retentionCapPercent"`
+	RetentionCap           database.Money             `json:"retentionCap"` // Cap amount on the current contract sum, 0 for no limit
+	DefectsLiabilityMonths int                        `json:"defectsLiabilityMonths"`
+	CompletedAt            *time.Time                 `json:"completedAt"`
+	DefectsLiabilityEndsAt *time.Time                 `json:"defectsLiabilityEndsAt"`
+	TotalDeducted          database.Money             `json:"totalDeducted"`
+	TotalReleased          database.Money             `json:"totalReleased"`
+	Balance                database.Money             `json:"balance"`               // Retention still held
+	NextRelease            enum.RetentionReleaseStage `json:"nextRelease,omitempty"` // Release that can be billed now, if any
+	NextReleaseAmount      database.Money             `json:"nextReleaseAmount"`
+	Entries                []ProjectRetentionEntry    `json:"entries"`
+}
+
+type ProjectRetentionEntry struct {
+	Invoice       primitive.ObjectID `json:"invoice"`
+	InvoiceNumber string             `json:"invoiceNumber"`
+	InvoiceStatus enum.InvoiceStatus `json:"invoiceStatus"`
+	Date          time.Time          `json:"date"` // Invoice issue date
+	Type          string             `json:"type"` // "deduction" or "release"
+	Description   string             `json:"description"`
+	Amount        database.Money     `json:"amount"`  // Positive for deductions, negative for releases
+	Balance       database.Money     `json:"balance"` // Running balance held
+}
@@ -0,0 +1,89 @@
+package model
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"renotech.com.my/internal/enum"
+)
+
+type ProjectTaskCreateRequest struct {
+	Project         primitive.ObjectID   `json:"project" binding:"required"`
+	Name            string               `json:"name" binding:"required"`
+	Description     string               `json:"description"`
+	Area            string               `json:"area"`
+	IsMilestone     bool                 `json:"isMilestone"`
+	PlannedStart    time.Time            `json:"plannedStart" binding:"required"`
+	PlannedEnd      time.Time            `json:"plannedEnd" binding:"required"`
+	ActualStart     *time.Time           `json:"actualStart"`
+	ActualEnd       *time.Time           `json:"actualEnd"`
+	Dependencies    []primitive.ObjectID `json:"dependencies"`
+	PercentComplete float64              `json:"percentComplete"`
+	AssignedTo      *primitive.ObjectID  `json:"assignedTo"`
+}
+
+type ProjectTaskUpdateRequest struct {
+	ID              primitive.ObjectID   `json:"_id" binding:"required"`
+	Name            string               `json:"name" binding:"required"`
+	Description     string               `json:"description"`
+	Area            string               `json:"area"`
+	IsMilestone     bool                 `json:"isMilestone"`
+	PlannedStart    time.Time            `json:"plannedStart" binding:"required"`
+	PlannedEnd      time.Time            `json:"plannedEnd" binding:"required"`
+	ActualStart     *time.Time           `json:"actualStart"`
+	ActualEnd       *time.Time           `json:"actualEnd"`
+	Dependencies    []primitive.ObjectID `json:"dependencies"`
+	PercentComplete float64              `json:"percentComplete"`
+	AssignedTo      *primitive.ObjectID  `json:"assignedTo"`
+}
+
+type ProjectTaskListRequest struct {
+	Page       int                 `json:"page"`
+	Limit      int                 `json:"limit"`
+	Sort       bson.M              `json:"sort"`
+	Project    primitive.ObjectID  `json:"project" binding:"required"`
+	Search     string              `json:"search"`
+	Area       string              `json:"area"`
+	AssignedTo *primitive.ObjectID `json:"assignedTo"`
+}
+
+type ProjectTaskListResponse struct {
+	Data       []bson.M `json:"data"`
+	Page       int      `json:"page"`
+	Limit      int      `json:"limit"`
+	Total      int64    `json:"total"`
+	TotalPages int      `json:"totalPages"`
+}
+
+// Gantt chart models
+type ProjectGanttResponse struct {
+	Project             primitive.ObjectID `json:"project"`
+	Name                string             `json:"name"`
+	Start               *time.Time         `json:"start"` // Earliest planned start, nil when there are no tasks
+	End                 *time.Time         `json:"end"`   // Latest planned end, nil when there are no tasks
+	EstimatedCompleteAt time.Time          `json:"estimatedCompleteAt"`
+	ForecastEnd         *time.Time         `json:"forecastEnd"` // Latest actual or overdue end across tasks
+	PercentComplete     float64            `json:"percentComplete"`
+	DelayedTaskCount    int                `json:"delayedTaskCount"`
+	Tasks               []ProjectGanttTask `json:"tasks"`
+}
+
+type ProjectGanttTask struct {
+	ID             primitive.ObjectID     `json:"id"`
+	Name           string                 `json:"name"`
+	Area           string                 `json:"area"`
+	IsMilestone    bool                   `json:"isMilestone"`
+	Start          time.Time              `json:"start"` // Planned
+	End            time.Time              `json:"end"`   // Planned
+	ActualStart    *time.Time             `json:"actualStart"`
+	ActualEnd      *time.Time             `json:"actualEnd"`
+	Progress       float64                `json:"progress"`
+	Dependencies   []string               `json:"dependencies"`
+	AssignedTo     *primitive.ObjectID    `json:"assignedTo"`
+	AssignedToName string                 `json:"assignedToName"`
+	Status         enum.ProjectTaskStatus `json:"status"`
+	StartDelayDays int                    `json:"startDelayDays"` // Positive when the task started (or is still waiting to start) after its planned start
+	EndDelayDays   int                    `json:"endDelayDays"`   // Positive when the task finished (or is still running) after its planned end
+	IsDelayed      bool                   `json:"isDelayed"`
+}
@@ -1,20 +1,27 @@
 package model
 
 import (
+	"time"
+
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
+	"renotech.com.my/internal/database"
+	"renotech.com.my/internal/enum"
 )
 
 // Quotation CRUD request/response models
 type QuotationListRequest struct {
-	Page        int                 `json:"page"`
-	Limit       int                 `json:"limit"`
-	Sort        bson.M              `json:"sort"`
-	Search      string              `json:"search"`
-	Name        string              `json:"name"`
-	Description string              `json:"description"`
-	Folder      *primitive.ObjectID `json:"folder"`
-	IsStared    *bool               `json:"isStared"`
+	Page        int                    `json:"page"`
+	Limit       int                    `json:"limit"`
+	Sort        bson.M                 `json:"sort"`
+	Search      string                 `json:"search"`
+	Name        string                 `json:"name"`
+	Description string                 `json:"description"`
+	Folder      *primitive.ObjectID    `json:"folder"`
+	IsStared    *bool                  `json:"isStared"`
+	Status      []enum.QuotationStatus `json:"status"`      // Any of the given statuses
+	ExpiresFrom *time.Time             `json:"expiresFrom"` // Expiry date range
+	ExpiresTo   *time.Time             `json:"expiresTo"`
 }
 
 type QuotationListResponse struct {
@@ -29,6 +36,11 @@ type QuotationToggleStarRequest struct {
 	IsStared bool `json:"isStared"`
 }
 
+type QuotationStatusUpdateRequest struct {
+	Status enum.QuotationStatus `json:"status" binding:"required"`
+	Remark string               `json:"remark"` // Optional reason, recorded in the action log
+}
+
 type QuotationCreateFolderRequest struct {
 	ID   primitive.ObjectID `json:"_id"`
 	Name string             `json:"name"`
@@ -38,3 +50,59 @@ type QuotationMoveRequest struct {
 	ID     primitive.ObjectID  `json:"_id"`
 	Folder *primitive.ObjectID `json:"folder"`
 }
+
+// QuotationSaveResponse is the saved quotation with the pricing report of the request that saved it
+type QuotationSaveResponse struct {
+	*database.Quotation
+	PricingReport QuotationPricingReport `json:"pricingReport"`
+}
+
+// QuotationPricingReport lists the amounts sent by the client that differ from the server calculation.
+// The calculated amounts are the ones stored.
+type QuotationPricingReport struct {
+	IsConsistent bool                    `json:"isConsistent"`
+	Issues       []QuotationPricingIssue `json:"issues"`
+}
+
+type QuotationPricingIssue struct {
+	Path       string         `json:"path"` // e.g. areaMaterials[0].materials[2].template[1].subTotal
+	Area       string         `json:"area,omitempty"`
+	Item       string         `json:"item,omitempty"`
+	Field      string         `json:"field"`
+	Sent       database.Money `json:"sent"`
+	Calculated database.Money `json:"calculated"`
+}
+
+// Client self-service models
+type QuotationClientRespondRequest struct {
+	Action    enum.QuotationClientAction `json:"action" binding:"required"`
+	Name      string                     `json:"name" binding:"required"`
+	Message   string                     `json:"message"`   // Required when requesting changes
+	Signature string                     `json:"signature"` // PNG data URL of the drawn signature, required when accepting
+}
+
+// QuotationClientView is the quotation shown to clients, without internal notes or action logs
+type QuotationClientView struct {
+	QuotationNumber       string                            `json:"quotationNumber"`
+	Name                  string                            `json:"name"`
+	CompanyName           string                            `json:"companyName"`
+	Client                database.SystemClient             `json:"client"`
+	Address               database.SystemAddress            `json:"address"`
+	ExpiredAt             time.Time                         `json:"expiredAt"`
+	Description           string                            `json:"description"`
+	Remark                string                            `json:"remark"`
+	Revision              int                               `json:"revision"`
+	RevisionLabel         string                            `json:"revisionLabel"`
+	AreaMaterials         []database.SystemAreaMaterial     `json:"areaMaterials"`
+	Discounts             []database.SystemDiscount         `json:"discounts"`
+	AdditionalCharges     []database.SystemAdditionalCharge `json:"additionalCharges"`
+	TotalCharge           database.Money                    `json:"totalCharge"`
+	TotalDiscount         database.Money                    `json:"totalDiscount"`
+	TotalAdditionalCharge database.Money                    `json:"totalAdditionalCharge"`
+	TotalNettCharge       database.Money                    `json:"totalNettCharge"`
+	TermConditions        []string                          `json:"termConditions"`
+	Status                enum.QuotationStatus              `json:"status"`
+	Acceptance            *database.QuotationAcceptance     `json:"acceptance,omitempty"`
+	CanRespond            bool                              `json:"canRespond"`
+	LinkExpiresAt         time.Time                         `json:"linkExpiresAt"`
+}
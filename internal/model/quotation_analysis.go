@@ -0,0 +1,55 @@
+package model
+
+import (
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"renotech.com.my/internal/database"
+	"renotech.com.my/internal/enum"
+)
+
+// QuotationAnalysisResponse is the internal cost and margin breakdown of a quotation.
+// It is for estimators only and is never part of client documents.
+type QuotationAnalysisResponse struct {
+	Quotation            primitive.ObjectID       `json:"quotation"`
+	Revision             int                      `json:"revision"`
+	MinimumMarginPercent float64                  `json:"minimumMarginPercent"` // Company setting, 0 when not configured
+	Areas                []QuotationAreaAnalysis  `json:"areas"`
+	Overall              QuotationMargin          `json:"overall"` // Line totals before discounts and additional charges
+	Nett                 QuotationMargin          `json:"nett"`    // Against the nett charge after discounts and additional charges
+	Warnings             []QuotationMarginWarning `json:"warnings"`
+}
+
+type QuotationAreaAnalysis struct {
+	Area   string                  `json:"area"`
+	Lines  []QuotationLineAnalysis `json:"lines"`
+	Totals QuotationMargin         `json:"totals"`
+}
+
+type QuotationLineAnalysis struct {
+	No           string              `json:"no"` // Same numbering as the quotation document
+	Path         string              `json:"path"`
+	Material     *primitive.ObjectID `json:"material"`
+	Name         string              `json:"name"`
+	Unit         string              `json:"unit"`
+	Quantity     float64             `json:"quantity"`
+	CostPerUnit  database.Money      `json:"costPerUnit"`
+	PricePerUnit database.Money      `json:"pricePerUnit"`
+	QuotationMargin
+}
+
+// QuotationMargin compares cost with price. Markup is profit over cost, margin is profit over price.
+type QuotationMargin struct {
+	Cost          database.Money `json:"cost"`
+	Price         database.Money `json:"price"`
+	Profit        database.Money `json:"profit"`
+	MarkupPercent float64        `json:"markupPercent"`
+	MarginPercent float64        `json:"marginPercent"`
+}
+
+type QuotationMarginWarning struct {
+	Type          enum.QuotationMarginWarningType `json:"type"`
+	Path          string                          `json:"path,omitempty"` // Empty for warnings on the whole quotation
+	Area          string                          `json:"area,omitempty"`
+	Item          string                          `json:"item,omitempty"`
+	MarginPercent float64                         `json:"marginPercent"`
+	Message       string                          `json:"message"`
+}
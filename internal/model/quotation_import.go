@@ -0,0 +1,40 @@
+package model
+
+import (
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"renotech.com.my/internal/database"
+	"renotech.com.my/internal/enum"
+)
+
+// QuotationImportRequest holds the form fields sent with a bill of quantities file
+type QuotationImportRequest struct {
+	FileName string              `json:"fileName"`
+	Name     string              `json:"name"` // Defaults to the file name
+	Folder   *primitive.ObjectID `json:"folder"`
+}
+
+// QuotationImportResponse is the draft quotation created from a bill of quantities with a report of
+// every row read from the file
+type QuotationImportResponse struct {
+	Quotation *database.Quotation  `json:"quotation"`
+	Summary   QuotationImportCount `json:"summary"`
+	Rows      []QuotationImportRow `json:"rows"`
+}
+
+type QuotationImportCount struct {
+	Total     int `json:"total"`
+	Matched   int `json:"matched"`
+	Unmatched int `json:"unmatched"`
+	Invalid   int `json:"invalid"`
+}
+
+type QuotationImportRow struct {
+	Row          int                           `json:"row"` // Row number in the file, starting at 1
+	Status       enum.QuotationImportRowStatus `json:"status"`
+	Area         string                        `json:"area"`
+	Item         string                        `json:"item"`
+	Material     *primitive.ObjectID           `json:"material,omitempty"`
+	MaterialName string                        `json:"materialName,omitempty"`
+	Score        float64                       `json:"score,omitempty"` // Similarity of the item name to the matched material, 0 to 1
+	Messages     []string                      `json:"messages"`
+}
@@ -0,0 +1,81 @@
+package model
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"renotech.com.my/internal/database"
+)
+
+type QuotationRevisionRestoreRequest struct {
+	Reason string `json:"reason"`
+}
+
+type QuotationRevisionSummary struct {
+	Revision        int                `json:"revision"`
+	Label           string             `json:"label"`
+	Reason          string             `json:"reason"`
+	TotalNettCharge database.Money     `json:"totalNettCharge"`
+	IsCurrent       bool               `json:"isCurrent"`
+	CreatedAt       time.Time          `json:"createdAt"`
+	CreatedBy       primitive.ObjectID `json:"createdBy"`
+	CreatedByName   string             `json:"createdByName"`
+}
+
+// QuotationRevisionDiffResponse lists what changed from one revision to another. Only changed
+// fields, areas, lines and adjustments are listed; totals are always listed.
+type QuotationRevisionDiffResponse struct {
+	Quotation   primitive.ObjectID                  `json:"quotation"`
+	From        QuotationRevisionSummary            `json:"from"`
+	To          QuotationRevisionSummary            `json:"to"`
+	Fields      []QuotationRevisionFieldChange      `json:"fields"`
+	Areas       []QuotationRevisionAreaChange       `json:"areas"`
+	Adjustments []QuotationRevisionAdjustmentChange `json:"adjustments"`
+	Totals      []QuotationRevisionTotalChange      `json:"totals"`
+}
+
+type QuotationRevisionFieldChange struct {
+	Field string `json:"field"`
+	From  string `json:"from"`
+	To    string `json:"to"`
+}
+
+type QuotationRevisionAreaChange struct {
+	Area         string                        `json:"area"`
+	Change       string                        `json:"change"` // "added", "removed" or "changed"
+	FromSubTotal database.Money                `json:"fromSubTotal"`
+	ToSubTotal   database.Money                `json:"toSubTotal"`
+	Delta        database.Money                `json:"delta"`
+	Lines        []QuotationRevisionLineChange `json:"lines"`
+}
+
+type QuotationRevisionLineChange struct {
+	Item             string              `json:"item"`
+	Unit             string              `json:"unit"`
+	Material         *primitive.ObjectID `json:"material,omitempty"`
+	Change           string              `json:"change"` // "added", "removed" or "changed"
+	FromQuantity     float64             `json:"fromQuantity"`
+	ToQuantity       float64             `json:"toQuantity"`
+	FromPricePerUnit database.Money      `json:"fromPricePerUnit"`
+	ToPricePerUnit   database.Money      `json:"toPricePerUnit"`
+	FromSubTotal     database.Money      `json:"fromSubTotal"`
+	ToSubTotal       database.Money      `json:"toSubTotal"`
+	Delta            database.Money      `json:"delta"`
+}
+
+type QuotationRevisionAdjustmentChange struct {
+	Kind      string         `json:"kind"` // "discount" or "additionalCharge"
+	Name      string         `json:"name"`
+	Change    string         `json:"change"` // "added", "removed" or "changed"
+	FromType  string         `json:"fromType"`
+	ToType    string         `json:"toType"`
+	FromValue database.Money `json:"fromValue"`
+	ToValue   database.Money `json:"toValue"`
+}
+
+type QuotationRevisionTotalChange struct {
+	Field string         `json:"field"`
+	From  database.Money `json:"from"`
+	To    database.Money `json:"to"`
+	Delta database.Money `json:"delta"`
+}
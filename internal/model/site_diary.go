@@ -0,0 +1,52 @@
+package model
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"renotech.com.my/internal/database"
+	"renotech.com.my/internal/enum"
+)
+
+type SiteDiaryCreateRequest struct {
+	Project  primitive.ObjectID       `json:"project" binding:"required"`
+	Date     time.Time                `json:"date" binding:"required"`
+	Weather  enum.SiteDiaryWeather    `json:"weather"`
+	Manpower int                      `json:"manpower"`
+	WorkDone []database.SiteDiaryWork `json:"workDone"`
+	Issues   []string                 `json:"issues"`
+	Media    []database.SystemMedia   `json:"media"`
+	Remark   string                   `json:"remark"`
+}
+
+type SiteDiaryUpdateRequest struct {
+	ID       primitive.ObjectID       `json:"_id" binding:"required"`
+	Date     time.Time                `json:"date" binding:"required"`
+	Weather  enum.SiteDiaryWeather    `json:"weather"`
+	Manpower int                      `json:"manpower"`
+	WorkDone []database.SiteDiaryWork `json:"workDone"`
+	Issues   []string                 `json:"issues"`
+	Media    []database.SystemMedia   `json:"media"`
+	Remark   string                   `json:"remark"`
+}
+
+// SiteDiaryFeedRequest pages through a project's diary newest first. Pass the
+// nextCursor of the previous response to load older entries.
+type SiteDiaryFeedRequest struct {
+	Project  primitive.ObjectID `json:"project" binding:"required"`
+	Area     string             `json:"area"` // Only entries with work done in this area
+	DateFrom *time.Time         `json:"dateFrom"`
+	DateTo   *time.Time         `json:"dateTo"`
+	Cursor   *SiteDiaryCursor   `json:"cursor"`
+	Limit    int                `json:"limit"`
+}
+
+type SiteDiaryCursor struct {
+	Date time.Time          `json:"date"`
+	ID   primitive.ObjectID `json:"_id"`
+}
+
+type SiteDiaryFeedResponse struct {
+	Data       []database.SiteDiary `json:"data"`
+	NextCursor *SiteDiaryCursor     `json:"nextCursor"` // Nil when there are no older entries
+}
@@ -0,0 +1,47 @@
+package model
+
+import (
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"renotech.com.my/internal/database"
+	"renotech.com.my/internal/enum"
+)
+
+type VariationOrderCreateRequest struct {
+	Project     primitive.ObjectID            `json:"project" binding:"required"`
+	Title       string                        `json:"title" binding:"required"`
+	Description string                        `json:"description"`
+	Reason      string                        `json:"reason"`
+	Lines       []database.VariationOrderLine `json:"lines" binding:"required,min=1"`
+}
+
+type VariationOrderUpdateRequest struct {
+	ID          primitive.ObjectID            `json:"_id" binding:"required"`
+	Title       string                        `json:"title" binding:"required"`
+	Description string                        `json:"description"`
+	Reason      string                        `json:"reason"`
+	Lines       []database.VariationOrderLine `json:"lines" binding:"required,min=1"`
+}
+
+type VariationOrderStatusUpdateRequest struct {
+	Status     enum.VariationOrderStatus `json:"status" binding:"required"`
+	ApprovedBy string                    `json:"approvedBy"` // Defaults to the current user when approving
+	Remark     string                    `json:"remark"`     // Optional reason, recorded in the action log
+}
+
+type VariationOrderListRequest struct {
+	Page    int                       `json:"page"`
+	Limit   int                       `json:"limit"`
+	Sort    bson.M                    `json:"sort"`
+	Project *primitive.ObjectID       `json:"project"`
+	Status  enum.VariationOrderStatus `json:"status"`
+	Search  string                    `json:"search"`
+}
+
+type VariationOrderListResponse struct {
+	Data       []bson.M `json:"data"`
+	Page       int      `json:"page"`
+	Limit      int      `json:"limit"`
+	Total      int64    `json:"total"`
+	TotalPages int      `json:"totalPages"`
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"math"
 	"strings"
+	"sync"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
@@ -26,6 +27,14 @@ func companyTenantCreateValidation(input *database.Company, systemContext *model
 		return err
 	}
 
+	if err := validateCompanyMoneyRounding(input); err != nil {
+		return err
+	}
+
+	if err := validateCompanyMinimumMargin(input); err != nil {
+		return err
+	}
+
 	// Check for duplicate company name (tenant scope)
 	if strings.TrimSpace(input.Name) != "" {
 		filter := bson.M{
@@ -133,6 +142,14 @@ func companyUpdateValidation(input *database.Company, systemContext *model.Syste
 		return err
 	}
 
+	if err := validateCompanyMoneyRounding(input); err != nil {
+		return err
+	}
+
+	if err := validateCompanyMinimumMargin(input); err != nil {
+		return err
+	}
+
 	// Check for duplicate company name (excluding current company)
 	if strings.TrimSpace(input.Name) != "" && input.ID != nil {
 		filter := bson.M{
@@ -175,21 +192,23 @@ func CompanyTenantUpdate(input *database.Company, systemContext *model.SystemCon
 
 	value := bson.M{
 		"$set": bson.M{
-			"name":                input.Name,
-			"clientDisplayName":   input.ClientDisplayName,
-			"supplierDisplayName": input.SupplierDisplayName,
-			"address":             input.Address,
-			"website":             input.Website,
-			"email":               input.Email,
-			"description":         input.Description,
-			"owner":               input.Owner,
-			"logo":                input.Logo,
-			"registrationNo":      input.RegistrationNo,
-			"contact":             input.Contact,
-			"termCondition":       input.TermCondition,
-			"isEnabled":           input.IsEnabled,
-			"updatedAt":           time.Now(),
-			"updatedBy":           systemContext.User.ID,
+			"name":                 input.Name,
+			"clientDisplayName":    input.ClientDisplayName,
+			"supplierDisplayName":  input.SupplierDisplayName,
+			"address":              input.Address,
+			"website":              input.Website,
+			"email":                input.Email,
+			"description":          input.Description,
+			"owner":                input.Owner,
+			"logo":                 input.Logo,
+			"registrationNo":       input.RegistrationNo,
+			"contact":              input.Contact,
+			"termCondition":        input.TermCondition,
+			"moneyRounding":        input.MoneyRounding,
+			"minimumMarginPercent": input.MinimumMarginPercent,
+			"isEnabled":            input.IsEnabled,
+			"updatedAt":            time.Now(),
+			"updatedBy":            systemContext.User.ID,
 		},
 	}
 
@@ -463,6 +482,26 @@ func CompanyDelete(input *primitive.ObjectID, systemContext *model.SystemContext
 }
 
 // Helper functions
+
+var (
+	companyLocationOnce  sync.Once
+	companyLocationValue *time.Location
+)
+
+// companyLocation is the time zone the business works in, used wherever a calendar date has to be
+// taken from a timestamp. It comes from TIMEZONE and falls back to Malaysian time.
+func companyLocation() *time.Location {
+	companyLocationOnce.Do(func() {
+		location, err := time.LoadLocation(utils.GetEnvString("TIMEZONE", "Asia/Kuala_Lumpur"))
+		if err != nil {
+			location = time.FixedZone("MYT", 8*60*60)
+		}
+		companyLocationValue = location
+	})
+
+	return companyLocationValue
+}
+
 func executeCompanyList(collection *mongo.Collection, filter bson.M, input model.CompanyListRequest, systemContext *model.SystemContext) (*model.CompanyListResponse, error) {
 	// Get total count
 	total, err := collection.CountDocuments(context.Background(), filter)
@@ -531,3 +570,31 @@ func executeCompanyList(collection *mongo.Collection, filter bson.M, input model
 
 	return response, nil
 }
+
+// validateCompanyMoneyRounding checks the money rounding setting, defaulting to rounding each line
+func validateCompanyMoneyRounding(input *database.Company) error {
+	switch input.MoneyRounding {
+	case "":
+		input.MoneyRounding = enum.MoneyRoundingLine
+	case enum.MoneyRoundingLine, enum.MoneyRoundingDocument:
+	default:
+		return utils.SystemError(
+			enum.ErrorCodeValidation,
+			"Invalid money rounding",
+			map[string]interface{}{"moneyRounding": input.MoneyRounding},
+		)
+	}
+	return nil
+}
+
+// validateCompanyMinimumMargin checks the minimum quotation margin used by the margin analysis
+func validateCompanyMinimumMargin(input *database.Company) error {
+	if input.MinimumMarginPercent < 0 || input.MinimumMarginPercent >= 100 {
+		return utils.SystemError(
+			enum.ErrorCodeValidation,
+			"Minimum margin must be between 0 and 100 percent",
+			map[string]interface{}{"minimumMarginPercent": input.MinimumMarginPercent},
+		)
+	}
+	return nil
+}
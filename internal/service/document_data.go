@@ -0,0 +1,154 @@
+package service
+
+import (
+	"encoding/base64"
+	"fmt"
+	"html"
+	"mime"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"renotech.com.my/internal/database"
+	"renotech.com.my/internal/model"
+	"renotech.com.my/internal/utils"
+)
+
+// Shared helpers for mapping database records into document template payloads.
+// Text values are HTML-escaped because the template engine substitutes them verbatim.
+
+// documentCompanyData returns the company header fields used by every document template
+func documentCompanyData(company *database.Company, systemContext *model.SystemContext) bson.M {
+	return bson.M{
+		"companyName":           html.EscapeString(company.Name),
+		"companyRegistrationNo": html.EscapeString(company.RegistrationNo),
+		"companyAddress":        documentMultiline(company.Address),
+		"companyEmail":          html.EscapeString(company.Email),
+		"companyContact":        html.EscapeString(company.Contact),
+		"companyWebsite":        html.EscapeString(company.Website),
+		"companyLogo":           documentImageDataURI(company.Logo, systemContext),
+	}
+}
+
+// documentImageDataURI inlines an uploaded image so the PDF renderer does not need to fetch it.
+// Only media of the company stored under the upload directory is read; anything else is left out,
+// as rendered documents are also served on public share links.
+func documentImageDataURI(path string, systemContext *model.SystemContext) string {
+	if strings.TrimSpace(path) == "" {
+		return ""
+	}
+
+	if !documentIsUploadedFile(path) || ValidateMediaPath(path, systemContext) != nil {
+		return ""
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+
+	mimeType := mime.TypeByExtension(strings.ToLower(filepath.Ext(path)))
+	if mimeType == "" {
+		mimeType = "application/octet-stream"
+	}
+
+	return "data:" + mimeType + ";base64," + base64.StdEncoding.EncodeToString(content)
+}
+
+// documentIsUploadedFile reports whether a path resolves, after following links, to a file inside UPLOAD_DIR
+func documentIsUploadedFile(path string) bool {
+	uploadDir, err := filepath.EvalSymlinks(utils.GetEnvString("UPLOAD_DIR", "./assets/client"))
+	if err != nil {
+		return false
+	}
+	filePath, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		return false
+	}
+
+	uploadDir, errDir := filepath.Abs(uploadDir)
+	filePath, errFile := filepath.Abs(filePath)
+	if errDir != nil || errFile != nil {
+		return false
+	}
+
+	relative, err := filepath.Rel(uploadDir, filePath)
+	return err == nil && relative != "." && relative != ".." && !strings.HasPrefix(relative, ".."+string(filepath.Separator))
+}
+
+// documentAddress formats an address as HTML lines, skipping empty parts
+func documentAddress(address database.SystemAddress) string {
+	var lines []string
+	for _, line := range []string{address.Line1, address.Line2, address.Line3} {
+		if strings.TrimSpace(line) != "" {
+			lines = append(lines, html.EscapeString(strings.TrimSpace(line)))
+		}
+	}
+
+	cityLine := strings.TrimSpace(strings.TrimSpace(address.Postcode) + " " + strings.TrimSpace(address.City))
+	if cityLine != "" {
+		lines = append(lines, html.EscapeString(cityLine))
+	}
+	if strings.TrimSpace(address.State) != "" {
+		lines = append(lines, html.EscapeString(strings.TrimSpace(address.State)))
+	}
+
+	return strings.Join(lines, "<br>")
+}
+
+// documentMultiline escapes free text and keeps its line breaks
+func documentMultiline(text string) string {
+	return strings.ReplaceAll(html.EscapeString(strings.TrimSpace(text)), "\n", "<br>")
+}
+
+// documentDate formats a date for printing in the company's time zone, leaving zero dates blank
+func documentDate(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.In(companyLocation()).Format("02/01/2006")
+}
+
+// documentMoney formats an amount with thousands separators and 2 decimal places
+func documentMoney(amount database.Money) string {
+	formatted := amount.String()
+
+	negative := strings.HasPrefix(formatted, "-")
+	formatted = strings.TrimPrefix(formatted, "-")
+
+	parts := strings.SplitN(formatted, ".", 2)
+	integer := parts[0]
+
+	var grouped strings.Builder
+	for i, digit := range integer {
+		if i > 0 && (len(integer)-i)%3 == 0 {
+			grouped.WriteByte(',')
+		}
+		grouped.WriteRune(digit)
+	}
+
+	result := grouped.String() + "." + parts[1]
+	if negative && result != "0.00" {
+		result = "-" + result
+	}
+
+	return result
+}
+
+// documentQuantity formats a quantity without trailing zeros
+func documentQuantity(quantity float64) string {
+	return strings.TrimSuffix(strings.TrimRight(fmt.Sprintf("%.4f", quantity), "0"), ".")
+}
+
+// documentStringList converts strings into a template array for primitive embeddedHtml
+func documentStringList(values []string) []interface{} {
+	list := make([]interface{}, 0, len(values))
+	for _, value := range values {
+		if strings.TrimSpace(value) != "" {
+			list = append(list, documentMultiline(value))
+		}
+	}
+	return list
+}
@@ -0,0 +1,223 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.uber.org/zap"
+	"renotech.com.my/internal/database"
+	"renotech.com.my/internal/enum"
+	"renotech.com.my/internal/model"
+	"renotech.com.my/internal/utils"
+)
+
+// QuotationSendEmail emails the quotation PDF to the client and records the send in the quotation's action log.
+// Draft quotations are marked as sent.
+func QuotationSendEmail(quotationID primitive.ObjectID, input *model.DocumentEmailRequest, systemContext *model.SystemContext) (*model.DocumentEmailResponse, error) {
+	quotation, err := QuotationGetByID(quotationID, systemContext)
+	if err != nil {
+		return nil, err
+	}
+
+	company, err := CompanyTenantGet(systemContext)
+	if err != nil {
+		return nil, err
+	}
+
+	pdfBuffer, filename, err := QuotationGeneratePDF(quotationID, nil, systemContext)
+	if err != nil {
+		return nil, err
+	}
+
+	reference := quotationReference(quotation)
+
+	response, err := sendDocumentEmail(documentEmail{
+		input:          input,
+		defaultTo:      quotation.Client.Email,
+		defaultSubject: fmt.Sprintf("Quotation %s from %s", reference, company.Name),
+		defaultMessage: fmt.Sprintf("Please find attached our quotation %s for your review.", reference),
+		recipientName:  quotation.Client.Name,
+		pdfBuffer:      pdfBuffer,
+		filename:       filename,
+		company:        company,
+	}, systemContext)
+	if err != nil {
+		return nil, err
+	}
+
+	appendDocumentEmailLog("quotation", quotationID, response, systemContext)
+
+	if quotation.Status == enum.QuotationStatusDraft {
+		if _, err := quotationTransitionStatus(quotation, enum.QuotationStatusSent, "Emailed to client", systemContext); err != nil {
+			systemContext.Logger.Warn("service.QuotationSendEmail status update", zap.Error(err))
+		}
+	}
+
+	return response, nil
+}
+
+// OrderSendEmail emails the purchase order PDF to the supplier and records the send in the order's action log.
+// Draft and pending orders are marked as sent.
+func OrderSendEmail(orderID primitive.ObjectID, input *model.DocumentEmailRequest, systemContext *model.SystemContext) (*model.DocumentEmailResponse, error) {
+	order, err := OrderGetByID(orderID, systemContext)
+	if err != nil {
+		return nil, err
+	}
+
+	if order.Status == enum.OrderStatusCancelled {
+		return nil, utils.SystemError(enum.ErrorCodeValidation, "Cancelled orders cannot be emailed", nil)
+	}
+
+	company, err := CompanyTenantGet(systemContext)
+	if err != nil {
+		return nil, err
+	}
+
+	pdfBuffer, filename, err := OrderGeneratePDF(orderID, systemContext)
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := sendDocumentEmail(documentEmail{
+		input:          input,
+		defaultTo:      order.Supplier.Email,
+		defaultSubject: fmt.Sprintf("Purchase Order %s from %s", order.PONumber, company.Name),
+		defaultMessage: fmt.Sprintf("Please find attached purchase order %s. Kindly confirm the order and expected delivery date.", order.PONumber),
+		recipientName:  order.Supplier.Name,
+		pdfBuffer:      pdfBuffer,
+		filename:       filename,
+		company:        company,
+	}, systemContext)
+	if err != nil {
+		return nil, err
+	}
+
+	appendDocumentEmailLog("order", orderID, response, systemContext)
+
+	if order.Status == enum.OrderStatusDraft || order.Status == enum.OrderStatusPending {
+		if _, err := orderTransitionStatus(order, enum.OrderStatusSent, "Emailed to supplier", systemContext); err != nil {
+			systemContext.Logger.Warn("service.OrderSendEmail status update", zap.Error(err))
+		}
+	}
+
+	return response, nil
+}
+
+// Helper functions
+type documentEmail struct {
+	input          *model.DocumentEmailRequest
+	defaultTo      string
+	defaultSubject string
+	defaultMessage string
+	recipientName  string
+	pdfBuffer      []byte
+	filename       string
+	company        *database.Company
+}
+
+func sendDocumentEmail(email documentEmail, systemContext *model.SystemContext) (*model.DocumentEmailResponse, error) {
+	to := trimEmailList(email.input.To)
+	if len(to) == 0 && strings.TrimSpace(email.defaultTo) != "" {
+		to = []string{strings.TrimSpace(email.defaultTo)}
+	}
+	if len(to) == 0 {
+		return nil, utils.SystemError(enum.ErrorCodeValidation, "Recipient email is required", nil)
+	}
+
+	subject := strings.TrimSpace(email.input.Subject)
+	if subject == "" {
+		subject = email.defaultSubject
+	}
+
+	message := strings.TrimSpace(email.input.Message)
+	if message == "" {
+		message = email.defaultMessage
+	}
+
+	greeting := "Dear Sir/Madam,"
+	if strings.TrimSpace(email.recipientName) != "" {
+		greeting = fmt.Sprintf("Dear %s,", strings.TrimSpace(email.recipientName))
+	}
+
+	signature := []string{"Regards,", systemContext.User.Username, email.company.Name}
+	if email.company.Contact != "" {
+		signature = append(signature, email.company.Contact)
+	}
+
+	textBody := greeting + "\n\n" + message + "\n\n" + strings.Join(signature, "\n")
+
+	var htmlSignature []string
+	for _, line := range signature {
+		htmlSignature = append(htmlSignature, html.EscapeString(line))
+	}
+	htmlBody := fmt.Sprintf(
+		"<!DOCTYPE html><html><body style=\"font-family: Arial, sans-serif; color: #2c3e50;\"><p>%s</p><p>%s</p><p>%s</p></body></html>",
+		html.EscapeString(greeting),
+		documentMultiline(message),
+		strings.Join(htmlSignature, "<br>"),
+	)
+
+	emailMessage := &utils.EmailMessage{
+		To:       to,
+		Cc:       trimEmailList(email.input.Cc),
+		Bcc:      trimEmailList(email.input.Bcc),
+		ReplyTo:  systemContext.User.Email,
+		Subject:  subject,
+		HTMLBody: htmlBody,
+		TextBody: textBody,
+		Attachments: []utils.EmailAttachment{
+			{
+				Filename:    email.filename,
+				ContentType: "application/pdf",
+				Content:     email.pdfBuffer,
+			},
+		},
+	}
+
+	if err := utils.SendMultipartEmail(emailMessage); err != nil {
+		systemContext.Logger.Error("service.sendDocumentEmail", zap.Error(err))
+		return nil, err
+	}
+
+	return &model.DocumentEmailResponse{
+		To:       emailMessage.To,
+		Cc:       emailMessage.Cc,
+		Bcc:      emailMessage.Bcc,
+		Subject:  subject,
+		Filename: email.filename,
+		SentAt:   time.Now(),
+	}, nil
+}
+
+// appendDocumentEmailLog records a sent email on the document. The email has already gone out,
+// so a failure here is logged rather than returned.
+func appendDocumentEmailLog(collectionName string, documentID primitive.ObjectID, response *model.DocumentEmailResponse, systemContext *model.SystemContext) {
+	description := "Emailed to " + strings.Join(response.To, ", ")
+	if len(response.Cc) > 0 {
+		description += " (cc: " + strings.Join(response.Cc, ", ") + ")"
+	}
+
+	_, err := systemContext.MongoDB.Collection(collectionName).UpdateOne(
+		context.Background(),
+		bson.M{"_id": documentID, "company": systemContext.User.Company},
+		bson.M{"$push": bson.M{"actionLogs": newSystemActionLog(description, systemContext)}},
+	)
+	if err != nil {
+		systemContext.Logger.Error("service.appendDocumentEmailLog", zap.Error(err))
+	}
+}
+
+func trimEmailList(emails []string) []string {
+	result := []string{}
+	for _, email := range emails {
+		if strings.TrimSpace(email) != "" {
+			result = append(result, strings.TrimSpace(email))
+		}
+	}
+	return result
+}
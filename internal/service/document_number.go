@@ -0,0 +1,404 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.uber.org/zap"
+	"renotech.com.my/internal/database"
+	"renotech.com.my/internal/enum"
+	"renotech.com.my/internal/model"
+	"renotech.com.my/internal/utils"
+)
+
+var (
+	documentNumberTokenRegex = regexp.MustCompile(`\{([^{}]*)\}`)
+	documentCounterIndex     = &indexOnce{}
+)
+
+// Default numbering used until a company configures its own pattern
+var defaultDocumentNumberConfigs = map[enum.DocumentNumberType]database.DocumentNumberConfig{
+	enum.DocumentNumberTypePurchaseOrder:  {Type: enum.DocumentNumberTypePurchaseOrder, Pattern: "PO-{YYYY}-{seq:5}", Reset: enum.DocumentNumberResetYearly},
+	enum.DocumentNumberTypeQuotation:      {Type: enum.DocumentNumberTypeQuotation, Pattern: "QT-{YYYY}-{seq:5}", Reset: enum.DocumentNumberResetYearly},
+	enum.DocumentNumberTypeInvoice:        {Type: enum.DocumentNumberTypeInvoice, Pattern: "INV-{YYYY}-{seq:5}", Reset: enum.DocumentNumberResetYearly},
+	enum.DocumentNumberTypeVariationOrder: {Type: enum.DocumentNumberTypeVariationOrder, Pattern: "VO-{YYYY}-{seq:5}", Reset: enum.DocumentNumberResetYearly},
+	enum.DocumentNumberTypeReceipt:        {Type: enum.DocumentNumberTypeReceipt, Pattern: "OR-{YYYY}-{seq:5}", Reset: enum.DocumentNumberResetYearly},
+}
+
+// documentNumberFields is where each document type stores its number. Numbers are unique per company.
+var documentNumberFields = map[enum.DocumentNumberType]struct {
+	collection string
+	field      string
+	index      *indexOnce
+}{
+	enum.DocumentNumberTypePurchaseOrder:  {"order", "poNumber", &indexOnce{}},
+	enum.DocumentNumberTypeQuotation:      {"quotation", "quotationNumber", &indexOnce{}},
+	enum.DocumentNumberTypeInvoice:        {"invoice", "invoiceNumber", &indexOnce{}},
+	enum.DocumentNumberTypeVariationOrder: {"variation_order", "voNumber", &indexOnce{}},
+	enum.DocumentNumberTypeReceipt:        {"payment", "receiptNumber", &indexOnce{}},
+}
+
+var documentNumberTypes = []enum.DocumentNumberType{
+	enum.DocumentNumberTypePurchaseOrder,
+	enum.DocumentNumberTypeQuotation,
+	enum.DocumentNumberTypeInvoice,
+	enum.DocumentNumberTypeVariationOrder,
+	enum.DocumentNumberTypeReceipt,
+}
+
+// DocumentNumberGenerate issues the next number for a document type in the user's company.
+// The sequence is incremented atomically, so concurrent requests never receive the same number.
+func DocumentNumberGenerate(docType enum.DocumentNumberType, systemContext *model.SystemContext) (string, error) {
+	config, err := getDocumentNumberConfig(docType, systemContext)
+	if err != nil {
+		return "", err
+	}
+
+	ensureDocumentNumberIndex(docType, systemContext)
+
+	// Periods and date tokens follow the company's calendar, not the server's
+	now := time.Now().In(companyLocation())
+	sequence, err := nextDocumentSequence(docType, documentNumberPeriod(config.Reset, now), systemContext)
+	if err != nil {
+		return "", err
+	}
+
+	return formatDocumentNumber(config.Pattern, sequence, now), nil
+}
+
+func DocumentNumberConfigList(systemContext *model.SystemContext) ([]model.DocumentNumberConfigResponse, error) {
+	results := make([]model.DocumentNumberConfigResponse, 0, len(documentNumberTypes))
+
+	for _, docType := range documentNumberTypes {
+		config, err := getDocumentNumberConfig(docType, systemContext)
+		if err != nil {
+			return nil, err
+		}
+
+		preview, err := previewDocumentNumber(config, systemContext)
+		if err != nil {
+			return nil, err
+		}
+
+		results = append(results, model.DocumentNumberConfigResponse{
+			Type:      config.Type,
+			Pattern:   config.Pattern,
+			Reset:     config.Reset,
+			IsDefault: config.ID == nil,
+			Preview:   preview,
+		})
+	}
+
+	return results, nil
+}
+
+func documentNumberConfigValidation(input *model.DocumentNumberConfigRequest) error {
+	if _, exists := defaultDocumentNumberConfigs[input.Type]; !exists {
+		return utils.SystemError(
+			enum.ErrorCodeValidation,
+			"Invalid document type",
+			map[string]interface{}{"type": input.Type},
+		)
+	}
+
+	// Same period as the default numbering
+	if input.Reset == "" {
+		input.Reset = enum.DocumentNumberResetYearly
+	}
+
+	switch input.Reset {
+	case enum.DocumentNumberResetNever, enum.DocumentNumberResetYearly, enum.DocumentNumberResetMonthly:
+	default:
+		return utils.SystemError(
+			enum.ErrorCodeValidation,
+			"Invalid reset period",
+			map[string]interface{}{"reset": input.Reset},
+		)
+	}
+
+	input.Pattern = strings.TrimSpace(input.Pattern)
+	return validateDocumentNumberPattern(input.Pattern, input.Reset)
+}
+
+func DocumentNumberConfigUpdate(input *model.DocumentNumberConfigRequest, systemContext *model.SystemContext) (*model.DocumentNumberConfigResponse, error) {
+	// Validate input
+	if err := documentNumberConfigValidation(input); err != nil {
+		return nil, err
+	}
+
+	collection := systemContext.MongoDB.Collection("document_number_config")
+
+	filter := bson.M{
+		"company": systemContext.User.Company,
+		"type":    input.Type,
+	}
+
+	update := bson.M{
+		"$set": bson.M{
+			"pattern":   input.Pattern,
+			"reset":     input.Reset,
+			"updatedAt": time.Now(),
+			"updatedBy": systemContext.User.ID,
+		},
+		"$setOnInsert": bson.M{
+			"createdAt": time.Now(),
+			"createdBy": systemContext.User.ID,
+		},
+	}
+
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+
+	var doc database.DocumentNumberConfig
+	err := collection.FindOneAndUpdate(context.Background(), filter, update, opts).Decode(&doc)
+	if err != nil {
+		systemContext.Logger.Error("service.DocumentNumberConfigUpdate", zap.Error(err))
+		return nil, utils.SystemError(enum.ErrorCodeInternal, "Failed to update document numbering", nil)
+	}
+
+	preview, err := previewDocumentNumber(&doc, systemContext)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.DocumentNumberConfigResponse{
+		Type:      doc.Type,
+		Pattern:   doc.Pattern,
+		Reset:     doc.Reset,
+		IsDefault: false,
+		Preview:   preview,
+	}, nil
+}
+
+// Helper functions
+func getDocumentNumberConfig(docType enum.DocumentNumberType, systemContext *model.SystemContext) (*database.DocumentNumberConfig, error) {
+	defaultConfig, exists := defaultDocumentNumberConfigs[docType]
+	if !exists {
+		return nil, utils.SystemError(
+			enum.ErrorCodeValidation,
+			"Invalid document type",
+			map[string]interface{}{"type": docType},
+		)
+	}
+
+	collection := systemContext.MongoDB.Collection("document_number_config")
+
+	var config database.DocumentNumberConfig
+	err := collection.FindOne(context.Background(), bson.M{
+		"company": systemContext.User.Company,
+		"type":    docType,
+	}).Decode(&config)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return &defaultConfig, nil
+		}
+		return nil, utils.SystemError(enum.ErrorCodeInternal, "Failed to retrieve document numbering", nil)
+	}
+
+	return &config, nil
+}
+
+// nextDocumentSequence increments and returns the counter for the given period
+func nextDocumentSequence(docType enum.DocumentNumberType, period string, systemContext *model.SystemContext) (int64, error) {
+	collection := systemContext.MongoDB.Collection("counter")
+	ensureDocumentCounterIndex(collection, systemContext)
+
+	filter := bson.M{
+		"company": systemContext.User.Company,
+		"type":    docType,
+		"period":  period,
+	}
+
+	update := bson.M{
+		"$inc": bson.M{"sequence": 1},
+		"$set": bson.M{"updatedAt": time.Now()},
+	}
+
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+
+	var counter database.DocumentCounter
+	err := collection.FindOneAndUpdate(context.Background(), filter, update, opts).Decode(&counter)
+
+	// Two concurrent upserts for a new period race on the unique index; the loser retries and increments the winner's document
+	if mongo.IsDuplicateKeyError(err) {
+		err = collection.FindOneAndUpdate(context.Background(), filter, update, opts).Decode(&counter)
+	}
+
+	if err != nil {
+		systemContext.Logger.Error("service.nextDocumentSequence", zap.Error(err))
+		return 0, utils.SystemError(enum.ErrorCodeInternal, "Failed to generate document number", nil)
+	}
+
+	return counter.Sequence, nil
+}
+
+// indexOnce creates an index the first time it is needed. Unlike sync.Once, a failed attempt is
+// retried on the next call.
+type indexOnce struct {
+	mu   sync.Mutex
+	done bool
+}
+
+func (o *indexOnce) Do(create func() error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if !o.done {
+		o.done = create() == nil
+	}
+}
+
+// ensureDocumentCounterIndex creates the unique counter index
+func ensureDocumentCounterIndex(collection *mongo.Collection, systemContext *model.SystemContext) {
+	documentCounterIndex.Do(func() error {
+		_, err := collection.Indexes().CreateOne(context.Background(), mongo.IndexModel{
+			Keys:    bson.D{{Key: "company", Value: 1}, {Key: "type", Value: 1}, {Key: "period", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		})
+		if err != nil {
+			systemContext.Logger.Error("service.ensureDocumentCounterIndex", zap.Error(err))
+		}
+		return err
+	})
+}
+
+// ensureDocumentNumberIndex makes the generated number of a document type unique within a company, so a
+// misconfigured pattern fails to save instead of issuing a duplicate. Documents without a number are skipped.
+func ensureDocumentNumberIndex(docType enum.DocumentNumberType, systemContext *model.SystemContext) {
+	target, exists := documentNumberFields[docType]
+	if !exists {
+		return
+	}
+
+	target.index.Do(func() error {
+		_, err := systemContext.MongoDB.Collection(target.collection).Indexes().CreateOne(context.Background(), mongo.IndexModel{
+			Keys: bson.D{{Key: "company", Value: 1}, {Key: target.field, Value: 1}},
+			Options: options.Index().
+				SetUnique(true).
+				SetPartialFilterExpression(bson.M{target.field: bson.M{"$gt": ""}}),
+		})
+		if err != nil {
+			systemContext.Logger.Error("service.ensureDocumentNumberIndex", zap.String("type", string(docType)), zap.Error(err))
+		}
+		return err
+	})
+}
+
+// previewDocumentNumber returns the number the next generation would produce, without incrementing
+func previewDocumentNumber(config *database.DocumentNumberConfig, systemContext *model.SystemContext) (string, error) {
+	now := time.Now().In(companyLocation())
+	collection := systemContext.MongoDB.Collection("counter")
+
+	var counter database.DocumentCounter
+	err := collection.FindOne(context.Background(), bson.M{
+		"company": systemContext.User.Company,
+		"type":    config.Type,
+		"period":  documentNumberPeriod(config.Reset, now),
+	}).Decode(&counter)
+	if err != nil && err != mongo.ErrNoDocuments {
+		return "", utils.SystemError(enum.ErrorCodeInternal, "Failed to retrieve document counter", nil)
+	}
+
+	return formatDocumentNumber(config.Pattern, counter.Sequence+1, now), nil
+}
+
+func documentNumberPeriod(reset enum.DocumentNumberReset, now time.Time) string {
+	switch reset {
+	case enum.DocumentNumberResetYearly:
+		return now.Format("2006")
+	case enum.DocumentNumberResetMonthly:
+		return now.Format("2006-01")
+	default:
+		return ""
+	}
+}
+
+// validateDocumentNumberPattern checks the pattern only uses known tokens and contains exactly one sequence.
+// A sequence that resets must be paired with the date tokens of its period, otherwise numbers repeat.
+func validateDocumentNumberPattern(pattern string, reset enum.DocumentNumberReset) error {
+	if pattern == "" {
+		return utils.SystemError(enum.ErrorCodeValidation, "Pattern is required", nil)
+	}
+
+	sequenceCount := 0
+	hasYear, hasMonth := false, false
+	for _, match := range documentNumberTokenRegex.FindAllStringSubmatch(pattern, -1) {
+		token := match[1]
+		switch {
+		case token == "YYYY" || token == "YY":
+			hasYear = true
+		case token == "MM":
+			hasMonth = true
+		case token == "DD":
+		case token == "seq":
+			sequenceCount++
+		case strings.HasPrefix(token, "seq:"):
+			width, err := strconv.Atoi(strings.TrimPrefix(token, "seq:"))
+			if err != nil || width < 1 || width > 12 {
+				return utils.SystemError(
+					enum.ErrorCodeValidation,
+					"Sequence width must be between 1 and 12",
+					map[string]interface{}{"token": match[0]},
+				)
+			}
+			sequenceCount++
+		default:
+			return utils.SystemError(
+				enum.ErrorCodeValidation,
+				"Unknown pattern token",
+				map[string]interface{}{"token": match[0]},
+			)
+		}
+	}
+
+	if sequenceCount != 1 {
+		return utils.SystemError(enum.ErrorCodeValidation, "Pattern must contain exactly one {seq} token", nil)
+	}
+
+	switch {
+	case reset == enum.DocumentNumberResetYearly && !hasYear:
+		return utils.SystemError(
+			enum.ErrorCodeValidation,
+			"Patterns that reset yearly must contain {YYYY} or {YY}",
+			map[string]interface{}{"reset": reset},
+		)
+	case reset == enum.DocumentNumberResetMonthly && (!hasYear || !hasMonth):
+		return utils.SystemError(
+			enum.ErrorCodeValidation,
+			"Patterns that reset monthly must contain {YYYY} or {YY} and {MM}",
+			map[string]interface{}{"reset": reset},
+		)
+	}
+
+	return nil
+}
+
+func formatDocumentNumber(pattern string, sequence int64, now time.Time) string {
+	return documentNumberTokenRegex.ReplaceAllStringFunc(pattern, func(match string) string {
+		token := match[1 : len(match)-1]
+		switch {
+		case token == "YYYY":
+			return now.Format("2006")
+		case token == "YY":
+			return now.Format("06")
+		case token == "MM":
+			return now.Format("01")
+		case token == "DD":
+			return now.Format("02")
+		case token == "seq":
+			return strconv.FormatInt(sequence, 10)
+		case strings.HasPrefix(token, "seq:"):
+			width, _ := strconv.Atoi(strings.TrimPrefix(token, "seq:"))
+			return fmt.Sprintf("%0*d", width, sequence)
+		default:
+			return match
+		}
+	})
+}
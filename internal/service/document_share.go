@@ -0,0 +1,198 @@
+package service
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.uber.org/zap"
+	"renotech.com.my/internal/database"
+	"renotech.com.my/internal/enum"
+	"renotech.com.my/internal/model"
+	"renotech.com.my/internal/utils"
+)
+
+// Public share links are "<shareID>.<expiryUnix>.<signature>". The signature stops tampering
+// with the ID or expiry; the stored record allows revocation. Links are signed with SHARE_LINK_SECRET
+// and cannot be created or opened while it is unset.
+
+const (
+	documentShareDefaultDays = 14
+	documentShareMaxDays     = 90
+)
+
+// documentShareCreate issues a signed link to one document
+func documentShareCreate(shareType enum.DocumentShareType, documentID primitive.ObjectID, input *model.DocumentShareCreateRequest, urlPath string, systemContext *model.SystemContext) (*model.DocumentShareResponse, error) {
+	days := input.ExpiresInDays
+	if days == 0 {
+		days = documentShareDefaultDays
+	}
+	if days < 1 || days > documentShareMaxDays {
+		return nil, utils.SystemError(
+			enum.ErrorCodeValidation,
+			fmt.Sprintf("Link validity must be between 1 and %d days", documentShareMaxDays),
+			map[string]interface{}{"expiresInDays": input.ExpiresInDays},
+		)
+	}
+
+	if _, err := documentShareSecret(); err != nil {
+		systemContext.Logger.Error("service.documentShareCreate", zap.Error(err))
+		return nil, utils.SystemError(enum.ErrorCodeInternal, "Share links are not configured", nil)
+	}
+
+	// Truncate to seconds so the stored expiry matches the signed one
+	expiresAt := time.Now().Add(time.Duration(days) * 24 * time.Hour).Truncate(time.Second)
+
+	share := database.DocumentShare{
+		Type:      shareType,
+		Document:  documentID,
+		Company:   systemContext.User.Company,
+		ExpiresAt: expiresAt,
+		IsRevoked: false,
+		ViewCount: 0,
+		CreatedAt: time.Now(),
+		CreatedBy: *systemContext.User.ID,
+	}
+
+	collection := systemContext.MongoDB.Collection("document_share")
+	result, err := collection.InsertOne(context.Background(), share)
+	if err != nil {
+		systemContext.Logger.Error("service.documentShareCreate", zap.Error(err))
+		return nil, utils.SystemError(enum.ErrorCodeInternal, "Failed to create share link", nil)
+	}
+
+	shareID := result.InsertedID.(primitive.ObjectID)
+	token, err := documentShareSign(shareID, shareType, expiresAt)
+	if err != nil {
+		systemContext.Logger.Error("service.documentShareCreate", zap.Error(err))
+		return nil, utils.SystemError(enum.ErrorCodeInternal, "Share links are not configured", nil)
+	}
+
+	return &model.DocumentShareResponse{
+		ID:        shareID,
+		Token:     token,
+		URL:       fmt.Sprintf("%s%s?token=%s", utils.GetEnvString("FRONTEND_URL", "https://app.renotech.space"), urlPath, token),
+		ExpiresAt: expiresAt,
+	}, nil
+}
+
+// documentShareRevokeAll revokes every active link to a document
+func documentShareRevokeAll(shareType enum.DocumentShareType, documentID primitive.ObjectID, systemContext *model.SystemContext) (int64, error) {
+	collection := systemContext.MongoDB.Collection("document_share")
+
+	result, err := collection.UpdateMany(context.Background(), bson.M{
+		"type":      shareType,
+		"document":  documentID,
+		"company":   systemContext.User.Company,
+		"isRevoked": false,
+	}, bson.M{
+		"$set": bson.M{
+			"isRevoked": true,
+			"revokedAt": time.Now(),
+		},
+	})
+	if err != nil {
+		systemContext.Logger.Error("service.documentShareRevokeAll", zap.Error(err))
+		return 0, utils.SystemError(enum.ErrorCodeInternal, "Failed to revoke share links", nil)
+	}
+
+	return result.ModifiedCount, nil
+}
+
+// documentShareResolve verifies a token and scopes the system context to the document's company.
+// The context has no user ID; publicName is used as the actor name in action logs.
+func documentShareResolve(token string, shareType enum.DocumentShareType, publicName string, countView bool, systemContext *model.SystemContext) (*database.DocumentShare, error) {
+	invalidErr := utils.SystemError(enum.ErrorCodeUnauthorized, "Invalid or expired link", nil)
+
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, invalidErr
+	}
+
+	shareID, err := primitive.ObjectIDFromHex(parts[0])
+	if err != nil {
+		return nil, invalidErr
+	}
+
+	expiryUnix, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		return nil, invalidErr
+	}
+	expiresAt := time.Unix(expiryUnix, 0)
+
+	expected, err := documentShareSign(shareID, shareType, expiresAt)
+	if err != nil {
+		systemContext.Logger.Error("service.documentShareResolve", zap.Error(err))
+		return nil, invalidErr
+	}
+	if !hmac.Equal([]byte(expected), []byte(token)) {
+		return nil, invalidErr
+	}
+
+	if time.Now().After(expiresAt) {
+		return nil, invalidErr
+	}
+
+	collection := systemContext.MongoDB.Collection("document_share")
+	filter := bson.M{
+		"_id":       shareID,
+		"type":      shareType,
+		"isRevoked": false,
+		"expiresAt": bson.M{"$gt": time.Now()},
+	}
+
+	var share database.DocumentShare
+	if countView {
+		update := bson.M{
+			"$inc": bson.M{"viewCount": 1},
+			"$set": bson.M{"lastViewedAt": time.Now()},
+		}
+		opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+		err = collection.FindOneAndUpdate(context.Background(), filter, update, opts).Decode(&share)
+	} else {
+		err = collection.FindOne(context.Background(), filter).Decode(&share)
+	}
+	if err != nil {
+		return nil, invalidErr
+	}
+
+	systemContext.User = database.User{
+		Username: publicName,
+		Company:  share.Company,
+	}
+
+	return &share, nil
+}
+
+func documentShareSign(shareID primitive.ObjectID, shareType enum.DocumentShareType, expiresAt time.Time) (string, error) {
+	secret, err := documentShareSecret()
+	if err != nil {
+		return "", err
+	}
+
+	payload := fmt.Sprintf("%s.%d", shareID.Hex(), expiresAt.Unix())
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(payload + "." + string(shareType)))
+
+	return payload + "." + hex.EncodeToString(mac.Sum(nil)), nil
+}
+
+// documentShareSecret returns the key share links are signed with. There is deliberately no default,
+// since anyone who knows the key can forge links.
+func documentShareSecret() (string, error) {
+	secret := strings.TrimSpace(utils.GetEnvString("SHARE_LINK_SECRET", ""))
+	if secret == "" {
+		return "", errors.New("SHARE_LINK_SECRET is not set")
+	}
+	return secret, nil
+}
@@ -342,6 +342,7 @@ func executeFolderList(collection *mongo.Collection, filter bson.M, input model.
 		systemContext.Logger.Error("service.FolderList", zap.Error(err))
 		return nil, utils.SystemError(enum.ErrorCodeInternal, "Failed to decode folders", nil)
 	}
+	moneyDocuments(folders)
 
 	response := &model.FolderListResponse{
 		Data:       folders,
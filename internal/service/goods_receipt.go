@@ -0,0 +1,477 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.uber.org/zap"
+	"renotech.com.my/internal/database"
+	"renotech.com.my/internal/enum"
+	"renotech.com.my/internal/model"
+	"renotech.com.my/internal/utils"
+)
+
+// quantityTolerance absorbs floating point noise when comparing quantities
+const quantityTolerance = 1e-9
+
+// Tenant services
+func goodsReceiptCreateValidation(input *model.GoodsReceiptCreateRequest, systemContext *model.SystemContext) (*database.Order, error) {
+	order, err := OrderGetByID(input.Order, systemContext)
+	if err != nil {
+		return nil, err
+	}
+
+	if order.Status != enum.OrderStatusConfirmed && order.Status != enum.OrderStatusPartial {
+		return nil, utils.SystemError(
+			enum.ErrorCodeValidation,
+			"Goods can only be received for confirmed or partially delivered orders",
+			map[string]interface{}{"status": order.Status},
+		)
+	}
+
+	if strings.TrimSpace(input.ReceivedBy) == "" {
+		input.ReceivedBy = systemContext.User.Username
+	}
+
+	// Validate delivery photos
+	mediaPaths := make([]string, len(input.Media))
+	for i, media := range input.Media {
+		mediaPaths[i] = media.Path
+	}
+	if err := ValidateMediaPaths(mediaPaths, systemContext); err != nil {
+		return nil, err
+	}
+
+	seen := make(map[int]bool)
+	hasQuantity := false
+
+	for i, line := range input.Items {
+		if line.ItemIndex < 0 || line.ItemIndex >= len(order.Items) {
+			return nil, utils.SystemError(
+				enum.ErrorCodeValidation,
+				"Item index out of range",
+				map[string]interface{}{"lineIndex": i, "itemIndex": line.ItemIndex},
+			)
+		}
+
+		if seen[line.ItemIndex] {
+			return nil, utils.SystemError(
+				enum.ErrorCodeValidation,
+				"Order item received more than once in the same receipt",
+				map[string]interface{}{"lineIndex": i, "itemIndex": line.ItemIndex},
+			)
+		}
+		seen[line.ItemIndex] = true
+
+		if line.ReceivedQuantity < 0 || line.RejectedQuantity < 0 {
+			return nil, utils.SystemError(
+				enum.ErrorCodeValidation,
+				"Received and rejected quantities cannot be negative",
+				map[string]interface{}{"lineIndex": i, "itemIndex": line.ItemIndex},
+			)
+		}
+
+		if line.RejectedQuantity > line.ReceivedQuantity+quantityTolerance {
+			return nil, utils.SystemError(
+				enum.ErrorCodeValidation,
+				"Rejected quantity cannot exceed received quantity",
+				map[string]interface{}{"lineIndex": i, "itemIndex": line.ItemIndex},
+			)
+		}
+
+		// Accepted quantity across all receipts must not exceed the ordered quantity
+		item := order.Items[line.ItemIndex]
+		accepted := item.ReceivedQuantity - item.RejectedQuantity + line.ReceivedQuantity - line.RejectedQuantity
+		if accepted > item.Quantity+quantityTolerance {
+			return nil, utils.SystemError(
+				enum.ErrorCodeValidation,
+				"Accepted quantity exceeds ordered quantity",
+				map[string]interface{}{
+					"lineIndex":   i,
+					"itemIndex":   line.ItemIndex,
+					"name":        item.Name,
+					"ordered":     item.Quantity,
+					"outstanding": item.Quantity - (item.ReceivedQuantity - item.RejectedQuantity),
+				},
+			)
+		}
+
+		if line.ReceivedQuantity > 0 {
+			hasQuantity = true
+		}
+	}
+
+	if !hasQuantity {
+		return nil, utils.SystemError(enum.ErrorCodeValidation, "Receipt must have at least one received quantity", nil)
+	}
+
+	return order, nil
+}
+
+// GoodsReceiptCreate records a delivery against an order, updates the received quantities
+// per order line and moves the order to partial or delivered.
+func GoodsReceiptCreate(input *model.GoodsReceiptCreateRequest, systemContext *model.SystemContext) (*model.GoodsReceiptCreateResponse, error) {
+	// Validate input
+	order, err := goodsReceiptCreateValidation(input, systemContext)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]database.GoodsReceiptItem, len(input.Items))
+	increments := bson.M{}
+	limits := bson.A{}
+	for i, line := range input.Items {
+		orderItem := order.Items[line.ItemIndex]
+		items[i] = database.GoodsReceiptItem{
+			ItemIndex:        line.ItemIndex,
+			Material:         orderItem.Material,
+			Name:             orderItem.Name,
+			Unit:             orderItem.Unit,
+			ReceivedQuantity: line.ReceivedQuantity,
+			RejectedQuantity: line.RejectedQuantity,
+			Remark:           line.Remark,
+		}
+		increments[fmt.Sprintf("items.%d.receivedQuantity", line.ItemIndex)] = line.ReceivedQuantity
+		increments[fmt.Sprintf("items.%d.rejectedQuantity", line.ItemIndex)] = line.RejectedQuantity
+		limits = append(limits, goodsReceiptAcceptedLimit(line.ItemIndex, line.ReceivedQuantity-line.RejectedQuantity))
+	}
+
+	// Apply received quantities to the order, guarded on status so a cancelled order is not updated and on
+	// the stored quantities so concurrent receipts cannot together accept more than was ordered
+	orderCollection := systemContext.MongoDB.Collection("order")
+	orderFilter := bson.M{
+		"_id":       order.ID,
+		"company":   systemContext.User.Company,
+		"status":    bson.M{"$in": []enum.OrderStatus{enum.OrderStatusConfirmed, enum.OrderStatusPartial}},
+		"isDeleted": false,
+		"$expr":     bson.M{"$and": limits},
+	}
+
+	orderUpdate := bson.M{
+		"$inc": increments,
+		"$set": bson.M{
+			"updatedAt": time.Now(),
+			"updatedBy": systemContext.User.ID,
+		},
+		"$push": bson.M{
+			"actionLogs": newSystemActionLog(
+				fmt.Sprintf("Goods received by %s (%d items)", input.ReceivedBy, len(items)),
+				systemContext,
+			),
+		},
+	}
+
+	var updatedOrder database.Order
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	err = orderCollection.FindOneAndUpdate(context.Background(), orderFilter, orderUpdate, opts).Decode(&updatedOrder)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, utils.SystemError(enum.ErrorCodeValidation, "Order status or received quantities were changed by another request, please reload", nil)
+		}
+		systemContext.Logger.Error("service.GoodsReceiptCreate", zap.Error(err))
+		return nil, utils.SystemError(enum.ErrorCodeInternal, "Failed to update order quantities", nil)
+	}
+
+	receipt := database.GoodsReceipt{
+		Order:        *order.ID,
+		Company:      systemContext.User.Company,
+		PONumber:     order.PONumber,
+		Supplier:     order.Supplier,
+		ReceivedDate: input.ReceivedDate,
+		ReceivedBy:   input.ReceivedBy,
+		Items:        items,
+		Media:        input.Media,
+		Remark:       input.Remark,
+		CreatedAt:    time.Now(),
+		CreatedBy:    *systemContext.User.ID,
+		UpdatedAt:    time.Now(),
+		UpdatedBy:    systemContext.User.ID,
+		IsDeleted:    false,
+	}
+
+	collection := systemContext.MongoDB.Collection("goods_receipt")
+	result, err := collection.InsertOne(context.Background(), receipt)
+	if err != nil {
+		systemContext.Logger.Error("service.GoodsReceiptCreate", zap.Error(err))
+		goodsReceiptRollback(order.ID, nil, increments, systemContext)
+		return nil, utils.SystemError(enum.ErrorCodeInternal, "Failed to create goods receipt", nil)
+	}
+
+	receiptID := result.InsertedID.(primitive.ObjectID)
+	receipt.ID = &receiptID
+
+	// Move the order along based on what has now been accepted. A concurrent receipt may have moved it
+	// first, so the order is read again once before giving up and undoing this receipt.
+	synced, err := goodsReceiptSyncOrderStatus(&updatedOrder, systemContext)
+	if err != nil {
+		if reloaded, reloadErr := OrderGetByID(*order.ID, systemContext); reloadErr == nil {
+			synced, err = goodsReceiptSyncOrderStatus(reloaded, systemContext)
+		}
+	}
+	if err != nil {
+		goodsReceiptRollback(order.ID, &receiptID, increments, systemContext)
+		return nil, err
+	}
+	updatedOrder = *synced
+
+	return &model.GoodsReceiptCreateResponse{
+		Receipt: receipt,
+		Order:   updatedOrder,
+	}, nil
+}
+
+func GoodsReceiptGetByID(receiptID primitive.ObjectID, systemContext *model.SystemContext) (*database.GoodsReceipt, error) {
+	collection := systemContext.MongoDB.Collection("goods_receipt")
+
+	filter := bson.M{
+		"_id":       receiptID,
+		"company":   systemContext.User.Company,
+		"isDeleted": false,
+	}
+
+	var doc database.GoodsReceipt
+	err := collection.FindOne(context.Background(), filter).Decode(&doc)
+	if err != nil {
+		return nil, utils.SystemError(enum.ErrorCodeNotFound, "Goods receipt not found", nil)
+	}
+
+	return &doc, nil
+}
+
+func GoodsReceiptList(input model.GoodsReceiptListRequest, systemContext *model.SystemContext) (*model.GoodsReceiptListResponse, error) {
+	collection := systemContext.MongoDB.Collection("goods_receipt")
+
+	// Build base filter
+	filter := bson.M{"isDeleted": false, "company": systemContext.User.Company}
+
+	// Add field-specific filters
+	if input.Order != nil {
+		filter["order"] = input.Order
+	}
+	if input.SupplierID != nil {
+		filter["supplier._id"] = input.SupplierID
+	}
+
+	// Add received date range filter
+	if input.DateFrom != nil || input.DateTo != nil {
+		dateFilter := bson.M{}
+		if input.DateFrom != nil {
+			dateFilter["$gte"] = input.DateFrom
+		}
+		if input.DateTo != nil {
+			dateFilter["$lte"] = input.DateTo
+		}
+		filter["receivedDate"] = dateFilter
+	}
+
+	// Get total count
+	total, err := collection.CountDocuments(context.Background(), filter)
+	if err != nil {
+		systemContext.Logger.Error("service.GoodsReceiptList", zap.Error(err))
+		return nil, utils.SystemError(enum.ErrorCodeInternal, "Failed to count goods receipts", nil)
+	}
+
+	// Set default pagination values
+	page := input.Page
+	if page <= 0 {
+		page = 1
+	}
+	limit := input.Limit
+	if limit <= 0 {
+		limit = 10
+	}
+	if limit > 100 {
+		limit = 100 // Maximum limit
+	}
+
+	skip := (page - 1) * limit
+	totalPages := int(math.Ceil(float64(total) / float64(limit)))
+
+	var sortOptions bson.D
+	if len(input.Sort) > 0 {
+		for key, value := range input.Sort {
+			sortOptions = append(sortOptions, bson.E{Key: key, Value: value})
+		}
+	} else {
+		// Default sort by received date descending (latest first)
+		sortOptions = bson.D{{Key: "receivedDate", Value: -1}}
+	}
+
+	findOptions := options.Find().
+		SetSkip(int64(skip)).
+		SetLimit(int64(limit)).
+		SetSort(sortOptions)
+
+	cursor, err := collection.Find(context.Background(), filter, findOptions)
+	if err != nil {
+		systemContext.Logger.Error("service.GoodsReceiptList", zap.Error(err))
+		return nil, utils.SystemError(enum.ErrorCodeInternal, "Failed to retrieve goods receipts", nil)
+	}
+	defer cursor.Close(context.Background())
+
+	var receipts []bson.M
+	if err = cursor.All(context.Background(), &receipts); err != nil {
+		systemContext.Logger.Error("service.GoodsReceiptList", zap.Error(err))
+		return nil, utils.SystemError(enum.ErrorCodeInternal, "Failed to decode goods receipts", nil)
+	}
+
+	return &model.GoodsReceiptListResponse{
+		Data:       receipts,
+		Page:       page,
+		Limit:      limit,
+		Total:      total,
+		TotalPages: totalPages,
+	}, nil
+}
+
+// OrderOutstandingReport lists quantities still to be delivered on confirmed and partially delivered orders, grouped by supplier
+func OrderOutstandingReport(input model.OrderOutstandingRequest, systemContext *model.SystemContext) (*model.OrderOutstandingResponse, error) {
+	collection := systemContext.MongoDB.Collection("order")
+
+	filter := bson.M{
+		"isDeleted": false,
+		"company":   systemContext.User.Company,
+		"status":    bson.M{"$in": []enum.OrderStatus{enum.OrderStatusConfirmed, enum.OrderStatusPartial}},
+	}
+	if input.SupplierID != nil {
+		filter["supplier._id"] = input.SupplierID
+	}
+	if input.Project != nil {
+		filter["project"] = input.Project
+	}
+
+	findOptions := options.Find().SetSort(bson.D{{Key: "expectedDelivery", Value: 1}})
+	cursor, err := collection.Find(context.Background(), filter, findOptions)
+	if err != nil {
+		systemContext.Logger.Error("service.OrderOutstandingReport", zap.Error(err))
+		return nil, utils.SystemError(enum.ErrorCodeInternal, "Failed to retrieve orders", nil)
+	}
+	defer cursor.Close(context.Background())
+
+	var orders []database.Order
+	if err = cursor.All(context.Background(), &orders); err != nil {
+		systemContext.Logger.Error("service.OrderOutstandingReport", zap.Error(err))
+		return nil, utils.SystemError(enum.ErrorCodeInternal, "Failed to decode orders", nil)
+	}
+
+	response := &model.OrderOutstandingResponse{Suppliers: []model.OrderOutstandingSupplier{}}
+	supplierIndex := make(map[string]int)
+
+	for _, order := range orders {
+		// External suppliers have no ID, so group them by name
+		supplierKey := "name:" + strings.ToLower(strings.TrimSpace(order.Supplier.Name))
+		if order.Supplier.ID != nil {
+			supplierKey = order.Supplier.ID.Hex()
+		}
+
+		index, exists := supplierIndex[supplierKey]
+		if !exists {
+			response.Suppliers = append(response.Suppliers, model.OrderOutstandingSupplier{
+				SupplierID:   order.Supplier.ID,
+				SupplierName: order.Supplier.Name,
+				Items:        []model.OrderOutstandingItem{},
+			})
+			index = len(response.Suppliers) - 1
+			supplierIndex[supplierKey] = index
+		}
+
+		supplier := &response.Suppliers[index]
+		hasOutstanding := false
+
+		for i, item := range order.Items {
+			accepted := item.ReceivedQuantity - item.RejectedQuantity
+			outstanding := item.Quantity - accepted
+			if outstanding <= quantityTolerance {
+				continue
+			}
+
+			value := item.UnitPrice.Times(outstanding)
+			supplier.Items = append(supplier.Items, model.OrderOutstandingItem{
+				Order:               *order.ID,
+				PONumber:            order.PONumber,
+				ExpectedDelivery:    order.ExpectedDelivery,
+				ItemIndex:           i,
+				Name:                item.Name,
+				Unit:                item.Unit,
+				OrderedQuantity:     item.Quantity,
+				AcceptedQuantity:    accepted,
+				OutstandingQuantity: outstanding,
+				OutstandingValue:    value,
+			})
+			supplier.OutstandingValue += value
+			response.OutstandingValue += value
+			hasOutstanding = true
+		}
+
+		if hasOutstanding {
+			supplier.OrderCount++
+		}
+	}
+
+	// Drop suppliers whose orders turned out to be fully accepted
+	suppliers := response.Suppliers[:0]
+	for _, supplier := range response.Suppliers {
+		if supplier.OrderCount > 0 {
+			suppliers = append(suppliers, supplier)
+		}
+	}
+	response.Suppliers = suppliers
+
+	return response, nil
+}
+
+// Helper functions
+// goodsReceiptAcceptedLimit is the $expr condition that accepting quantity more of an order item keeps its
+// accepted quantity within the ordered quantity
+func goodsReceiptAcceptedLimit(itemIndex int, quantity float64) bson.M {
+	item := func(field string) bson.M {
+		return bson.M{"$arrayElemAt": bson.A{"$items." + field, itemIndex}}
+	}
+
+	return bson.M{"$lte": bson.A{
+		bson.M{"$add": bson.A{bson.M{"$subtract": bson.A{item("receivedQuantity"), item("rejectedQuantity")}}, quantity}},
+		bson.M{"$add": bson.A{item("quantity"), quantityTolerance}},
+	}}
+}
+
+// goodsReceiptSyncOrderStatus moves an order to partial or delivered to match its accepted quantities
+func goodsReceiptSyncOrderStatus(order *database.Order, systemContext *model.SystemContext) (*database.Order, error) {
+	targetStatus := enum.OrderStatusDelivered
+	for _, item := range order.Items {
+		if item.ReceivedQuantity-item.RejectedQuantity < item.Quantity-quantityTolerance {
+			targetStatus = enum.OrderStatusPartial
+			break
+		}
+	}
+
+	if order.Status == targetStatus {
+		return order, nil
+	}
+
+	return orderTransitionStatus(order, targetStatus, "", systemContext)
+}
+
+// goodsReceiptRollback undoes a receipt that could not be completed: the quantities added to the order
+// are taken off again and the receipt, if already saved, is removed
+func goodsReceiptRollback(orderID *primitive.ObjectID, receiptID *primitive.ObjectID, increments bson.M, systemContext *model.SystemContext) {
+	if receiptID != nil {
+		if _, err := systemContext.MongoDB.Collection("goods_receipt").DeleteOne(context.Background(), bson.M{"_id": receiptID}); err != nil {
+			systemContext.Logger.Error("service.goodsReceiptRollback receipt", zap.Error(err))
+		}
+	}
+
+	revert := bson.M{}
+	for key, value := range increments {
+		revert[key] = -value.(float64)
+	}
+	if _, err := systemContext.MongoDB.Collection("order").UpdateOne(context.Background(), bson.M{"_id": orderID}, bson.M{"$inc": revert}); err != nil {
+		systemContext.Logger.Error("service.goodsReceiptRollback order", zap.Error(err))
+	}
+}
@@ -0,0 +1,1027 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"math"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.uber.org/zap"
+	"renotech.com.my/internal/database"
+	"renotech.com.my/internal/enum"
+	"renotech.com.my/internal/model"
+	"renotech.com.my/internal/utils"
+)
+
+const defaultInvoicePaymentTermDays = 30
+
+// invoiceStatusTransitions lists the statuses each invoice status may move to.
+// Paid invoices cannot be voided; the payments must be reversed first.
+var invoiceStatusTransitions = map[enum.InvoiceStatus][]enum.InvoiceStatus{
+	enum.InvoiceStatusDraft:         {enum.InvoiceStatusIssued, enum.InvoiceStatusVoid},
+	enum.InvoiceStatusIssued:        {enum.InvoiceStatusPartiallyPaid, enum.InvoiceStatusPaid, enum.InvoiceStatusVoid},
+	enum.InvoiceStatusPartiallyPaid: {enum.InvoiceStatusPaid},
+	enum.InvoiceStatusPaid:          {},
+	enum.InvoiceStatusVoid:          {},
+}
+
+// invoiceBilling is what a billing request resolves to: the lines and the retention withheld from them
+type invoiceBilling struct {
+	lines            []database.InvoiceLine
+	rounding         enum.MoneyRounding
+	retentionPercent float64
+	retentionAmount  database.Money
+}
+
+// Tenant services
+func invoiceCreateValidation(input *model.InvoiceCreateRequest, systemContext *model.SystemContext) (*database.Project, *invoiceBilling, error) {
+	project, err := ProjectGetByID(input.Project, systemContext)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	billing, err := validateInvoiceBilling(project, &input.InvoiceBillingRequest, nil, systemContext)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return project, billing, nil
+}
+
+func InvoiceCreate(input *model.InvoiceCreateRequest, systemContext *model.SystemContext) (*database.Invoice, error) {
+	// Validate input
+	project, billing, err := invoiceCreateValidation(input, systemContext)
+	if err != nil {
+		return nil, err
+	}
+
+	invoiceNumber, err := DocumentNumberGenerate(enum.DocumentNumberTypeInvoice, systemContext)
+	if err != nil {
+		return nil, err
+	}
+
+	subTotal, taxAmount, total := calculateInvoiceTotals(billing, input.TaxRate)
+
+	invoice := &database.Invoice{
+		Project:          input.Project,
+		Company:          systemContext.User.Company,
+		InvoiceNumber:    invoiceNumber,
+		BillingType:      input.BillingType,
+		Title:            invoiceTitle(&input.InvoiceBillingRequest),
+		IssueDate:        input.IssueDate,
+		DueDate:          invoiceDueDate(&input.InvoiceBillingRequest),
+		Lines:            billing.lines,
+		SubTotal:         subTotal,
+		RetentionPercent: billing.retentionPercent,
+		RetentionAmount:  billing.retentionAmount,
+		TaxRate:          input.TaxRate,
+		TaxAmount:        taxAmount,
+		Total:            total,
+		PaidAmount:       0,
+		BalanceDue:       total,
+		Status:           enum.InvoiceStatusDraft,
+		Remark:           input.Remark,
+		ActionLogs:       []database.SystemActionLog{newSystemActionLog("Invoice created", systemContext)},
+		CreatedAt:        time.Now(),
+		CreatedBy:        *systemContext.User.ID,
+		UpdatedAt:        time.Now(),
+		UpdatedBy:        systemContext.User.ID,
+		IsDeleted:        false,
+	}
+
+	// Snapshot the client so later quotation edits do not change issued invoices
+	if quotation, err := QuotationGetByID(project.Quotation, systemContext); err == nil {
+		invoice.Client = quotation.Client
+		invoice.Address = quotation.Address
+	}
+
+	// Take the amount off the project's billing caps first, so concurrent claims cannot both fit
+	claim := invoiceClaimOf(billing.lines)
+	if err := projectReserveBilling(project, claim, systemContext); err != nil {
+		return nil, err
+	}
+
+	collection := systemContext.MongoDB.Collection("invoice")
+	result, err := collection.InsertOne(context.Background(), invoice)
+	if err != nil {
+		systemContext.Logger.Error("service.InvoiceCreate", zap.Error(err))
+		projectReleaseBilling(*project.ID, claim, systemContext)
+		return nil, utils.SystemError(enum.ErrorCodeInternal, "Failed to create invoice", nil)
+	}
+
+	return InvoiceGetByID(result.InsertedID.(primitive.ObjectID), systemContext)
+}
+
+func invoiceUpdateValidation(input *model.InvoiceUpdateRequest, systemContext *model.SystemContext) (*database.Invoice, *database.Project, *invoiceBilling, error) {
+	invoice, err := InvoiceGetByID(input.ID, systemContext)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	if invoice.Status != enum.InvoiceStatusDraft {
+		return nil, nil, nil, utils.SystemError(
+			enum.ErrorCodeValidation,
+			"Only draft invoices can be edited",
+			map[string]interface{}{"status": invoice.Status},
+		)
+	}
+
+	project, err := ProjectGetByID(invoice.Project, systemContext)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	billing, err := validateInvoiceBilling(project, &input.InvoiceBillingRequest, invoice.ID, systemContext)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	return invoice, project, billing, nil
+}
+
+func InvoiceUpdate(input *model.InvoiceUpdateRequest, systemContext *model.SystemContext) (*database.Invoice, error) {
+	// Validate input
+	invoice, project, billing, err := invoiceUpdateValidation(input, systemContext)
+	if err != nil {
+		return nil, err
+	}
+
+	subTotal, taxAmount, total := calculateInvoiceTotals(billing, input.TaxRate)
+
+	// Only the difference to what the draft already claimed is taken off the billing caps
+	previous := invoiceClaimOf(invoice.Lines)
+	current := invoiceClaimOf(billing.lines)
+	delta := invoiceBillingClaim{
+		amount:       current.amount - previous.amount,
+		stagePercent: current.stagePercent - previous.stagePercent,
+	}
+	if err := projectReserveBilling(project, delta, systemContext); err != nil {
+		return nil, err
+	}
+
+	collection := systemContext.MongoDB.Collection("invoice")
+
+	// Only update the draft as it was read, so the claim replaced above is the one stored
+	filter := bson.M{
+		"_id":       input.ID,
+		"company":   systemContext.User.Company,
+		"status":    enum.InvoiceStatusDraft,
+		"updatedAt": invoice.UpdatedAt,
+		"isDeleted": false,
+	}
+
+	update := bson.M{
+		"$set": bson.M{
+			"billingType":      input.BillingType,
+			"title":            invoiceTitle(&input.InvoiceBillingRequest),
+			"issueDate":        input.IssueDate,
+			"dueDate":          invoiceDueDate(&input.InvoiceBillingRequest),
+			"lines":            billing.lines,
+			"subTotal":         subTotal,
+			"retentionPercent": billing.retentionPercent,
+			"retentionAmount":  billing.retentionAmount,
+			"taxRate":          input.TaxRate,
+			"taxAmount":        taxAmount,
+			"total":            total,
+			"balanceDue":       total,
+			"remark":           input.Remark,
+			"updatedAt":        time.Now(),
+			"updatedBy":        systemContext.User.ID,
+		},
+		"$push": bson.M{
+			"actionLogs": newSystemActionLog("Invoice updated", systemContext),
+		},
+	}
+
+	result, err := collection.UpdateOne(context.Background(), filter, update)
+	if err != nil {
+		projectReleaseBilling(*project.ID, delta, systemContext)
+		return nil, utils.SystemError(enum.ErrorCodeInternal, "Failed to update invoice", nil)
+	}
+
+	if result.MatchedCount == 0 {
+		projectReleaseBilling(*project.ID, delta, systemContext)
+		return nil, utils.SystemError(enum.ErrorCodeValidation, "Invoice was changed by another request, please reload", nil)
+	}
+
+	return InvoiceGetByID(input.ID, systemContext)
+}
+
+func InvoiceGetByID(invoiceID primitive.ObjectID, systemContext *model.SystemContext) (*database.Invoice, error) {
+	collection := systemContext.MongoDB.Collection("invoice")
+
+	filter := bson.M{
+		"_id":       invoiceID,
+		"company":   systemContext.User.Company,
+		"isDeleted": false,
+	}
+
+	var doc database.Invoice
+	err := collection.FindOne(context.Background(), filter).Decode(&doc)
+	if err != nil {
+		return nil, utils.SystemError(enum.ErrorCodeNotFound, "Invoice not found", nil)
+	}
+
+	return &doc, nil
+}
+
+func InvoiceList(input model.InvoiceListRequest, systemContext *model.SystemContext) (*model.InvoiceListResponse, error) {
+	collection := systemContext.MongoDB.Collection("invoice")
+
+	// Build base filter
+	filter := bson.M{"isDeleted": false, "company": systemContext.User.Company}
+
+	// Add field-specific filters
+	if input.Project != nil {
+		filter["project"] = input.Project
+	}
+	if input.Status != "" {
+		filter["status"] = input.Status
+	}
+
+	// Overdue: issued or partially paid past the due date
+	if input.IsOverdue != nil {
+		outstanding := []enum.InvoiceStatus{enum.InvoiceStatusIssued, enum.InvoiceStatusPartiallyPaid}
+		if *input.IsOverdue {
+			filter["dueDate"] = bson.M{"$lt": time.Now()}
+			if input.Status == "" {
+				filter["status"] = bson.M{"$in": outstanding}
+			}
+		} else {
+			filter["$nor"] = []bson.M{{
+				"dueDate": bson.M{"$lt": time.Now()},
+				"status":  bson.M{"$in": outstanding},
+			}}
+		}
+	}
+
+	// Add issue date range filter
+	if input.DateFrom != nil || input.DateTo != nil {
+		dateFilter := bson.M{}
+		if input.DateFrom != nil {
+			dateFilter["$gte"] = input.DateFrom
+		}
+		if input.DateTo != nil {
+			dateFilter["$lte"] = input.DateTo
+		}
+		filter["issueDate"] = dateFilter
+	}
+
+	// Add global search filter
+	if strings.TrimSpace(input.Search) != "" {
+		searchRegex := primitive.Regex{Pattern: input.Search, Options: "i"}
+		filter["$or"] = []bson.M{
+			{"invoiceNumber": searchRegex},
+			{"title": searchRegex},
+			{"client.Nname": searchRegex},
+		}
+	}
+
+	// Get total count
+	total, err := collection.CountDocuments(context.Background(), filter)
+	if err != nil {
+		systemContext.Logger.Error("service.InvoiceList", zap.Error(err))
+		return nil, utils.SystemError(enum.ErrorCodeInternal, "Failed to count invoices", nil)
+	}
+
+	// Set default pagination values
+	page := input.Page
+	if page <= 0 {
+		page = 1
+	}
+	limit := input.Limit
+	if limit <= 0 {
+		limit = 10
+	}
+	if limit > 100 {
+		limit = 100 // Maximum limit
+	}
+
+	skip := (page - 1) * limit
+	totalPages := int(math.Ceil(float64(total) / float64(limit)))
+
+	var sortOptions bson.D
+	if len(input.Sort) > 0 {
+		for key, value := range input.Sort {
+			sortOptions = append(sortOptions, bson.E{Key: key, Value: value})
+		}
+	} else {
+		// Default sort by issue date descending (latest first)
+		sortOptions = bson.D{{Key: "issueDate", Value: -1}, {Key: "createdAt", Value: -1}}
+	}
+
+	findOptions := options.Find().
+		SetSkip(int64(skip)).
+		SetLimit(int64(limit)).
+		SetSort(sortOptions)
+
+	cursor, err := collection.Find(context.Background(), filter, findOptions)
+	if err != nil {
+		systemContext.Logger.Error("service.InvoiceList", zap.Error(err))
+		return nil, utils.SystemError(enum.ErrorCodeInternal, "Failed to retrieve invoices", nil)
+	}
+	defer cursor.Close(context.Background())
+
+	var invoices []bson.M
+	if err = cursor.All(context.Background(), &invoices); err != nil {
+		systemContext.Logger.Error("service.InvoiceList", zap.Error(err))
+		return nil, utils.SystemError(enum.ErrorCodeInternal, "Failed to decode invoices", nil)
+	}
+	moneyDocuments(invoices)
+
+	return &model.InvoiceListResponse{
+		Data:       invoices,
+		Page:       page,
+		Limit:      limit,
+		Total:      total,
+		TotalPages: totalPages,
+	}, nil
+}
+
+// InvoiceStatusUpdate moves an invoice through its lifecycle. Issuing stamps the issue time;
+// voiding requires a reason and releases whatever the invoice billed so it can be billed again.
+// Partially paid and paid are set by payments, not by this endpoint.
+func InvoiceStatusUpdate(invoiceID primitive.ObjectID, input *model.InvoiceStatusUpdateRequest, systemContext *model.SystemContext) (*database.Invoice, error) {
+	invoice, err := InvoiceGetByID(invoiceID, systemContext)
+	if err != nil {
+		return nil, err
+	}
+
+	if input.Status == enum.InvoiceStatusPartiallyPaid || input.Status == enum.InvoiceStatusPaid {
+		return nil, utils.SystemError(enum.ErrorCodeValidation, "Payment status is updated by recording payments against the invoice", nil)
+	}
+
+	if input.Status == enum.InvoiceStatusVoid && strings.TrimSpace(input.Remark) == "" {
+		return nil, utils.SystemError(enum.ErrorCodeValidation, "A reason is required to void an invoice", nil)
+	}
+
+	if input.Status == enum.InvoiceStatusVoid && invoice.PaidAmount > 0 {
+		return nil, utils.SystemError(
+			enum.ErrorCodeValidation,
+			"Invoice has payments allocated, void those payments first",
+			map[string]interface{}{"paidAmount": invoice.PaidAmount},
+		)
+	}
+
+	set := bson.M{}
+	switch input.Status {
+	case enum.InvoiceStatusIssued:
+		set["issuedAt"] = time.Now()
+	case enum.InvoiceStatusVoid:
+		set["voidedAt"] = time.Now()
+		set["voidReason"] = strings.TrimSpace(input.Remark)
+	}
+
+	updated, err := invoiceTransitionStatus(invoice, input.Status, input.Remark, set, systemContext)
+	if err != nil {
+		return nil, err
+	}
+
+	if updated.Status == enum.InvoiceStatusVoid {
+		projectReleaseBilling(invoice.Project, invoiceClaimOf(invoice.Lines), systemContext)
+	}
+
+	return updated, nil
+}
+
+// InvoiceDelete soft deletes a draft invoice. Issued invoices must be voided instead so the number stays accounted for.
+func InvoiceDelete(invoiceID primitive.ObjectID, systemContext *model.SystemContext) error {
+	collection := systemContext.MongoDB.Collection("invoice")
+
+	filter := bson.M{
+		"_id":       invoiceID,
+		"company":   systemContext.User.Company,
+		"status":    enum.InvoiceStatusDraft,
+		"isDeleted": false,
+	}
+
+	update := bson.M{
+		"$set": bson.M{
+			"isDeleted": true,
+			"updatedAt": time.Now(),
+			"updatedBy": systemContext.User.ID,
+		},
+	}
+
+	var invoice database.Invoice
+	err := collection.FindOneAndUpdate(context.Background(), filter, update).Decode(&invoice)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return utils.SystemError(enum.ErrorCodeValidation, "Only draft invoices can be deleted, void issued invoices instead", nil)
+		}
+		return utils.SystemError(enum.ErrorCodeInternal, "Failed to delete invoice", nil)
+	}
+
+	projectReleaseBilling(invoice.Project, invoiceClaimOf(invoice.Lines), systemContext)
+
+	return nil
+}
+
+// InvoiceGeneratePDF renders an invoice through the company's invoice document template
+func InvoiceGeneratePDF(invoiceID primitive.ObjectID, systemContext *model.SystemContext) ([]byte, string, error) {
+	invoice, err := InvoiceGetByID(invoiceID, systemContext)
+	if err != nil {
+		return nil, "", err
+	}
+
+	project, err := ProjectGetByID(invoice.Project, systemContext)
+	if err != nil {
+		return nil, "", err
+	}
+
+	company, err := CompanyTenantGet(systemContext)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return DocumentTemplateGenerate("invoice", invoiceDocumentData(invoice, project, company, systemContext), systemContext.User.Company, systemContext)
+}
+
+// Helper functions
+func invoiceTransitionStatus(invoice *database.Invoice, status enum.InvoiceStatus, remark string, set bson.M, systemContext *model.SystemContext) (*database.Invoice, error) {
+	if err := validateInvoiceStatusTransition(invoice.Status, status); err != nil {
+		return nil, err
+	}
+
+	description := fmt.Sprintf("Status changed from %s to %s", invoice.Status, status)
+	if strings.TrimSpace(remark) != "" {
+		description += ": " + strings.TrimSpace(remark)
+	}
+
+	collection := systemContext.MongoDB.Collection("invoice")
+
+	filter := bson.M{
+		"_id":       invoice.ID,
+		"company":   systemContext.User.Company,
+		"status":    invoice.Status,
+		"isDeleted": false,
+	}
+
+	set["status"] = status
+	set["updatedAt"] = time.Now()
+	set["updatedBy"] = systemContext.User.ID
+
+	update := bson.M{
+		"$set": set,
+		"$push": bson.M{
+			"actionLogs": newSystemActionLog(description, systemContext),
+		},
+	}
+
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+
+	var doc database.Invoice
+	err := collection.FindOneAndUpdate(context.Background(), filter, update, opts).Decode(&doc)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, utils.SystemError(
+				enum.ErrorCodeValidation,
+				"Invoice status was changed by another request, please reload",
+				map[string]interface{}{"currentStatus": invoice.Status},
+			)
+		}
+		systemContext.Logger.Error("service.invoiceTransitionStatus", zap.Error(err))
+		return nil, utils.SystemError(enum.ErrorCodeInternal, "Failed to update invoice status", nil)
+	}
+
+	return &doc, nil
+}
+
+func validateInvoiceStatusTransition(from enum.InvoiceStatus, to enum.InvoiceStatus) error {
+	if _, exists := invoiceStatusTransitions[to]; !exists {
+		return utils.SystemError(
+			enum.ErrorCodeValidation,
+			"Invalid invoice status",
+			map[string]interface{}{"status": to},
+		)
+	}
+
+	allowed := invoiceStatusTransitions[from]
+	for _, status := range allowed {
+		if status == to {
+			return nil
+		}
+	}
+
+	if allowed == nil {
+		allowed = []enum.InvoiceStatus{}
+	}
+
+	return utils.SystemError(
+		enum.ErrorCodeValidation,
+		"Invoice status transition not allowed",
+		map[string]interface{}{
+			"from":    from,
+			"to":      to,
+			"allowed": allowed,
+		},
+	)
+}
+
+// validateInvoiceBilling checks the billing request and builds the invoice lines. Amounts already
+// billed on other non-void invoices of the project are taken into account so nothing is billed twice.
+// Progress claims withhold the project's retention; retention releases do not.
+func validateInvoiceBilling(project *database.Project, input *model.InvoiceBillingRequest, excludeInvoiceID *primitive.ObjectID, systemContext *model.SystemContext) (*invoiceBilling, error) {
+	if input.TaxRate < 0 || input.TaxRate > 100 {
+		return nil, utils.SystemError(
+			enum.ErrorCodeValidation,
+			"Tax rate must be between 0 and 100",
+			map[string]interface{}{"taxRate": input.TaxRate},
+		)
+	}
+
+	if input.PaymentTermDays < 0 {
+		return nil, utils.SystemError(enum.ErrorCodeValidation, "Payment term cannot be negative", nil)
+	}
+
+	if input.DueDate != nil && input.DueDate.Before(input.IssueDate) {
+		return nil, utils.SystemError(enum.ErrorCodeValidation, "Due date cannot be before issue date", nil)
+	}
+
+	invoices, err := projectBilledInvoices(*project.ID, excludeInvoiceID, systemContext)
+	if err != nil {
+		return nil, err
+	}
+
+	var lines []database.InvoiceLine
+	switch input.BillingType {
+	case enum.InvoiceBillingTypeStage:
+		lines, err = invoiceStageLines(project, input, invoices)
+	case enum.InvoiceBillingTypeItems:
+		lines, err = invoiceItemLines(project, input, invoices)
+	case enum.InvoiceBillingTypeVariation:
+		lines, err = invoiceVariationLines(project, input, invoices, systemContext)
+	case enum.InvoiceBillingTypeRetention:
+		lines, err = invoiceRetentionLines(project, input, invoices)
+	default:
+		return nil, utils.SystemError(
+			enum.ErrorCodeValidation,
+			"Invalid billing type",
+			map[string]interface{}{"billingType": input.BillingType},
+		)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	billing := &invoiceBilling{lines: lines, rounding: companyMoneyRounding(systemContext)}
+
+	// Variation invoices may be negative, crediting the client for omissions
+	subTotal := invoiceSubTotal(billing)
+	if subTotal == 0 || (subTotal < 0 && input.BillingType != enum.InvoiceBillingTypeVariation) {
+		return nil, utils.SystemError(enum.ErrorCodeValidation, "Invoice amount must be greater than zero", nil)
+	}
+
+	// Stage, item and variation billing share one ceiling: the contract sum including approved variations
+	if input.BillingType != enum.InvoiceBillingTypeRetention {
+		contractSum := projectContractSum(project)
+		billed := invoiceContractBilled(invoices)
+		if billed+subTotal > contractSum {
+			return nil, utils.SystemError(
+				enum.ErrorCodeValidation,
+				"Invoice exceeds the unbilled contract sum",
+				map[string]interface{}{
+					"contractSum": contractSum,
+					"billed":      billed,
+					"remaining":   database.MaxMoney(contractSum-billed, 0),
+					"amount":      subTotal,
+				},
+			)
+		}
+	}
+
+	if input.BillingType != enum.InvoiceBillingTypeRetention && project.RetentionPercent > 0 && subTotal > 0 {
+		billing.retentionPercent = project.RetentionPercent
+		billing.retentionAmount = invoiceRetentionDeduction(project, subTotal, invoices)
+	}
+
+	return billing, nil
+}
+
+// invoiceStageLines bills a percentage of the original contract sum, capped at 100% across all stage
+// invoices. Approved variations are billed separately through variation billing.
+func invoiceStageLines(project *database.Project, input *model.InvoiceBillingRequest, invoices []database.Invoice) ([]database.InvoiceLine, error) {
+	if input.StagePercent <= 0 || input.StagePercent > 100 {
+		return nil, utils.SystemError(
+			enum.ErrorCodeValidation,
+			"Stage percentage must be between 0 and 100",
+			map[string]interface{}{"stagePercent": input.StagePercent},
+		)
+	}
+
+	var billedPercent float64
+	for _, invoice := range invoices {
+		for _, line := range invoice.Lines {
+			billedPercent += line.StagePercent
+		}
+	}
+
+	if billedPercent+input.StagePercent > 100+quantityTolerance {
+		return nil, utils.SystemError(
+			enum.ErrorCodeValidation,
+			"Stage billing exceeds 100% of the contract sum",
+			map[string]interface{}{
+				"billedPercent":    billedPercent,
+				"remainingPercent": math.Max(100-billedPercent, 0),
+			},
+		)
+	}
+
+	contractSum := project.TotalNettCharge
+	description := strings.TrimSpace(input.Title)
+	if description == "" {
+		description = "Progress claim"
+	}
+
+	return []database.InvoiceLine{{
+		Description:  fmt.Sprintf("%s (%s%% of original contract sum %s)", description, documentQuantity(input.StagePercent), documentMoney(contractSum)),
+		StagePercent: input.StagePercent,
+		Unit:         "%",
+		Quantity:     input.StagePercent,
+		UnitPrice:    contractSum.Percent(1),
+		Amount:       contractSum.Percent(input.StagePercent),
+	}}, nil
+}
+
+// invoiceItemLines bills selected area items at their quoted unit price, up to the unbilled quantity
+func invoiceItemLines(project *database.Project, input *model.InvoiceBillingRequest, invoices []database.Invoice) ([]database.InvoiceLine, error) {
+	if len(input.Items) == 0 {
+		return nil, utils.SystemError(enum.ErrorCodeValidation, "At least one item is required", nil)
+	}
+
+	billed := make(map[string]float64)
+	for _, invoice := range invoices {
+		for _, line := range invoice.Lines {
+			if line.ItemIndex != nil {
+				billed[invoiceItemKey(line.Area, *line.ItemIndex)] += line.Quantity
+			}
+		}
+	}
+
+	lines := []database.InvoiceLine{}
+	for i, selection := range input.Items {
+		area := strings.TrimSpace(selection.Area)
+
+		var materials []database.SystemAreaMaterialDetail
+		found := false
+		for _, areaMaterial := range project.AreaMaterials {
+			if strings.TrimSpace(areaMaterial.Area.Name) == area {
+				materials = areaMaterial.Materials
+				found = true
+				break
+			}
+		}
+
+		if !found || selection.ItemIndex < 0 || selection.ItemIndex >= len(materials) {
+			return nil, utils.SystemError(
+				enum.ErrorCodeValidation,
+				"Item not found in project",
+				map[string]interface{}{"index": i, "area": selection.Area, "itemIndex": selection.ItemIndex},
+			)
+		}
+
+		material := materials[selection.ItemIndex]
+		key := invoiceItemKey(area, selection.ItemIndex)
+		remaining := material.Quantity - billed[key]
+
+		quantity := selection.Quantity
+		if quantity == 0 {
+			quantity = remaining
+		}
+
+		if quantity <= 0 || quantity > remaining+quantityTolerance {
+			return nil, utils.SystemError(
+				enum.ErrorCodeValidation,
+				"Quantity exceeds the unbilled quantity of the item",
+				map[string]interface{}{"index": i, "item": material.Name, "unbilledQuantity": math.Max(remaining, 0)},
+			)
+		}
+		billed[key] += quantity
+
+		itemIndex := selection.ItemIndex
+		lines = append(lines, database.InvoiceLine{
+			Description: material.Name,
+			Area:        area,
+			ItemIndex:   &itemIndex,
+			Unit:        material.Unit,
+			Quantity:    quantity,
+			UnitPrice:   material.PricePerUnit,
+			Amount:      material.PricePerUnit.Times(quantity),
+		})
+	}
+
+	return lines, nil
+}
+
+// invoiceVariationLines bills approved variation orders that are not on another invoice yet
+func invoiceVariationLines(project *database.Project, input *model.InvoiceBillingRequest, invoices []database.Invoice, systemContext *model.SystemContext) ([]database.InvoiceLine, error) {
+	if len(input.VariationOrders) == 0 {
+		return nil, utils.SystemError(enum.ErrorCodeValidation, "At least one variation order is required", nil)
+	}
+
+	billed := make(map[primitive.ObjectID]string)
+	for _, invoice := range invoices {
+		for _, line := range invoice.Lines {
+			if line.VariationOrder != nil {
+				billed[*line.VariationOrder] = invoice.InvoiceNumber
+			}
+		}
+	}
+
+	lines := []database.InvoiceLine{}
+	seen := make(map[primitive.ObjectID]bool)
+	for _, variationOrderID := range input.VariationOrders {
+		if seen[variationOrderID] {
+			continue
+		}
+		seen[variationOrderID] = true
+
+		variationOrder, err := VariationOrderGetByID(variationOrderID, systemContext)
+		if err != nil {
+			return nil, err
+		}
+
+		if variationOrder.Project != *project.ID {
+			return nil, utils.SystemError(
+				enum.ErrorCodeValidation,
+				"Variation order belongs to another project",
+				map[string]interface{}{"variationOrder": variationOrderID.Hex()},
+			)
+		}
+
+		if variationOrder.Status != enum.VariationOrderStatusApproved {
+			return nil, utils.SystemError(
+				enum.ErrorCodeValidation,
+				"Only approved variation orders can be billed",
+				map[string]interface{}{"voNumber": variationOrder.VONumber, "status": variationOrder.Status},
+			)
+		}
+
+		if invoiceNumber, ok := billed[variationOrderID]; ok {
+			return nil, utils.SystemError(
+				enum.ErrorCodeValidation,
+				"Variation order has already been billed",
+				map[string]interface{}{"voNumber": variationOrder.VONumber, "invoiceNumber": invoiceNumber},
+			)
+		}
+
+		voID := variationOrderID
+		lines = append(lines, database.InvoiceLine{
+			Description:    fmt.Sprintf("Variation order %s - %s", variationOrder.VONumber, variationOrder.Title),
+			VariationOrder: &voID,
+			Unit:           "lot",
+			Quantity:       1,
+			UnitPrice:      variationOrder.NetAmount,
+			Amount:         variationOrder.NetAmount,
+		})
+	}
+
+	return lines, nil
+}
+
+// projectBilledInvoices returns the project's invoices that still count as billed (everything but void)
+func projectBilledInvoices(projectID primitive.ObjectID, excludeInvoiceID *primitive.ObjectID, systemContext *model.SystemContext) ([]database.Invoice, error) {
+	collection := systemContext.MongoDB.Collection("invoice")
+
+	filter := bson.M{
+		"project":   projectID,
+		"company":   systemContext.User.Company,
+		"status":    bson.M{"$ne": enum.InvoiceStatusVoid},
+		"isDeleted": false,
+	}
+	if excludeInvoiceID != nil {
+		filter["_id"] = bson.M{"$ne": excludeInvoiceID}
+	}
+
+	cursor, err := collection.Find(context.Background(), filter)
+	if err != nil {
+		systemContext.Logger.Error("service.projectBilledInvoices", zap.Error(err))
+		return nil, utils.SystemError(enum.ErrorCodeInternal, "Failed to retrieve project invoices", nil)
+	}
+	defer cursor.Close(context.Background())
+
+	var invoices []database.Invoice
+	if err = cursor.All(context.Background(), &invoices); err != nil {
+		systemContext.Logger.Error("service.projectBilledInvoices", zap.Error(err))
+		return nil, utils.SystemError(enum.ErrorCodeInternal, "Failed to decode project invoices", nil)
+	}
+
+	return invoices, nil
+}
+
+// calculateInvoiceTotals returns the sub total, tax and total. Tax is charged on the amount after retention,
+// the retained part is taxed when it is released.
+func calculateInvoiceTotals(billing *invoiceBilling, taxRate float64) (database.Money, database.Money, database.Money) {
+	subTotal := invoiceSubTotal(billing)
+	netAmount := subTotal - billing.retentionAmount
+	taxAmount := netAmount.Percent(taxRate)
+
+	return subTotal, taxAmount, netAmount + taxAmount
+}
+
+// invoiceSubTotal adds up the lines. Item lines are priced per unit, so under document rounding their
+// exact amounts are added; other lines are lump sums.
+func invoiceSubTotal(billing *invoiceBilling) database.Money {
+	total := newMoneyTotal(billing.rounding)
+	for _, line := range billing.lines {
+		if line.ItemIndex != nil {
+			total.addLine(line.UnitPrice, line.Quantity)
+		} else {
+			total.add(line.Amount)
+		}
+	}
+	return total.value()
+}
+
+// projectContractSum is the revised contract sum, falling back to the nett charge for projects
+// created before variation orders existed
+func projectContractSum(project *database.Project) database.Money {
+	if project.RevisedContractSum != 0 {
+		return project.RevisedContractSum
+	}
+	return project.TotalNettCharge
+}
+
+// invoiceContractBilled adds up what the invoices billed against the contract; retention releases
+// only pay out amounts withheld earlier, so they are left out
+func invoiceContractBilled(invoices []database.Invoice) database.Money {
+	var billed database.Money
+	for _, invoice := range invoices {
+		for _, line := range invoice.Lines {
+			if line.RetentionRelease == "" {
+				billed += line.Amount
+			}
+		}
+	}
+	return billed
+}
+
+// invoiceBillingClaim is what an invoice counts against the project's billing caps
+type invoiceBillingClaim struct {
+	amount       database.Money
+	stagePercent float64
+}
+
+// invoiceClaimOf adds up the contract billing and stage percentage of invoice lines, leaving out
+// retention releases as invoiceContractBilled does
+func invoiceClaimOf(lines []database.InvoiceLine) invoiceBillingClaim {
+	var claim invoiceBillingClaim
+	for _, line := range lines {
+		if line.RetentionRelease == "" {
+			claim.amount += line.Amount
+			claim.stagePercent += line.StagePercent
+		}
+	}
+	return claim
+}
+
+// projectReserveBilling adds a claim to the project's billed counters. The update only applies while
+// the totals stay within the contract sum and 100% stage billing, so concurrent claims cannot both fit.
+func projectReserveBilling(project *database.Project, claim invoiceBillingClaim, systemContext *model.SystemContext) error {
+	if claim.amount == 0 && claim.stagePercent == 0 {
+		return nil
+	}
+
+	conditions := bson.A{}
+	if claim.amount > 0 {
+		conditions = append(conditions, bson.M{"$lte": bson.A{
+			bson.M{"$add": bson.A{bson.M{"$ifNull": bson.A{"$billedAmount", 0}}, claim.amount}},
+			projectContractSum(project),
+		}})
+	}
+	if claim.stagePercent > 0 {
+		conditions = append(conditions, bson.M{"$lte": bson.A{
+			bson.M{"$add": bson.A{bson.M{"$ifNull": bson.A{"$billedStagePercent", 0}}, claim.stagePercent}},
+			100 + quantityTolerance,
+		}})
+	}
+
+	filter := bson.M{
+		"_id":       project.ID,
+		"company":   systemContext.User.Company,
+		"isDeleted": false,
+	}
+	if len(conditions) > 0 {
+		filter["$expr"] = bson.M{"$and": conditions}
+	}
+
+	update := bson.M{"$inc": bson.M{"billedAmount": claim.amount, "billedStagePercent": claim.stagePercent}}
+
+	result, err := systemContext.MongoDB.Collection("project").UpdateOne(context.Background(), filter, update)
+	if err != nil {
+		systemContext.Logger.Error("service.projectReserveBilling", zap.Error(err))
+		return utils.SystemError(enum.ErrorCodeInternal, "Failed to update project billing", nil)
+	}
+
+	if result.MatchedCount == 0 {
+		return utils.SystemError(
+			enum.ErrorCodeValidation,
+			"Invoice exceeds the unbilled contract sum, another invoice may have been created, please reload",
+			map[string]interface{}{"amount": claim.amount, "stagePercent": claim.stagePercent},
+		)
+	}
+
+	return nil
+}
+
+// projectReleaseBilling takes a claim back off the project's billed counters, logging rather than
+// returning failures
+func projectReleaseBilling(projectID primitive.ObjectID, claim invoiceBillingClaim, systemContext *model.SystemContext) {
+	if claim.amount == 0 && claim.stagePercent == 0 {
+		return
+	}
+
+	update := bson.M{"$inc": bson.M{"billedAmount": -claim.amount, "billedStagePercent": -claim.stagePercent}}
+	if _, err := systemContext.MongoDB.Collection("project").UpdateOne(context.Background(), bson.M{"_id": projectID}, update); err != nil {
+		systemContext.Logger.Error("service.projectReleaseBilling", zap.Error(err))
+	}
+}
+
+func invoiceItemKey(area string, itemIndex int) string {
+	return fmt.Sprintf("%s#%d", strings.TrimSpace(area), itemIndex)
+}
+
+func invoiceTitle(input *model.InvoiceBillingRequest) string {
+	if title := strings.TrimSpace(input.Title); title != "" {
+		return title
+	}
+
+	switch input.BillingType {
+	case enum.InvoiceBillingTypeStage:
+		return "Progress claim"
+	case enum.InvoiceBillingTypeVariation:
+		return "Variation works"
+	case enum.InvoiceBillingTypeRetention:
+		return "Release of retention"
+	default:
+		return "Works completed"
+	}
+}
+
+func invoiceDueDate(input *model.InvoiceBillingRequest) time.Time {
+	if input.DueDate != nil {
+		return *input.DueDate
+	}
+
+	days := input.PaymentTermDays
+	if days == 0 {
+		days = defaultInvoicePaymentTermDays
+	}
+
+	return input.IssueDate.AddDate(0, 0, days)
+}
+
+func invoiceDocumentData(invoice *database.Invoice, project *database.Project, company *database.Company, systemContext *model.SystemContext) bson.M {
+	lines := make([]interface{}, len(invoice.Lines))
+	for i, line := range invoice.Lines {
+		lines[i] = map[string]interface{}{
+			"no":          i + 1,
+			"description": documentMultiline(line.Description),
+			"area":        html.EscapeString(line.Area),
+			"unit":        html.EscapeString(line.Unit),
+			"quantity":    documentQuantity(line.Quantity),
+			"unitPrice":   documentMoney(line.UnitPrice),
+			"amount":      documentMoney(line.Amount),
+		}
+	}
+
+	invoiceData := bson.M{
+		"invoiceNumber":   html.EscapeString(invoice.InvoiceNumber),
+		"title":           html.EscapeString(invoice.Title),
+		"billingType":     string(invoice.BillingType),
+		"status":          string(invoice.Status),
+		"issueDate":       documentDate(invoice.IssueDate),
+		"dueDate":         documentDate(invoice.DueDate),
+		"projectName":     html.EscapeString(project.Name),
+		"contractSum":     documentMoney(projectContractSum(project)),
+		"clientName":      html.EscapeString(invoice.Client.Name),
+		"clientContact":   html.EscapeString(invoice.Client.Contact),
+		"clientEmail":     html.EscapeString(invoice.Client.Email),
+		"address":         documentAddress(invoice.Address),
+		"lines":           lines,
+		"subTotal":        documentMoney(invoice.SubTotal),
+		"retentionRate":   documentQuantity(invoice.RetentionPercent),
+		"retentionAmount": documentMoney(invoice.RetentionAmount),
+		"netAmount":       documentMoney(invoice.SubTotal - invoice.RetentionAmount),
+		"taxRate":         documentQuantity(invoice.TaxRate),
+		"taxAmount":       documentMoney(invoice.TaxAmount),
+		"total":           documentMoney(invoice.Total),
+		"paidAmount":      documentMoney(invoice.PaidAmount),
+		"balanceDue":      documentMoney(invoice.BalanceDue),
+		"remark":          documentMultiline(invoice.Remark),
+		"termConditions":  documentStringList(company.TermCondition),
+	}
+
+	data := documentCompanyData(company, systemContext)
+	for key, value := range invoiceData {
+		data[key] = value
+	}
+
+	return data
+}
@@ -642,6 +642,7 @@ func executeMaterialList(collection *mongo.Collection, filter bson.M, input mode
 		systemContext.Logger.Error("service.MaterialList", zap.Error(err))
 		return nil, utils.SystemError(enum.ErrorCodeInternal, "Failed to decode materials", nil)
 	}
+	moneyDocuments(materials)
 
 	response := &model.MaterialListResponse{
 		Data:       materials,
@@ -0,0 +1,338 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.uber.org/zap"
+	"renotech.com.my/internal/database"
+	"renotech.com.my/internal/enum"
+)
+
+// migration is a one-off data change. Applied migrations are recorded in the migration collection
+// so each runs once per database.
+type migration struct {
+	id  string
+	run func(db *mongo.Database, logger *zap.Logger) error
+}
+
+// Migrations in the order they are applied. Never reorder or remove an entry once released.
+var migrations = []migration{
+	{id: "2026-10-money-decimal", run: migrateMoneyToDecimal},
+	{id: "2026-10-quotation-revisions", run: migrateQuotationRevisions},
+	{id: "2026-10-quotation-status", run: migrateQuotationStatus},
+	{id: "2026-10-project-billing", run: migrateProjectBilling},
+	{id: "2026-10-adjustment-decimal", run: migrateAdjustmentsToDecimal},
+}
+
+// moneyFields lists, per collection, the field names holding money. Fields are matched at any depth,
+// which covers area materials, template children and line items.
+var moneyFields = map[string][]string{
+	"quotation":       {"budget", "pricePerUnit", "subTotal", "totalCharge", "totalDiscount", "totalAdditionalCharge", "totalNettCharge"},
+	"project":         {"pricePerUnit", "subTotal", "totalCharge", "totalDiscount", "totalAdditionalCharge", "totalNettCharge", "totalCost", "approvedVariation", "revisedContractSum"},
+	"folder":          {"budget"},
+	"material":        {"costPerUnit", "pricePerUnit"},
+	"order":           {"unitPrice", "totalPrice", "subTotal", "taxAmount", "totalCharge"},
+	"invoice":         {"unitPrice", "amount", "subTotal", "retentionAmount", "taxAmount", "total", "paidAmount", "balanceDue"},
+	"payment":         {"amount", "allocatedAmount", "unallocatedAmount"},
+	"project_cost":    {"amount"},
+	"variation_order": {"pricePerUnit", "subTotal", "amount", "totalAddition", "totalOmission", "netAmount"},
+}
+
+// MigrationRun applies the migrations that have not been applied to the database yet. It runs at
+// startup before the server accepts requests.
+func MigrationRun(db *mongo.Database, logger *zap.Logger) error {
+	collection := db.Collection("migration")
+
+	for _, m := range migrations {
+		count, err := collection.CountDocuments(context.Background(), bson.M{"_id": m.id})
+		if err != nil {
+			return fmt.Errorf("failed to check migration %s: %w", m.id, err)
+		}
+		if count > 0 {
+			continue
+		}
+
+		logger.Info("Migration started", zap.String("migration", m.id))
+		if err := m.run(db, logger); err != nil {
+			return fmt.Errorf("migration %s failed: %w", m.id, err)
+		}
+
+		if _, err := collection.InsertOne(context.Background(), bson.M{"_id": m.id, "appliedAt": time.Now()}); err != nil {
+			return fmt.Errorf("failed to record migration %s: %w", m.id, err)
+		}
+		logger.Info("Migration completed", zap.String("migration", m.id))
+	}
+
+	return nil
+}
+
+// migrateMoneyToDecimal rewrites money stored as doubles or integers in ringgit as decimals. Values
+// already stored as decimals are left alone, so an interrupted run can simply be repeated.
+func migrateMoneyToDecimal(db *mongo.Database, logger *zap.Logger) error {
+	for collectionName, fields := range moneyFields {
+		keys := make(map[string]bool)
+		for _, field := range fields {
+			keys[field] = true
+		}
+
+		collection := db.Collection(collectionName)
+		cursor, err := collection.Find(context.Background(), bson.M{})
+		if err != nil {
+			return err
+		}
+
+		updated := 0
+		for cursor.Next(context.Background()) {
+			var document bson.M
+			if err := cursor.Decode(&document); err != nil {
+				cursor.Close(context.Background())
+				return err
+			}
+
+			if !migrateMoneyValue(document, keys) {
+				continue
+			}
+
+			if _, err := collection.ReplaceOne(context.Background(), bson.M{"_id": document["_id"]}, document); err != nil {
+				cursor.Close(context.Background())
+				return err
+			}
+			updated++
+		}
+
+		err = cursor.Err()
+		cursor.Close(context.Background())
+		if err != nil {
+			return err
+		}
+
+		logger.Info("Money migrated", zap.String("collection", collectionName), zap.Int("documents", updated))
+	}
+
+	return nil
+}
+
+// migrateMoneyValue converts numeric money fields in place and reports whether anything changed
+func migrateMoneyValue(value interface{}, keys map[string]bool) bool {
+	changed := false
+
+	switch typed := value.(type) {
+	case bson.M:
+		for key, item := range typed {
+			if keys[key] {
+				if amount, ok := legacyMoney(item); ok {
+					typed[key] = amount
+					changed = true
+					continue
+				}
+			}
+			if migrateMoneyValue(item, keys) {
+				changed = true
+			}
+		}
+	case bson.A:
+		for _, item := range typed {
+			if migrateMoneyValue(item, keys) {
+				changed = true
+			}
+		}
+	}
+
+	return changed
+}
+
+func legacyMoney(value interface{}) (database.Money, bool) {
+	switch number := value.(type) {
+	case float64:
+		return database.MoneyFromFloat(number), true
+	case int32:
+		return database.Money(int64(number) * 100), true
+	case int64:
+		return database.Money(number * 100), true
+	}
+	return 0, false
+}
+
+// migrateQuotationRevisions makes every existing quotation revision A, with a snapshot of its
+// content as last saved
+func migrateQuotationRevisions(db *mongo.Database, logger *zap.Logger) error {
+	collection := db.Collection("quotation")
+	revisionCollection := db.Collection("quotation_revision")
+
+	cursor, err := collection.Find(context.Background(), bson.M{"revision": bson.M{"$in": bson.A{0, nil}}})
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(context.Background())
+
+	migrated := 0
+	for cursor.Next(context.Background()) {
+		var quotation database.Quotation
+		if err := cursor.Decode(&quotation); err != nil {
+			return err
+		}
+
+		quotation.Revision = 1
+		quotation.RevisionReason = "Revision history started"
+
+		createdBy := quotation.CreatedBy
+		if quotation.UpdatedBy != nil {
+			createdBy = *quotation.UpdatedBy
+		}
+
+		// Upsert so a repeated run does not store the snapshot twice
+		revision := newQuotationRevision(&quotation, createdBy, "", quotation.UpdatedAt)
+		revisionFilter := bson.M{"quotation": quotation.ID, "revision": revision.Revision}
+		if _, err := revisionCollection.ReplaceOne(context.Background(), revisionFilter, revision, options.Replace().SetUpsert(true)); err != nil {
+			return err
+		}
+
+		update := bson.M{"$set": bson.M{"revision": quotation.Revision, "revisionReason": quotation.RevisionReason}}
+		if _, err := collection.UpdateOne(context.Background(), bson.M{"_id": quotation.ID}, update); err != nil {
+			return err
+		}
+		migrated++
+	}
+
+	if err := cursor.Err(); err != nil {
+		return err
+	}
+
+	logger.Info("Quotation revisions migrated", zap.Int("quotations", migrated))
+	return nil
+}
+
+// migrateQuotationStatus gives quotations stored before the sales pipeline a status. Quotations already
+// converted to a project are accepted, the rest start as drafts.
+func migrateQuotationStatus(db *mongo.Database, logger *zap.Logger) error {
+	collection := db.Collection("quotation")
+	projectCollection := db.Collection("project")
+
+	cursor, err := projectCollection.Find(context.Background(), bson.M{"isDeleted": false}, options.Find().SetProjection(bson.M{"quotation": 1, "createdAt": 1}))
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(context.Background())
+
+	accepted := 0
+	for cursor.Next(context.Background()) {
+		var project database.Project
+		if err := cursor.Decode(&project); err != nil {
+			return err
+		}
+
+		filter := bson.M{"_id": project.Quotation, "status": bson.M{"$exists": false}}
+		update := bson.M{"$set": bson.M{"status": enum.QuotationStatusAccepted, "acceptedAt": project.CreatedAt}}
+		result, err := collection.UpdateOne(context.Background(), filter, update)
+		if err != nil {
+			return err
+		}
+		accepted += int(result.ModifiedCount)
+	}
+
+	if err := cursor.Err(); err != nil {
+		return err
+	}
+
+	result, err := collection.UpdateMany(context.Background(), bson.M{"status": bson.M{"$exists": false}}, bson.M{"$set": bson.M{"status": enum.QuotationStatusDraft}})
+	if err != nil {
+		return err
+	}
+
+	logger.Info("Quotation status migrated", zap.Int("accepted", accepted), zap.Int64("draft", result.ModifiedCount))
+	return nil
+}
+
+// migrateProjectBilling fills the project billed counters from the invoices billed so far, so the
+// billing caps hold for projects invoiced before the counters existed
+func migrateProjectBilling(db *mongo.Database, logger *zap.Logger) error {
+	cursor, err := db.Collection("invoice").Find(context.Background(), bson.M{
+		"status":    bson.M{"$ne": enum.InvoiceStatusVoid},
+		"isDeleted": false,
+	})
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(context.Background())
+
+	claims := make(map[primitive.ObjectID]invoiceBillingClaim)
+	for cursor.Next(context.Background()) {
+		var invoice database.Invoice
+		if err := cursor.Decode(&invoice); err != nil {
+			return err
+		}
+
+		claim := invoiceClaimOf(invoice.Lines)
+		total := claims[invoice.Project]
+		total.amount += claim.amount
+		total.stagePercent += claim.stagePercent
+		claims[invoice.Project] = total
+	}
+
+	if err := cursor.Err(); err != nil {
+		return err
+	}
+
+	// Set rather than increment so an interrupted run can simply be repeated
+	for projectID, claim := range claims {
+		update := bson.M{"$set": bson.M{"billedAmount": claim.amount, "billedStagePercent": claim.stagePercent}}
+		if _, err := db.Collection("project").UpdateOne(context.Background(), bson.M{"_id": projectID}, update); err != nil {
+			return err
+		}
+	}
+
+	logger.Info("Project billing migrated", zap.Int("projects", len(claims)))
+	return nil
+}
+
+// migrateAdjustmentsToDecimal rewrites discount and additional charge values stored as doubles as
+// decimals. Revisions are left as saved; doubles there are still read correctly.
+func migrateAdjustmentsToDecimal(db *mongo.Database, logger *zap.Logger) error {
+	keys := map[string]bool{"value": true}
+
+	for _, collectionName := range []string{"quotation", "project"} {
+		collection := db.Collection(collectionName)
+		cursor, err := collection.Find(context.Background(), bson.M{}, options.Find().SetProjection(bson.M{"discounts": 1, "additionalCharges": 1}))
+		if err != nil {
+			return err
+		}
+
+		updated := 0
+		for cursor.Next(context.Background()) {
+			var document bson.M
+			if err := cursor.Decode(&document); err != nil {
+				cursor.Close(context.Background())
+				return err
+			}
+
+			discountsChanged := migrateMoneyValue(document["discounts"], keys)
+			chargesChanged := migrateMoneyValue(document["additionalCharges"], keys)
+			if !discountsChanged && !chargesChanged {
+				continue
+			}
+
+			update := bson.M{"$set": bson.M{"discounts": document["discounts"], "additionalCharges": document["additionalCharges"]}}
+			if _, err := collection.UpdateOne(context.Background(), bson.M{"_id": document["_id"]}, update); err != nil {
+				cursor.Close(context.Background())
+				return err
+			}
+			updated++
+		}
+
+		err = cursor.Err()
+		cursor.Close(context.Background())
+		if err != nil {
+			return err
+		}
+
+		logger.Info("Adjustments migrated", zap.String("collection", collectionName), zap.Int("documents", updated))
+	}
+
+	return nil
+}
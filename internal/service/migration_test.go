@@ -0,0 +1,129 @@
+package service
+
+import (
+	"reflect"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+	"renotech.com.my/internal/database"
+)
+
+func TestLegacyMoney(t *testing.T) {
+	tests := []struct {
+		name   string
+		value  interface{}
+		want   database.Money
+		wantOK bool
+	}{
+		{"double", 12.5, 1250, true},
+		{"double rounds half up", 1.005, 101, true},
+		{"negative double", -0.05, -5, true},
+		{"int32 in ringgit", int32(12), 1200, true},
+		{"int64 in ringgit", int64(7), 700, true},
+		{"already money", database.Money(1250), 0, false},
+		{"string", "12.50", 0, false},
+		{"nil", nil, 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := legacyMoney(tt.value)
+			if ok != tt.wantOK || got != tt.want {
+				t.Errorf("legacyMoney(%v) = %d, %v, want %d, %v", tt.value, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestMigrateMoneyValue(t *testing.T) {
+	keys := map[string]bool{"subTotal": true, "pricePerUnit": true, "totalNettCharge": true}
+
+	tests := []struct {
+		name        string
+		document    bson.M
+		want        bson.M
+		wantChanged bool
+	}{
+		{
+			name:        "top level fields",
+			document:    bson.M{"subTotal": 100.5, "totalNettCharge": int32(80), "name": "Kitchen"},
+			want:        bson.M{"subTotal": database.Money(10050), "totalNettCharge": database.Money(8000), "name": "Kitchen"},
+			wantChanged: true,
+		},
+		{
+			name: "nested area materials",
+			document: bson.M{"areaMaterials": bson.A{
+				bson.M{"subTotal": 1.005, "materials": bson.A{bson.M{"pricePerUnit": int64(3), "quantity": 2.5}}},
+			}},
+			want: bson.M{"areaMaterials": bson.A{
+				bson.M{"subTotal": database.Money(101), "materials": bson.A{bson.M{"pricePerUnit": database.Money(300), "quantity": 2.5}}},
+			}},
+			wantChanged: true,
+		},
+		{
+			name:        "fields not listed are left alone",
+			document:    bson.M{"quantity": 2.5, "taxRate": 6.0},
+			want:        bson.M{"quantity": 2.5, "taxRate": 6.0},
+			wantChanged: false,
+		},
+		{
+			name:        "already migrated",
+			document:    bson.M{"subTotal": database.Money(10050)},
+			want:        bson.M{"subTotal": database.Money(10050)},
+			wantChanged: false,
+		},
+		{
+			name:        "null money field",
+			document:    bson.M{"subTotal": nil},
+			want:        bson.M{"subTotal": nil},
+			wantChanged: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			changed := migrateMoneyValue(tt.document, keys)
+			if changed != tt.wantChanged {
+				t.Errorf("changed = %v, want %v", changed, tt.wantChanged)
+			}
+			if !reflect.DeepEqual(tt.document, tt.want) {
+				t.Errorf("document = %v, want %v", tt.document, tt.want)
+			}
+		})
+	}
+}
+
+// Migrated values must decode back to the same amount once written as decimals
+func TestMigrateMoneyValueRoundTrip(t *testing.T) {
+	document := bson.M{"subTotal": 1234.5, "items": bson.A{bson.M{"subTotal": int32(-2)}}}
+	if !migrateMoneyValue(document, map[string]bool{"subTotal": true}) {
+		t.Fatal("expected document to change")
+	}
+
+	data, err := bson.Marshal(document)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+
+	var raw bson.Raw = data
+	if got := raw.Lookup("subTotal").Type; got != bsontype.Decimal128 {
+		t.Errorf("stored type = %s, want %s", got, bsontype.Decimal128)
+	}
+
+	var decoded struct {
+		SubTotal database.Money `bson:"subTotal"`
+		Items    []struct {
+			SubTotal database.Money `bson:"subTotal"`
+		} `bson:"items"`
+	}
+	if err := bson.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if decoded.SubTotal != 123450 {
+		t.Errorf("subTotal = %d, want 123450", decoded.SubTotal)
+	}
+	if len(decoded.Items) != 1 || decoded.Items[0].SubTotal != -200 {
+		t.Errorf("items = %v, want one item with subTotal -200", decoded.Items)
+	}
+}
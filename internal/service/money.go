@@ -0,0 +1,96 @@
+package service
+
+import (
+	"math/big"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"renotech.com.my/internal/database"
+	"renotech.com.my/internal/enum"
+	"renotech.com.my/internal/model"
+)
+
+// moneyTotal adds up line amounts under a company's rounding rule. With line rounding each line is
+// rounded to the sen before it is added; with document rounding the exact amounts are added and only
+// the total is rounded.
+type moneyTotal struct {
+	rounding enum.MoneyRounding
+	exact    *big.Rat
+}
+
+func newMoneyTotal(rounding enum.MoneyRounding) *moneyTotal {
+	return &moneyTotal{rounding: rounding, exact: new(big.Rat)}
+}
+
+// addLine adds price x quantity and returns the line amount rounded to the sen
+func (t *moneyTotal) addLine(price database.Money, quantity float64) database.Money {
+	exact := price.TimesExact(quantity)
+	rounded := database.RoundMoney(exact)
+
+	if t.rounding == enum.MoneyRoundingDocument {
+		t.exact.Add(t.exact, exact)
+	} else {
+		t.exact.Add(t.exact, rounded.Rat())
+	}
+
+	return rounded
+}
+
+// add adds an amount that is already in whole sen
+func (t *moneyTotal) add(amount database.Money) {
+	t.exact.Add(t.exact, amount.Rat())
+}
+
+// addTotal adds another total without rounding it first, so nested subtotals follow the same rule
+func (t *moneyTotal) addTotal(other *moneyTotal) {
+	if t.rounding == enum.MoneyRoundingDocument {
+		t.exact.Add(t.exact, other.exact)
+	} else {
+		t.add(other.value())
+	}
+}
+
+func (t *moneyTotal) value() database.Money {
+	return database.RoundMoney(t.exact)
+}
+
+// companyMoneyRounding returns the rounding rule of the user's company, line rounding unless configured otherwise
+func companyMoneyRounding(systemContext *model.SystemContext) enum.MoneyRounding {
+	company, err := CompanyTenantGet(systemContext)
+	if err != nil || company.MoneyRounding != enum.MoneyRoundingDocument {
+		return enum.MoneyRoundingLine
+	}
+	return enum.MoneyRoundingDocument
+}
+
+// moneyDocuments converts the decimal amounts of documents decoded into bson.M to Money, so list
+// responses carry the same numbers as responses decoded into structs
+func moneyDocuments(documents []bson.M) {
+	for _, document := range documents {
+		moneyValue(document)
+	}
+}
+
+func moneyValue(value interface{}) interface{} {
+	switch typed := value.(type) {
+	case primitive.Decimal128:
+		amount, err := database.MoneyFromDecimal128(typed)
+		if err != nil {
+			return value
+		}
+		return amount
+	case bson.M:
+		for key, item := range typed {
+			typed[key] = moneyValue(item)
+		}
+	case bson.A:
+		for i, item := range typed {
+			typed[i] = moneyValue(item)
+		}
+	case bson.D:
+		for i := range typed {
+			typed[i].Value = moneyValue(typed[i].Value)
+		}
+	}
+	return value
+}
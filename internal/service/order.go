@@ -0,0 +1,1211 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"math"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.uber.org/zap"
+	"renotech.com.my/internal/database"
+	"renotech.com.my/internal/enum"
+	"renotech.com.my/internal/model"
+	"renotech.com.my/internal/utils"
+)
+
+// Tenant services
+func orderCreateValidation(input *model.OrderCreateRequest, systemContext *model.SystemContext) error {
+	// Validate project if provided - must belong to user's company
+	if input.Project != nil {
+		projectCollection := systemContext.MongoDB.Collection("project")
+		count, err := projectCollection.CountDocuments(context.Background(), bson.M{
+			"_id":       input.Project,
+			"company":   systemContext.User.Company,
+			"isDeleted": false,
+		})
+		if err != nil {
+			return utils.SystemError(enum.ErrorCodeInternal, "Failed to validate project", nil)
+		}
+
+		if count == 0 {
+			return utils.SystemError(enum.ErrorCodeValidation, "Project not found", nil)
+		}
+	}
+
+	if err := validateOrderSupplier(&input.Supplier, systemContext); err != nil {
+		return err
+	}
+
+	if err := validateOrderItems(input.Items, systemContext); err != nil {
+		return err
+	}
+
+	if err := validateOrderTaxAndPriority(input.TaxRate, input.Priority); err != nil {
+		return err
+	}
+
+	if input.ExpectedDelivery.Before(input.OrderDate) {
+		return utils.SystemError(enum.ErrorCodeValidation, "Expected delivery cannot be before order date", nil)
+	}
+
+	if input.Priority == "" {
+		input.Priority = enum.OrderPriorityMedium
+	}
+
+	return nil
+}
+
+func OrderCreate(input *model.OrderCreateRequest, systemContext *model.SystemContext) (*database.Order, error) {
+	// Validate input
+	if err := orderCreateValidation(input, systemContext); err != nil {
+		return nil, err
+	}
+
+	collection := systemContext.MongoDB.Collection("order")
+
+	poNumber, err := DocumentNumberGenerate(enum.DocumentNumberTypePurchaseOrder, systemContext)
+	if err != nil {
+		return nil, err
+	}
+
+	// Calculate totals
+	items, subTotal, taxAmount, totalCharge := calculateOrderTotals(input.Items, input.TaxRate, companyMoneyRounding(systemContext))
+
+	// Create order object
+	order := &database.Order{
+		Project:          input.Project,
+		Company:          systemContext.User.Company,
+		Supplier:         input.Supplier,
+		PONumber:         poNumber,
+		OrderDate:        input.OrderDate,
+		ExpectedDelivery: input.ExpectedDelivery,
+		DeliveryAddress:  input.DeliveryAddress,
+		DeliveryContact:  input.DeliveryContact,
+		DeliveryPhone:    input.DeliveryPhone,
+		DeliveryRemark:   input.DeliveryRemark,
+		TermConditions:   input.TermConditions,
+		Items:            items,
+		SubTotal:         subTotal,
+		TaxRate:          input.TaxRate,
+		TaxAmount:        taxAmount,
+		TotalCharge:      totalCharge,
+		Status:           enum.OrderStatusDraft,
+		Priority:         input.Priority,
+		Remark:           input.Remark,
+		InternalNotes:    input.InternalNotes,
+		ActionLogs:       []database.SystemActionLog{newSystemActionLog("Order created", systemContext)},
+		CreatedAt:        time.Now(),
+		CreatedBy:        *systemContext.User.ID,
+		UpdatedAt:        time.Now(),
+		UpdatedBy:        systemContext.User.ID,
+		IsDeleted:        false,
+	}
+
+	result, err := collection.InsertOne(context.Background(), order)
+	if err != nil {
+		return nil, utils.SystemError(enum.ErrorCodeInternal, "Failed to create order", nil)
+	}
+
+	orderID := result.InsertedID.(primitive.ObjectID)
+
+	var doc database.Order
+	err = collection.FindOne(context.Background(), bson.M{"_id": orderID}).Decode(&doc)
+	if err != nil {
+		return nil, utils.SystemError(enum.ErrorCodeInternal, "Failed to retrieve order", nil)
+	}
+
+	return &doc, nil
+}
+
+func orderUpdateValidation(input *model.OrderUpdateRequest, systemContext *model.SystemContext) error {
+	collection := systemContext.MongoDB.Collection("order")
+
+	// Check if order exists and belongs to user's company
+	filter := bson.M{
+		"_id":       input.ID,
+		"company":   systemContext.User.Company,
+		"isDeleted": false,
+	}
+
+	var doc database.Order
+	err := collection.FindOne(context.Background(), filter).Decode(&doc)
+	if err != nil {
+		return utils.SystemError(enum.ErrorCodeNotFound, "Order not found", nil)
+	}
+
+	// Items cannot change once goods have been received against them
+	switch doc.Status {
+	case enum.OrderStatusPartial, enum.OrderStatusDelivered, enum.OrderStatusCancelled:
+		return utils.SystemError(
+			enum.ErrorCodeValidation,
+			"Order can no longer be edited",
+			map[string]interface{}{"status": doc.Status},
+		)
+	}
+
+	if err := validateOrderSupplier(&input.Supplier, systemContext); err != nil {
+		return err
+	}
+
+	if err := validateOrderItems(input.Items, systemContext); err != nil {
+		return err
+	}
+
+	if err := validateOrderTaxAndPriority(input.TaxRate, input.Priority); err != nil {
+		return err
+	}
+
+	if input.ExpectedDelivery.Before(input.OrderDate) {
+		return utils.SystemError(enum.ErrorCodeValidation, "Expected delivery cannot be before order date", nil)
+	}
+
+	if input.Priority == "" {
+		input.Priority = enum.OrderPriorityMedium
+	}
+
+	return nil
+}
+
+func OrderUpdate(input *model.OrderUpdateRequest, systemContext *model.SystemContext) (*database.Order, error) {
+	// Validate input
+	if err := orderUpdateValidation(input, systemContext); err != nil {
+		return nil, err
+	}
+
+	collection := systemContext.MongoDB.Collection("order")
+
+	// Items are replaced wholesale, so only update while nothing has been received against them,
+	// in case a goods receipt was recorded since the order was read
+	filter := bson.M{
+		"_id":       input.ID,
+		"company":   systemContext.User.Company,
+		"status":    bson.M{"$nin": []enum.OrderStatus{enum.OrderStatusPartial, enum.OrderStatusDelivered, enum.OrderStatusCancelled}},
+		"items":     bson.M{"$not": bson.M{"$elemMatch": bson.M{"receivedQuantity": bson.M{"$gt": 0}}}},
+		"isDeleted": false,
+	}
+
+	// Calculate totals
+	items, subTotal, taxAmount, totalCharge := calculateOrderTotals(input.Items, input.TaxRate, companyMoneyRounding(systemContext))
+
+	update := bson.M{
+		"$set": bson.M{
+			"supplier":         input.Supplier,
+			"orderDate":        input.OrderDate,
+			"expectedDelivery": input.ExpectedDelivery,
+			"deliveryAddress":  input.DeliveryAddress,
+			"deliveryContact":  input.DeliveryContact,
+			"deliveryPhone":    input.DeliveryPhone,
+			"deliveryRemark":   input.DeliveryRemark,
+			"termConditions":   input.TermConditions,
+			"items":            items,
+			"subTotal":         subTotal,
+			"taxRate":          input.TaxRate,
+			"taxAmount":        taxAmount,
+			"totalCharge":      totalCharge,
+			"priority":         input.Priority,
+			"remark":           input.Remark,
+			"internalNotes":    input.InternalNotes,
+			"updatedAt":        time.Now(),
+			"updatedBy":        systemContext.User.ID,
+		},
+	}
+
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+
+	var doc database.Order
+	err := collection.FindOneAndUpdate(context.Background(), filter, update, opts).Decode(&doc)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, utils.SystemError(enum.ErrorCodeValidation, "Order was changed by another request and can no longer be edited, please reload", nil)
+		}
+		systemContext.Logger.Error("service.OrderUpdate", zap.Error(err))
+		return nil, utils.SystemError(enum.ErrorCodeInternal, "Failed to update order", nil)
+	}
+
+	return &doc, nil
+}
+
+func OrderGetByID(orderID primitive.ObjectID, systemContext *model.SystemContext) (*database.Order, error) {
+	collection := systemContext.MongoDB.Collection("order")
+
+	filter := bson.M{
+		"_id":       orderID,
+		"company":   systemContext.User.Company,
+		"isDeleted": false,
+	}
+
+	var doc database.Order
+	err := collection.FindOne(context.Background(), filter).Decode(&doc)
+	if err != nil {
+		return nil, utils.SystemError(enum.ErrorCodeNotFound, "Order not found", nil)
+	}
+
+	return &doc, nil
+}
+
+func OrderList(input model.OrderListRequest, systemContext *model.SystemContext) (*model.OrderListResponse, error) {
+	// Check if user has a company
+	if systemContext.User.Company == nil {
+		return &model.OrderListResponse{
+			Data:       []bson.M{},
+			Page:       1,
+			Limit:      10,
+			Total:      0,
+			TotalPages: 0,
+		}, nil
+	}
+
+	collection := systemContext.MongoDB.Collection("order")
+
+	// Build base filter - tenant can only see their company's orders
+	filter := bson.M{
+		"company":   systemContext.User.Company,
+		"isDeleted": false,
+	}
+
+	// Add field-specific filters
+	if input.Project != nil {
+		filter["project"] = input.Project
+	}
+	if input.SupplierID != nil {
+		filter["supplier._id"] = input.SupplierID
+	}
+	if strings.TrimSpace(input.SupplierName) != "" {
+		filter["supplier.name"] = primitive.Regex{Pattern: input.SupplierName, Options: "i"}
+	}
+	if input.Status != nil {
+		filter["status"] = *input.Status
+	}
+	if input.Priority != nil {
+		filter["priority"] = *input.Priority
+	}
+	if strings.TrimSpace(input.PONumber) != "" {
+		filter["poNumber"] = primitive.Regex{Pattern: input.PONumber, Options: "i"}
+	}
+
+	// Add order date range filter
+	if input.DateFrom != nil || input.DateTo != nil {
+		dateFilter := bson.M{}
+		if input.DateFrom != nil {
+			dateFilter["$gte"] = *input.DateFrom
+		}
+		if input.DateTo != nil {
+			dateFilter["$lte"] = *input.DateTo
+		}
+		filter["orderDate"] = dateFilter
+	}
+
+	// Add global search filter
+	if strings.TrimSpace(input.Search) != "" {
+		searchRegex := primitive.Regex{Pattern: input.Search, Options: "i"}
+		searchFilter := bson.M{
+			"$or": []bson.M{
+				{"poNumber": searchRegex},
+				{"supplier.name": searchRegex},
+				{"items.name": searchRegex},
+				{"remark": searchRegex},
+			},
+		}
+
+		// Combine existing filter with search filter
+		if len(filter) > 2 { // More than just company and isDeleted
+			filter = bson.M{
+				"$and": []bson.M{
+					filter,
+					searchFilter,
+				},
+			}
+		} else {
+			filter["$or"] = searchFilter["$or"]
+		}
+	}
+
+	return executeOrderList(collection, filter, input, systemContext)
+}
+
+func OrderDelete(orderID primitive.ObjectID, systemContext *model.SystemContext) error {
+	collection := systemContext.MongoDB.Collection("order")
+
+	// Check if order exists and belongs to user's company
+	filter := bson.M{
+		"_id":       orderID,
+		"company":   systemContext.User.Company,
+		"isDeleted": false,
+	}
+
+	var doc database.Order
+	err := collection.FindOne(context.Background(), filter).Decode(&doc)
+	if err != nil {
+		return utils.SystemError(enum.ErrorCodeNotFound, "Order not found or access denied", nil)
+	}
+
+	// Soft delete the order
+	update := bson.M{
+		"$set": bson.M{
+			"isDeleted": true,
+			"updatedAt": time.Now(),
+			"updatedBy": systemContext.User.ID,
+		},
+	}
+
+	_, err = collection.UpdateOne(context.Background(), filter, update)
+	if err != nil {
+		return utils.SystemError(enum.ErrorCodeInternal, "Failed to delete order", nil)
+	}
+
+	return nil
+}
+
+// orderStatusTransitions lists the statuses each order status may move to.
+// Delivered and cancelled are terminal.
+var orderStatusTransitions = map[enum.OrderStatus][]enum.OrderStatus{
+	enum.OrderStatusDraft:     {enum.OrderStatusPending, enum.OrderStatusSent, enum.OrderStatusCancelled},
+	enum.OrderStatusPending:   {enum.OrderStatusDraft, enum.OrderStatusSent, enum.OrderStatusCancelled},
+	enum.OrderStatusSent:      {enum.OrderStatusConfirmed, enum.OrderStatusRejected, enum.OrderStatusCancelled},
+	enum.OrderStatusConfirmed: {enum.OrderStatusPartial, enum.OrderStatusDelivered, enum.OrderStatusCancelled},
+	enum.OrderStatusPartial:   {enum.OrderStatusDelivered},
+	enum.OrderStatusRejected:  {enum.OrderStatusDraft, enum.OrderStatusCancelled},
+	enum.OrderStatusDelivered: {},
+	enum.OrderStatusCancelled: {},
+}
+
+// OrderStatusUpdate is the manual status change. Partial and delivered follow from goods receipts only,
+// so they cannot be set here.
+func OrderStatusUpdate(orderID primitive.ObjectID, input *model.OrderStatusUpdateRequest, systemContext *model.SystemContext) (*database.Order, error) {
+	if input.Status == enum.OrderStatusPartial || input.Status == enum.OrderStatusDelivered {
+		return nil, utils.SystemError(
+			enum.ErrorCodeValidation,
+			"Order status is set to partial or delivered by recording goods receipts",
+			map[string]interface{}{"status": input.Status},
+		)
+	}
+
+	order, err := OrderGetByID(orderID, systemContext)
+	if err != nil {
+		return nil, err
+	}
+
+	return orderTransitionStatus(order, input.Status, input.Remark, systemContext)
+}
+
+// orderTransitionStatus moves an order to a new status and appends the change to its action log.
+// The update only applies if the stored status is unchanged, so concurrent transitions cannot both succeed.
+func orderTransitionStatus(order *database.Order, status enum.OrderStatus, remark string, systemContext *model.SystemContext) (*database.Order, error) {
+	if err := validateOrderStatusTransition(order.Status, status); err != nil {
+		return nil, err
+	}
+
+	description := fmt.Sprintf("Status changed from %s to %s", order.Status, status)
+	if strings.TrimSpace(remark) != "" {
+		description += ": " + strings.TrimSpace(remark)
+	}
+
+	collection := systemContext.MongoDB.Collection("order")
+
+	filter := bson.M{
+		"_id":       order.ID,
+		"company":   systemContext.User.Company,
+		"status":    order.Status,
+		"isDeleted": false,
+	}
+
+	update := bson.M{
+		"$set": bson.M{
+			"status":    status,
+			"updatedAt": time.Now(),
+			"updatedBy": systemContext.User.ID,
+		},
+		"$push": bson.M{
+			"actionLogs": newSystemActionLog(description, systemContext),
+		},
+	}
+
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+
+	var doc database.Order
+	err := collection.FindOneAndUpdate(context.Background(), filter, update, opts).Decode(&doc)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, utils.SystemError(
+				enum.ErrorCodeValidation,
+				"Order status was changed by another request, please reload",
+				map[string]interface{}{"currentStatus": order.Status},
+			)
+		}
+		systemContext.Logger.Error("service.orderTransitionStatus", zap.Error(err))
+		return nil, utils.SystemError(enum.ErrorCodeInternal, "Failed to update order status", nil)
+	}
+
+	return &doc, nil
+}
+
+func validateOrderStatusTransition(from enum.OrderStatus, to enum.OrderStatus) error {
+	if _, exists := orderStatusTransitions[to]; !exists {
+		return utils.SystemError(
+			enum.ErrorCodeValidation,
+			"Invalid order status",
+			map[string]interface{}{"status": to},
+		)
+	}
+
+	allowed := orderStatusTransitions[from]
+	for _, status := range allowed {
+		if status == to {
+			return nil
+		}
+	}
+
+	if allowed == nil {
+		allowed = []enum.OrderStatus{}
+	}
+
+	return utils.SystemError(
+		enum.ErrorCodeValidation,
+		"Order status transition not allowed",
+		map[string]interface{}{
+			"from":    from,
+			"to":      to,
+			"allowed": allowed,
+		},
+	)
+}
+
+// OrderGeneratePDF renders an order through the company's purchase_order document template
+func OrderGeneratePDF(orderID primitive.ObjectID, systemContext *model.SystemContext) ([]byte, string, error) {
+	order, err := OrderGetByID(orderID, systemContext)
+	if err != nil {
+		return nil, "", err
+	}
+
+	company, err := CompanyTenantGet(systemContext)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return DocumentTemplateGenerate("purchase_order", orderDocumentData(order, company, systemContext), systemContext.User.Company, systemContext)
+}
+
+// orderDocumentData maps an order into the purchase_order template payload.
+// InternalNotes are deliberately left out as they must never reach the supplier.
+func orderDocumentData(order *database.Order, company *database.Company, systemContext *model.SystemContext) bson.M {
+	items := make([]interface{}, len(order.Items))
+	for i, item := range order.Items {
+		items[i] = map[string]interface{}{
+			"no":          i + 1,
+			"name":        html.EscapeString(item.Name),
+			"description": documentMultiline(item.Description),
+			"brand":       html.EscapeString(item.Brand),
+			"unit":        html.EscapeString(item.Unit),
+			"quantity":    documentQuantity(item.Quantity),
+			"unitPrice":   documentMoney(item.UnitPrice),
+			"totalPrice":  documentMoney(item.TotalPrice),
+			"remark":      documentMultiline(item.Remark),
+		}
+	}
+
+	// Fall back to the company's default terms when the order has none
+	termConditions := order.TermConditions
+	if len(termConditions) == 0 {
+		termConditions = company.TermCondition
+	}
+
+	orderData := bson.M{
+		"poNumber":         html.EscapeString(order.PONumber),
+		"orderDate":        documentDate(order.OrderDate),
+		"expectedDelivery": documentDate(order.ExpectedDelivery),
+		"status":           string(order.Status),
+		"priority":         string(order.Priority),
+		"supplierName":     html.EscapeString(order.Supplier.Name),
+		"supplierContact":  html.EscapeString(order.Supplier.Contact),
+		"supplierEmail":    html.EscapeString(order.Supplier.Email),
+		"supplierAddress":  documentAddress(order.Supplier.Address),
+		"supplierLogo":     documentImageDataURI(order.Supplier.Logo, systemContext),
+		"deliveryAddress":  documentAddress(order.DeliveryAddress),
+		"deliveryContact":  html.EscapeString(order.DeliveryContact),
+		"deliveryPhone":    html.EscapeString(order.DeliveryPhone),
+		"deliveryRemark":   documentMultiline(order.DeliveryRemark),
+		"items":            items,
+		"subTotal":         documentMoney(order.SubTotal),
+		"taxRate":          documentQuantity(order.TaxRate),
+		"taxAmount":        documentMoney(order.TaxAmount),
+		"totalCharge":      documentMoney(order.TotalCharge),
+		"termConditions":   documentStringList(termConditions),
+		"remark":           documentMultiline(order.Remark),
+	}
+
+	data := documentCompanyData(company, systemContext)
+	for key, value := range orderData {
+		data[key] = value
+	}
+
+	return data
+}
+
+func orderInitValidation(input *model.OrderInitRequest, systemContext *model.SystemContext) (*database.Project, error) {
+	projectCollection := systemContext.MongoDB.Collection("project")
+
+	// Check if project exists and belongs to user's company
+	var project database.Project
+	err := projectCollection.FindOne(context.Background(), bson.M{
+		"_id":       input.ProjectID,
+		"company":   systemContext.User.Company,
+		"isDeleted": false,
+	}).Decode(&project)
+	if err != nil {
+		return nil, utils.SystemError(enum.ErrorCodeNotFound, "Project not found", nil)
+	}
+
+	if len(project.AreaMaterials) == 0 {
+		return nil, utils.SystemError(enum.ErrorCodeValidation, "Project has no materials to order", nil)
+	}
+
+	return &project, nil
+}
+
+// OrderInitFromProject splits a project's materials into one draft order per supplier, one line per material.
+// Items without a supplier are returned as unassigned until resolved through input.Assignments; orders
+// are only created once every item has a supplier, and only once per project.
+func OrderInitFromProject(input *model.OrderInitRequest, systemContext *model.SystemContext) (*model.OrderInitResponse, error) {
+	// Validate input
+	project, err := orderInitValidation(input, systemContext)
+	if err != nil {
+		return nil, err
+	}
+
+	// Walk area materials and aggregate quantities per material
+	collector := &orderInitCollector{
+		lines:         make(map[string]*orderInitLine),
+		materialCache: make(map[primitive.ObjectID]*database.Material),
+		systemContext: systemContext,
+	}
+	for _, areaMaterial := range project.AreaMaterials {
+		collector.area = strings.TrimSpace(areaMaterial.Area.Name)
+		for _, materialDetail := range areaMaterial.Materials {
+			if err := collector.collect(materialDetail, 1); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	// Resolve suppliers, applying user assignments to unassigned lines
+	supplierCollection := systemContext.MongoDB.Collection("supplier")
+	supplierCache := make(map[primitive.ObjectID]*database.Supplier)
+	getSupplier := func(supplierID primitive.ObjectID) *database.Supplier {
+		if supplier, exists := supplierCache[supplierID]; exists {
+			return supplier
+		}
+
+		var supplier database.Supplier
+		err := supplierCollection.FindOne(context.Background(), bson.M{
+			"_id":       supplierID,
+			"company":   systemContext.User.Company,
+			"isDeleted": false,
+		}).Decode(&supplier)
+		if err != nil {
+			supplierCache[supplierID] = nil
+			return nil
+		}
+
+		supplierCache[supplierID] = &supplier
+		return &supplier
+	}
+
+	for key, supplierID := range input.Assignments {
+		line, exists := collector.lines[key]
+		if !exists {
+			return nil, utils.SystemError(
+				enum.ErrorCodeValidation,
+				"Assigned item not found in project",
+				map[string]interface{}{"key": key},
+			)
+		}
+
+		if getSupplier(supplierID) == nil {
+			return nil, utils.SystemError(
+				enum.ErrorCodeValidation,
+				"Supplier not found or does not belong to your company",
+				map[string]interface{}{"key": key, "supplierId": supplierID.Hex()},
+			)
+		}
+
+		assigned := supplierID
+		line.supplier = &assigned
+	}
+
+	// Group lines by supplier, keeping first-seen order for stable output
+	var supplierOrder []primitive.ObjectID
+	itemsBySupplier := make(map[primitive.ObjectID][]database.OrderItem)
+	var unassigned []model.OrderUnassignedItem
+
+	for _, key := range collector.keys {
+		line := collector.lines[key]
+		if line.supplier == nil || getSupplier(*line.supplier) == nil {
+			unassigned = append(unassigned, model.OrderUnassignedItem{Key: key, Item: line.item})
+			continue
+		}
+
+		if _, exists := itemsBySupplier[*line.supplier]; !exists {
+			supplierOrder = append(supplierOrder, *line.supplier)
+		}
+		itemsBySupplier[*line.supplier] = append(itemsBySupplier[*line.supplier], line.item)
+	}
+
+	// Every item must have a supplier before orders are created, otherwise it would never be ordered
+	if !input.Preview && len(unassigned) > 0 {
+		return nil, utils.SystemError(
+			enum.ErrorCodeValidation,
+			"Assign a supplier to every item before creating the orders",
+			map[string]interface{}{"unassigned": unassigned},
+		)
+	}
+
+	// Use the project folder address as the delivery address when available
+	var deliveryAddress database.SystemAddress
+	if !project.Folder.IsZero() {
+		if folder, err := FolderGetByID(project.Folder, systemContext); err == nil {
+			deliveryAddress = folder.Address
+		}
+	}
+
+	response := &model.OrderInitResponse{
+		Orders:     []database.Order{},
+		Unassigned: []model.OrderUnassignedItem{},
+		Warnings:   []string{},
+	}
+	response.Summary.BySupplier = make(map[string]model.OrderSupplierSummary)
+	if len(collector.warnings) > 0 {
+		response.Warnings = collector.warnings
+	}
+
+	collection := systemContext.MongoDB.Collection("order")
+	rounding := companyMoneyRounding(systemContext)
+
+	// Claim the project so a repeated or concurrent request cannot order the same materials twice
+	var created []primitive.ObjectID
+	if !input.Preview {
+		if err := orderInitClaimProject(project, systemContext); err != nil {
+			return nil, err
+		}
+	}
+	fail := func(err error) (*model.OrderInitResponse, error) {
+		orderInitReleaseProject(project, created, systemContext)
+		return nil, err
+	}
+
+	for _, supplierID := range supplierOrder {
+		supplier := getSupplier(supplierID)
+		items, subTotal, taxAmount, totalCharge := calculateOrderTotals(itemsBySupplier[supplierID], 0, rounding)
+
+		order := database.Order{
+			Project:          project.ID,
+			Company:          systemContext.User.Company,
+			Supplier:         orderSupplierFromSupplier(supplier),
+			OrderDate:        time.Now(),
+			ExpectedDelivery: time.Now(),
+			DeliveryAddress:  deliveryAddress,
+			Items:            items,
+			SubTotal:         subTotal,
+			TaxRate:          0,
+			TaxAmount:        taxAmount,
+			TotalCharge:      totalCharge,
+			Status:           enum.OrderStatusDraft,
+			Priority:         enum.OrderPriorityMedium,
+			ActionLogs:       []database.SystemActionLog{newSystemActionLog("Order initialised from project", systemContext)},
+			CreatedAt:        time.Now(),
+			CreatedBy:        *systemContext.User.ID,
+			UpdatedAt:        time.Now(),
+			UpdatedBy:        systemContext.User.ID,
+			IsDeleted:        false,
+		}
+
+		if !input.Preview {
+			poNumber, err := DocumentNumberGenerate(enum.DocumentNumberTypePurchaseOrder, systemContext)
+			if err != nil {
+				return fail(err)
+			}
+			order.PONumber = poNumber
+
+			result, err := collection.InsertOne(context.Background(), order)
+			if err != nil {
+				systemContext.Logger.Error("service.OrderInitFromProject", zap.Error(err))
+				return fail(utils.SystemError(enum.ErrorCodeInternal, "Failed to create order", nil))
+			}
+
+			orderID := result.InsertedID.(primitive.ObjectID)
+			order.ID = &orderID
+			created = append(created, orderID)
+		}
+
+		response.Orders = append(response.Orders, order)
+		response.Summary.TotalValue += order.TotalCharge
+		response.Summary.BySupplier[supplierID.Hex()] = model.OrderSupplierSummary{
+			SupplierName: supplier.Name,
+			ItemCount:    len(order.Items),
+			TotalValue:   order.TotalCharge,
+		}
+	}
+
+	if len(unassigned) > 0 {
+		unassignedValue := newMoneyTotal(rounding)
+		for _, unassignedItem := range unassigned {
+			unassignedValue.addLine(unassignedItem.Item.UnitPrice, unassignedItem.Item.Quantity)
+		}
+
+		response.Unassigned = unassigned
+		response.Summary.BySupplier["unassigned"] = model.OrderSupplierSummary{
+			SupplierName: "Unassigned",
+			ItemCount:    len(unassigned),
+			TotalValue:   unassignedValue.value(),
+		}
+	}
+
+	response.Summary.TotalOrders = len(response.Orders)
+	response.Summary.SupplierCount = len(supplierOrder)
+
+	return response, nil
+}
+
+// Helper functions
+
+// orderInitClaimProject marks a project's materials as ordered. Initialising again is only allowed once
+// every order from the earlier run has been cancelled, rejected or deleted.
+func orderInitClaimProject(project *database.Project, systemContext *model.SystemContext) error {
+	if project.OrdersInitialisedAt != nil {
+		cursor, err := systemContext.MongoDB.Collection("order").Find(context.Background(), bson.M{
+			"project":   project.ID,
+			"company":   systemContext.User.Company,
+			"status":    bson.M{"$nin": []enum.OrderStatus{enum.OrderStatusCancelled, enum.OrderStatusRejected}},
+			"isDeleted": false,
+		}, options.Find().SetProjection(bson.M{"poNumber": 1}))
+		if err != nil {
+			systemContext.Logger.Error("service.orderInitClaimProject", zap.Error(err))
+			return utils.SystemError(enum.ErrorCodeInternal, "Failed to check existing orders", nil)
+		}
+
+		var orders []database.Order
+		if err := cursor.All(context.Background(), &orders); err != nil {
+			systemContext.Logger.Error("service.orderInitClaimProject", zap.Error(err))
+			return utils.SystemError(enum.ErrorCodeInternal, "Failed to check existing orders", nil)
+		}
+
+		if len(orders) > 0 {
+			poNumbers := make([]string, len(orders))
+			for i, order := range orders {
+				poNumbers[i] = order.PONumber
+			}
+			return utils.SystemError(
+				enum.ErrorCodeValidation,
+				"Orders have already been created for this project",
+				map[string]interface{}{
+					"initialisedAt": project.OrdersInitialisedAt,
+					"poNumbers":     poNumbers,
+				},
+			)
+		}
+	}
+
+	result, err := systemContext.MongoDB.Collection("project").UpdateOne(context.Background(), bson.M{
+		"_id":                 project.ID,
+		"company":             systemContext.User.Company,
+		"ordersInitialisedAt": project.OrdersInitialisedAt,
+		"isDeleted":           false,
+	}, bson.M{
+		"$set": bson.M{"ordersInitialisedAt": time.Now()},
+	})
+	if err != nil {
+		systemContext.Logger.Error("service.orderInitClaimProject", zap.Error(err))
+		return utils.SystemError(enum.ErrorCodeInternal, "Failed to update project", nil)
+	}
+	if result.MatchedCount == 0 {
+		return utils.SystemError(enum.ErrorCodeValidation, "Orders for this project are being created by another request, please reload", nil)
+	}
+
+	return nil
+}
+
+// orderInitReleaseProject undoes a failed initialisation: the orders created so far are removed and the
+// project can be initialised again
+func orderInitReleaseProject(project *database.Project, created []primitive.ObjectID, systemContext *model.SystemContext) {
+	if len(created) > 0 {
+		if _, err := systemContext.MongoDB.Collection("order").DeleteMany(context.Background(), bson.M{
+			"_id":     bson.M{"$in": created},
+			"company": systemContext.User.Company,
+		}); err != nil {
+			systemContext.Logger.Error("service.orderInitReleaseProject", zap.Error(err))
+		}
+	}
+
+	update := bson.M{"$unset": bson.M{"ordersInitialisedAt": ""}}
+	if project.OrdersInitialisedAt != nil {
+		update = bson.M{"$set": bson.M{"ordersInitialisedAt": project.OrdersInitialisedAt}}
+	}
+	if _, err := systemContext.MongoDB.Collection("project").UpdateOne(context.Background(), bson.M{"_id": project.ID}, update); err != nil {
+		systemContext.Logger.Error("service.orderInitReleaseProject", zap.Error(err))
+	}
+}
+
+func executeOrderList(collection *mongo.Collection, filter bson.M, input model.OrderListRequest, systemContext *model.SystemContext) (*model.OrderListResponse, error) {
+	// Get total count
+	total, err := collection.CountDocuments(context.Background(), filter)
+	if err != nil {
+		systemContext.Logger.Error("service.OrderList", zap.Error(err))
+		return nil, utils.SystemError(enum.ErrorCodeInternal, "Failed to count orders", nil)
+	}
+
+	// Set default pagination values
+	page := input.Page
+	if page <= 0 {
+		page = 1
+	}
+	limit := input.Limit
+	if limit <= 0 {
+		limit = 10
+	}
+	if limit > 100 {
+		limit = 100 // Maximum limit
+	}
+
+	// Calculate pagination
+	skip := (page - 1) * limit
+	totalPages := int(math.Ceil(float64(total) / float64(limit)))
+
+	// Build sort options - use bson.D to preserve order for multiple sort fields
+	var sortOptions bson.D
+	if len(input.Sort) > 0 {
+		// Convert bson.M to bson.D to preserve field order
+		for key, value := range input.Sort {
+			sortOptions = append(sortOptions, bson.E{Key: key, Value: value})
+		}
+	} else {
+		// Default sort by orderDate descending (latest first)
+		sortOptions = bson.D{{Key: "orderDate", Value: -1}}
+	}
+
+	// Create find options
+	findOptions := options.Find().
+		SetSkip(int64(skip)).
+		SetLimit(int64(limit)).
+		SetSort(sortOptions)
+
+	// Execute query
+	cursor, err := collection.Find(context.Background(), filter, findOptions)
+	if err != nil {
+		systemContext.Logger.Error("service.OrderList", zap.Error(err))
+		return nil, utils.SystemError(enum.ErrorCodeInternal, "Failed to retrieve orders", nil)
+	}
+	defer cursor.Close(context.Background())
+
+	// Decode results
+	var orders []bson.M
+	if err = cursor.All(context.Background(), &orders); err != nil {
+		systemContext.Logger.Error("service.OrderList", zap.Error(err))
+		return nil, utils.SystemError(enum.ErrorCodeInternal, "Failed to decode orders", nil)
+	}
+	moneyDocuments(orders)
+
+	response := &model.OrderListResponse{
+		Data:       orders,
+		Page:       page,
+		Limit:      limit,
+		Total:      total,
+		TotalPages: totalPages,
+	}
+
+	return response, nil
+}
+
+func validateOrderSupplier(supplier *database.OrderSupplier, systemContext *model.SystemContext) error {
+	if strings.TrimSpace(supplier.Name) == "" {
+		return utils.SystemError(enum.ErrorCodeValidation, "Supplier name is required", nil)
+	}
+
+	// System supplier is optional, but must belong to user's company when provided
+	if supplier.ID != nil {
+		supplierCollection := systemContext.MongoDB.Collection("supplier")
+		count, err := supplierCollection.CountDocuments(context.Background(), bson.M{
+			"_id":       supplier.ID,
+			"company":   systemContext.User.Company,
+			"isDeleted": false,
+		})
+		if err != nil {
+			return utils.SystemError(enum.ErrorCodeInternal, "Failed to validate supplier", nil)
+		}
+
+		if count == 0 {
+			return utils.SystemError(enum.ErrorCodeValidation, "Supplier not found or does not belong to your company", nil)
+		}
+	}
+
+	// The logo is embedded into purchase order documents, so it must be one of the company's uploads
+	if err := ValidateMediaPath(supplier.Logo, systemContext); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func validateOrderItems(items []database.OrderItem, systemContext *model.SystemContext) error {
+	if len(items) == 0 {
+		return utils.SystemError(enum.ErrorCodeValidation, "Order must have at least one item", nil)
+	}
+
+	materialCollection := systemContext.MongoDB.Collection("material")
+
+	for i, item := range items {
+		// Received quantities are only recorded through goods receipts
+		items[i].ReceivedQuantity = 0
+		items[i].RejectedQuantity = 0
+
+		if strings.TrimSpace(item.Name) == "" {
+			return utils.SystemError(
+				enum.ErrorCodeValidation,
+				"Item name is required",
+				map[string]interface{}{"itemIndex": i},
+			)
+		}
+		if item.Quantity <= 0 {
+			return utils.SystemError(
+				enum.ErrorCodeValidation,
+				"Item quantity must be greater than 0",
+				map[string]interface{}{"itemIndex": i, "name": item.Name},
+			)
+		}
+		if item.UnitPrice < 0 {
+			return utils.SystemError(
+				enum.ErrorCodeValidation,
+				"Item unit price cannot be negative",
+				map[string]interface{}{"itemIndex": i, "name": item.Name},
+			)
+		}
+
+		// Material reference is optional, but must belong to user's company when provided
+		if item.Material != nil {
+			count, err := materialCollection.CountDocuments(context.Background(), bson.M{
+				"_id":       item.Material,
+				"company":   systemContext.User.Company,
+				"isDeleted": false,
+			})
+			if err != nil {
+				return utils.SystemError(enum.ErrorCodeInternal, "Failed to validate material", nil)
+			}
+
+			if count == 0 {
+				return utils.SystemError(
+					enum.ErrorCodeValidation,
+					"Material not found or does not belong to your company",
+					map[string]interface{}{"itemIndex": i, "materialId": item.Material.Hex()},
+				)
+			}
+		}
+	}
+
+	return nil
+}
+
+func validateOrderTaxAndPriority(taxRate float64, priority enum.OrderPriority) error {
+	if taxRate < 0 || taxRate > 100 {
+		return utils.SystemError(
+			enum.ErrorCodeValidation,
+			"Tax rate must be between 0 and 100",
+			map[string]interface{}{"taxRate": taxRate},
+		)
+	}
+
+	switch priority {
+	case "", enum.OrderPriorityLow, enum.OrderPriorityMedium, enum.OrderPriorityHigh, enum.OrderPriorityUrgent:
+	default:
+		return utils.SystemError(
+			enum.ErrorCodeValidation,
+			"Invalid order priority",
+			map[string]interface{}{"priority": priority},
+		)
+	}
+
+	return nil
+}
+
+// calculateOrderTotals recomputes every line total server-side and returns the items with the order totals
+func calculateOrderTotals(items []database.OrderItem, taxRate float64, rounding enum.MoneyRounding) ([]database.OrderItem, database.Money, database.Money, database.Money) {
+	total := newMoneyTotal(rounding)
+
+	for i := range items {
+		items[i].TotalPrice = total.addLine(items[i].UnitPrice, items[i].Quantity)
+	}
+
+	subTotal := total.value()
+	taxAmount := subTotal.Percent(taxRate)
+
+	return items, subTotal, taxAmount, subTotal + taxAmount
+}
+
+// roundPercent rounds a percentage to 2 decimal places
+func roundPercent(value float64) float64 {
+	return math.Round(value*100) / 100
+}
+
+// newSystemActionLog builds an action log entry stamped with the current user
+func newSystemActionLog(description string, systemContext *model.SystemContext) database.SystemActionLog {
+	return database.SystemActionLog{
+		Description: description,
+		Time:        time.Now(),
+		ByName:      systemContext.User.Username,
+		ById:        systemContext.User.ID,
+	}
+}
+
+// orderInitLine is one aggregated order item collected from a project
+type orderInitLine struct {
+	supplier *primitive.ObjectID
+	item     database.OrderItem
+}
+
+// orderInitCollector walks project area materials, expanding templates into their component materials
+// and adding up the quantity of each material across all areas. The current area is only used in warnings.
+type orderInitCollector struct {
+	area          string
+	keys          []string
+	lines         map[string]*orderInitLine
+	warnings      []string
+	materialCache map[primitive.ObjectID]*database.Material
+	systemContext *model.SystemContext
+}
+
+// collect adds a material detail to the collector. Template children quantities are
+// per unit of their parent, so they are scaled by the parent quantity.
+func (c *orderInitCollector) collect(detail database.SystemAreaMaterialDetail, multiplier float64) error {
+	quantity := detail.Quantity * multiplier
+	if quantity <= 0 {
+		return nil
+	}
+
+	// Expand template children snapshotted on the project
+	if len(detail.Template) > 0 {
+		for _, child := range detail.Template {
+			if err := c.collect(child, quantity); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	var material *database.Material
+	if detail.Material != nil {
+		var err error
+		material, err = c.getMaterial(*detail.Material)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Expand catalogue template materials when the project did not snapshot the children
+	if material != nil && material.Type == enum.MaterialTypeTemplate && len(material.Template) > 0 {
+		for _, templateItem := range material.Template {
+			component, err := c.getMaterial(templateItem.Material)
+			if err != nil {
+				return err
+			}
+			if component == nil {
+				c.warnings = append(c.warnings, fmt.Sprintf("%s: a component of %s is no longer in the catalogue and was not ordered", c.area, material.Name))
+				continue
+			}
+			c.add(component, database.SystemAreaMaterialDetail{Name: component.Name}, quantity*templateItem.DefaultQuantity)
+		}
+		return nil
+	}
+
+	c.add(material, detail, quantity)
+	return nil
+}
+
+func (c *orderInitCollector) add(material *database.Material, detail database.SystemAreaMaterialDetail, quantity float64) {
+	var key string
+	var line orderInitLine
+
+	if material != nil {
+		key = material.ID.Hex()
+
+		name := material.SupplierDisplayName
+		if strings.TrimSpace(name) == "" {
+			name = material.Name
+		}
+
+		line = orderInitLine{
+			supplier: material.Supplier,
+			item: database.OrderItem{
+				Material:    material.ID,
+				Name:        name,
+				Description: material.Description,
+				Brand:       material.Brand,
+				Unit:        material.Unit,
+				UnitPrice:   material.CostPerUnit,
+			},
+		}
+	} else {
+		// Free-text line without a catalogue material, grouped by name and unit
+		key = "item:" + strings.ToLower(strings.TrimSpace(detail.Name)) + "|" + strings.ToLower(strings.TrimSpace(detail.Unit))
+		line = orderInitLine{
+			item: database.OrderItem{
+				Name:        detail.Name,
+				Description: detail.Description,
+				Brand:       detail.Brand,
+				Unit:        detail.Unit,
+			},
+		}
+	}
+
+	if existing, exists := c.lines[key]; exists {
+		existing.item.Quantity += quantity
+		return
+	}
+
+	line.item.Quantity = quantity
+	c.lines[key] = &line
+	c.keys = append(c.keys, key)
+}
+
+// getMaterial loads a company material, returning nil when it no longer exists
+func (c *orderInitCollector) getMaterial(materialID primitive.ObjectID) (*database.Material, error) {
+	if material, exists := c.materialCache[materialID]; exists {
+		return material, nil
+	}
+
+	var material database.Material
+	err := c.systemContext.MongoDB.Collection("material").FindOne(context.Background(), bson.M{
+		"_id":       materialID,
+		"company":   c.systemContext.User.Company,
+		"isDeleted": false,
+	}).Decode(&material)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.materialCache[materialID] = nil
+			return nil, nil
+		}
+		return nil, utils.SystemError(enum.ErrorCodeInternal, "Failed to retrieve material", nil)
+	}
+
+	c.materialCache[materialID] = &material
+	return &material, nil
+}
+
+// orderSupplierFromSupplier embeds a system supplier into an order
+func orderSupplierFromSupplier(supplier *database.Supplier) database.OrderSupplier {
+	orderSupplier := database.OrderSupplier{
+		ID:      supplier.ID,
+		Name:    supplier.Name,
+		Contact: supplier.Contact,
+		Email:   supplier.Email,
+		Logo:    supplier.Logo,
+	}
+
+	if len(supplier.OfficeAddress) > 0 {
+		orderSupplier.Address = supplier.OfficeAddress[0]
+	}
+
+	return orderSupplier
+}
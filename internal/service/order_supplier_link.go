@@ -0,0 +1,360 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.uber.org/zap"
+	"renotech.com.my/internal/database"
+	"renotech.com.my/internal/enum"
+	"renotech.com.my/internal/model"
+	"renotech.com.my/internal/utils"
+)
+
+// Tenant services
+func OrderShareCreate(orderID primitive.ObjectID, input *model.DocumentShareCreateRequest, systemContext *model.SystemContext) (*model.DocumentShareResponse, error) {
+	order, err := OrderGetByID(orderID, systemContext)
+	if err != nil {
+		return nil, err
+	}
+
+	if order.Status == enum.OrderStatusCancelled {
+		return nil, utils.SystemError(enum.ErrorCodeValidation, "Cannot share a cancelled order", nil)
+	}
+
+	share, err := documentShareCreate(enum.DocumentShareTypePurchaseOrder, orderID, input, "/supplier/order", systemContext)
+	if err != nil {
+		return nil, err
+	}
+
+	appendOrderActionLog(orderID, fmt.Sprintf("Supplier link created, expires %s", documentDate(share.ExpiresAt)), systemContext)
+
+	return share, nil
+}
+
+func OrderShareRevoke(orderID primitive.ObjectID, systemContext *model.SystemContext) error {
+	if _, err := OrderGetByID(orderID, systemContext); err != nil {
+		return err
+	}
+
+	revoked, err := documentShareRevokeAll(enum.DocumentShareTypePurchaseOrder, orderID, systemContext)
+	if err != nil {
+		return err
+	}
+
+	if revoked > 0 {
+		appendOrderActionLog(orderID, "Supplier link revoked", systemContext)
+	}
+
+	return nil
+}
+
+func orderDeliveryProposalReviewValidation(input *model.OrderDeliveryProposalReviewRequest, order *database.Order) error {
+	if input.Status != enum.DeliveryProposalStatusAccepted && input.Status != enum.DeliveryProposalStatusRejected {
+		return utils.SystemError(
+			enum.ErrorCodeValidation,
+			"Invalid delivery proposal status",
+			map[string]interface{}{"status": input.Status},
+		)
+	}
+
+	response := order.SupplierResponse
+	if response == nil || response.Action != enum.SupplierResponseActionProposeDelivery || response.ProposedDelivery == nil {
+		return utils.SystemError(enum.ErrorCodeValidation, "Supplier has not proposed a delivery date", nil)
+	}
+
+	if response.ProposalStatus != enum.DeliveryProposalStatusPending {
+		return utils.SystemError(
+			enum.ErrorCodeValidation,
+			"Proposed delivery date has already been reviewed",
+			map[string]interface{}{"proposalStatus": response.ProposalStatus},
+		)
+	}
+
+	if order.Status == enum.OrderStatusDelivered || order.Status == enum.OrderStatusCancelled {
+		return utils.SystemError(
+			enum.ErrorCodeValidation,
+			"Cannot change the delivery date of a delivered or cancelled order",
+			map[string]interface{}{"status": order.Status},
+		)
+	}
+
+	return nil
+}
+
+// OrderDeliveryProposalReview accepts or rejects the delivery date proposed by the supplier. Accepting
+// moves the order's expected delivery to the proposed date; the order status is left unchanged.
+func OrderDeliveryProposalReview(orderID primitive.ObjectID, input *model.OrderDeliveryProposalReviewRequest, systemContext *model.SystemContext) (*database.Order, error) {
+	order, err := OrderGetByID(orderID, systemContext)
+	if err != nil {
+		return nil, err
+	}
+
+	// Validate input
+	if err := orderDeliveryProposalReviewValidation(input, order); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	proposed := *order.SupplierResponse.ProposedDelivery
+	remark := strings.TrimSpace(input.Remark)
+
+	set := bson.M{
+		"supplierResponse.proposalStatus":     input.Status,
+		"supplierResponse.proposalReviewedBy": systemContext.User.ID,
+		"supplierResponse.proposalReviewedAt": now,
+		"supplierResponse.proposalRemark":     remark,
+		"updatedAt":                           now,
+		"updatedBy":                           systemContext.User.ID,
+	}
+
+	description := fmt.Sprintf("Supplier proposed expected delivery %s rejected", documentDate(proposed))
+	if input.Status == enum.DeliveryProposalStatusAccepted {
+		set["expectedDelivery"] = proposed
+		description = fmt.Sprintf("Supplier proposed expected delivery %s accepted (was %s)", documentDate(proposed), documentDate(order.ExpectedDelivery))
+	}
+	if remark != "" {
+		description += ": " + remark
+	}
+
+	// Only the proposal that was reviewed, and only once
+	filter := bson.M{
+		"_id":                             order.ID,
+		"company":                         systemContext.User.Company,
+		"status":                          bson.M{"$nin": []enum.OrderStatus{enum.OrderStatusDelivered, enum.OrderStatusCancelled}},
+		"supplierResponse.respondedAt":    order.SupplierResponse.RespondedAt,
+		"supplierResponse.proposalStatus": enum.DeliveryProposalStatusPending,
+		"isDeleted":                       false,
+	}
+
+	update := bson.M{
+		"$set": set,
+		"$push": bson.M{
+			"actionLogs": newSystemActionLog(description, systemContext),
+		},
+	}
+
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+
+	var doc database.Order
+	err = systemContext.MongoDB.Collection("order").FindOneAndUpdate(context.Background(), filter, update, opts).Decode(&doc)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, utils.SystemError(enum.ErrorCodeValidation, "Order was changed by another request, please reload", nil)
+		}
+		systemContext.Logger.Error("service.OrderDeliveryProposalReview", zap.Error(err))
+		return nil, utils.SystemError(enum.ErrorCodeInternal, "Failed to review proposed delivery date", nil)
+	}
+
+	return &doc, nil
+}
+
+// Public services, authorised by share token instead of JWT
+func OrderSupplierGet(token string, systemContext *model.SystemContext) (*model.OrderSupplierView, error) {
+	share, order, err := orderSupplierResolve(token, true, systemContext)
+	if err != nil {
+		return nil, err
+	}
+
+	company, err := CompanyTenantGet(systemContext)
+	if err != nil {
+		return nil, err
+	}
+
+	return orderSupplierView(order, company, share), nil
+}
+
+func OrderSupplierPreview(token string, systemContext *model.SystemContext) (string, error) {
+	_, order, err := orderSupplierResolve(token, false, systemContext)
+	if err != nil {
+		return "", err
+	}
+
+	company, err := CompanyTenantGet(systemContext)
+	if err != nil {
+		return "", err
+	}
+
+	return DocumentTemplatePreview("purchase_order", orderDocumentData(order, company, systemContext), systemContext.User.Company, systemContext)
+}
+
+func orderSupplierRespondValidation(input *model.OrderSupplierRespondRequest, order *database.Order) error {
+	if order.Status != enum.OrderStatusSent {
+		return utils.SystemError(
+			enum.ErrorCodeValidation,
+			"Order is no longer awaiting your response",
+			map[string]interface{}{"status": order.Status},
+		)
+	}
+
+	switch input.Action {
+	case enum.SupplierResponseActionConfirm:
+	case enum.SupplierResponseActionReject:
+		if strings.TrimSpace(input.Reason) == "" {
+			return utils.SystemError(enum.ErrorCodeValidation, "Reason is required when rejecting an order", nil)
+		}
+	case enum.SupplierResponseActionProposeDelivery:
+		if input.ExpectedDelivery == nil {
+			return utils.SystemError(enum.ErrorCodeValidation, "Expected delivery date is required", nil)
+		}
+		if input.ExpectedDelivery.Before(order.OrderDate) {
+			return utils.SystemError(enum.ErrorCodeValidation, "Expected delivery cannot be before order date", nil)
+		}
+	default:
+		return utils.SystemError(
+			enum.ErrorCodeValidation,
+			"Invalid response action",
+			map[string]interface{}{"action": input.Action},
+		)
+	}
+
+	return nil
+}
+
+// OrderSupplierRespond records the supplier's response. Confirming and rejecting move the order
+// through the status machine; a proposed delivery date is recorded for staff to review with
+// OrderDeliveryProposalReview.
+func OrderSupplierRespond(token string, input *model.OrderSupplierRespondRequest, systemContext *model.SystemContext) (*model.OrderSupplierView, error) {
+	share, order, err := orderSupplierResolve(token, false, systemContext)
+	if err != nil {
+		return nil, err
+	}
+
+	// Validate input
+	if err := orderSupplierRespondValidation(input, order); err != nil {
+		return nil, err
+	}
+
+	respondedBy := strings.TrimSpace(input.RespondentName)
+	if respondedBy == "" {
+		respondedBy = order.Supplier.Name
+	}
+	systemContext.User.Username = "Supplier: " + respondedBy
+
+	response := database.OrderSupplierResponse{
+		Action:      input.Action,
+		Reason:      strings.TrimSpace(input.Reason),
+		RespondedBy: respondedBy,
+		RespondedAt: time.Now(),
+	}
+	if input.Action == enum.SupplierResponseActionProposeDelivery {
+		response.ProposedDelivery = input.ExpectedDelivery
+		response.ProposalStatus = enum.DeliveryProposalStatusPending
+	}
+
+	update := bson.M{
+		"$set": bson.M{
+			"supplierResponse": response,
+			"updatedAt":        time.Now(),
+		},
+	}
+	if input.Action == enum.SupplierResponseActionProposeDelivery {
+		update["$push"] = bson.M{
+			"actionLogs": newSystemActionLog(
+				fmt.Sprintf("Supplier proposed expected delivery %s (currently %s)", documentDate(*input.ExpectedDelivery), documentDate(order.ExpectedDelivery)),
+				systemContext,
+			),
+		}
+	}
+
+	collection := systemContext.MongoDB.Collection("order")
+	result, err := collection.UpdateOne(context.Background(), bson.M{
+		"_id":       order.ID,
+		"company":   systemContext.User.Company,
+		"status":    enum.OrderStatusSent,
+		"isDeleted": false,
+	}, update)
+	if err != nil {
+		systemContext.Logger.Error("service.OrderSupplierRespond", zap.Error(err))
+		return nil, utils.SystemError(enum.ErrorCodeInternal, "Failed to record response", nil)
+	}
+	if result.MatchedCount == 0 {
+		return nil, utils.SystemError(enum.ErrorCodeValidation, "Order is no longer awaiting your response", nil)
+	}
+
+	switch input.Action {
+	case enum.SupplierResponseActionConfirm:
+		order, err = orderTransitionStatus(order, enum.OrderStatusConfirmed, "Confirmed by supplier", systemContext)
+	case enum.SupplierResponseActionReject:
+		order, err = orderTransitionStatus(order, enum.OrderStatusRejected, response.Reason, systemContext)
+	default:
+		order, err = OrderGetByID(*order.ID, systemContext)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	company, err := CompanyTenantGet(systemContext)
+	if err != nil {
+		return nil, err
+	}
+
+	return orderSupplierView(order, company, share), nil
+}
+
+// Helper functions
+func orderSupplierResolve(token string, countView bool, systemContext *model.SystemContext) (*database.DocumentShare, *database.Order, error) {
+	share, err := documentShareResolve(token, enum.DocumentShareTypePurchaseOrder, "Supplier", countView, systemContext)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	order, err := OrderGetByID(share.Document, systemContext)
+	if err != nil {
+		return nil, nil, utils.SystemError(enum.ErrorCodeUnauthorized, "Invalid or expired link", nil)
+	}
+
+	if order.Status == enum.OrderStatusCancelled {
+		return nil, nil, utils.SystemError(enum.ErrorCodeValidation, "Order has been cancelled", nil)
+	}
+
+	return share, order, nil
+}
+
+func orderSupplierView(order *database.Order, company *database.Company, share *database.DocumentShare) *model.OrderSupplierView {
+	termConditions := order.TermConditions
+	if len(termConditions) == 0 {
+		termConditions = company.TermCondition
+	}
+
+	return &model.OrderSupplierView{
+		PONumber:         order.PONumber,
+		CompanyName:      company.Name,
+		Supplier:         order.Supplier,
+		OrderDate:        order.OrderDate,
+		ExpectedDelivery: order.ExpectedDelivery,
+		DeliveryAddress:  order.DeliveryAddress,
+		DeliveryContact:  order.DeliveryContact,
+		DeliveryPhone:    order.DeliveryPhone,
+		DeliveryRemark:   order.DeliveryRemark,
+		TermConditions:   termConditions,
+		Items:            order.Items,
+		SubTotal:         order.SubTotal,
+		TaxRate:          order.TaxRate,
+		TaxAmount:        order.TaxAmount,
+		TotalCharge:      order.TotalCharge,
+		Status:           order.Status,
+		Remark:           order.Remark,
+		SupplierResponse: order.SupplierResponse,
+		CanRespond:       order.Status == enum.OrderStatusSent,
+		LinkExpiresAt:    share.ExpiresAt,
+	}
+}
+
+// appendOrderActionLog adds an entry to an order's action log, logging rather than returning failures
+func appendOrderActionLog(orderID primitive.ObjectID, description string, systemContext *model.SystemContext) {
+	_, err := systemContext.MongoDB.Collection("order").UpdateOne(
+		context.Background(),
+		bson.M{"_id": orderID, "company": systemContext.User.Company},
+		bson.M{"$push": bson.M{"actionLogs": newSystemActionLog(description, systemContext)}},
+	)
+	if err != nil {
+		systemContext.Logger.Error("service.appendOrderActionLog", zap.Error(err))
+	}
+}
@@ -0,0 +1,1072 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.uber.org/zap"
+	"renotech.com.my/internal/database"
+	"renotech.com.my/internal/enum"
+	"renotech.com.my/internal/model"
+	"renotech.com.my/internal/utils"
+)
+
+var paymentMethods = map[enum.PaymentMethod]string{
+	enum.PaymentMethodCash:         "Cash",
+	enum.PaymentMethodBankTransfer: "Bank Transfer",
+	enum.PaymentMethodCheque:       "Cheque",
+	enum.PaymentMethodCard:         "Card",
+	enum.PaymentMethodOnline:       "Online Payment",
+}
+
+// paymentTarget is the project and/or quotation a payment is recorded against
+type paymentTarget struct {
+	project   *database.Project
+	quotation *database.Quotation
+}
+
+// Tenant services
+func paymentCreateValidation(input *model.PaymentCreateRequest, systemContext *model.SystemContext) (*paymentTarget, error) {
+	if _, exists := paymentMethods[input.Method]; !exists {
+		return nil, utils.SystemError(
+			enum.ErrorCodeValidation,
+			"Invalid payment method",
+			map[string]interface{}{"method": input.Method},
+		)
+	}
+
+	if input.Amount <= 0 {
+		return nil, utils.SystemError(enum.ErrorCodeValidation, "Payment amount must be greater than zero", nil)
+	}
+
+	if input.PaymentDate.After(time.Now()) {
+		return nil, utils.SystemError(enum.ErrorCodeValidation, "Payment date cannot be in the future", nil)
+	}
+
+	if input.KeepAsCredit && len(input.Allocations) > 0 {
+		return nil, utils.SystemError(enum.ErrorCodeValidation, "Allocations cannot be given when keeping the payment as credit", nil)
+	}
+
+	return resolvePaymentTarget(input.Project, input.Quotation, systemContext)
+}
+
+func PaymentCreate(input *model.PaymentCreateRequest, systemContext *model.SystemContext) (*database.Payment, error) {
+	// Validate input
+	target, err := paymentCreateValidation(input, systemContext)
+	if err != nil {
+		return nil, err
+	}
+
+	amount := input.Amount
+
+	allocations := []database.PaymentAllocation{}
+	if !input.KeepAsCredit {
+		allocations, err = buildPaymentAllocations(target.project, input.Allocations, amount, systemContext)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	receiptNumber, err := DocumentNumberGenerate(enum.DocumentNumberTypeReceipt, systemContext)
+	if err != nil {
+		return nil, err
+	}
+
+	// Hold the allocations on the invoices until the payment is recorded and the invoices synced, so
+	// a concurrent payment cannot settle the same balance
+	if err := invoiceReserveAllocations(allocations, systemContext); err != nil {
+		return nil, err
+	}
+	defer invoiceReleaseAllocations(allocations, systemContext)
+
+	allocated := paymentAllocatedTotal(allocations)
+
+	payment := &database.Payment{
+		Company:           systemContext.User.Company,
+		ReceiptNumber:     receiptNumber,
+		PaymentDate:       input.PaymentDate,
+		Method:            input.Method,
+		Reference:         strings.TrimSpace(input.Reference),
+		Amount:            amount,
+		Allocations:       allocations,
+		AllocatedAmount:   allocated,
+		UnallocatedAmount: amount - allocated,
+		Status:            enum.PaymentStatusReceived,
+		Remark:            input.Remark,
+		ActionLogs:        []database.SystemActionLog{newSystemActionLog("Payment received", systemContext)},
+		CreatedAt:         time.Now(),
+		CreatedBy:         *systemContext.User.ID,
+		UpdatedAt:         time.Now(),
+		UpdatedBy:         systemContext.User.ID,
+		IsDeleted:         false,
+	}
+
+	if target.project != nil {
+		payment.Project = target.project.ID
+	}
+	if target.quotation != nil {
+		payment.Quotation = target.quotation.ID
+		payment.Client = target.quotation.Client
+	}
+
+	collection := systemContext.MongoDB.Collection("payment")
+	result, err := collection.InsertOne(context.Background(), payment)
+	if err != nil {
+		systemContext.Logger.Error("service.PaymentCreate", zap.Error(err))
+		return nil, utils.SystemError(enum.ErrorCodeInternal, "Failed to record payment", nil)
+	}
+
+	paymentID := result.InsertedID.(primitive.ObjectID)
+
+	// The invoices are derived from the payments, so if they cannot be brought up to date the payment is
+	// removed again and the invoices recalculated without it
+	invoiceIDs := paymentAllocationInvoices(allocations)
+	if err := invoiceSyncPayments(invoiceIDs, fmt.Sprintf("Payment %s received", receiptNumber), systemContext); err != nil {
+		if _, deleteErr := collection.DeleteOne(context.Background(), bson.M{"_id": paymentID}); deleteErr != nil {
+			systemContext.Logger.Error("service.PaymentCreate rollback", zap.Error(deleteErr))
+		} else if syncErr := invoiceSyncPayments(invoiceIDs, fmt.Sprintf("Payment %s reverted", receiptNumber), systemContext); syncErr != nil {
+			systemContext.Logger.Error("service.PaymentCreate rollback", zap.Error(syncErr))
+		}
+		return nil, err
+	}
+
+	return PaymentGetByID(paymentID, systemContext)
+}
+
+func PaymentGetByID(paymentID primitive.ObjectID, systemContext *model.SystemContext) (*database.Payment, error) {
+	collection := systemContext.MongoDB.Collection("payment")
+
+	filter := bson.M{
+		"_id":       paymentID,
+		"company":   systemContext.User.Company,
+		"isDeleted": false,
+	}
+
+	var doc database.Payment
+	err := collection.FindOne(context.Background(), filter).Decode(&doc)
+	if err != nil {
+		return nil, utils.SystemError(enum.ErrorCodeNotFound, "Payment not found", nil)
+	}
+
+	return &doc, nil
+}
+
+func PaymentList(input model.PaymentListRequest, systemContext *model.SystemContext) (*model.PaymentListResponse, error) {
+	collection := systemContext.MongoDB.Collection("payment")
+
+	// Build base filter
+	filter := bson.M{"isDeleted": false, "company": systemContext.User.Company}
+
+	// Add field-specific filters
+	if input.Project != nil {
+		filter["project"] = input.Project
+	}
+	if input.Quotation != nil {
+		filter["quotation"] = input.Quotation
+	}
+	if input.Method != "" {
+		filter["method"] = input.Method
+	}
+	if input.Status != "" {
+		filter["status"] = input.Status
+	}
+	if input.HasCredit != nil {
+		if *input.HasCredit {
+			filter["unallocatedAmount"] = bson.M{"$gt": 0}
+		} else {
+			filter["unallocatedAmount"] = bson.M{"$lte": 0}
+		}
+	}
+
+	// Add payment date range filter
+	if input.DateFrom != nil || input.DateTo != nil {
+		dateFilter := bson.M{}
+		if input.DateFrom != nil {
+			dateFilter["$gte"] = input.DateFrom
+		}
+		if input.DateTo != nil {
+			dateFilter["$lte"] = input.DateTo
+		}
+		filter["paymentDate"] = dateFilter
+	}
+
+	// Add global search filter
+	if strings.TrimSpace(input.Search) != "" {
+		searchRegex := primitive.Regex{Pattern: input.Search, Options: "i"}
+		filter["$or"] = []bson.M{
+			{"receiptNumber": searchRegex},
+			{"reference": searchRegex},
+			{"client.Nname": searchRegex},
+			{"allocations.invoiceNumber": searchRegex},
+		}
+	}
+
+	// Get total count
+	total, err := collection.CountDocuments(context.Background(), filter)
+	if err != nil {
+		systemContext.Logger.Error("service.PaymentList", zap.Error(err))
+		return nil, utils.SystemError(enum.ErrorCodeInternal, "Failed to count payments", nil)
+	}
+
+	// Set default pagination values
+	page := input.Page
+	if page <= 0 {
+		page = 1
+	}
+	limit := input.Limit
+	if limit <= 0 {
+		limit = 10
+	}
+	if limit > 100 {
+		limit = 100 // Maximum limit
+	}
+
+	skip := (page - 1) * limit
+	totalPages := int(math.Ceil(float64(total) / float64(limit)))
+
+	var sortOptions bson.D
+	if len(input.Sort) > 0 {
+		for key, value := range input.Sort {
+			sortOptions = append(sortOptions, bson.E{Key: key, Value: value})
+		}
+	} else {
+		// Default sort by payment date descending (latest first)
+		sortOptions = bson.D{{Key: "paymentDate", Value: -1}, {Key: "createdAt", Value: -1}}
+	}
+
+	findOptions := options.Find().
+		SetSkip(int64(skip)).
+		SetLimit(int64(limit)).
+		SetSort(sortOptions)
+
+	cursor, err := collection.Find(context.Background(), filter, findOptions)
+	if err != nil {
+		systemContext.Logger.Error("service.PaymentList", zap.Error(err))
+		return nil, utils.SystemError(enum.ErrorCodeInternal, "Failed to retrieve payments", nil)
+	}
+	defer cursor.Close(context.Background())
+
+	var payments []bson.M
+	if err = cursor.All(context.Background(), &payments); err != nil {
+		systemContext.Logger.Error("service.PaymentList", zap.Error(err))
+		return nil, utils.SystemError(enum.ErrorCodeInternal, "Failed to decode payments", nil)
+	}
+	moneyDocuments(payments)
+
+	return &model.PaymentListResponse{
+		Data:       payments,
+		Page:       page,
+		Limit:      limit,
+		Total:      total,
+		TotalPages: totalPages,
+	}, nil
+}
+
+// PaymentAllocate applies a payment's credit balance to outstanding invoices. A deposit recorded
+// against a quotation is linked to the project once one has been created from that quotation.
+func PaymentAllocate(paymentID primitive.ObjectID, input *model.PaymentAllocateRequest, systemContext *model.SystemContext) (*database.Payment, error) {
+	payment, err := PaymentGetByID(paymentID, systemContext)
+	if err != nil {
+		return nil, err
+	}
+
+	if payment.Status != enum.PaymentStatusReceived {
+		return nil, utils.SystemError(enum.ErrorCodeValidation, "Void payments cannot be allocated", nil)
+	}
+
+	if payment.UnallocatedAmount <= 0 {
+		return nil, utils.SystemError(enum.ErrorCodeValidation, "Payment has no credit balance left to allocate", nil)
+	}
+
+	target, err := resolvePaymentTarget(payment.Project, payment.Quotation, systemContext)
+	if err != nil {
+		return nil, err
+	}
+
+	if target.project == nil {
+		return nil, utils.SystemError(enum.ErrorCodeValidation, "No project has been created from the quotation yet, there are no invoices to allocate to", nil)
+	}
+
+	allocations, err := buildPaymentAllocations(target.project, input.Allocations, payment.UnallocatedAmount, systemContext)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(allocations) == 0 {
+		return nil, utils.SystemError(enum.ErrorCodeValidation, "Project has no outstanding invoices", nil)
+	}
+
+	if err := invoiceReserveAllocations(allocations, systemContext); err != nil {
+		return nil, err
+	}
+	defer invoiceReleaseAllocations(allocations, systemContext)
+
+	allocated := paymentAllocatedTotal(allocations)
+
+	collection := systemContext.MongoDB.Collection("payment")
+
+	// Only apply if the credit has not been used by another request in the meantime
+	filter := bson.M{
+		"_id":               payment.ID,
+		"company":           systemContext.User.Company,
+		"status":            enum.PaymentStatusReceived,
+		"unallocatedAmount": payment.UnallocatedAmount,
+		"isDeleted":         false,
+	}
+
+	update := bson.M{
+		"$set": bson.M{
+			"project":           target.project.ID,
+			"allocatedAmount":   payment.AllocatedAmount + allocated,
+			"unallocatedAmount": payment.UnallocatedAmount - allocated,
+			"updatedAt":         time.Now(),
+			"updatedBy":         systemContext.User.ID,
+		},
+		"$push": bson.M{
+			"allocations": bson.M{"$each": allocations},
+			"actionLogs":  newSystemActionLog(fmt.Sprintf("Credit of %s allocated to %s", documentMoney(allocated), paymentAllocationNumbers(allocations)), systemContext),
+		},
+	}
+
+	result, err := collection.UpdateOne(context.Background(), filter, update)
+	if err != nil {
+		systemContext.Logger.Error("service.PaymentAllocate", zap.Error(err))
+		return nil, utils.SystemError(enum.ErrorCodeInternal, "Failed to allocate payment", nil)
+	}
+
+	if result.MatchedCount == 0 {
+		return nil, utils.SystemError(enum.ErrorCodeValidation, "Payment was changed by another request, please reload", nil)
+	}
+
+	invoiceIDs := paymentAllocationInvoices(allocations)
+	if err := invoiceSyncPayments(invoiceIDs, fmt.Sprintf("Credit from payment %s applied", payment.ReceiptNumber), systemContext); err != nil {
+		// Take the allocations back off the payment so it matches the invoices again
+		revert := bson.M{
+			"$set": bson.M{
+				"project":           payment.Project,
+				"allocatedAmount":   payment.AllocatedAmount,
+				"unallocatedAmount": payment.UnallocatedAmount,
+			},
+			"$pull": bson.M{
+				"allocations": bson.M{"allocatedAt": allocations[0].AllocatedAt, "invoice": bson.M{"$in": invoiceIDs}},
+			},
+		}
+		if _, revertErr := collection.UpdateOne(context.Background(), bson.M{"_id": payment.ID}, revert); revertErr != nil {
+			systemContext.Logger.Error("service.PaymentAllocate rollback", zap.Error(revertErr))
+		} else if syncErr := invoiceSyncPayments(invoiceIDs, fmt.Sprintf("Credit from payment %s reverted", payment.ReceiptNumber), systemContext); syncErr != nil {
+			systemContext.Logger.Error("service.PaymentAllocate rollback", zap.Error(syncErr))
+		}
+		return nil, err
+	}
+
+	return PaymentGetByID(paymentID, systemContext)
+}
+
+// PaymentVoid cancels a payment recorded in error. Its allocations stop counting, so the invoices
+// it settled become outstanding again.
+func PaymentVoid(paymentID primitive.ObjectID, input *model.PaymentVoidRequest, systemContext *model.SystemContext) (*database.Payment, error) {
+	reason := strings.TrimSpace(input.Reason)
+	if reason == "" {
+		return nil, utils.SystemError(enum.ErrorCodeValidation, "A reason is required to void a payment", nil)
+	}
+
+	collection := systemContext.MongoDB.Collection("payment")
+
+	filter := bson.M{
+		"_id":       paymentID,
+		"company":   systemContext.User.Company,
+		"status":    enum.PaymentStatusReceived,
+		"isDeleted": false,
+	}
+
+	update := bson.M{
+		"$set": bson.M{
+			"status":     enum.PaymentStatusVoid,
+			"voidedAt":   time.Now(),
+			"voidReason": reason,
+			"updatedAt":  time.Now(),
+			"updatedBy":  systemContext.User.ID,
+		},
+		"$push": bson.M{
+			"actionLogs": newSystemActionLog("Payment voided: "+reason, systemContext),
+		},
+	}
+
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+
+	var doc database.Payment
+	err := collection.FindOneAndUpdate(context.Background(), filter, update, opts).Decode(&doc)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, utils.SystemError(enum.ErrorCodeNotFound, "Payment not found or already void", nil)
+		}
+		systemContext.Logger.Error("service.PaymentVoid", zap.Error(err))
+		return nil, utils.SystemError(enum.ErrorCodeInternal, "Failed to void payment", nil)
+	}
+
+	if err := invoiceSyncPayments(paymentAllocationInvoices(doc.Allocations), fmt.Sprintf("Payment %s voided", doc.ReceiptNumber), systemContext); err != nil {
+		return nil, err
+	}
+
+	return &doc, nil
+}
+
+// PaymentBalance summarises what has been invoiced, received and is still owed on a project or quotation
+func PaymentBalance(input *model.PaymentBalanceRequest, systemContext *model.SystemContext) (*model.PaymentBalanceResponse, error) {
+	target, err := resolvePaymentTarget(input.Project, input.Quotation, systemContext)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &model.PaymentBalanceResponse{}
+
+	if target.project != nil {
+		invoices, err := paymentInvoices(bson.M{
+			"project": target.project.ID,
+			"status":  bson.M{"$in": []enum.InvoiceStatus{enum.InvoiceStatusIssued, enum.InvoiceStatusPartiallyPaid, enum.InvoiceStatusPaid}},
+		}, systemContext)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, invoice := range invoices {
+			response.TotalInvoiced += invoice.Total
+			response.Outstanding += invoice.BalanceDue
+		}
+	}
+
+	payments, err := paymentsReceived(paymentTargetFilter(target), systemContext)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, payment := range payments {
+		response.TotalReceived += payment.Amount
+		response.TotalPaid += payment.AllocatedAmount
+		response.CreditBalance += payment.UnallocatedAmount
+	}
+
+	return response, nil
+}
+
+// PaymentAging buckets outstanding invoice balances by days past due, per client and for the whole company.
+// Balances are as they stand now; AsOf only moves the date the days are counted to.
+func PaymentAging(input *model.PaymentAgingRequest, systemContext *model.SystemContext) (*model.PaymentAgingResponse, error) {
+	asOf := time.Now()
+	if input.AsOf != nil {
+		asOf = *input.AsOf
+	}
+
+	invoiceFilter := bson.M{
+		"status":     bson.M{"$in": []enum.InvoiceStatus{enum.InvoiceStatusIssued, enum.InvoiceStatusPartiallyPaid}},
+		"balanceDue": bson.M{"$gt": 0},
+		"issueDate":  bson.M{"$lte": asOf},
+	}
+	paymentFilter := bson.M{
+		"unallocatedAmount": bson.M{"$gt": 0},
+		"paymentDate":       bson.M{"$lte": asOf},
+	}
+
+	if input.Project != nil {
+		invoiceFilter["project"] = input.Project
+		paymentFilter["project"] = input.Project
+	}
+	if strings.TrimSpace(input.Client) != "" {
+		clientRegex := primitive.Regex{Pattern: input.Client, Options: "i"}
+		invoiceFilter["client.Nname"] = clientRegex
+		paymentFilter["client.Nname"] = clientRegex
+	}
+
+	invoices, err := paymentInvoices(invoiceFilter, systemContext)
+	if err != nil {
+		return nil, err
+	}
+
+	payments, err := paymentsReceived(paymentFilter, systemContext)
+	if err != nil {
+		return nil, err
+	}
+
+	clients := make(map[string]*model.PaymentAgingClient)
+	clientOf := func(client database.SystemClient) *model.PaymentAgingClient {
+		key := strings.ToLower(strings.TrimSpace(client.Name))
+		if _, exists := clients[key]; !exists {
+			clients[key] = &model.PaymentAgingClient{Client: client, Invoices: []model.PaymentAgingInvoice{}}
+		}
+		return clients[key]
+	}
+
+	asOfDate := paymentAgingDay(asOf)
+	for _, invoice := range invoices {
+		daysOverdue := int(asOfDate.Sub(paymentAgingDay(invoice.DueDate)).Hours() / 24)
+		if daysOverdue < 0 {
+			daysOverdue = 0
+		}
+		bucket := addPaymentAging(&clientOf(invoice.Client).Buckets, daysOverdue, invoice.BalanceDue)
+
+		client := clientOf(invoice.Client)
+		client.Invoices = append(client.Invoices, model.PaymentAgingInvoice{
+			Invoice:       *invoice.ID,
+			InvoiceNumber: invoice.InvoiceNumber,
+			Project:       invoice.Project,
+			IssueDate:     invoice.IssueDate,
+			DueDate:       invoice.DueDate,
+			DaysOverdue:   daysOverdue,
+			Bucket:        bucket,
+			BalanceDue:    invoice.BalanceDue,
+		})
+	}
+
+	for _, payment := range payments {
+		clientOf(payment.Client).Buckets.Credit += payment.UnallocatedAmount
+	}
+
+	response := &model.PaymentAgingResponse{
+		AsOf:    asOf,
+		Clients: []model.PaymentAgingClient{},
+	}
+
+	for _, client := range clients {
+		finalisePaymentAging(&client.Buckets)
+		sort.Slice(client.Invoices, func(i, j int) bool {
+			return client.Invoices[i].DueDate.Before(client.Invoices[j].DueDate)
+		})
+
+		response.Company.Current += client.Buckets.Current
+		response.Company.Days1To30 += client.Buckets.Days1To30
+		response.Company.Days31To60 += client.Buckets.Days31To60
+		response.Company.Days61To90 += client.Buckets.Days61To90
+		response.Company.Over90 += client.Buckets.Over90
+		response.Company.Credit += client.Buckets.Credit
+
+		response.Clients = append(response.Clients, *client)
+	}
+	finalisePaymentAging(&response.Company)
+
+	// Largest debtors first for collections meetings
+	sort.Slice(response.Clients, func(i, j int) bool {
+		if response.Clients[i].Buckets.NetOutstanding != response.Clients[j].Buckets.NetOutstanding {
+			return response.Clients[i].Buckets.NetOutstanding > response.Clients[j].Buckets.NetOutstanding
+		}
+		return response.Clients[i].Client.Name < response.Clients[j].Client.Name
+	})
+
+	return response, nil
+}
+
+// PaymentGenerateReceipt renders the official receipt through the company's receipt document template
+func PaymentGenerateReceipt(paymentID primitive.ObjectID, systemContext *model.SystemContext) ([]byte, string, error) {
+	payment, err := PaymentGetByID(paymentID, systemContext)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if payment.Status == enum.PaymentStatusVoid {
+		return nil, "", utils.SystemError(enum.ErrorCodeValidation, "Receipts cannot be issued for void payments", nil)
+	}
+
+	company, err := CompanyTenantGet(systemContext)
+	if err != nil {
+		return nil, "", err
+	}
+
+	data := paymentReceiptData(payment, company, systemContext)
+
+	if payment.Project != nil {
+		if project, err := ProjectGetByID(*payment.Project, systemContext); err == nil {
+			data["projectName"] = html.EscapeString(project.Name)
+		}
+	}
+	if payment.Quotation != nil {
+		if quotation, err := QuotationGetByID(*payment.Quotation, systemContext); err == nil {
+			data["quotationNumber"] = html.EscapeString(quotationReference(quotation))
+		}
+	}
+
+	return DocumentTemplateGenerate("receipt", data, systemContext.User.Company, systemContext)
+}
+
+// Helper functions
+
+// resolvePaymentTarget loads the project and quotation a payment belongs to. A quotation that
+// already has a project resolves to that project, so deposits can be allocated to its invoices.
+func resolvePaymentTarget(projectID *primitive.ObjectID, quotationID *primitive.ObjectID, systemContext *model.SystemContext) (*paymentTarget, error) {
+	if projectID == nil && quotationID == nil {
+		return nil, utils.SystemError(enum.ErrorCodeValidation, "Project or quotation is required", nil)
+	}
+
+	target := &paymentTarget{}
+
+	if projectID != nil {
+		project, err := ProjectGetByID(*projectID, systemContext)
+		if err != nil {
+			return nil, err
+		}
+
+		if quotationID != nil && *quotationID != project.Quotation {
+			return nil, utils.SystemError(enum.ErrorCodeValidation, "Quotation does not belong to the project", nil)
+		}
+
+		target.project = project
+		quotationID = &project.Quotation
+	}
+
+	quotation, err := QuotationGetByID(*quotationID, systemContext)
+	if err != nil {
+		if target.project == nil {
+			return nil, err
+		}
+	} else {
+		target.quotation = quotation
+	}
+
+	if target.project == nil {
+		project, err := projectByQuotation(*quotationID, systemContext)
+		if err != nil {
+			return nil, err
+		}
+		target.project = project
+	}
+
+	return target, nil
+}
+
+// paymentTargetFilter matches the payments of a target, including quotation deposits not yet linked to its project
+func paymentTargetFilter(target *paymentTarget) bson.M {
+	var or []bson.M
+	if target.project != nil {
+		or = append(or, bson.M{"project": target.project.ID})
+	}
+	if target.quotation != nil {
+		or = append(or, bson.M{"quotation": target.quotation.ID})
+	}
+	return bson.M{"$or": or}
+}
+
+func projectByQuotation(quotationID primitive.ObjectID, systemContext *model.SystemContext) (*database.Project, error) {
+	collection := systemContext.MongoDB.Collection("project")
+
+	filter := bson.M{
+		"quotation": quotationID,
+		"company":   systemContext.User.Company,
+		"isDeleted": false,
+	}
+
+	var doc database.Project
+	err := collection.FindOne(context.Background(), filter).Decode(&doc)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		systemContext.Logger.Error("service.projectByQuotation", zap.Error(err))
+		return nil, utils.SystemError(enum.ErrorCodeInternal, "Failed to retrieve project", nil)
+	}
+
+	return &doc, nil
+}
+
+// buildPaymentAllocations splits up to available across the project's outstanding invoices. Requested
+// allocations are validated against each invoice balance; without any, the oldest due invoices are settled first.
+func buildPaymentAllocations(project *database.Project, requested []model.PaymentAllocationRequest, available database.Money, systemContext *model.SystemContext) ([]database.PaymentAllocation, error) {
+	allocations := []database.PaymentAllocation{}
+
+	if project == nil {
+		if len(requested) > 0 {
+			return nil, utils.SystemError(enum.ErrorCodeValidation, "Payments recorded against a quotation cannot be allocated to invoices until its project is created", nil)
+		}
+		return allocations, nil
+	}
+
+	invoices, err := projectOutstandingInvoices(*project.ID, systemContext)
+	if err != nil {
+		return nil, err
+	}
+
+	remaining := available
+	now := time.Now()
+
+	if len(requested) == 0 {
+		for _, invoice := range invoices {
+			if remaining <= 0 {
+				break
+			}
+			balance := invoiceAllocatableBalance(&invoice)
+			if balance <= 0 {
+				continue
+			}
+			amount := database.MinMoney(balance, remaining)
+			allocations = append(allocations, database.PaymentAllocation{
+				Invoice:       *invoice.ID,
+				InvoiceNumber: invoice.InvoiceNumber,
+				Amount:        amount,
+				AllocatedAt:   now,
+			})
+			remaining -= amount
+		}
+		return allocations, nil
+	}
+
+	outstanding := make(map[primitive.ObjectID]database.Invoice)
+	for _, invoice := range invoices {
+		outstanding[*invoice.ID] = invoice
+	}
+
+	seen := make(map[primitive.ObjectID]bool)
+	for i, allocation := range requested {
+		invoice, exists := outstanding[allocation.Invoice]
+		if !exists {
+			return nil, utils.SystemError(
+				enum.ErrorCodeValidation,
+				"Invoice is not an outstanding invoice of the project",
+				map[string]interface{}{"index": i, "invoice": allocation.Invoice.Hex()},
+			)
+		}
+
+		if seen[allocation.Invoice] {
+			return nil, utils.SystemError(
+				enum.ErrorCodeValidation,
+				"Invoice is allocated more than once",
+				map[string]interface{}{"index": i, "invoiceNumber": invoice.InvoiceNumber},
+			)
+		}
+		seen[allocation.Invoice] = true
+
+		balance := invoiceAllocatableBalance(&invoice)
+
+		amount := allocation.Amount
+		if amount == 0 {
+			amount = database.MinMoney(balance, remaining)
+		}
+
+		if amount <= 0 || amount > balance {
+			return nil, utils.SystemError(
+				enum.ErrorCodeValidation,
+				"Allocation exceeds the invoice balance",
+				map[string]interface{}{"index": i, "invoiceNumber": invoice.InvoiceNumber, "balanceDue": balance},
+			)
+		}
+
+		if amount > remaining {
+			return nil, utils.SystemError(
+				enum.ErrorCodeValidation,
+				"Allocations exceed the payment amount",
+				map[string]interface{}{"index": i, "available": remaining},
+			)
+		}
+
+		allocations = append(allocations, database.PaymentAllocation{
+			Invoice:       allocation.Invoice,
+			InvoiceNumber: invoice.InvoiceNumber,
+			Amount:        amount,
+			AllocatedAt:   now,
+		})
+		remaining -= amount
+	}
+
+	return allocations, nil
+}
+
+// projectOutstandingInvoices returns the issued and partially paid invoices of a project, oldest due first
+func projectOutstandingInvoices(projectID primitive.ObjectID, systemContext *model.SystemContext) ([]database.Invoice, error) {
+	return paymentInvoices(bson.M{
+		"project":    projectID,
+		"status":     bson.M{"$in": []enum.InvoiceStatus{enum.InvoiceStatusIssued, enum.InvoiceStatusPartiallyPaid}},
+		"balanceDue": bson.M{"$gt": 0},
+	}, systemContext)
+}
+
+func paymentInvoices(filter bson.M, systemContext *model.SystemContext) ([]database.Invoice, error) {
+	collection := systemContext.MongoDB.Collection("invoice")
+
+	filter["company"] = systemContext.User.Company
+	filter["isDeleted"] = false
+
+	findOptions := options.Find().SetSort(bson.D{{Key: "dueDate", Value: 1}, {Key: "issueDate", Value: 1}})
+
+	cursor, err := collection.Find(context.Background(), filter, findOptions)
+	if err != nil {
+		systemContext.Logger.Error("service.paymentInvoices", zap.Error(err))
+		return nil, utils.SystemError(enum.ErrorCodeInternal, "Failed to retrieve invoices", nil)
+	}
+	defer cursor.Close(context.Background())
+
+	var invoices []database.Invoice
+	if err = cursor.All(context.Background(), &invoices); err != nil {
+		systemContext.Logger.Error("service.paymentInvoices", zap.Error(err))
+		return nil, utils.SystemError(enum.ErrorCodeInternal, "Failed to decode invoices", nil)
+	}
+
+	return invoices, nil
+}
+
+func paymentsReceived(filter bson.M, systemContext *model.SystemContext) ([]database.Payment, error) {
+	collection := systemContext.MongoDB.Collection("payment")
+
+	filter["company"] = systemContext.User.Company
+	filter["status"] = enum.PaymentStatusReceived
+	filter["isDeleted"] = false
+
+	cursor, err := collection.Find(context.Background(), filter)
+	if err != nil {
+		systemContext.Logger.Error("service.paymentsReceived", zap.Error(err))
+		return nil, utils.SystemError(enum.ErrorCodeInternal, "Failed to retrieve payments", nil)
+	}
+	defer cursor.Close(context.Background())
+
+	var payments []database.Payment
+	if err = cursor.All(context.Background(), &payments); err != nil {
+		systemContext.Logger.Error("service.paymentsReceived", zap.Error(err))
+		return nil, utils.SystemError(enum.ErrorCodeInternal, "Failed to decode payments", nil)
+	}
+
+	return payments, nil
+}
+
+// invoiceSyncPayments recalculates the paid amount, balance and payment status of invoices from the
+// allocations of all payments that are not void, so the invoice never drifts from its payments.
+func invoiceSyncPayments(invoiceIDs []primitive.ObjectID, description string, systemContext *model.SystemContext) error {
+	if len(invoiceIDs) == 0 {
+		return nil
+	}
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{
+			"company":             systemContext.User.Company,
+			"status":              enum.PaymentStatusReceived,
+			"isDeleted":           false,
+			"allocations.invoice": bson.M{"$in": invoiceIDs},
+		}}},
+		{{Key: "$unwind", Value: "$allocations"}},
+		{{Key: "$match", Value: bson.M{"allocations.invoice": bson.M{"$in": invoiceIDs}}}},
+		{{Key: "$group", Value: bson.M{"_id": "$allocations.invoice", "paid": bson.M{"$sum": "$allocations.amount"}}}},
+	}
+
+	cursor, err := systemContext.MongoDB.Collection("payment").Aggregate(context.Background(), pipeline)
+	if err != nil {
+		systemContext.Logger.Error("service.invoiceSyncPayments", zap.Error(err))
+		return utils.SystemError(enum.ErrorCodeInternal, "Failed to update invoice payments", nil)
+	}
+	defer cursor.Close(context.Background())
+
+	var results []struct {
+		ID   primitive.ObjectID `bson:"_id"`
+		Paid database.Money     `bson:"paid"`
+	}
+	if err = cursor.All(context.Background(), &results); err != nil {
+		systemContext.Logger.Error("service.invoiceSyncPayments", zap.Error(err))
+		return utils.SystemError(enum.ErrorCodeInternal, "Failed to update invoice payments", nil)
+	}
+
+	paid := make(map[primitive.ObjectID]database.Money)
+	for _, result := range results {
+		paid[result.ID] = result.Paid
+	}
+
+	invoices, err := paymentInvoices(bson.M{
+		"_id":    bson.M{"$in": invoiceIDs},
+		"status": bson.M{"$in": []enum.InvoiceStatus{enum.InvoiceStatusIssued, enum.InvoiceStatusPartiallyPaid, enum.InvoiceStatusPaid}},
+	}, systemContext)
+	if err != nil {
+		return err
+	}
+
+	collection := systemContext.MongoDB.Collection("invoice")
+	for _, invoice := range invoices {
+		paidAmount := paid[*invoice.ID]
+		balanceDue := invoice.Total - paidAmount
+
+		status := enum.InvoiceStatusIssued
+		if balanceDue <= 0 {
+			status = enum.InvoiceStatusPaid
+		} else if paidAmount > 0 {
+			status = enum.InvoiceStatusPartiallyPaid
+		}
+
+		logDescription := description
+		if status != invoice.Status {
+			logDescription = fmt.Sprintf("%s, status changed from %s to %s", description, invoice.Status, status)
+		}
+
+		update := bson.M{
+			"$set": bson.M{
+				"paidAmount": paidAmount,
+				"balanceDue": balanceDue,
+				"status":     status,
+				"updatedAt":  time.Now(),
+				"updatedBy":  systemContext.User.ID,
+			},
+			"$push": bson.M{
+				"actionLogs": newSystemActionLog(logDescription, systemContext),
+			},
+		}
+
+		if _, err := collection.UpdateOne(context.Background(), bson.M{"_id": invoice.ID}, update); err != nil {
+			systemContext.Logger.Error("service.invoiceSyncPayments", zap.Error(err))
+			return utils.SystemError(enum.ErrorCodeInternal, "Failed to update invoice payments", nil)
+		}
+	}
+
+	return nil
+}
+
+// invoiceAllocatableBalance is the part of an invoice balance not already held by another payment
+func invoiceAllocatableBalance(invoice *database.Invoice) database.Money {
+	return invoice.BalanceDue - invoice.AllocatingAmount
+}
+
+// invoiceReserveAllocations holds each allocation on its invoice. The update only applies while the
+// balance not already held covers the amount, so concurrent payments cannot both settle it.
+func invoiceReserveAllocations(allocations []database.PaymentAllocation, systemContext *model.SystemContext) error {
+	collection := systemContext.MongoDB.Collection("invoice")
+
+	for i, allocation := range allocations {
+		filter := bson.M{
+			"_id":       allocation.Invoice,
+			"company":   systemContext.User.Company,
+			"status":    bson.M{"$in": []enum.InvoiceStatus{enum.InvoiceStatusIssued, enum.InvoiceStatusPartiallyPaid}},
+			"isDeleted": false,
+			"$expr": bson.M{"$gte": bson.A{
+				bson.M{"$subtract": bson.A{"$balanceDue", bson.M{"$ifNull": bson.A{"$allocatingAmount", 0}}}},
+				allocation.Amount,
+			}},
+		}
+
+		update := bson.M{"$inc": bson.M{"allocatingAmount": allocation.Amount}}
+
+		result, err := collection.UpdateOne(context.Background(), filter, update)
+		if err != nil {
+			systemContext.Logger.Error("service.invoiceReserveAllocations", zap.Error(err))
+			invoiceReleaseAllocations(allocations[:i], systemContext)
+			return utils.SystemError(enum.ErrorCodeInternal, "Failed to allocate payment", nil)
+		}
+
+		if result.MatchedCount == 0 {
+			invoiceReleaseAllocations(allocations[:i], systemContext)
+			return utils.SystemError(
+				enum.ErrorCodeValidation,
+				"Allocation exceeds the invoice balance, another payment may have been recorded, please reload",
+				map[string]interface{}{"index": i, "invoiceNumber": allocation.InvoiceNumber},
+			)
+		}
+	}
+
+	return nil
+}
+
+// invoiceReleaseAllocations takes held allocations back off their invoices, logging rather than
+// returning failures
+func invoiceReleaseAllocations(allocations []database.PaymentAllocation, systemContext *model.SystemContext) {
+	collection := systemContext.MongoDB.Collection("invoice")
+
+	for _, allocation := range allocations {
+		update := bson.M{"$inc": bson.M{"allocatingAmount": -allocation.Amount}}
+		if _, err := collection.UpdateOne(context.Background(), bson.M{"_id": allocation.Invoice}, update); err != nil {
+			systemContext.Logger.Error("service.invoiceReleaseAllocations", zap.Error(err))
+		}
+	}
+}
+
+func paymentAllocatedTotal(allocations []database.PaymentAllocation) database.Money {
+	var total database.Money
+	for _, allocation := range allocations {
+		total += allocation.Amount
+	}
+	return total
+}
+
+func paymentAllocationInvoices(allocations []database.PaymentAllocation) []primitive.ObjectID {
+	invoiceIDs := []primitive.ObjectID{}
+	seen := make(map[primitive.ObjectID]bool)
+	for _, allocation := range allocations {
+		if !seen[allocation.Invoice] {
+			seen[allocation.Invoice] = true
+			invoiceIDs = append(invoiceIDs, allocation.Invoice)
+		}
+	}
+	return invoiceIDs
+}
+
+func paymentAllocationNumbers(allocations []database.PaymentAllocation) string {
+	numbers := make([]string, len(allocations))
+	for i, allocation := range allocations {
+		numbers[i] = allocation.InvoiceNumber
+	}
+	return strings.Join(numbers, ", ")
+}
+
+// addPaymentAging adds an amount to the bucket for the days overdue and returns the bucket label
+func addPaymentAging(buckets *model.PaymentAgingBuckets, daysOverdue int, amount database.Money) string {
+	switch {
+	case daysOverdue <= 0:
+		buckets.Current += amount
+		return "current"
+	case daysOverdue <= 30:
+		buckets.Days1To30 += amount
+		return "1-30"
+	case daysOverdue <= 60:
+		buckets.Days31To60 += amount
+		return "31-60"
+	case daysOverdue <= 90:
+		buckets.Days61To90 += amount
+		return "61-90"
+	default:
+		buckets.Over90 += amount
+		return "90+"
+	}
+}
+
+func finalisePaymentAging(buckets *model.PaymentAgingBuckets) {
+	buckets.Total = buckets.Current + buckets.Days1To30 + buckets.Days31To60 + buckets.Days61To90 + buckets.Over90
+	buckets.NetOutstanding = buckets.Total - buckets.Credit
+}
+
+// paymentAgingDay truncates a time to midnight in the company's time zone so day counts follow the local
+// calendar rather than UTC
+func paymentAgingDay(t time.Time) time.Time {
+	t = t.In(companyLocation())
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+func paymentReceiptData(payment *database.Payment, company *database.Company, systemContext *model.SystemContext) bson.M {
+	allocations := make([]interface{}, len(payment.Allocations))
+	for i, allocation := range payment.Allocations {
+		allocations[i] = map[string]interface{}{
+			"no":            i + 1,
+			"invoiceNumber": html.EscapeString(allocation.InvoiceNumber),
+			"amount":        documentMoney(allocation.Amount),
+		}
+	}
+
+	receiptData := bson.M{
+		"receiptNumber":     html.EscapeString(payment.ReceiptNumber),
+		"paymentDate":       documentDate(payment.PaymentDate),
+		"method":            paymentMethods[payment.Method],
+		"reference":         html.EscapeString(payment.Reference),
+		"amount":            documentMoney(payment.Amount),
+		"clientName":        html.EscapeString(payment.Client.Name),
+		"clientContact":     html.EscapeString(payment.Client.Contact),
+		"clientEmail":       html.EscapeString(payment.Client.Email),
+		"projectName":       "",
+		"quotationNumber":   "",
+		"allocations":       allocations,
+		"allocatedAmount":   documentMoney(payment.AllocatedAmount),
+		"unallocatedAmount": documentMoney(payment.UnallocatedAmount),
+		"remark":            documentMultiline(payment.Remark),
+	}
+
+	data := documentCompanyData(company, systemContext)
+	for key, value := range receiptData {
+		data[key] = value
+	}
+
+	return data
+}
@@ -0,0 +1,817 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"math"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.uber.org/zap"
+	"renotech.com.my/internal/database"
+	"renotech.com.my/internal/enum"
+	"renotech.com.my/internal/model"
+	"renotech.com.my/internal/utils"
+)
+
+var projectQuotationIndex = &indexOnce{}
+
+// Tenant services
+func projectCreateValidation(input *model.ProjectCreateRequest, systemContext *model.SystemContext) (*database.Quotation, []database.User, error) {
+	quotation, err := QuotationGetByID(input.QuotationID, systemContext)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// A quotation can only be converted once
+	collection := systemContext.MongoDB.Collection("project")
+	count, err := collection.CountDocuments(context.Background(), bson.M{
+		"quotation": input.QuotationID,
+		"company":   systemContext.User.Company,
+		"isDeleted": false,
+	})
+	if err != nil {
+		return nil, nil, utils.SystemError(enum.ErrorCodeInternal, "Failed to validate quotation", nil)
+	}
+
+	if count > 0 {
+		return nil, nil, utils.SystemError(
+			enum.ErrorCodeValidation,
+			"Quotation has already been converted to a project",
+			map[string]interface{}{"quotationId": input.QuotationID.Hex()},
+		)
+	}
+
+	// Only quotations the client has received or accepted can become projects
+	if quotation.Status != enum.QuotationStatusSent && quotation.Status != enum.QuotationStatusAccepted {
+		return nil, nil, utils.SystemError(
+			enum.ErrorCodeValidation,
+			"Only sent or accepted quotations can be converted to a project",
+			map[string]interface{}{"status": quotation.Status},
+		)
+	}
+
+	pic, users, err := validateProjectPIC(input.PIC, systemContext)
+	if err != nil {
+		return nil, nil, err
+	}
+	input.PIC = pic
+
+	return quotation, users, nil
+}
+
+// ProjectCreateFromQuotation snapshots a quotation into a new project
+func ProjectCreateFromQuotation(input *model.ProjectCreateRequest, systemContext *model.SystemContext) (*database.Project, error) {
+	// Validate input
+	quotation, picUsers, err := projectCreateValidation(input, systemContext)
+	if err != nil {
+		return nil, err
+	}
+
+	// Converting a quotation means the client accepted it
+	if quotation.Status != enum.QuotationStatusAccepted {
+		if err := validateQuotationStatusTransition(quotation.Status, enum.QuotationStatusAccepted); err != nil {
+			return nil, err
+		}
+	}
+
+	totalCost := calculateProjectTotalCost(quotation.AreaMaterials, systemContext)
+
+	var folder primitive.ObjectID
+	if quotation.Folder != nil {
+		folder = *quotation.Folder
+	}
+
+	project := &database.Project{
+		Folder:                 folder,
+		Quotation:              *quotation.ID,
+		Name:                   quotation.Name,
+		Description:            quotation.Description,
+		Remark:                 quotation.Remark,
+		AreaMaterials:          quotation.AreaMaterials,
+		Discounts:              quotation.Discounts,
+		AdditionalCharges:      quotation.AdditionalCharges,
+		TotalDiscount:          quotation.TotalDiscount,
+		TotalAdditionalCharge:  quotation.TotalAdditionalCharge,
+		TotalCharge:            quotation.TotalCharge,
+		TotalNettCharge:        quotation.TotalNettCharge,
+		TotalCost:              totalCost,
+		ApprovedVariation:      0,
+		RevisedContractSum:     quotation.TotalNettCharge,
+		DefectsLiabilityMonths: defaultDefectsLiabilityMonths,
+		IsStared:               false,
+		CreatedAt:              time.Now(),
+		CreatedBy:              *systemContext.User.ID,
+		UpdatedAt:              time.Now(),
+		UpdatedBy:              systemContext.User.ID,
+		EstimatedCompleteAt:    input.EstimatedCompleteAt,
+		ActionLogs:             []database.SystemActionLog{newSystemActionLog("Project created from quotation "+quotationReference(quotation), systemContext)},
+		PIC:                    input.PIC,
+		Company:                systemContext.User.Company,
+		IsDeleted:              false,
+	}
+
+	collection := systemContext.MongoDB.Collection("project")
+	ensureProjectQuotationIndex(collection, systemContext)
+
+	result, err := collection.InsertOne(context.Background(), project)
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return nil, utils.SystemError(enum.ErrorCodeValidation, "Quotation has already been converted to a project", nil)
+		}
+		systemContext.Logger.Error("service.ProjectCreateFromQuotation", zap.Error(err))
+		return nil, utils.SystemError(enum.ErrorCodeInternal, "Failed to create project", nil)
+	}
+
+	projectID := result.InsertedID.(primitive.ObjectID)
+
+	// The quotation is only accepted once the project exists. If it was changed by another request in
+	// the meantime, the project is removed again rather than converting a quotation that moved on.
+	if quotation.Status != enum.QuotationStatusAccepted {
+		if _, err := quotationTransitionStatus(quotation, enum.QuotationStatusAccepted, "Converted to project", systemContext); err != nil {
+			if _, deleteErr := collection.DeleteOne(context.Background(), bson.M{"_id": projectID}); deleteErr != nil {
+				systemContext.Logger.Error("service.ProjectCreateFromQuotation rollback", zap.Error(deleteErr))
+			}
+			return nil, err
+		}
+	}
+
+	var doc database.Project
+	err = collection.FindOne(context.Background(), bson.M{"_id": projectID}).Decode(&doc)
+	if err != nil {
+		return nil, utils.SystemError(enum.ErrorCodeInternal, "Failed to retrieve project", nil)
+	}
+
+	notifyProjectPICAssigned(&doc, picUsers, systemContext)
+
+	return &doc, nil
+}
+
+func projectUpdateValidation(input *model.ProjectUpdateRequest, systemContext *model.SystemContext) (*database.Project, []database.User, error) {
+	project, err := ProjectGetByID(input.ID, systemContext)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := validateProjectScopeUnchanged(project, input, systemContext); err != nil {
+		return nil, nil, err
+	}
+
+	pic, users, err := validateProjectPIC(input.PIC, systemContext)
+	if err != nil {
+		return nil, nil, err
+	}
+	input.PIC = pic
+
+	return project, users, nil
+}
+
+func ProjectUpdate(input *model.ProjectUpdateRequest, systemContext *model.SystemContext) (*database.Project, error) {
+	// Validate input
+	existing, picUsers, err := projectUpdateValidation(input, systemContext)
+	if err != nil {
+		return nil, err
+	}
+
+	collection := systemContext.MongoDB.Collection("project")
+
+	filter := bson.M{
+		"_id":       input.ID,
+		"company":   systemContext.User.Company,
+		"isDeleted": false,
+	}
+
+	// Area materials and totals stay as contracted, scope changes go through variation orders
+	update := bson.M{
+		"$set": bson.M{
+			"name":                input.Name,
+			"description":         input.Description,
+			"remark":              input.Remark,
+			"pic":                 input.PIC,
+			"estimatedCompleteAt": input.EstimatedCompleteAt,
+			"updatedAt":           time.Now(),
+			"updatedBy":           systemContext.User.ID,
+		},
+		"$push": bson.M{
+			"actionLogs": newSystemActionLog("Project updated", systemContext),
+		},
+	}
+
+	_, err = collection.UpdateOne(context.Background(), filter, update)
+	if err != nil {
+		return nil, utils.SystemError(enum.ErrorCodeInternal, "Failed to update project", nil)
+	}
+
+	project, err := ProjectGetByID(input.ID, systemContext)
+	if err != nil {
+		return nil, err
+	}
+
+	notifyProjectPICAssigned(project, newlyAssignedPIC(existing.PIC, picUsers), systemContext)
+
+	return project, nil
+}
+
+// validateProjectScopeUnchanged rejects updates that change the contracted area materials, discounts
+// or additional charges. These make up the original contract sum, which only variation orders may revise.
+// Clients may send the scope back unchanged or leave it out.
+func validateProjectScopeUnchanged(project *database.Project, input *model.ProjectUpdateRequest, systemContext *model.SystemContext) error {
+	if input.AreaMaterials == nil && input.Discounts == nil && input.AdditionalCharges == nil {
+		return nil
+	}
+
+	contracted := &database.Quotation{
+		AreaMaterials:     project.AreaMaterials,
+		Discounts:         project.Discounts,
+		AdditionalCharges: project.AdditionalCharges,
+	}
+	requested := &database.Quotation{
+		AreaMaterials:     input.AreaMaterials,
+		Discounts:         input.Discounts,
+		AdditionalCharges: input.AdditionalCharges,
+	}
+	if input.AreaMaterials == nil {
+		requested.AreaMaterials = project.AreaMaterials
+	}
+	if input.Discounts == nil {
+		requested.Discounts = project.Discounts
+	}
+	if input.AdditionalCharges == nil {
+		requested.AdditionalCharges = project.AdditionalCharges
+	}
+
+	// Compare like for like, whatever subtotals the client sent
+	rounding := companyMoneyRounding(systemContext)
+	recalculateAreaMaterials(contracted.AreaMaterials, rounding)
+	recalculateAreaMaterials(requested.AreaMaterials, rounding)
+
+	areaChanges := quotationAreaChanges(contracted.AreaMaterials, requested.AreaMaterials)
+	adjustmentChanges := quotationAdjustmentChanges(contracted, requested)
+	if len(areaChanges) == 0 && len(adjustmentChanges) == 0 {
+		return nil
+	}
+
+	return utils.SystemError(
+		enum.ErrorCodeValidation,
+		"Project scope and pricing cannot be changed, raise a variation order instead",
+		map[string]interface{}{
+			"areas":       areaChanges,
+			"adjustments": adjustmentChanges,
+		},
+	)
+}
+
+func ProjectGetByID(projectID primitive.ObjectID, systemContext *model.SystemContext) (*database.Project, error) {
+	collection := systemContext.MongoDB.Collection("project")
+
+	filter := bson.M{
+		"_id":       projectID,
+		"company":   systemContext.User.Company,
+		"isDeleted": false,
+	}
+
+	var doc database.Project
+	err := collection.FindOne(context.Background(), filter).Decode(&doc)
+	if err != nil {
+		return nil, utils.SystemError(enum.ErrorCodeNotFound, "Project not found", nil)
+	}
+
+	return &doc, nil
+}
+
+func ProjectList(input model.ProjectListRequest, systemContext *model.SystemContext) (*model.ProjectListResponse, error) {
+	// Check if user has a company
+	if systemContext.User.Company == nil {
+		return &model.ProjectListResponse{
+			Data:       []bson.M{},
+			Page:       1,
+			Limit:      10,
+			Total:      0,
+			TotalPages: 0,
+		}, nil
+	}
+
+	// Build base filter
+	filter := bson.M{"isDeleted": false, "company": systemContext.User.Company}
+
+	return projectList(filter, input, systemContext)
+}
+
+// ProjectListMine lists the projects where the authenticated user is a person-in-charge
+func ProjectListMine(input model.ProjectListRequest, systemContext *model.SystemContext) (*model.ProjectListResponse, error) {
+	if systemContext.User.Company == nil || systemContext.User.ID == nil {
+		return &model.ProjectListResponse{
+			Data:       []bson.M{},
+			Page:       1,
+			Limit:      10,
+			Total:      0,
+			TotalPages: 0,
+		}, nil
+	}
+
+	// Build base filter
+	filter := bson.M{"isDeleted": false, "company": systemContext.User.Company, "pic": systemContext.User.ID}
+
+	return projectList(filter, input, systemContext)
+}
+
+// projectList applies the list request filters on top of the given base filter
+func projectList(filter bson.M, input model.ProjectListRequest, systemContext *model.SystemContext) (*model.ProjectListResponse, error) {
+	collection := systemContext.MongoDB.Collection("project")
+	baseFilterSize := len(filter)
+
+	// Add field-specific filters
+	if strings.TrimSpace(input.Name) != "" {
+		filter["name"] = primitive.Regex{Pattern: input.Name, Options: "i"}
+	}
+	if strings.TrimSpace(input.Description) != "" {
+		filter["description"] = primitive.Regex{Pattern: input.Description, Options: "i"}
+	}
+	if input.Folder != nil {
+		filter["folder"] = input.Folder
+	}
+	if input.Quotation != nil {
+		filter["quotation"] = input.Quotation
+	}
+	if input.IsStared != nil {
+		filter["isStared"] = *input.IsStared
+	}
+
+	// Add global search filter
+	if strings.TrimSpace(input.Search) != "" {
+		searchRegex := primitive.Regex{Pattern: input.Search, Options: "i"}
+		searchFilter := bson.M{
+			"$or": []bson.M{
+				{"name": searchRegex},
+				{"description": searchRegex},
+				{"remark": searchRegex},
+			},
+		}
+
+		// Combine existing filter with search filter
+		if len(filter) > baseFilterSize {
+			filter = bson.M{
+				"$and": []bson.M{
+					filter,
+					searchFilter,
+				},
+			}
+		} else {
+			filter["$or"] = searchFilter["$or"]
+		}
+	}
+
+	return executeProjectList(collection, filter, input, systemContext)
+}
+
+func ProjectDelete(projectID primitive.ObjectID, systemContext *model.SystemContext) error {
+	collection := systemContext.MongoDB.Collection("project")
+
+	// Check if project exists and belongs to user's company
+	filter := bson.M{
+		"_id":       projectID,
+		"company":   systemContext.User.Company,
+		"isDeleted": false,
+	}
+
+	count, err := collection.CountDocuments(context.Background(), filter)
+	if err != nil {
+		return utils.SystemError(enum.ErrorCodeInternal, "Failed to validate project", nil)
+	}
+
+	if count == 0 {
+		return utils.SystemError(enum.ErrorCodeNotFound, "Project not found or access denied", nil)
+	}
+
+	// Soft delete the project
+	update := bson.M{
+		"$set": bson.M{
+			"isDeleted": true,
+			"updatedAt": time.Now(),
+			"updatedBy": systemContext.User.ID,
+		},
+	}
+
+	_, err = collection.UpdateOne(context.Background(), filter, update)
+	if err != nil {
+		return utils.SystemError(enum.ErrorCodeInternal, "Failed to delete project", nil)
+	}
+
+	return nil
+}
+
+func ProjectToggleStar(projectID primitive.ObjectID, isStared bool, systemContext *model.SystemContext) (*database.Project, error) {
+	collection := systemContext.MongoDB.Collection("project")
+
+	filter := bson.M{
+		"_id":       projectID,
+		"company":   systemContext.User.Company,
+		"isDeleted": false,
+	}
+
+	update := bson.M{
+		"$set": bson.M{
+			"isStared":  isStared,
+			"updatedAt": time.Now(),
+			"updatedBy": systemContext.User.ID,
+		},
+	}
+
+	result, err := collection.UpdateOne(context.Background(), filter, update)
+	if err != nil {
+		return nil, utils.SystemError(enum.ErrorCodeInternal, "Failed to toggle star", nil)
+	}
+
+	if result.MatchedCount == 0 {
+		return nil, utils.SystemError(enum.ErrorCodeNotFound, "Project not found", nil)
+	}
+
+	return ProjectGetByID(projectID, systemContext)
+}
+
+// ProjectAssignPIC adds company users as persons-in-charge of a project
+func ProjectAssignPIC(projectID primitive.ObjectID, input *model.ProjectPICRequest, systemContext *model.SystemContext) (*database.Project, error) {
+	project, err := ProjectGetByID(projectID, systemContext)
+	if err != nil {
+		return nil, err
+	}
+
+	_, users, err := validateProjectPIC(input.PIC, systemContext)
+	if err != nil {
+		return nil, err
+	}
+
+	added := newlyAssignedPIC(project.PIC, users)
+	if len(added) == 0 {
+		return project, nil
+	}
+
+	addedIDs := make([]primitive.ObjectID, 0, len(added))
+	addedNames := make([]string, 0, len(added))
+	for _, user := range added {
+		addedIDs = append(addedIDs, *user.ID)
+		addedNames = append(addedNames, user.Username)
+	}
+
+	collection := systemContext.MongoDB.Collection("project")
+	filter := bson.M{
+		"_id":       projectID,
+		"company":   systemContext.User.Company,
+		"isDeleted": false,
+	}
+
+	update := bson.M{
+		"$addToSet": bson.M{
+			"pic": bson.M{"$each": addedIDs},
+		},
+		"$set": bson.M{
+			"updatedAt": time.Now(),
+			"updatedBy": systemContext.User.ID,
+		},
+		"$push": bson.M{
+			"actionLogs": newSystemActionLog("Assigned PIC: "+strings.Join(addedNames, ", "), systemContext),
+		},
+	}
+
+	_, err = collection.UpdateOne(context.Background(), filter, update)
+	if err != nil {
+		return nil, utils.SystemError(enum.ErrorCodeInternal, "Failed to assign PIC", nil)
+	}
+
+	project, err = ProjectGetByID(projectID, systemContext)
+	if err != nil {
+		return nil, err
+	}
+
+	notifyProjectPICAssigned(project, added, systemContext)
+
+	return project, nil
+}
+
+// ProjectUnassignPIC removes persons-in-charge from a project. A project always keeps at least one PIC.
+func ProjectUnassignPIC(projectID primitive.ObjectID, input *model.ProjectPICRequest, systemContext *model.SystemContext) (*database.Project, error) {
+	project, err := ProjectGetByID(projectID, systemContext)
+	if err != nil {
+		return nil, err
+	}
+
+	removeSet := make(map[primitive.ObjectID]bool)
+	for _, userID := range input.PIC {
+		removeSet[userID] = true
+	}
+
+	removed := []primitive.ObjectID{}
+	remaining := 0
+	for _, userID := range project.PIC {
+		if removeSet[userID] {
+			removed = append(removed, userID)
+		} else {
+			remaining++
+		}
+	}
+
+	if len(removed) == 0 {
+		return nil, utils.SystemError(enum.ErrorCodeValidation, "PIC is not assigned to this project", nil)
+	}
+
+	if remaining == 0 {
+		return nil, utils.SystemError(enum.ErrorCodeValidation, "Project must have at least one PIC", nil)
+	}
+
+	collection := systemContext.MongoDB.Collection("project")
+	filter := bson.M{
+		"_id":       projectID,
+		"company":   systemContext.User.Company,
+		"isDeleted": false,
+	}
+
+	update := bson.M{
+		"$pull": bson.M{
+			"pic": bson.M{"$in": removed},
+		},
+		"$set": bson.M{
+			"updatedAt": time.Now(),
+			"updatedBy": systemContext.User.ID,
+		},
+		"$push": bson.M{
+			"actionLogs": newSystemActionLog("Unassigned PIC: "+strings.Join(projectUserNames(removed, systemContext), ", "), systemContext),
+		},
+	}
+
+	_, err = collection.UpdateOne(context.Background(), filter, update)
+	if err != nil {
+		return nil, utils.SystemError(enum.ErrorCodeInternal, "Failed to unassign PIC", nil)
+	}
+
+	return ProjectGetByID(projectID, systemContext)
+}
+
+// Helper functions
+func executeProjectList(collection *mongo.Collection, filter bson.M, input model.ProjectListRequest, systemContext *model.SystemContext) (*model.ProjectListResponse, error) {
+	// Get total count
+	total, err := collection.CountDocuments(context.Background(), filter)
+	if err != nil {
+		systemContext.Logger.Error("service.ProjectList", zap.Error(err))
+		return nil, utils.SystemError(enum.ErrorCodeInternal, "Failed to count projects", nil)
+	}
+
+	// Set default pagination values
+	page := input.Page
+	if page <= 0 {
+		page = 1
+	}
+	limit := input.Limit
+	if limit <= 0 {
+		limit = 10
+	}
+	if limit > 100 {
+		limit = 100 // Maximum limit
+	}
+
+	// Calculate pagination
+	skip := (page - 1) * limit
+	totalPages := int(math.Ceil(float64(total) / float64(limit)))
+
+	// Build sort options - use bson.D to preserve order for multiple sort fields
+	var sortOptions bson.D
+	if len(input.Sort) > 0 {
+		for key, value := range input.Sort {
+			sortOptions = append(sortOptions, bson.E{Key: key, Value: value})
+		}
+	} else {
+		// Default sort by createdAt descending (latest first)
+		sortOptions = bson.D{{Key: "createdAt", Value: -1}}
+	}
+
+	// Create find options
+	findOptions := options.Find().
+		SetSkip(int64(skip)).
+		SetLimit(int64(limit)).
+		SetSort(sortOptions)
+
+	// Execute query
+	cursor, err := collection.Find(context.Background(), filter, findOptions)
+	if err != nil {
+		systemContext.Logger.Error("service.ProjectList", zap.Error(err))
+		return nil, utils.SystemError(enum.ErrorCodeInternal, "Failed to retrieve projects", nil)
+	}
+	defer cursor.Close(context.Background())
+
+	// Decode results
+	var projects []bson.M
+	if err = cursor.All(context.Background(), &projects); err != nil {
+		systemContext.Logger.Error("service.ProjectList", zap.Error(err))
+		return nil, utils.SystemError(enum.ErrorCodeInternal, "Failed to decode projects", nil)
+	}
+	moneyDocuments(projects)
+
+	return &model.ProjectListResponse{
+		Data:       projects,
+		Page:       page,
+		Limit:      limit,
+		Total:      total,
+		TotalPages: totalPages,
+	}, nil
+}
+
+// validateProjectPIC checks every PIC is an enabled user of the same company, removing duplicates
+func validateProjectPIC(pic []primitive.ObjectID, systemContext *model.SystemContext) ([]primitive.ObjectID, []database.User, error) {
+	seen := make(map[primitive.ObjectID]bool)
+	unique := []primitive.ObjectID{}
+	for _, userID := range pic {
+		if !seen[userID] {
+			seen[userID] = true
+			unique = append(unique, userID)
+		}
+	}
+
+	if len(unique) == 0 {
+		return unique, []database.User{}, nil
+	}
+
+	collection := systemContext.MongoDB.Collection("user")
+	cursor, err := collection.Find(context.Background(), bson.M{
+		"_id":       bson.M{"$in": unique},
+		"company":   systemContext.User.Company,
+		"isDeleted": false,
+		"isEnabled": true,
+	})
+	if err != nil {
+		return nil, nil, utils.SystemError(enum.ErrorCodeInternal, "Failed to validate PIC", nil)
+	}
+	defer cursor.Close(context.Background())
+
+	var users []database.User
+	if err = cursor.All(context.Background(), &users); err != nil {
+		return nil, nil, utils.SystemError(enum.ErrorCodeInternal, "Failed to validate PIC", nil)
+	}
+
+	if len(users) != len(unique) {
+		return nil, nil, utils.SystemError(enum.ErrorCodeValidation, "PIC must be active users of your company", nil)
+	}
+
+	return unique, users, nil
+}
+
+// calculateProjectTotalCost sums CostPerUnit x quantity over all materials using the costs snapshotted
+// on the quotation, so later catalogue price changes do not move the budget
+func calculateProjectTotalCost(areaMaterials []database.SystemAreaMaterial, systemContext *model.SystemContext) database.Money {
+	totalCost := newMoneyTotal(companyMoneyRounding(systemContext))
+	for _, areaMaterial := range areaMaterials {
+		for _, materialDetail := range areaMaterial.Materials {
+			totalCost.addLine(materialDetail.CostPerUnit, materialDetail.Quantity)
+		}
+	}
+
+	return totalCost.value()
+}
+
+// ensureProjectQuotationIndex stops concurrent requests converting the same quotation twice
+func ensureProjectQuotationIndex(collection *mongo.Collection, systemContext *model.SystemContext) {
+	projectQuotationIndex.Do(func() error {
+		_, err := collection.Indexes().CreateOne(context.Background(), mongo.IndexModel{
+			Keys: bson.D{{Key: "quotation", Value: 1}},
+			Options: options.Index().
+				SetUnique(true).
+				SetPartialFilterExpression(bson.M{"isDeleted": false}),
+		})
+		if err != nil {
+			systemContext.Logger.Error("service.ensureProjectQuotationIndex", zap.Error(err))
+		}
+		return err
+	})
+}
+
+// quotationReference is the quotation number, falling back to its name for older quotations
+func quotationReference(quotation *database.Quotation) string {
+	if quotation.QuotationNumber != "" {
+		return quotation.QuotationNumber
+	}
+	return quotation.Name
+}
+
+// newlyAssignedPIC returns the users that are not already in the current PIC list
+func newlyAssignedPIC(current []primitive.ObjectID, users []database.User) []database.User {
+	existing := make(map[primitive.ObjectID]bool)
+	for _, userID := range current {
+		existing[userID] = true
+	}
+
+	added := []database.User{}
+	for _, user := range users {
+		if user.ID != nil && !existing[*user.ID] {
+			added = append(added, user)
+		}
+	}
+
+	return added
+}
+
+// projectUserNames resolves user IDs to usernames for action logs, falling back to the ID
+func projectUserNames(userIDs []primitive.ObjectID, systemContext *model.SystemContext) []string {
+	names := make(map[primitive.ObjectID]string)
+
+	collection := systemContext.MongoDB.Collection("user")
+	cursor, err := collection.Find(context.Background(), bson.M{
+		"_id":     bson.M{"$in": userIDs},
+		"company": systemContext.User.Company,
+	})
+	if err == nil {
+		var users []database.User
+		if cursor.All(context.Background(), &users) == nil {
+			for _, user := range users {
+				names[*user.ID] = user.Username
+			}
+		}
+	}
+
+	result := make([]string, 0, len(userIDs))
+	for _, userID := range userIDs {
+		if name, ok := names[userID]; ok {
+			result = append(result, name)
+		} else {
+			result = append(result, userID.Hex())
+		}
+	}
+
+	return result
+}
+
+// ProjectPICNotifier is called with the users newly assigned as PIC of a project.
+// The default emails each user; replace it to route notifications elsewhere.
+var ProjectPICNotifier = projectPICEmailNotifier
+
+// notifyProjectPICAssigned runs the notifier in the background so a mail failure never fails the request.
+// Users assigning themselves are not notified.
+func notifyProjectPICAssigned(project *database.Project, users []database.User, systemContext *model.SystemContext) {
+	recipients := []database.User{}
+	for _, user := range users {
+		if systemContext.User.ID != nil && user.ID != nil && *user.ID == *systemContext.User.ID {
+			continue
+		}
+		recipients = append(recipients, user)
+	}
+
+	if len(recipients) == 0 || ProjectPICNotifier == nil {
+		return
+	}
+
+	notifier := ProjectPICNotifier
+	assignedBy := systemContext.User.Username
+	logger := systemContext.Logger
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				logger.Error("service.notifyProjectPICAssigned", zap.Any("panic", r))
+			}
+		}()
+		notifier(project, recipients, assignedBy, logger)
+	}()
+}
+
+func projectPICEmailNotifier(project *database.Project, users []database.User, assignedBy string, logger *zap.Logger) {
+	projectLink := fmt.Sprintf("%s/project/%s",
+		utils.GetEnvString("FRONTEND_URL", "https://app.renotech.space"),
+		project.ID.Hex(),
+	)
+
+	for _, user := range users {
+		if strings.TrimSpace(user.Email) == "" {
+			continue
+		}
+
+		htmlBody := fmt.Sprintf(
+			"<p>Hi %s,</p><p>%s has assigned you as a person-in-charge of project <strong>%s</strong>.</p><p><a href=\"%s\">View project</a></p>",
+			html.EscapeString(user.Username),
+			html.EscapeString(assignedBy),
+			html.EscapeString(project.Name),
+			projectLink,
+		)
+		textBody := fmt.Sprintf(
+			"Hi %s,\n\n%s has assigned you as a person-in-charge of project %s.\n\nView project: %s\n",
+			user.Username,
+			assignedBy,
+			project.Name,
+			projectLink,
+		)
+
+		err := utils.SendMultipartEmail(&utils.EmailMessage{
+			To:       []string{user.Email},
+			Subject:  fmt.Sprintf("You have been assigned to project %s", project.Name),
+			HTMLBody: htmlBody,
+			TextBody: textBody,
+		})
+		if err != nil {
+			logger.Error("service.projectPICEmailNotifier",
+				zap.String("projectID", project.ID.Hex()),
+				zap.String("userEmail", user.Email),
+				zap.Error(err),
+			)
+		}
+	}
+}
@@ -0,0 +1,511 @@
+package service
+
+import (
+	"context"
+	"math"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.uber.org/zap"
+	"renotech.com.my/internal/database"
+	"renotech.com.my/internal/enum"
+	"renotech.com.my/internal/model"
+	"renotech.com.my/internal/utils"
+)
+
+// Order statuses whose value counts as committed project cost
+var projectCommittedOrderStatuses = []enum.OrderStatus{
+	enum.OrderStatusConfirmed,
+	enum.OrderStatusPartial,
+	enum.OrderStatusDelivered,
+}
+
+// Tenant services
+func projectCostCreateValidation(input *model.ProjectCostCreateRequest, systemContext *model.SystemContext) error {
+	project, err := ProjectGetByID(input.Project, systemContext)
+	if err != nil {
+		return err
+	}
+
+	return validateProjectCostFields(project, input.Type, input.Area, input.Amount, input.Media, systemContext)
+}
+
+func ProjectCostCreate(input *model.ProjectCostCreateRequest, systemContext *model.SystemContext) (*database.ProjectCost, error) {
+	// Validate input
+	if err := projectCostCreateValidation(input, systemContext); err != nil {
+		return nil, err
+	}
+
+	cost := &database.ProjectCost{
+		Project:     input.Project,
+		Company:     systemContext.User.Company,
+		Type:        input.Type,
+		Area:        strings.TrimSpace(input.Area),
+		Description: input.Description,
+		Amount:      input.Amount,
+		IncurredAt:  input.IncurredAt,
+		Reference:   input.Reference,
+		Media:       input.Media,
+		CreatedAt:   time.Now(),
+		CreatedBy:   *systemContext.User.ID,
+		UpdatedAt:   time.Now(),
+		UpdatedBy:   systemContext.User.ID,
+		IsDeleted:   false,
+	}
+
+	if cost.Media == nil {
+		cost.Media = []database.SystemMedia{}
+	}
+
+	collection := systemContext.MongoDB.Collection("project_cost")
+	result, err := collection.InsertOne(context.Background(), cost)
+	if err != nil {
+		systemContext.Logger.Error("service.ProjectCostCreate", zap.Error(err))
+		return nil, utils.SystemError(enum.ErrorCodeInternal, "Failed to create project cost", nil)
+	}
+
+	return ProjectCostGetByID(result.InsertedID.(primitive.ObjectID), systemContext)
+}
+
+func projectCostUpdateValidation(input *model.ProjectCostUpdateRequest, systemContext *model.SystemContext) error {
+	existing, err := ProjectCostGetByID(input.ID, systemContext)
+	if err != nil {
+		return err
+	}
+
+	project, err := ProjectGetByID(existing.Project, systemContext)
+	if err != nil {
+		return err
+	}
+
+	return validateProjectCostFields(project, input.Type, input.Area, input.Amount, input.Media, systemContext)
+}
+
+func ProjectCostUpdate(input *model.ProjectCostUpdateRequest, systemContext *model.SystemContext) (*database.ProjectCost, error) {
+	// Validate input
+	if err := projectCostUpdateValidation(input, systemContext); err != nil {
+		return nil, err
+	}
+
+	media := input.Media
+	if media == nil {
+		media = []database.SystemMedia{}
+	}
+
+	collection := systemContext.MongoDB.Collection("project_cost")
+
+	filter := bson.M{
+		"_id":       input.ID,
+		"company":   systemContext.User.Company,
+		"isDeleted": false,
+	}
+
+	update := bson.M{
+		"$set": bson.M{
+			"type":        input.Type,
+			"area":        strings.TrimSpace(input.Area),
+			"description": input.Description,
+			"amount":      input.Amount,
+			"incurredAt":  input.IncurredAt,
+			"reference":   input.Reference,
+			"media":       media,
+			"updatedAt":   time.Now(),
+			"updatedBy":   systemContext.User.ID,
+		},
+	}
+
+	_, err := collection.UpdateOne(context.Background(), filter, update)
+	if err != nil {
+		return nil, utils.SystemError(enum.ErrorCodeInternal, "Failed to update project cost", nil)
+	}
+
+	return ProjectCostGetByID(input.ID, systemContext)
+}
+
+func ProjectCostGetByID(costID primitive.ObjectID, systemContext *model.SystemContext) (*database.ProjectCost, error) {
+	collection := systemContext.MongoDB.Collection("project_cost")
+
+	filter := bson.M{
+		"_id":       costID,
+		"company":   systemContext.User.Company,
+		"isDeleted": false,
+	}
+
+	var doc database.ProjectCost
+	err := collection.FindOne(context.Background(), filter).Decode(&doc)
+	if err != nil {
+		return nil, utils.SystemError(enum.ErrorCodeNotFound, "Project cost not found", nil)
+	}
+
+	return &doc, nil
+}
+
+func ProjectCostDelete(costID primitive.ObjectID, systemContext *model.SystemContext) error {
+	collection := systemContext.MongoDB.Collection("project_cost")
+
+	filter := bson.M{
+		"_id":       costID,
+		"company":   systemContext.User.Company,
+		"isDeleted": false,
+	}
+
+	update := bson.M{
+		"$set": bson.M{
+			"isDeleted": true,
+			"updatedAt": time.Now(),
+			"updatedBy": systemContext.User.ID,
+		},
+	}
+
+	result, err := collection.UpdateOne(context.Background(), filter, update)
+	if err != nil {
+		return utils.SystemError(enum.ErrorCodeInternal, "Failed to delete project cost", nil)
+	}
+
+	if result.MatchedCount == 0 {
+		return utils.SystemError(enum.ErrorCodeNotFound, "Project cost not found or access denied", nil)
+	}
+
+	return nil
+}
+
+func ProjectCostList(input model.ProjectCostListRequest, systemContext *model.SystemContext) (*model.ProjectCostListResponse, error) {
+	collection := systemContext.MongoDB.Collection("project_cost")
+
+	// Build base filter
+	filter := bson.M{"isDeleted": false, "company": systemContext.User.Company, "project": input.Project}
+
+	// Add field-specific filters
+	if input.Type != "" {
+		filter["type"] = input.Type
+	}
+	if strings.TrimSpace(input.Area) != "" {
+		filter["area"] = strings.TrimSpace(input.Area)
+	}
+
+	// Add incurred date range filter
+	if input.DateFrom != nil || input.DateTo != nil {
+		dateFilter := bson.M{}
+		if input.DateFrom != nil {
+			dateFilter["$gte"] = input.DateFrom
+		}
+		if input.DateTo != nil {
+			dateFilter["$lte"] = input.DateTo
+		}
+		filter["incurredAt"] = dateFilter
+	}
+
+	// Get total count
+	total, err := collection.CountDocuments(context.Background(), filter)
+	if err != nil {
+		systemContext.Logger.Error("service.ProjectCostList", zap.Error(err))
+		return nil, utils.SystemError(enum.ErrorCodeInternal, "Failed to count project costs", nil)
+	}
+
+	// Set default pagination values
+	page := input.Page
+	if page <= 0 {
+		page = 1
+	}
+	limit := input.Limit
+	if limit <= 0 {
+		limit = 10
+	}
+	if limit > 100 {
+		limit = 100 // Maximum limit
+	}
+
+	skip := (page - 1) * limit
+	totalPages := int(math.Ceil(float64(total) / float64(limit)))
+
+	var sortOptions bson.D
+	if len(input.Sort) > 0 {
+		for key, value := range input.Sort {
+			sortOptions = append(sortOptions, bson.E{Key: key, Value: value})
+		}
+	} else {
+		// Default sort by incurred date descending (latest first)
+		sortOptions = bson.D{{Key: "incurredAt", Value: -1}}
+	}
+
+	findOptions := options.Find().
+		SetSkip(int64(skip)).
+		SetLimit(int64(limit)).
+		SetSort(sortOptions)
+
+	cursor, err := collection.Find(context.Background(), filter, findOptions)
+	if err != nil {
+		systemContext.Logger.Error("service.ProjectCostList", zap.Error(err))
+		return nil, utils.SystemError(enum.ErrorCodeInternal, "Failed to retrieve project costs", nil)
+	}
+	defer cursor.Close(context.Background())
+
+	var costs []bson.M
+	if err = cursor.All(context.Background(), &costs); err != nil {
+		systemContext.Logger.Error("service.ProjectCostList", zap.Error(err))
+		return nil, utils.SystemError(enum.ErrorCodeInternal, "Failed to decode project costs", nil)
+	}
+	moneyDocuments(costs)
+
+	return &model.ProjectCostListResponse{
+		Data:       costs,
+		Page:       page,
+		Limit:      limit,
+		Total:      total,
+		TotalPages: totalPages,
+	}, nil
+}
+
+// ProjectCostReport compares the revised contract sum of a project against its purchase orders and manual costs.
+// Committed cost counts the full value of confirmed, partial and delivered orders; actual cost counts
+// only accepted deliveries (or the full value once an order is delivered). Manual costs count as both.
+// Order items and manual costs without a matching area are reported under an unallocated area.
+func ProjectCostReport(projectID primitive.ObjectID, systemContext *model.SystemContext) (*model.ProjectCostReportResponse, error) {
+	project, err := ProjectGetByID(projectID, systemContext)
+	if err != nil {
+		return nil, err
+	}
+
+	// Spread the nett charge over areas by subtotal so area quotes add up to the project total
+	areas := []*model.ProjectCostAreaReport{}
+	areaIndex := make(map[string]*model.ProjectCostAreaReport)
+	for _, areaMaterial := range project.AreaMaterials {
+		name := strings.TrimSpace(areaMaterial.Area.Name)
+
+		budgetCost := calculateProjectTotalCost([]database.SystemAreaMaterial{areaMaterial}, systemContext)
+
+		area, ok := areaIndex[name]
+		if !ok {
+			area = &model.ProjectCostAreaReport{Area: name}
+			areaIndex[name] = area
+			areas = append(areas, area)
+		}
+		area.QuotedAmount += areaMaterial.SubTotal.Scale(project.TotalNettCharge, project.TotalCharge)
+		area.BudgetCost += budgetCost
+	}
+
+	// Approved variation orders adjust the area quotes and may add new areas
+	variationOrders, err := projectApprovedVariationOrders(projectID, nil, systemContext)
+	if err != nil {
+		return nil, err
+	}
+
+	var approvedVariation database.Money
+	for _, variationOrder := range variationOrders {
+		approvedVariation += variationOrder.NetAmount
+
+		for _, line := range variationOrder.Lines {
+			name := strings.TrimSpace(line.Area.Name)
+
+			area, ok := areaIndex[name]
+			if !ok {
+				area = &model.ProjectCostAreaReport{Area: name}
+				areaIndex[name] = area
+				areas = append(areas, area)
+			}
+			area.QuotedAmount += line.Amount
+		}
+	}
+
+	unallocated := &model.ProjectCostAreaReport{Area: ""}
+	areaFor := func(name string) *model.ProjectCostAreaReport {
+		if area, ok := areaIndex[strings.TrimSpace(name)]; ok && name != "" {
+			return area
+		}
+		return unallocated
+	}
+
+	report := &model.ProjectCostReportResponse{
+		Project:            projectID,
+		Name:               project.Name,
+		QuotedAmount:       project.TotalNettCharge,
+		ApprovedVariation:  approvedVariation,
+		RevisedContractSum: project.TotalNettCharge + approvedVariation,
+		BudgetCost:         project.TotalCost,
+		ManualCost:         make(map[string]database.Money),
+		Orders:             []model.ProjectCostOrderSummary{},
+	}
+
+	// Purchase orders
+	orders, err := projectCostOrders(projectID, systemContext)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, order := range orders {
+		summary := model.ProjectCostOrderSummary{
+			Order:        *order.ID,
+			PONumber:     order.PONumber,
+			SupplierName: order.Supplier.Name,
+			Status:       order.Status,
+		}
+
+		for _, item := range order.Items {
+			committed := item.TotalPrice.Percent(100 + order.TaxRate)
+
+			// Actual cost follows what was accepted on delivery, even once the order is delivered,
+			// with fully accepted lines taken at their committed value to avoid rounding drift
+			actual := committed
+			accepted := math.Min(math.Max(item.ReceivedQuantity-item.RejectedQuantity, 0), item.Quantity)
+			if accepted < item.Quantity-quantityTolerance {
+				actual = item.UnitPrice.Times(accepted).Percent(100 + order.TaxRate)
+			}
+
+			area := areaFor(item.Area)
+			area.CommittedCost += committed
+			area.ActualCost += actual
+
+			summary.CommittedCost += committed
+			summary.ActualCost += actual
+		}
+
+		report.OrderCost.Committed += summary.CommittedCost
+		report.OrderCost.Actual += summary.ActualCost
+		report.Orders = append(report.Orders, summary)
+	}
+
+	// Manual costs
+	costs, err := projectCostEntries(projectID, systemContext)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, cost := range costs {
+		area := areaFor(cost.Area)
+		area.CommittedCost += cost.Amount
+		area.ActualCost += cost.Amount
+
+		report.ManualCost[string(cost.Type)] += cost.Amount
+	}
+
+	var manualTotal database.Money
+	for _, amount := range report.ManualCost {
+		manualTotal += amount
+	}
+
+	report.CommittedCost = report.OrderCost.Committed + manualTotal
+	report.ActualCost = report.OrderCost.Actual + manualTotal
+	report.GrossMargin = report.RevisedContractSum - report.CommittedCost
+	report.MarginPercent = projectMarginPercent(report.GrossMargin, report.RevisedContractSum)
+
+	if unallocated.CommittedCost > 0 || unallocated.ActualCost > 0 {
+		areas = append(areas, unallocated)
+	}
+
+	report.Areas = make([]model.ProjectCostAreaReport, 0, len(areas))
+	for _, area := range areas {
+		area.GrossMargin = area.QuotedAmount - area.CommittedCost
+		area.MarginPercent = projectMarginPercent(area.GrossMargin, area.QuotedAmount)
+		report.Areas = append(report.Areas, *area)
+	}
+
+	return report, nil
+}
+
+// Helper functions
+func validateProjectCostFields(project *database.Project, costType enum.ProjectCostType, area string, amount database.Money, media []database.SystemMedia, systemContext *model.SystemContext) error {
+	switch costType {
+	case enum.ProjectCostTypeLabour, enum.ProjectCostTypeTransport, enum.ProjectCostTypeSubcontract:
+	default:
+		return utils.SystemError(
+			enum.ErrorCodeValidation,
+			"Invalid project cost type",
+			map[string]interface{}{"type": costType},
+		)
+	}
+
+	if amount <= 0 {
+		return utils.SystemError(
+			enum.ErrorCodeValidation,
+			"Cost amount must be greater than zero",
+			map[string]interface{}{"amount": amount},
+		)
+	}
+
+	area = strings.TrimSpace(area)
+	if area != "" {
+		found := false
+		for _, areaMaterial := range project.AreaMaterials {
+			if strings.TrimSpace(areaMaterial.Area.Name) == area {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			return utils.SystemError(
+				enum.ErrorCodeValidation,
+				"Area not found in project",
+				map[string]interface{}{"area": area},
+			)
+		}
+	}
+
+	mediaPaths := make([]string, len(media))
+	for i, item := range media {
+		mediaPaths[i] = item.Path
+	}
+
+	return ValidateMediaPaths(mediaPaths, systemContext)
+}
+
+func projectCostOrders(projectID primitive.ObjectID, systemContext *model.SystemContext) ([]database.Order, error) {
+	collection := systemContext.MongoDB.Collection("order")
+
+	filter := bson.M{
+		"project":   projectID,
+		"company":   systemContext.User.Company,
+		"isDeleted": false,
+		"status":    bson.M{"$in": projectCommittedOrderStatuses},
+	}
+
+	cursor, err := collection.Find(context.Background(), filter, options.Find().SetSort(bson.D{{Key: "orderDate", Value: 1}}))
+	if err != nil {
+		systemContext.Logger.Error("service.projectCostOrders", zap.Error(err))
+		return nil, utils.SystemError(enum.ErrorCodeInternal, "Failed to retrieve project orders", nil)
+	}
+	defer cursor.Close(context.Background())
+
+	var orders []database.Order
+	if err = cursor.All(context.Background(), &orders); err != nil {
+		systemContext.Logger.Error("service.projectCostOrders", zap.Error(err))
+		return nil, utils.SystemError(enum.ErrorCodeInternal, "Failed to decode project orders", nil)
+	}
+
+	return orders, nil
+}
+
+func projectCostEntries(projectID primitive.ObjectID, systemContext *model.SystemContext) ([]database.ProjectCost, error) {
+	collection := systemContext.MongoDB.Collection("project_cost")
+
+	filter := bson.M{
+		"project":   projectID,
+		"company":   systemContext.User.Company,
+		"isDeleted": false,
+	}
+
+	cursor, err := collection.Find(context.Background(), filter)
+	if err != nil {
+		systemContext.Logger.Error("service.projectCostEntries", zap.Error(err))
+		return nil, utils.SystemError(enum.ErrorCodeInternal, "Failed to retrieve project costs", nil)
+	}
+	defer cursor.Close(context.Background())
+
+	var costs []database.ProjectCost
+	if err = cursor.All(context.Background(), &costs); err != nil {
+		systemContext.Logger.Error("service.projectCostEntries", zap.Error(err))
+		return nil, utils.SystemError(enum.ErrorCodeInternal, "Failed to decode project costs", nil)
+	}
+
+	return costs, nil
+}
+
+func projectMarginPercent(margin, quoted database.Money) float64 {
+	if quoted == 0 {
+		return 0
+	}
+	return roundPercent(margin.Float64() / quoted.Float64() * 100)
+}
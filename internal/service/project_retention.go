@@ -0,0 +1,349 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"renotech.com.my/internal/database"
+	"renotech.com.my/internal/enum"
+	"renotech.com.my/internal/model"
+	"renotech.com.my/internal/utils"
+)
+
+const (
+	defaultDefectsLiabilityMonths = 12
+	maxDefectsLiabilityMonths     = 60
+)
+
+// Tenant services
+func projectRetentionUpdateValidation(input *model.ProjectRetentionUpdateRequest) error {
+	if input.RetentionPercent < 0 || input.RetentionPercent > 100 {
+		return utils.SystemError(
+			enum.ErrorCodeValidation,
+			"Retention percentage must be between 0 and 100",
+			map[string]interface{}{"retentionPercent": input.RetentionPercent},
+		)
+	}
+
+	if input.RetentionCapPercent < 0 || input.RetentionCapPercent > 100 {
+		return utils.SystemError(
+			enum.ErrorCodeValidation,
+			"Retention limit must be between 0 and 100 percent of the contract sum",
+			map[string]interface{}{"retentionCapPercent": input.RetentionCapPercent},
+		)
+	}
+
+	if input.DefectsLiabilityMonths < 0 || input.DefectsLiabilityMonths > maxDefectsLiabilityMonths {
+		return utils.SystemError(
+			enum.ErrorCodeValidation,
+			fmt.Sprintf("Defects liability period must be between 0 and %d months", maxDefectsLiabilityMonths),
+			map[string]interface{}{"defectsLiabilityMonths": input.DefectsLiabilityMonths},
+		)
+	}
+
+	return nil
+}
+
+// ProjectRetentionUpdate sets the retention terms of a project. Invoices already raised keep the
+// retention they were created with; the new terms apply to later progress claims.
+func ProjectRetentionUpdate(projectID primitive.ObjectID, input *model.ProjectRetentionUpdateRequest, systemContext *model.SystemContext) (*database.Project, error) {
+	// Validate input
+	if err := projectRetentionUpdateValidation(input); err != nil {
+		return nil, err
+	}
+
+	project, err := ProjectGetByID(projectID, systemContext)
+	if err != nil {
+		return nil, err
+	}
+
+	set := bson.M{
+		"retentionPercent":       input.RetentionPercent,
+		"retentionCapPercent":    input.RetentionCapPercent,
+		"defectsLiabilityMonths": input.DefectsLiabilityMonths,
+		"updatedAt":              time.Now(),
+		"updatedBy":              systemContext.User.ID,
+	}
+	if project.CompletedAt != nil {
+		set["defectsLiabilityEndsAt"] = project.CompletedAt.AddDate(0, input.DefectsLiabilityMonths, 0)
+	}
+
+	description := fmt.Sprintf("Retention set to %s%%", documentQuantity(input.RetentionPercent))
+	if input.RetentionCapPercent > 0 {
+		description += fmt.Sprintf(" limited to %s%% of the contract sum", documentQuantity(input.RetentionCapPercent))
+	}
+	description += fmt.Sprintf(", defects liability period %d months", input.DefectsLiabilityMonths)
+
+	if err := projectRetentionSet(projectID, set, description, systemContext); err != nil {
+		return nil, err
+	}
+
+	return ProjectGetByID(projectID, systemContext)
+}
+
+// ProjectCompletionRecord records practical completion, which starts the defects liability period
+// and allows the first half of the retention to be released.
+func ProjectCompletionRecord(projectID primitive.ObjectID, input *model.ProjectCompletionRequest, systemContext *model.SystemContext) (*database.Project, error) {
+	if input.CompletedAt.After(time.Now()) {
+		return nil, utils.SystemError(enum.ErrorCodeValidation, "Completion date cannot be in the future", nil)
+	}
+
+	project, err := ProjectGetByID(projectID, systemContext)
+	if err != nil {
+		return nil, err
+	}
+
+	invoices, err := projectBilledInvoices(projectID, nil, systemContext)
+	if err != nil {
+		return nil, err
+	}
+
+	// The end of the defects liability period cannot move once its release has been billed
+	if _, _, released := projectRetentionTotals(invoices); released[enum.RetentionReleaseStageDefectsLiability] != "" {
+		return nil, utils.SystemError(
+			enum.ErrorCodeValidation,
+			"Retention has been fully released, the completion date can no longer be changed",
+			map[string]interface{}{"invoiceNumber": released[enum.RetentionReleaseStageDefectsLiability]},
+		)
+	}
+
+	endsAt := input.CompletedAt.AddDate(0, project.DefectsLiabilityMonths, 0)
+
+	set := bson.M{
+		"completedAt":            input.CompletedAt,
+		"defectsLiabilityEndsAt": endsAt,
+		"updatedAt":              time.Now(),
+		"updatedBy":              systemContext.User.ID,
+	}
+
+	description := fmt.Sprintf("Practical completion recorded on %s, defects liability period ends %s",
+		documentDate(input.CompletedAt), documentDate(endsAt))
+
+	if err := projectRetentionSet(projectID, set, description, systemContext); err != nil {
+		return nil, err
+	}
+
+	return ProjectGetByID(projectID, systemContext)
+}
+
+// ProjectRetentionLedger lists retention withheld and released by the project's invoices with a running balance
+func ProjectRetentionLedger(projectID primitive.ObjectID, systemContext *model.SystemContext) (*model.ProjectRetentionResponse, error) {
+	project, err := ProjectGetByID(projectID, systemContext)
+	if err != nil {
+		return nil, err
+	}
+
+	invoices, err := projectBilledInvoices(projectID, nil, systemContext)
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(invoices, func(i, j int) bool {
+		if !invoices[i].IssueDate.Equal(invoices[j].IssueDate) {
+			return invoices[i].IssueDate.Before(invoices[j].IssueDate)
+		}
+		return invoices[i].CreatedAt.Before(invoices[j].CreatedAt)
+	})
+
+	response := &model.ProjectRetentionResponse{
+		Project:                projectID,
+		RetentionPercent:       project.RetentionPercent,
+		RetentionCapPercent:    project.RetentionCapPercent,
+		RetentionCap:           projectRetentionCap(project),
+		DefectsLiabilityMonths: project.DefectsLiabilityMonths,
+		CompletedAt:            project.CompletedAt,
+		DefectsLiabilityEndsAt: project.DefectsLiabilityEndsAt,
+		Entries:                []model.ProjectRetentionEntry{},
+	}
+
+	var balance database.Money
+	for _, invoice := range invoices {
+		if invoice.RetentionAmount > 0 {
+			balance += invoice.RetentionAmount
+			response.TotalDeducted += invoice.RetentionAmount
+			response.Entries = append(response.Entries, model.ProjectRetentionEntry{
+				Invoice:       *invoice.ID,
+				InvoiceNumber: invoice.InvoiceNumber,
+				InvoiceStatus: invoice.Status,
+				Date:          invoice.IssueDate,
+				Type:          "deduction",
+				Description:   fmt.Sprintf("%s%% retention on %s", documentQuantity(invoice.RetentionPercent), invoice.Title),
+				Amount:        invoice.RetentionAmount,
+				Balance:       balance,
+			})
+		}
+
+		for _, line := range invoice.Lines {
+			if line.RetentionRelease == "" {
+				continue
+			}
+			balance -= line.Amount
+			response.TotalReleased += line.Amount
+			response.Entries = append(response.Entries, model.ProjectRetentionEntry{
+				Invoice:       *invoice.ID,
+				InvoiceNumber: invoice.InvoiceNumber,
+				InvoiceStatus: invoice.Status,
+				Date:          invoice.IssueDate,
+				Type:          "release",
+				Description:   line.Description,
+				Amount:        -line.Amount,
+				Balance:       balance,
+			})
+		}
+	}
+
+	response.Balance = response.TotalDeducted - response.TotalReleased
+
+	for _, stage := range []enum.RetentionReleaseStage{enum.RetentionReleaseStageCompletion, enum.RetentionReleaseStageDefectsLiability} {
+		if amount, err := projectRetentionReleaseAmount(project, stage, invoices); err == nil {
+			response.NextRelease = stage
+			response.NextReleaseAmount = amount
+			break
+		}
+	}
+
+	return response, nil
+}
+
+// Helper functions
+func projectRetentionSet(projectID primitive.ObjectID, set bson.M, description string, systemContext *model.SystemContext) error {
+	collection := systemContext.MongoDB.Collection("project")
+
+	filter := bson.M{
+		"_id":       projectID,
+		"company":   systemContext.User.Company,
+		"isDeleted": false,
+	}
+
+	update := bson.M{
+		"$set": set,
+		"$push": bson.M{
+			"actionLogs": newSystemActionLog(description, systemContext),
+		},
+	}
+
+	result, err := collection.UpdateOne(context.Background(), filter, update)
+	if err != nil {
+		return utils.SystemError(enum.ErrorCodeInternal, "Failed to update project retention", nil)
+	}
+
+	if result.MatchedCount == 0 {
+		return utils.SystemError(enum.ErrorCodeNotFound, "Project not found", nil)
+	}
+
+	return nil
+}
+
+// invoiceRetentionDeduction is the retention withheld from a progress claim, limited so the total
+// withheld across the project's invoices never exceeds the retention cap
+func invoiceRetentionDeduction(project *database.Project, subTotal database.Money, invoices []database.Invoice) database.Money {
+	deduction := subTotal.Percent(project.RetentionPercent)
+
+	if retentionCap := projectRetentionCap(project); retentionCap > 0 {
+		deducted, _, _ := projectRetentionTotals(invoices)
+		deduction = database.MinMoney(deduction, database.MaxMoney(retentionCap-deducted, 0))
+	}
+
+	return deduction
+}
+
+// invoiceRetentionLines bills the release of retention for the requested stage
+func invoiceRetentionLines(project *database.Project, input *model.InvoiceBillingRequest, invoices []database.Invoice) ([]database.InvoiceLine, error) {
+	amount, err := projectRetentionReleaseAmount(project, input.RetentionRelease, invoices)
+	if err != nil {
+		return nil, err
+	}
+
+	description := "Release of retention - first half upon practical completion"
+	if input.RetentionRelease == enum.RetentionReleaseStageDefectsLiability {
+		description = "Release of retention - balance upon expiry of the defects liability period"
+	}
+
+	return []database.InvoiceLine{{
+		Description:      description,
+		RetentionRelease: input.RetentionRelease,
+		Unit:             "lot",
+		Quantity:         1,
+		UnitPrice:        amount,
+		Amount:           amount,
+	}}, nil
+}
+
+// projectRetentionReleaseAmount checks a release stage is due and returns the amount it releases:
+// half of the retention held at practical completion, the remainder once the defects liability period ends
+func projectRetentionReleaseAmount(project *database.Project, stage enum.RetentionReleaseStage, invoices []database.Invoice) (database.Money, error) {
+	if stage != enum.RetentionReleaseStageCompletion && stage != enum.RetentionReleaseStageDefectsLiability {
+		return 0, utils.SystemError(
+			enum.ErrorCodeValidation,
+			"Invalid retention release",
+			map[string]interface{}{"retentionRelease": stage},
+		)
+	}
+
+	deducted, releasedAmount, released := projectRetentionTotals(invoices)
+
+	if invoiceNumber, exists := released[stage]; exists {
+		return 0, utils.SystemError(
+			enum.ErrorCodeValidation,
+			"Retention release has already been billed",
+			map[string]interface{}{"retentionRelease": stage, "invoiceNumber": invoiceNumber},
+		)
+	}
+
+	if project.CompletedAt == nil || project.DefectsLiabilityEndsAt == nil {
+		return 0, utils.SystemError(enum.ErrorCodeValidation, "Record practical completion of the project before releasing retention", nil)
+	}
+
+	held := deducted - releasedAmount
+
+	amount := held
+	if stage == enum.RetentionReleaseStageCompletion {
+		if _, exists := released[enum.RetentionReleaseStageDefectsLiability]; exists {
+			return 0, utils.SystemError(enum.ErrorCodeValidation, "Retention has already been fully released", nil)
+		}
+		amount = held.Scale(1, 2)
+	} else if time.Now().Before(*project.DefectsLiabilityEndsAt) {
+		return 0, utils.SystemError(
+			enum.ErrorCodeValidation,
+			"Defects liability period has not ended yet",
+			map[string]interface{}{"defectsLiabilityEndsAt": project.DefectsLiabilityEndsAt},
+		)
+	}
+
+	if amount <= 0 {
+		return 0, utils.SystemError(enum.ErrorCodeValidation, "No retention is held on the project", nil)
+	}
+
+	return amount, nil
+}
+
+// projectRetentionTotals sums retention withheld and released by invoices, and maps each release
+// stage already billed to its invoice number
+func projectRetentionTotals(invoices []database.Invoice) (database.Money, database.Money, map[enum.RetentionReleaseStage]string) {
+	var deducted, released database.Money
+	stages := make(map[enum.RetentionReleaseStage]string)
+
+	for _, invoice := range invoices {
+		deducted += invoice.RetentionAmount
+		for _, line := range invoice.Lines {
+			if line.RetentionRelease != "" {
+				released += line.Amount
+				stages[line.RetentionRelease] = invoice.InvoiceNumber
+			}
+		}
+	}
+
+	return deducted, released, stages
+}
+
+// projectRetentionCap is the most retention that may be withheld, 0 when there is no limit
+func projectRetentionCap(project *database.Project) database.Money {
+	if project.RetentionCapPercent <= 0 {
+		return 0
+	}
+	return projectContractSum(project).Percent(project.RetentionCapPercent)
+}
@@ -0,0 +1,326 @@
+package service
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"renotech.com.my/internal/database"
+	"renotech.com.my/internal/enum"
+	"renotech.com.my/internal/model"
+	"renotech.com.my/internal/utils"
+)
+
+// Tenant services
+
+// ProjectGantt returns the project's tasks in a Gantt-friendly shape with delays derived from planned vs actual dates
+func ProjectGantt(projectID primitive.ObjectID, systemContext *model.SystemContext) (*model.ProjectGanttResponse, error) {
+	project, err := ProjectGetByID(projectID, systemContext)
+	if err != nil {
+		return nil, err
+	}
+
+	tasks, err := projectTasksForProject(projectID, systemContext)
+	if err != nil {
+		return nil, err
+	}
+
+	return projectGanttBuild(project, tasks, time.Now(), systemContext), nil
+}
+
+// ProjectScheduleICS exports the project's tasks as an iCalendar file
+func ProjectScheduleICS(projectID primitive.ObjectID, systemContext *model.SystemContext) ([]byte, string, error) {
+	project, err := ProjectGetByID(projectID, systemContext)
+	if err != nil {
+		return nil, "", err
+	}
+
+	tasks, err := projectTasksForProject(projectID, systemContext)
+	if err != nil {
+		return nil, "", err
+	}
+
+	gantt := projectGanttBuild(project, tasks, time.Now(), systemContext)
+
+	return projectScheduleCalendar(project, gantt, time.Now()), projectScheduleFilename(project), nil
+}
+
+// ProjectCalendarShareCreate issues a tokenised calendar feed URL that calendar apps can subscribe to without logging in
+func ProjectCalendarShareCreate(projectID primitive.ObjectID, input *model.DocumentShareCreateRequest, systemContext *model.SystemContext) (*model.DocumentShareResponse, error) {
+	if _, err := ProjectGetByID(projectID, systemContext); err != nil {
+		return nil, err
+	}
+
+	share, err := documentShareCreate(enum.DocumentShareTypeProjectSchedule, projectID, input, "", systemContext)
+	if err != nil {
+		return nil, err
+	}
+
+	// Calendar apps fetch the feed from the API directly rather than through the frontend
+	apiURL := utils.GetEnvString("API_URL", utils.GetEnvString("FRONTEND_URL", "https://app.renotech.space"))
+	share.URL = fmt.Sprintf("%s/api/v1/public/project/%s/schedule.ics", strings.TrimRight(apiURL, "/"), share.Token)
+
+	return share, nil
+}
+
+func ProjectCalendarShareRevoke(projectID primitive.ObjectID, systemContext *model.SystemContext) error {
+	if _, err := ProjectGetByID(projectID, systemContext); err != nil {
+		return err
+	}
+
+	_, err := documentShareRevokeAll(enum.DocumentShareTypeProjectSchedule, projectID, systemContext)
+	return err
+}
+
+// Public services, authorised by share token instead of JWT
+func ProjectCalendarFeed(token string, systemContext *model.SystemContext) ([]byte, string, error) {
+	share, err := documentShareResolve(token, enum.DocumentShareTypeProjectSchedule, "Calendar", true, systemContext)
+	if err != nil {
+		return nil, "", err
+	}
+
+	data, filename, err := ProjectScheduleICS(share.Document, systemContext)
+	if err != nil {
+		return nil, "", utils.SystemError(enum.ErrorCodeUnauthorized, "Invalid or expired link", nil)
+	}
+
+	return data, filename, nil
+}
+
+// Helper functions
+func projectGanttBuild(project *database.Project, tasks []database.ProjectTask, now time.Time, systemContext *model.SystemContext) *model.ProjectGanttResponse {
+	assigneeIDs := []primitive.ObjectID{}
+	for _, task := range tasks {
+		if task.AssignedTo != nil {
+			assigneeIDs = append(assigneeIDs, *task.AssignedTo)
+		}
+	}
+
+	assigneeNames := make(map[primitive.ObjectID]string)
+	if len(assigneeIDs) > 0 {
+		for i, name := range projectUserNames(assigneeIDs, systemContext) {
+			assigneeNames[assigneeIDs[i]] = name
+		}
+	}
+
+	gantt := &model.ProjectGanttResponse{
+		Project:             *project.ID,
+		Name:                project.Name,
+		EstimatedCompleteAt: project.EstimatedCompleteAt,
+		Tasks:               make([]model.ProjectGanttTask, 0, len(tasks)),
+	}
+
+	var progressTotal float64
+	for _, task := range tasks {
+		item := projectGanttTask(task, now)
+		if task.AssignedTo != nil {
+			item.AssignedToName = assigneeNames[*task.AssignedTo]
+		}
+
+		if gantt.Start == nil || task.PlannedStart.Before(*gantt.Start) {
+			start := task.PlannedStart
+			gantt.Start = &start
+		}
+		if gantt.End == nil || task.PlannedEnd.After(*gantt.End) {
+			end := task.PlannedEnd
+			gantt.End = &end
+		}
+
+		forecastEnd := task.PlannedEnd
+		if task.ActualEnd != nil {
+			forecastEnd = *task.ActualEnd
+		} else if item.Status != enum.ProjectTaskStatusCompleted && now.After(task.PlannedEnd) {
+			forecastEnd = now
+		}
+		if gantt.ForecastEnd == nil || forecastEnd.After(*gantt.ForecastEnd) {
+			gantt.ForecastEnd = &forecastEnd
+		}
+
+		if item.IsDelayed {
+			gantt.DelayedTaskCount++
+		}
+
+		progressTotal += item.Progress
+		gantt.Tasks = append(gantt.Tasks, item)
+	}
+
+	if len(tasks) > 0 {
+		gantt.PercentComplete = roundPercent(progressTotal / float64(len(tasks)))
+	}
+
+	return gantt
+}
+
+func projectGanttTask(task database.ProjectTask, now time.Time) model.ProjectGanttTask {
+	dependencies := make([]string, len(task.Dependencies))
+	for i, dependency := range task.Dependencies {
+		dependencies[i] = dependency.Hex()
+	}
+
+	item := model.ProjectGanttTask{
+		ID:           *task.ID,
+		Name:         task.Name,
+		Area:         task.Area,
+		IsMilestone:  task.IsMilestone,
+		Start:        task.PlannedStart,
+		End:          task.PlannedEnd,
+		ActualStart:  task.ActualStart,
+		ActualEnd:    task.ActualEnd,
+		Progress:     task.PercentComplete,
+		Dependencies: dependencies,
+		AssignedTo:   task.AssignedTo,
+	}
+
+	switch {
+	case task.ActualEnd != nil || task.PercentComplete >= 100:
+		item.Status = enum.ProjectTaskStatusCompleted
+		item.Progress = 100
+	case task.ActualStart != nil || task.PercentComplete > 0:
+		item.Status = enum.ProjectTaskStatusInProgress
+	default:
+		item.Status = enum.ProjectTaskStatusNotStarted
+	}
+
+	// Start delay: actual start vs planned, or how long a not yet started task is overdue
+	if task.ActualStart != nil {
+		item.StartDelayDays = projectDelayDays(task.PlannedStart, *task.ActualStart)
+	} else if item.Status == enum.ProjectTaskStatusNotStarted && now.After(task.PlannedStart) {
+		item.StartDelayDays = projectDelayDays(task.PlannedStart, now)
+	}
+
+	// End delay: actual end vs planned, or how long an unfinished task is overdue
+	if task.ActualEnd != nil {
+		item.EndDelayDays = projectDelayDays(task.PlannedEnd, *task.ActualEnd)
+	} else if item.Status != enum.ProjectTaskStatusCompleted && now.After(task.PlannedEnd) {
+		item.EndDelayDays = projectDelayDays(task.PlannedEnd, now)
+	}
+
+	item.IsDelayed = item.EndDelayDays > 0 || (item.Status == enum.ProjectTaskStatusNotStarted && item.StartDelayDays > 0)
+
+	return item
+}
+
+// projectDelayDays is the number of calendar days actual falls after planned; negative when early
+func projectDelayDays(planned, actual time.Time) int {
+	plannedDay := projectScheduleDay(planned)
+	actualDay := projectScheduleDay(actual)
+	return int(math.Round(actualDay.Sub(plannedDay).Hours() / 24))
+}
+
+// projectScheduleDay is the calendar date of t in the company's time zone, as midnight UTC so that
+// day arithmetic is not affected by the offset
+func projectScheduleDay(t time.Time) time.Time {
+	t = t.In(companyLocation())
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// projectScheduleCalendar renders tasks as all-day VEVENTs (RFC 5545)
+func projectScheduleCalendar(project *database.Project, gantt *model.ProjectGanttResponse, now time.Time) []byte {
+	var lines []string
+	lines = append(lines,
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"PRODID:-//RenoTech//Project Schedule//EN",
+		"CALSCALE:GREGORIAN",
+		"METHOD:PUBLISH",
+		"X-WR-CALNAME:"+icsEscape(project.Name),
+	)
+
+	stamp := now.UTC().Format("20060102T150405Z")
+	for _, task := range gantt.Tasks {
+		start := task.Start
+		end := task.End
+		if task.ActualStart != nil {
+			start = *task.ActualStart
+		}
+		if task.ActualEnd != nil {
+			end = *task.ActualEnd
+		}
+		if projectScheduleDay(end).Before(projectScheduleDay(start)) {
+			end = start
+		}
+
+		summary := task.Name
+		if task.IsMilestone {
+			summary = "Milestone: " + summary
+		}
+		if task.Area != "" {
+			summary = fmt.Sprintf("%s (%s)", summary, task.Area)
+		}
+
+		description := []string{
+			fmt.Sprintf("Project: %s", project.Name),
+			fmt.Sprintf("Planned: %s - %s", documentDate(task.Start), documentDate(task.End)),
+			fmt.Sprintf("Progress: %s%%", documentQuantity(task.Progress)),
+		}
+		if task.AssignedToName != "" {
+			description = append(description, "Assigned to: "+task.AssignedToName)
+		}
+		if task.IsDelayed {
+			description = append(description, fmt.Sprintf("Delayed by %d day(s)", task.EndDelayDays))
+		}
+
+		// DTEND is exclusive for all-day events
+		lines = append(lines,
+			"BEGIN:VEVENT",
+			fmt.Sprintf("UID:%s@renotech", task.ID.Hex()),
+			"DTSTAMP:"+stamp,
+			"DTSTART;VALUE=DATE:"+projectScheduleDay(start).Format("20060102"),
+			"DTEND;VALUE=DATE:"+projectScheduleDay(end).AddDate(0, 0, 1).Format("20060102"),
+			"SUMMARY:"+icsEscape(summary),
+			"DESCRIPTION:"+icsEscape(strings.Join(description, "\n")),
+		)
+		if task.Status == enum.ProjectTaskStatusCompleted {
+			lines = append(lines, "STATUS:CONFIRMED")
+		}
+		lines = append(lines, "END:VEVENT")
+	}
+
+	lines = append(lines, "END:VCALENDAR")
+
+	var builder strings.Builder
+	for _, line := range lines {
+		builder.WriteString(icsFold(line))
+		builder.WriteString("\r\n")
+	}
+
+	return []byte(builder.String())
+}
+
+func projectScheduleFilename(project *database.Project) string {
+	name := strings.TrimSpace(project.Name)
+	if name == "" {
+		name = project.ID.Hex()
+	}
+
+	replacer := strings.NewReplacer("/", "-", "\\", "-", "\"", "", " ", "_")
+	return replacer.Replace(name) + "_schedule.ics"
+}
+
+func icsEscape(value string) string {
+	replacer := strings.NewReplacer("\\", "\\\\", ";", "\\;", ",", "\\,", "\r\n", "\\n", "\n", "\\n")
+	return replacer.Replace(value)
+}
+
+// icsFold splits content lines longer than 75 octets without breaking UTF-8 sequences
+func icsFold(line string) string {
+	if len(line) <= 75 {
+		return line
+	}
+
+	var builder strings.Builder
+	lineLength := 0
+	for _, r := range line {
+		size := len(string(r))
+		if lineLength+size > 75 {
+			builder.WriteString("\r\n ")
+			lineLength = 1
+		}
+		builder.WriteRune(r)
+		lineLength += size
+	}
+
+	return builder.String()
+}
@@ -0,0 +1,491 @@
+package service
+
+import (
+	"context"
+	"math"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.uber.org/zap"
+	"renotech.com.my/internal/database"
+	"renotech.com.my/internal/enum"
+	"renotech.com.my/internal/model"
+	"renotech.com.my/internal/utils"
+)
+
+// projectTaskFields holds the editable task fields shared by create and update
+type projectTaskFields struct {
+	name            string
+	area            string
+	plannedStart    time.Time
+	plannedEnd      time.Time
+	actualStart     *time.Time
+	actualEnd       *time.Time
+	dependencies    []primitive.ObjectID
+	percentComplete float64
+	assignedTo      *primitive.ObjectID
+}
+
+// Tenant services
+func projectTaskCreateValidation(input *model.ProjectTaskCreateRequest, systemContext *model.SystemContext) error {
+	project, err := ProjectGetByID(input.Project, systemContext)
+	if err != nil {
+		return err
+	}
+
+	fields := projectTaskFields{
+		name:            input.Name,
+		area:            input.Area,
+		plannedStart:    input.PlannedStart,
+		plannedEnd:      input.PlannedEnd,
+		actualStart:     input.ActualStart,
+		actualEnd:       input.ActualEnd,
+		dependencies:    input.Dependencies,
+		percentComplete: input.PercentComplete,
+		assignedTo:      input.AssignedTo,
+	}
+
+	dependencies, err := validateProjectTaskFields(project, nil, &fields, systemContext)
+	if err != nil {
+		return err
+	}
+	input.Dependencies = dependencies
+
+	return nil
+}
+
+func ProjectTaskCreate(input *model.ProjectTaskCreateRequest, systemContext *model.SystemContext) (*database.ProjectTask, error) {
+	// Validate input
+	if err := projectTaskCreateValidation(input, systemContext); err != nil {
+		return nil, err
+	}
+
+	plannedEnd := input.PlannedEnd
+	if input.IsMilestone {
+		plannedEnd = input.PlannedStart
+	}
+
+	task := &database.ProjectTask{
+		Project:         input.Project,
+		Company:         systemContext.User.Company,
+		Name:            strings.TrimSpace(input.Name),
+		Description:     input.Description,
+		Area:            strings.TrimSpace(input.Area),
+		IsMilestone:     input.IsMilestone,
+		PlannedStart:    input.PlannedStart,
+		PlannedEnd:      plannedEnd,
+		ActualStart:     input.ActualStart,
+		ActualEnd:       input.ActualEnd,
+		Dependencies:    input.Dependencies,
+		PercentComplete: input.PercentComplete,
+		AssignedTo:      input.AssignedTo,
+		CreatedAt:       time.Now(),
+		CreatedBy:       *systemContext.User.ID,
+		UpdatedAt:       time.Now(),
+		UpdatedBy:       systemContext.User.ID,
+		IsDeleted:       false,
+	}
+
+	collection := systemContext.MongoDB.Collection("project_task")
+	result, err := collection.InsertOne(context.Background(), task)
+	if err != nil {
+		systemContext.Logger.Error("service.ProjectTaskCreate", zap.Error(err))
+		return nil, utils.SystemError(enum.ErrorCodeInternal, "Failed to create project task", nil)
+	}
+
+	return ProjectTaskGetByID(result.InsertedID.(primitive.ObjectID), systemContext)
+}
+
+func projectTaskUpdateValidation(input *model.ProjectTaskUpdateRequest, systemContext *model.SystemContext) error {
+	existing, err := ProjectTaskGetByID(input.ID, systemContext)
+	if err != nil {
+		return err
+	}
+
+	project, err := ProjectGetByID(existing.Project, systemContext)
+	if err != nil {
+		return err
+	}
+
+	fields := projectTaskFields{
+		name:            input.Name,
+		area:            input.Area,
+		plannedStart:    input.PlannedStart,
+		plannedEnd:      input.PlannedEnd,
+		actualStart:     input.ActualStart,
+		actualEnd:       input.ActualEnd,
+		dependencies:    input.Dependencies,
+		percentComplete: input.PercentComplete,
+		assignedTo:      input.AssignedTo,
+	}
+
+	dependencies, err := validateProjectTaskFields(project, &input.ID, &fields, systemContext)
+	if err != nil {
+		return err
+	}
+	input.Dependencies = dependencies
+
+	return nil
+}
+
+func ProjectTaskUpdate(input *model.ProjectTaskUpdateRequest, systemContext *model.SystemContext) (*database.ProjectTask, error) {
+	// Validate input
+	if err := projectTaskUpdateValidation(input, systemContext); err != nil {
+		return nil, err
+	}
+
+	plannedEnd := input.PlannedEnd
+	if input.IsMilestone {
+		plannedEnd = input.PlannedStart
+	}
+
+	collection := systemContext.MongoDB.Collection("project_task")
+
+	filter := bson.M{
+		"_id":       input.ID,
+		"company":   systemContext.User.Company,
+		"isDeleted": false,
+	}
+
+	update := bson.M{
+		"$set": bson.M{
+			"name":            strings.TrimSpace(input.Name),
+			"description":     input.Description,
+			"area":            strings.TrimSpace(input.Area),
+			"isMilestone":     input.IsMilestone,
+			"plannedStart":    input.PlannedStart,
+			"plannedEnd":      plannedEnd,
+			"actualStart":     input.ActualStart,
+			"actualEnd":       input.ActualEnd,
+			"dependencies":    input.Dependencies,
+			"percentComplete": input.PercentComplete,
+			"assignedTo":      input.AssignedTo,
+			"updatedAt":       time.Now(),
+			"updatedBy":       systemContext.User.ID,
+		},
+	}
+
+	_, err := collection.UpdateOne(context.Background(), filter, update)
+	if err != nil {
+		return nil, utils.SystemError(enum.ErrorCodeInternal, "Failed to update project task", nil)
+	}
+
+	return ProjectTaskGetByID(input.ID, systemContext)
+}
+
+func ProjectTaskGetByID(taskID primitive.ObjectID, systemContext *model.SystemContext) (*database.ProjectTask, error) {
+	collection := systemContext.MongoDB.Collection("project_task")
+
+	filter := bson.M{
+		"_id":       taskID,
+		"company":   systemContext.User.Company,
+		"isDeleted": false,
+	}
+
+	var doc database.ProjectTask
+	err := collection.FindOne(context.Background(), filter).Decode(&doc)
+	if err != nil {
+		return nil, utils.SystemError(enum.ErrorCodeNotFound, "Project task not found", nil)
+	}
+
+	return &doc, nil
+}
+
+func ProjectTaskList(input model.ProjectTaskListRequest, systemContext *model.SystemContext) (*model.ProjectTaskListResponse, error) {
+	collection := systemContext.MongoDB.Collection("project_task")
+
+	// Build base filter
+	filter := bson.M{"isDeleted": false, "company": systemContext.User.Company, "project": input.Project}
+
+	// Add field-specific filters
+	if strings.TrimSpace(input.Area) != "" {
+		filter["area"] = strings.TrimSpace(input.Area)
+	}
+	if input.AssignedTo != nil {
+		filter["assignedTo"] = input.AssignedTo
+	}
+
+	// Add global search filter
+	if strings.TrimSpace(input.Search) != "" {
+		searchRegex := primitive.Regex{Pattern: input.Search, Options: "i"}
+		filter["$or"] = []bson.M{
+			{"name": searchRegex},
+			{"description": searchRegex},
+		}
+	}
+
+	// Get total count
+	total, err := collection.CountDocuments(context.Background(), filter)
+	if err != nil {
+		systemContext.Logger.Error("service.ProjectTaskList", zap.Error(err))
+		return nil, utils.SystemError(enum.ErrorCodeInternal, "Failed to count project tasks", nil)
+	}
+
+	// Set default pagination values
+	page := input.Page
+	if page <= 0 {
+		page = 1
+	}
+	limit := input.Limit
+	if limit <= 0 {
+		limit = 10
+	}
+	if limit > 100 {
+		limit = 100 // Maximum limit
+	}
+
+	skip := (page - 1) * limit
+	totalPages := int(math.Ceil(float64(total) / float64(limit)))
+
+	var sortOptions bson.D
+	if len(input.Sort) > 0 {
+		for key, value := range input.Sort {
+			sortOptions = append(sortOptions, bson.E{Key: key, Value: value})
+		}
+	} else {
+		// Default sort by planned start, earliest first
+		sortOptions = bson.D{{Key: "plannedStart", Value: 1}}
+	}
+
+	findOptions := options.Find().
+		SetSkip(int64(skip)).
+		SetLimit(int64(limit)).
+		SetSort(sortOptions)
+
+	cursor, err := collection.Find(context.Background(), filter, findOptions)
+	if err != nil {
+		systemContext.Logger.Error("service.ProjectTaskList", zap.Error(err))
+		return nil, utils.SystemError(enum.ErrorCodeInternal, "Failed to retrieve project tasks", nil)
+	}
+	defer cursor.Close(context.Background())
+
+	var tasks []bson.M
+	if err = cursor.All(context.Background(), &tasks); err != nil {
+		systemContext.Logger.Error("service.ProjectTaskList", zap.Error(err))
+		return nil, utils.SystemError(enum.ErrorCodeInternal, "Failed to decode project tasks", nil)
+	}
+
+	return &model.ProjectTaskListResponse{
+		Data:       tasks,
+		Page:       page,
+		Limit:      limit,
+		Total:      total,
+		TotalPages: totalPages,
+	}, nil
+}
+
+// ProjectTaskDelete soft deletes a task and removes it from the dependencies of other tasks
+func ProjectTaskDelete(taskID primitive.ObjectID, systemContext *model.SystemContext) error {
+	task, err := ProjectTaskGetByID(taskID, systemContext)
+	if err != nil {
+		return err
+	}
+
+	collection := systemContext.MongoDB.Collection("project_task")
+
+	filter := bson.M{
+		"_id":       taskID,
+		"company":   systemContext.User.Company,
+		"isDeleted": false,
+	}
+
+	update := bson.M{
+		"$set": bson.M{
+			"isDeleted": true,
+			"updatedAt": time.Now(),
+			"updatedBy": systemContext.User.ID,
+		},
+	}
+
+	_, err = collection.UpdateOne(context.Background(), filter, update)
+	if err != nil {
+		return utils.SystemError(enum.ErrorCodeInternal, "Failed to delete project task", nil)
+	}
+
+	_, err = collection.UpdateMany(context.Background(), bson.M{
+		"project":      task.Project,
+		"company":      systemContext.User.Company,
+		"dependencies": taskID,
+	}, bson.M{
+		"$pull": bson.M{"dependencies": taskID},
+	})
+	if err != nil {
+		systemContext.Logger.Error("service.ProjectTaskDelete", zap.Error(err))
+	}
+
+	return nil
+}
+
+// Helper functions
+func validateProjectTaskFields(project *database.Project, taskID *primitive.ObjectID, fields *projectTaskFields, systemContext *model.SystemContext) ([]primitive.ObjectID, error) {
+	if strings.TrimSpace(fields.name) == "" {
+		return nil, utils.SystemError(enum.ErrorCodeValidation, "Task name is required", nil)
+	}
+
+	if fields.plannedEnd.Before(fields.plannedStart) {
+		return nil, utils.SystemError(enum.ErrorCodeValidation, "Planned end cannot be before planned start", nil)
+	}
+
+	if fields.actualEnd != nil && fields.actualStart == nil {
+		return nil, utils.SystemError(enum.ErrorCodeValidation, "Actual end requires an actual start", nil)
+	}
+
+	if fields.actualStart != nil && fields.actualEnd != nil && fields.actualEnd.Before(*fields.actualStart) {
+		return nil, utils.SystemError(enum.ErrorCodeValidation, "Actual end cannot be before actual start", nil)
+	}
+
+	if fields.percentComplete < 0 || fields.percentComplete > 100 {
+		return nil, utils.SystemError(
+			enum.ErrorCodeValidation,
+			"Percent complete must be between 0 and 100",
+			map[string]interface{}{"percentComplete": fields.percentComplete},
+		)
+	}
+
+	area := strings.TrimSpace(fields.area)
+	if area != "" {
+		found := false
+		for _, areaMaterial := range project.AreaMaterials {
+			if strings.TrimSpace(areaMaterial.Area.Name) == area {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			return nil, utils.SystemError(
+				enum.ErrorCodeValidation,
+				"Area not found in project",
+				map[string]interface{}{"area": area},
+			)
+		}
+	}
+
+	if fields.assignedTo != nil {
+		if _, _, err := validateProjectPIC([]primitive.ObjectID{*fields.assignedTo}, systemContext); err != nil {
+			return nil, utils.SystemError(enum.ErrorCodeValidation, "Assigned user must be an active user of your company", nil)
+		}
+	}
+
+	return validateProjectTaskDependencies(*project.ID, taskID, fields.dependencies, systemContext)
+}
+
+// validateProjectTaskDependencies removes duplicates, checks every dependency is a task of the same
+// project and rejects dependency cycles
+func validateProjectTaskDependencies(projectID primitive.ObjectID, taskID *primitive.ObjectID, dependencies []primitive.ObjectID, systemContext *model.SystemContext) ([]primitive.ObjectID, error) {
+	seen := make(map[primitive.ObjectID]bool)
+	unique := []primitive.ObjectID{}
+	for _, dependency := range dependencies {
+		if taskID != nil && dependency == *taskID {
+			return nil, utils.SystemError(enum.ErrorCodeValidation, "Task cannot depend on itself", nil)
+		}
+		if !seen[dependency] {
+			seen[dependency] = true
+			unique = append(unique, dependency)
+		}
+	}
+
+	if len(unique) == 0 {
+		return unique, nil
+	}
+
+	tasks, err := projectTasksForProject(projectID, systemContext)
+	if err != nil {
+		return nil, err
+	}
+
+	graph := make(map[primitive.ObjectID][]primitive.ObjectID)
+	for _, task := range tasks {
+		graph[*task.ID] = task.Dependencies
+	}
+
+	for _, dependency := range unique {
+		if _, ok := graph[dependency]; !ok {
+			return nil, utils.SystemError(
+				enum.ErrorCodeValidation,
+				"Dependency not found in project",
+				map[string]interface{}{"dependency": dependency.Hex()},
+			)
+		}
+	}
+
+	// A new task has no dependants, so it cannot close a cycle
+	if taskID == nil {
+		return unique, nil
+	}
+
+	graph[*taskID] = unique
+	if path := findProjectTaskCycle(*taskID, graph); path != nil {
+		names := make(map[primitive.ObjectID]string)
+		for _, task := range tasks {
+			names[*task.ID] = task.Name
+		}
+
+		cycle := make([]string, len(path))
+		for i, id := range path {
+			cycle[i] = names[id]
+		}
+
+		return nil, utils.SystemError(
+			enum.ErrorCodeValidation,
+			"Task dependencies form a cycle",
+			map[string]interface{}{"cycle": strings.Join(cycle, " -> ")},
+		)
+	}
+
+	return unique, nil
+}
+
+// findProjectTaskCycle walks dependencies from start and returns the path back to start, or nil
+func findProjectTaskCycle(start primitive.ObjectID, graph map[primitive.ObjectID][]primitive.ObjectID) []primitive.ObjectID {
+	visited := make(map[primitive.ObjectID]bool)
+
+	var walk func(node primitive.ObjectID, path []primitive.ObjectID) []primitive.ObjectID
+	walk = func(node primitive.ObjectID, path []primitive.ObjectID) []primitive.ObjectID {
+		for _, next := range graph[node] {
+			if next == start {
+				return append(path, next)
+			}
+			if visited[next] {
+				continue
+			}
+			visited[next] = true
+			if found := walk(next, append(path, next)); found != nil {
+				return found
+			}
+		}
+		return nil
+	}
+
+	return walk(start, []primitive.ObjectID{start})
+}
+
+func projectTasksForProject(projectID primitive.ObjectID, systemContext *model.SystemContext) ([]database.ProjectTask, error) {
+	collection := systemContext.MongoDB.Collection("project_task")
+
+	filter := bson.M{
+		"project":   projectID,
+		"company":   systemContext.User.Company,
+		"isDeleted": false,
+	}
+
+	findOptions := options.Find().SetSort(bson.D{{Key: "plannedStart", Value: 1}, {Key: "createdAt", Value: 1}})
+
+	cursor, err := collection.Find(context.Background(), filter, findOptions)
+	if err != nil {
+		systemContext.Logger.Error("service.projectTasksForProject", zap.Error(err))
+		return nil, utils.SystemError(enum.ErrorCodeInternal, "Failed to retrieve project tasks", nil)
+	}
+	defer cursor.Close(context.Background())
+
+	var tasks []database.ProjectTask
+	if err = cursor.All(context.Background(), &tasks); err != nil {
+		systemContext.Logger.Error("service.projectTasksForProject", zap.Error(err))
+		return nil, utils.SystemError(enum.ErrorCodeInternal, "Failed to decode project tasks", nil)
+	}
+
+	return tasks, nil
+}
@@ -3,7 +3,9 @@ package service
 import (
 	"context"
 	"fmt"
+	"html"
 	"math"
+	"math/big"
 	"strings"
 	"time"
 
@@ -54,7 +56,9 @@ func quotationCreateValidation(input *database.Quotation, systemContext *model.S
 	return nil
 }
 
-func QuotationCreate(input *database.Quotation, systemContext *model.SystemContext) (*database.Quotation, error) {
+// QuotationCreate saves a new quotation. Line, area and document totals are recalculated on the server;
+// the response reports the amounts from the request that disagreed.
+func QuotationCreate(input *database.Quotation, systemContext *model.SystemContext) (*model.QuotationSaveResponse, error) {
 	// Validate input
 	if err := quotationCreateValidation(input, systemContext); err != nil {
 		return nil, err
@@ -62,14 +66,25 @@ func QuotationCreate(input *database.Quotation, systemContext *model.SystemConte
 
 	collection := systemContext.MongoDB.Collection("quotation")
 
-	// Calculate totals
-	totalCharge, totalDiscount, totalAdditionalCharge, totalNettCharge := calculateQuotationTotals(input.AreaMaterials, input.Discounts, input.AdditionalCharges)
+	quotationNumber, err := DocumentNumberGenerate(enum.DocumentNumberTypeQuotation, systemContext)
+	if err != nil {
+		return nil, err
+	}
+
+	// Record the cost of each line for the margin analysis
+	if err := quotationSnapshotCosts(input.AreaMaterials, nil, systemContext); err != nil {
+		return nil, err
+	}
+
+	// Recalculate line, area and document totals
+	pricingReport := recalculateQuotationPricing(input, companyMoneyRounding(systemContext))
 
 	// Create quotation object
 	quotation := &database.Quotation{
 		Folder:                input.Folder,
 		Company:               systemContext.User.Company,
 		Name:                  input.Name,
+		QuotationNumber:       quotationNumber,
 		Client:                input.Client,
 		Budget:                input.Budget,
 		Address:               input.Address,
@@ -79,11 +94,14 @@ func QuotationCreate(input *database.Quotation, systemContext *model.SystemConte
 		AreaMaterials:         input.AreaMaterials,
 		Discounts:             input.Discounts,
 		AdditionalCharges:     input.AdditionalCharges,
-		TotalCharge:           totalCharge,
-		TotalDiscount:         totalDiscount,
-		TotalAdditionalCharge: totalAdditionalCharge,
-		TotalNettCharge:       totalNettCharge,
+		TotalCharge:           input.TotalCharge,
+		TotalDiscount:         input.TotalDiscount,
+		TotalAdditionalCharge: input.TotalAdditionalCharge,
+		TotalNettCharge:       input.TotalNettCharge,
+		Revision:              1,
+		RevisionReason:        quotationRevisionReason(input.RevisionReason, "Quotation created"),
 		IsStared:              input.IsStared,
+		Status:                enum.QuotationStatusDraft,
 		CreatedAt:             time.Now(),
 		CreatedBy:             *systemContext.User.ID,
 		UpdatedAt:             time.Now(),
@@ -104,7 +122,11 @@ func QuotationCreate(input *database.Quotation, systemContext *model.SystemConte
 		return nil, utils.SystemError(enum.ErrorCodeInternal, "Failed to retrieve quotation", nil)
 	}
 
-	return &doc, nil
+	if err := quotationRevisionCreate(&doc, systemContext); err != nil {
+		return nil, err
+	}
+
+	return &model.QuotationSaveResponse{Quotation: &doc, PricingReport: pricingReport}, nil
 }
 
 func quotationUpdateValidation(input *database.Quotation, systemContext *model.SystemContext) error {
@@ -159,7 +181,8 @@ func quotationUpdateValidation(input *database.Quotation, systemContext *model.S
 	return nil
 }
 
-func QuotationUpdate(input *database.Quotation, systemContext *model.SystemContext) (*database.Quotation, error) {
+// QuotationUpdate saves changes to a quotation, recalculating totals the same way as QuotationCreate
+func QuotationUpdate(input *database.Quotation, systemContext *model.SystemContext) (*model.QuotationSaveResponse, error) {
 	// Validate input
 	if err := quotationUpdateValidation(input, systemContext); err != nil {
 		return nil, err
@@ -180,8 +203,26 @@ func QuotationUpdate(input *database.Quotation, systemContext *model.SystemConte
 		return nil, utils.SystemError(enum.ErrorCodeNotFound, "quotation not found", nil)
 	}
 
-	// Calculate totals
-	totalCharge, totalDiscount, totalAdditionalCharge, totalNettCharge := calculateQuotationTotals(input.AreaMaterials, input.Discounts, input.AdditionalCharges)
+	if err := validateQuotationEditable(&doc); err != nil {
+		return nil, err
+	}
+
+	// Reject changes made on top of an older revision
+	if input.Revision != 0 && input.Revision != doc.Revision {
+		return nil, utils.SystemError(
+			enum.ErrorCodeValidation,
+			"Quotation was changed by another request, please reload",
+			map[string]interface{}{"revision": input.Revision, "currentRevision": doc.Revision},
+		)
+	}
+
+	// Keep the recorded cost of existing lines and record the cost of new ones
+	if err := quotationSnapshotCosts(input.AreaMaterials, doc.AreaMaterials, systemContext); err != nil {
+		return nil, err
+	}
+
+	// Recalculate line, area and document totals
+	pricingReport := recalculateQuotationPricing(input, companyMoneyRounding(systemContext))
 
 	// Build update object
 	updateFields := bson.M{
@@ -197,28 +238,21 @@ func QuotationUpdate(input *database.Quotation, systemContext *model.SystemConte
 		"discounts":             input.Discounts,
 		"media":                 input.Media,
 		"additionalCharges":     input.AdditionalCharges,
-		"totalCharge":           totalCharge,
-		"totalDiscount":         totalDiscount,
-		"totalAdditionalCharge": totalAdditionalCharge,
-		"totalNettCharge":       totalNettCharge,
+		"totalCharge":           input.TotalCharge,
+		"totalDiscount":         input.TotalDiscount,
+		"totalAdditionalCharge": input.TotalAdditionalCharge,
+		"totalNettCharge":       input.TotalNettCharge,
 		"updatedAt":             time.Now(),
 		"updatedBy":             systemContext.User.ID,
 	}
 
-	update := bson.M{"$set": updateFields}
-
-	_, err = collection.UpdateOne(context.Background(), filter, update)
-	if err != nil {
-		return nil, utils.SystemError(enum.ErrorCodeInternal, "failed to update quotation", nil)
-	}
-
-	// Return updated quotation
-	err = collection.FindOne(context.Background(), filter).Decode(&doc)
+	// Save as the next revision
+	updated, err := quotationSaveRevision(&doc, updateFields, quotationRevisionReason(input.RevisionReason, "Quotation updated"), systemContext)
 	if err != nil {
-		return nil, utils.SystemError(enum.ErrorCodeInternal, "Failed to retrieve updated quotation", nil)
+		return nil, err
 	}
 
-	return &doc, nil
+	return &model.QuotationSaveResponse{Quotation: updated, PricingReport: pricingReport}, nil
 }
 
 func QuotationGetByID(quotationID primitive.ObjectID, systemContext *model.SystemContext) (*database.Quotation, error) {
@@ -270,6 +304,21 @@ func QuotationList(input model.QuotationListRequest, systemContext *model.System
 	if input.IsStared != nil {
 		filter["isStared"] = *input.IsStared
 	}
+	if len(input.Status) > 0 {
+		filter["status"] = bson.M{"$in": input.Status}
+	}
+
+	// Add expiry date range filter
+	if input.ExpiresFrom != nil || input.ExpiresTo != nil {
+		expiryFilter := bson.M{}
+		if input.ExpiresFrom != nil {
+			expiryFilter["$gte"] = *input.ExpiresFrom
+		}
+		if input.ExpiresTo != nil {
+			expiryFilter["$lte"] = *input.ExpiresTo
+		}
+		filter["expiredAt"] = expiryFilter
+	}
 
 	// Add global search filter
 	if strings.TrimSpace(input.Search) != "" {
@@ -277,6 +326,7 @@ func QuotationList(input model.QuotationListRequest, systemContext *model.System
 		searchFilter := bson.M{
 			"$or": []bson.M{
 				{"name": searchRegex},
+				{"quotationNumber": searchRegex},
 				{"description": searchRegex},
 				{"remark": searchRegex},
 			},
@@ -430,6 +480,7 @@ func executeQuotationList(collection *mongo.Collection, filter bson.M, input mod
 		systemContext.Logger.Error("service.QuotationList", zap.Error(err))
 		return nil, utils.SystemError(enum.ErrorCodeInternal, "Failed to decode quotations", nil)
 	}
+	moneyDocuments(quotations)
 
 	response := &model.QuotationListResponse{
 		Data:       quotations,
@@ -528,14 +579,14 @@ func validateAreaMaterials(areaMaterials []database.SystemAreaMaterial, systemCo
 	return nil
 }
 
-func calculateQuotationTotals(areaMaterials []database.SystemAreaMaterial, discounts []database.SystemDiscount, additionalCharges []database.SystemAdditionalCharge) (float64, float64, float64, float64) {
-	var totalCharge float64
+func calculateQuotationTotals(areaMaterials []database.SystemAreaMaterial, discounts []database.SystemDiscount, additionalCharges []database.SystemAdditionalCharge, rounding enum.MoneyRounding) (database.Money, database.Money, database.Money, database.Money) {
+	exactCharge := new(big.Rat)
 
-	// Sum up area subtotals (use SubTotal values from payload as-is)
+	// Sum up area subtotals, calculated from the line items rather than trusting the payload
 	for i := range areaMaterials {
-		// Add area SubTotal to overall total charge
-		totalCharge += areaMaterials[i].SubTotal
+		exactCharge.Add(exactCharge, quotationAreaSubTotal(areaMaterials[i], rounding))
 	}
+	totalCharge := database.RoundMoney(exactCharge)
 
 	// Calculate total discounts
 	totalDiscount := calculateDiscounts(discounts, totalCharge)
@@ -544,21 +595,18 @@ func calculateQuotationTotals(areaMaterials []database.SystemAreaMaterial, disco
 	totalAdditionalCharge := calculateAdditionalCharges(additionalCharges, totalCharge)
 
 	// Calculate net charge (total charge - discount + additional charges)
-	totalNettCharge := totalCharge - totalDiscount + totalAdditionalCharge
-	if totalNettCharge < 0 {
-		totalNettCharge = 0
-	}
+	totalNettCharge := database.MaxMoney(totalCharge-totalDiscount+totalAdditionalCharge, 0)
 
 	return totalCharge, totalDiscount, totalAdditionalCharge, totalNettCharge
 }
 
-func calculateDiscounts(discounts []database.SystemDiscount, totalCharge float64) float64 {
-	var totalDiscount float64
+func calculateDiscounts(discounts []database.SystemDiscount, totalCharge database.Money) database.Money {
+	var totalDiscount database.Money
 
 	for _, discount := range discounts {
 		switch discount.Type {
 		case enum.DiscountTypeRate:
-			totalDiscount += totalCharge * (discount.Value / 100)
+			totalDiscount += totalCharge.Percent(discount.Value.Float64())
 		case enum.DiscountTypeAmount:
 			totalDiscount += discount.Value
 		}
@@ -567,13 +615,13 @@ func calculateDiscounts(discounts []database.SystemDiscount, totalCharge float64
 	return totalDiscount
 }
 
-func calculateAdditionalCharges(charges []database.SystemAdditionalCharge, totalCharge float64) float64 {
-	var totalAdditionalCharge float64
+func calculateAdditionalCharges(charges []database.SystemAdditionalCharge, totalCharge database.Money) database.Money {
+	var totalAdditionalCharge database.Money
 
 	for _, charge := range charges {
 		switch charge.Type {
 		case enum.AdditionalChargeTypeRate:
-			totalAdditionalCharge += totalCharge * (charge.Value / 100)
+			totalAdditionalCharge += totalCharge.Percent(charge.Value.Float64())
 		case enum.AdditionalChargeTypeAmount:
 			totalAdditionalCharge += charge.Value
 		}
@@ -582,6 +630,395 @@ func calculateAdditionalCharges(charges []database.SystemAdditionalCharge, total
 	return totalAdditionalCharge
 }
 
+// recalculateQuotationPricing replaces every line, area and document total of a quotation with the
+// server calculation and reports the client-sent amounts that disagreed
+func recalculateQuotationPricing(quotation *database.Quotation, rounding enum.MoneyRounding) model.QuotationPricingReport {
+	report := model.QuotationPricingReport{Issues: recalculateAreaMaterials(quotation.AreaMaterials, rounding)}
+
+	totalCharge, totalDiscount, totalAdditionalCharge, totalNettCharge := calculateQuotationTotals(quotation.AreaMaterials, quotation.Discounts, quotation.AdditionalCharges, rounding)
+
+	totals := []struct {
+		field      string
+		sent       database.Money
+		calculated database.Money
+	}{
+		{"totalCharge", quotation.TotalCharge, totalCharge},
+		{"totalDiscount", quotation.TotalDiscount, totalDiscount},
+		{"totalAdditionalCharge", quotation.TotalAdditionalCharge, totalAdditionalCharge},
+		{"totalNettCharge", quotation.TotalNettCharge, totalNettCharge},
+	}
+	for _, total := range totals {
+		if total.sent != total.calculated {
+			report.Issues = append(report.Issues, model.QuotationPricingIssue{
+				Path:       total.field,
+				Field:      total.field,
+				Sent:       total.sent,
+				Calculated: total.calculated,
+			})
+		}
+	}
+
+	quotation.TotalCharge = totalCharge
+	quotation.TotalDiscount = totalDiscount
+	quotation.TotalAdditionalCharge = totalAdditionalCharge
+	quotation.TotalNettCharge = totalNettCharge
+
+	report.IsConsistent = len(report.Issues) == 0
+	return report
+}
+
+// recalculateAreaMaterials rewrites line and area subtotals in place and returns the ones that changed
+func recalculateAreaMaterials(areaMaterials []database.SystemAreaMaterial, rounding enum.MoneyRounding) []model.QuotationPricingIssue {
+	issues := []model.QuotationPricingIssue{}
+
+	for i := range areaMaterials {
+		area := &areaMaterials[i]
+		areaPath := fmt.Sprintf("areaMaterials[%d]", i)
+
+		for j := range area.Materials {
+			issues = append(issues, recalculateMaterialDetail(&area.Materials[j], fmt.Sprintf("%s.materials[%d]", areaPath, j), area.Area.Name, rounding)...)
+		}
+
+		subTotal := database.RoundMoney(quotationAreaSubTotal(*area, rounding))
+		if area.SubTotal != subTotal {
+			issues = append(issues, model.QuotationPricingIssue{
+				Path:       areaPath + ".subTotal",
+				Area:       area.Area.Name,
+				Field:      "subTotal",
+				Sent:       area.SubTotal,
+				Calculated: subTotal,
+			})
+		}
+		area.SubTotal = subTotal
+	}
+
+	return issues
+}
+
+// recalculateMaterialDetail rewrites the subtotal of a line and its template children. A template
+// line is priced at the sum of its children, whose quantities are per one unit of the parent.
+func recalculateMaterialDetail(detail *database.SystemAreaMaterialDetail, path string, area string, rounding enum.MoneyRounding) []model.QuotationPricingIssue {
+	issues := []model.QuotationPricingIssue{}
+
+	for i := range detail.Template {
+		issues = append(issues, recalculateMaterialDetail(&detail.Template[i], fmt.Sprintf("%s.template[%d]", path, i), area, rounding)...)
+	}
+
+	check := func(field string, sent database.Money, calculated database.Money) {
+		if sent != calculated {
+			issues = append(issues, model.QuotationPricingIssue{
+				Path:       path + "." + field,
+				Area:       area,
+				Item:       detail.Name,
+				Field:      field,
+				Sent:       sent,
+				Calculated: calculated,
+			})
+		}
+	}
+
+	unitPrice, subTotal := quotationDetailAmounts(*detail, rounding)
+
+	pricePerUnit := database.RoundMoney(unitPrice)
+	check("pricePerUnit", detail.PricePerUnit, pricePerUnit)
+	detail.PricePerUnit = pricePerUnit
+
+	lineTotal := database.RoundMoney(subTotal)
+	check("subTotal", detail.SubTotal, lineTotal)
+	detail.SubTotal = lineTotal
+
+	return issues
+}
+
+// quotationAreaSubTotal is the area subtotal in sen, exact under document rounding
+func quotationAreaSubTotal(area database.SystemAreaMaterial, rounding enum.MoneyRounding) *big.Rat {
+	subTotal := new(big.Rat)
+	for _, detail := range area.Materials {
+		_, lineTotal := quotationDetailAmounts(detail, rounding)
+		subTotal.Add(subTotal, lineTotal)
+	}
+	return subTotal
+}
+
+// quotationDetailAmounts returns the unit price and subtotal of a line in sen. The unit price is the
+// line's own, or for a template line the cost of one unit built from its children. Under line rounding
+// every subtotal is rounded to the sen; under document rounding they are kept exact.
+func quotationDetailAmounts(detail database.SystemAreaMaterialDetail, rounding enum.MoneyRounding) (*big.Rat, *big.Rat) {
+	unitPrice := detail.PricePerUnit.Rat()
+	if len(detail.Template) > 0 {
+		unitPrice = new(big.Rat)
+		for _, child := range detail.Template {
+			_, childTotal := quotationDetailAmounts(child, rounding)
+			unitPrice.Add(unitPrice, childTotal)
+		}
+	}
+
+	subTotal := new(big.Rat).Mul(unitPrice, database.DecimalRat(detail.Quantity))
+	if rounding != enum.MoneyRoundingDocument {
+		subTotal = database.RoundMoney(subTotal).Rat()
+	}
+
+	return unitPrice, subTotal
+}
+
+// QuotationGeneratePDF renders a quotation through the company's quotation document template, or
+// through the given quotation template when templateID is set
+func QuotationGeneratePDF(quotationID primitive.ObjectID, templateID *primitive.ObjectID, systemContext *model.SystemContext) ([]byte, string, error) {
+	quotation, err := QuotationGetByID(quotationID, systemContext)
+	if err != nil {
+		return nil, "", err
+	}
+
+	company, err := CompanyTenantGet(systemContext)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if templateID == nil {
+		return DocumentTemplateGenerate("quotation", quotationDocumentData(quotation, company, systemContext), systemContext.User.Company, systemContext)
+	}
+
+	input, err := quotationTemplateRequest(quotation, company, *templateID, systemContext)
+	if err != nil {
+		return nil, "", err
+	}
+
+	pdfBuffer, filename, err := QuotationTemplateGenerate(input, systemContext)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return pdfBuffer, documentTemplateProcessFilename(filename, bson.M(input.Variables)), nil
+}
+
+// QuotationPreview renders the same HTML as QuotationGeneratePDF for display in the browser
+func QuotationPreview(quotationID primitive.ObjectID, templateID *primitive.ObjectID, systemContext *model.SystemContext) (string, error) {
+	quotation, err := QuotationGetByID(quotationID, systemContext)
+	if err != nil {
+		return "", err
+	}
+
+	company, err := CompanyTenantGet(systemContext)
+	if err != nil {
+		return "", err
+	}
+
+	if templateID == nil {
+		return DocumentTemplatePreview("quotation", quotationDocumentData(quotation, company, systemContext), systemContext.User.Company, systemContext)
+	}
+
+	input, err := quotationTemplateRequest(quotation, company, *templateID, systemContext)
+	if err != nil {
+		return "", err
+	}
+
+	return QuotationTemplatePreview(input, true, systemContext)
+}
+
+// quotationDocumentData maps a quotation into the quotation template payload.
+// Areas embed their materials, which in turn embed template children.
+func quotationDocumentData(quotation *database.Quotation, company *database.Company, systemContext *model.SystemContext) bson.M {
+	areas := make([]interface{}, len(quotation.AreaMaterials))
+	for i, areaMaterial := range quotation.AreaMaterials {
+		materials := make([]interface{}, len(areaMaterial.Materials))
+		for j, material := range areaMaterial.Materials {
+			materialData := quotationDocumentMaterial(material)
+			materialData["no"] = fmt.Sprintf("%d.%d", i+1, j+1)
+			materials[j] = materialData
+		}
+
+		areas[i] = map[string]interface{}{
+			"no":          i + 1,
+			"name":        html.EscapeString(areaMaterial.Area.Name),
+			"description": documentMultiline(areaMaterial.Area.Description),
+			"subTotal":    documentMoney(areaMaterial.SubTotal),
+			"materials":   materials,
+		}
+	}
+
+	discounts := make([]interface{}, len(quotation.Discounts))
+	for i, discount := range quotation.Discounts {
+		amount := discount.Value
+		value := documentMoney(amount)
+		if discount.Type == enum.DiscountTypeRate {
+			amount = quotation.TotalCharge.Percent(discount.Value.Float64())
+			value = documentQuantity(discount.Value.Float64()) + "%"
+		}
+
+		discounts[i] = map[string]interface{}{
+			"name":        html.EscapeString(discount.Name),
+			"description": documentMultiline(discount.Description),
+			"value":       value,
+			"amount":      documentMoney(amount),
+		}
+	}
+
+	additionalCharges := make([]interface{}, len(quotation.AdditionalCharges))
+	for i, charge := range quotation.AdditionalCharges {
+		amount := charge.Value
+		value := documentMoney(amount)
+		if charge.Type == enum.AdditionalChargeTypeRate {
+			amount = quotation.TotalCharge.Percent(charge.Value.Float64())
+			value = documentQuantity(charge.Value.Float64()) + "%"
+		}
+
+		additionalCharges[i] = map[string]interface{}{
+			"name":        html.EscapeString(charge.Name),
+			"description": documentMultiline(charge.Description),
+			"value":       value,
+			"amount":      documentMoney(amount),
+		}
+	}
+
+	quotationData := bson.M{
+		"quotationNumber":       html.EscapeString(quotation.QuotationNumber),
+		"name":                  html.EscapeString(quotation.Name),
+		"revision":              quotation.Revision,
+		"revisionLabel":         quotationRevisionLabel(quotation.Revision),
+		"date":                  documentDate(quotation.CreatedAt),
+		"expiredAt":             documentDate(quotation.ExpiredAt),
+		"description":           documentMultiline(quotation.Description),
+		"remark":                documentMultiline(quotation.Remark),
+		"clientName":            html.EscapeString(quotation.Client.Name),
+		"clientContact":         html.EscapeString(quotation.Client.Contact),
+		"clientEmail":           html.EscapeString(quotation.Client.Email),
+		"address":               documentAddress(quotation.Address),
+		"areas":                 areas,
+		"discounts":             discounts,
+		"additionalCharges":     additionalCharges,
+		"totalCharge":           documentMoney(quotation.TotalCharge),
+		"totalDiscount":         documentMoney(quotation.TotalDiscount),
+		"totalAdditionalCharge": documentMoney(quotation.TotalAdditionalCharge),
+		"totalNettCharge":       documentMoney(quotation.TotalNettCharge),
+		"termConditions":        documentStringList(company.TermCondition),
+	}
+
+	data := documentCompanyData(company, systemContext)
+	for key, value := range quotationData {
+		data[key] = value
+	}
+
+	return data
+}
+
+func quotationDocumentMaterial(material database.SystemAreaMaterialDetail) map[string]interface{} {
+	template := make([]interface{}, len(material.Template))
+	for i, child := range material.Template {
+		template[i] = quotationDocumentMaterial(child)
+	}
+
+	return map[string]interface{}{
+		"name":         html.EscapeString(material.Name),
+		"type":         string(material.Type),
+		"brand":        html.EscapeString(material.Brand),
+		"unit":         html.EscapeString(material.Unit),
+		"description":  documentMultiline(material.Description),
+		"remark":       documentMultiline(material.Remark),
+		"quantity":     documentQuantity(material.Quantity),
+		"pricePerUnit": documentMoney(material.PricePerUnit),
+		"subTotal":     documentMoney(material.SubTotal),
+		"template":     template,
+	}
+}
+
+// quotationTemplateRequest maps a quotation into a quotation template request. The scalar fields of the
+// document payload become template variables; variables the quotation has no value for are left blank.
+func quotationTemplateRequest(quotation *database.Quotation, company *database.Company, templateID primitive.ObjectID, systemContext *model.SystemContext) (*model.QuotationTemplatePreviewRequest, error) {
+	template, err := QuotationTemplateGetByID(templateID, systemContext)
+	if err != nil {
+		return nil, err
+	}
+
+	if template.Company != nil && (systemContext.User.Company == nil || *template.Company != *systemContext.User.Company) {
+		return nil, utils.SystemError(enum.ErrorCodeNotFound, "Quotation template not found", nil)
+	}
+
+	if !template.IsEnabled {
+		return nil, utils.SystemError(enum.ErrorCodeValidation, "Quotation template is disabled", nil)
+	}
+
+	variables := map[string]interface{}{}
+	for key, value := range quotationDocumentData(quotation, company, systemContext) {
+		switch value.(type) {
+		case string, int:
+			variables[key] = value
+		}
+	}
+	for _, variable := range template.VariableList {
+		if _, exists := variables[variable]; !exists {
+			variables[variable] = ""
+		}
+	}
+
+	areas := make([]model.QuotationTemplatePreviewArea, len(quotation.AreaMaterials))
+	for i, areaMaterial := range quotation.AreaMaterials {
+		items := make([]model.QuotationTemplatePreviewAreaItem, len(areaMaterial.Materials))
+		for j, material := range areaMaterial.Materials {
+			items[j] = model.QuotationTemplatePreviewAreaItem{
+				ItemNo:          fmt.Sprintf("%d.%d", i+1, j+1),
+				ItemName:        quotationTemplateItemName(material),
+				ItemDescription: quotationTemplateItemDescription(material),
+				ItemQuantity:    documentQuantity(material.Quantity),
+				ItemUnit:        html.EscapeString(material.Unit),
+				ItemUnitPrice:   documentMoney(material.PricePerUnit),
+				ItemTotalPrince: documentMoney(material.SubTotal),
+			}
+		}
+
+		areas[i] = model.QuotationTemplatePreviewArea{
+			AreaNameTitle:     fmt.Sprintf("%d", i+1),
+			AreaName:          html.EscapeString(areaMaterial.Area.Name),
+			AreaDetail:        documentMultiline(areaMaterial.Area.Description),
+			AreaItems:         items,
+			AreaSubTotalTitle: "Sub Total",
+			AreaSubTotal:      documentMoney(areaMaterial.SubTotal),
+		}
+	}
+
+	termConditions := []string{}
+	for _, condition := range company.TermCondition {
+		if strings.TrimSpace(condition) != "" {
+			termConditions = append(termConditions, documentMultiline(condition))
+		}
+	}
+
+	return &model.QuotationTemplatePreviewRequest{
+		TemplateID:     templateID,
+		Variables:      variables,
+		Areas:          areas,
+		TermConditions: termConditions,
+	}, nil
+}
+
+func quotationTemplateItemName(material database.SystemAreaMaterialDetail) string {
+	name := html.EscapeString(material.Name)
+	if strings.TrimSpace(material.Brand) != "" {
+		name += " (" + html.EscapeString(strings.TrimSpace(material.Brand)) + ")"
+	}
+	return name
+}
+
+// quotationTemplateItemDescription combines an item's description and remark, and lists the contents
+// of template materials. Template children are priced into their parent, so only quantities are shown.
+func quotationTemplateItemDescription(material database.SystemAreaMaterialDetail) string {
+	var lines []string
+	for _, text := range []string{material.Description, material.Remark} {
+		if strings.TrimSpace(text) != "" {
+			lines = append(lines, documentMultiline(text))
+		}
+	}
+
+	for _, child := range material.Template {
+		line := fmt.Sprintf("- %s: %s %s", quotationTemplateItemName(child), documentQuantity(child.Quantity), html.EscapeString(child.Unit))
+		lines = append(lines, strings.TrimSpace(line))
+		if len(child.Template) > 0 {
+			lines = append(lines, quotationTemplateItemDescription(database.SystemAreaMaterialDetail{Template: child.Template}))
+		}
+	}
+
+	return strings.Join(lines, "<br>")
+}
+
 func quotationCreateFolderValidation(input *model.QuotationCreateFolderRequest, systemContext *model.SystemContext) error {
 	collection := systemContext.MongoDB.Collection("quotation")
 
@@ -771,7 +1208,7 @@ func quotationDuplicateValidation(input *primitive.ObjectID, systemContext *mode
 	return nil
 }
 
-func QuotationDuplicate(input *primitive.ObjectID, systemContext *model.SystemContext) (*database.Quotation, error) {
+func QuotationDuplicate(input *primitive.ObjectID, systemContext *model.SystemContext) (*model.QuotationSaveResponse, error) {
 	// Validate input
 	if err := quotationDuplicateValidation(input, systemContext); err != nil {
 		return nil, err
@@ -790,18 +1227,25 @@ func QuotationDuplicate(input *primitive.ObjectID, systemContext *model.SystemCo
 		return nil, err
 	}
 
-	// Calculate totals
-	totalCharge, totalDiscount, totalAdditionalCharge, totalNettCharge := calculateQuotationTotals(
-		original.AreaMaterials,
-		original.Discounts,
-		original.AdditionalCharges,
-	)
+	quotationNumber, err := DocumentNumberGenerate(enum.DocumentNumberTypeQuotation, systemContext)
+	if err != nil {
+		return nil, err
+	}
+
+	// The copy is a new quotation, so its costs come from the current catalogue
+	if err := quotationSnapshotCosts(original.AreaMaterials, nil, systemContext); err != nil {
+		return nil, err
+	}
+
+	// Recalculate totals so the copy does not inherit amounts stored before server-side pricing
+	pricingReport := recalculateQuotationPricing(original, companyMoneyRounding(systemContext))
 
 	// Create new quotation with duplicated data
 	newQuotation := &database.Quotation{
 		Folder:                original.Folder,
 		Company:               systemContext.User.Company,
 		Name:                  uniqueName,
+		QuotationNumber:       quotationNumber,
 		Client:                original.Client,
 		Budget:                original.Budget,
 		Address:               original.Address,
@@ -811,12 +1255,15 @@ func QuotationDuplicate(input *primitive.ObjectID, systemContext *model.SystemCo
 		AreaMaterials:         original.AreaMaterials,
 		Discounts:             original.Discounts,
 		AdditionalCharges:     original.AdditionalCharges,
-		TotalCharge:           totalCharge,
-		TotalDiscount:         totalDiscount,
-		TotalAdditionalCharge: totalAdditionalCharge,
-		TotalNettCharge:       totalNettCharge,
+		TotalCharge:           original.TotalCharge,
+		TotalDiscount:         original.TotalDiscount,
+		TotalAdditionalCharge: original.TotalAdditionalCharge,
+		TotalNettCharge:       original.TotalNettCharge,
+		Revision:              1,
+		RevisionReason:        fmt.Sprintf("Duplicated from %s revision %s", quotationReference(original), quotationRevisionLabel(original.Revision)),
 		Media:                 original.Media,
 		IsStared:              false, // Reset star status
+		Status:                enum.QuotationStatusDraft,
 		CreatedAt:             time.Now(),
 		CreatedBy:             *systemContext.User.ID,
 		UpdatedAt:             time.Now(),
@@ -838,5 +1285,9 @@ func QuotationDuplicate(input *primitive.ObjectID, systemContext *model.SystemCo
 		return nil, utils.SystemError(enum.ErrorCodeInternal, "Failed to retrieve duplicated quotation", nil)
 	}
 
-	return &duplicatedDoc, nil
+	if err := quotationRevisionCreate(&duplicatedDoc, systemContext); err != nil {
+		return nil, err
+	}
+
+	return &model.QuotationSaveResponse{Quotation: &duplicatedDoc, PricingReport: pricingReport}, nil
 }
@@ -0,0 +1,247 @@
+package service
+
+import (
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"renotech.com.my/internal/database"
+	"renotech.com.my/internal/enum"
+	"renotech.com.my/internal/model"
+)
+
+// Tenant services
+
+// QuotationAnalysis reports cost, price, markup and margin per line, per area and for the whole
+// quotation, using the costs snapshotted when each line was quoted
+func QuotationAnalysis(quotationID primitive.ObjectID, systemContext *model.SystemContext) (*model.QuotationAnalysisResponse, error) {
+	quotation, err := QuotationGetByID(quotationID, systemContext)
+	if err != nil {
+		return nil, err
+	}
+
+	company, err := CompanyTenantGet(systemContext)
+	if err != nil {
+		return nil, err
+	}
+
+	rounding := companyMoneyRounding(systemContext)
+	minimumMargin := company.MinimumMarginPercent
+
+	response := &model.QuotationAnalysisResponse{
+		Quotation:            *quotation.ID,
+		Revision:             quotation.Revision,
+		MinimumMarginPercent: minimumMargin,
+		Areas:                []model.QuotationAreaAnalysis{},
+		Warnings:             []model.QuotationMarginWarning{},
+	}
+
+	totalCost := newMoneyTotal(rounding)
+	for i, areaMaterial := range quotation.AreaMaterials {
+		areaCost := newMoneyTotal(rounding)
+		lines := make([]model.QuotationLineAnalysis, len(areaMaterial.Materials))
+
+		for j, material := range areaMaterial.Materials {
+			cost := areaCost.addLine(material.CostPerUnit, material.Quantity)
+			line := model.QuotationLineAnalysis{
+				No:              fmt.Sprintf("%d.%d", i+1, j+1),
+				Path:            fmt.Sprintf("areaMaterials[%d].materials[%d]", i, j),
+				Material:        material.Material,
+				Name:            material.Name,
+				Unit:            material.Unit,
+				Quantity:        material.Quantity,
+				CostPerUnit:     material.CostPerUnit,
+				PricePerUnit:    material.PricePerUnit,
+				QuotationMargin: quotationMargin(cost, material.SubTotal),
+			}
+			lines[j] = line
+
+			if warning := quotationLineMarginWarning(line, areaMaterial.Area.Name, minimumMargin); warning != nil {
+				response.Warnings = append(response.Warnings, *warning)
+			}
+		}
+
+		totalCost.addTotal(areaCost)
+		response.Areas = append(response.Areas, model.QuotationAreaAnalysis{
+			Area:   areaMaterial.Area.Name,
+			Lines:  lines,
+			Totals: quotationMargin(areaCost.value(), areaMaterial.SubTotal),
+		})
+	}
+
+	response.Overall = quotationMargin(totalCost.value(), quotation.TotalCharge)
+	response.Nett = quotationMargin(totalCost.value(), quotation.TotalNettCharge)
+
+	// Discounts can take the whole quotation below cost or the minimum even when every line is fine
+	if response.Nett.Cost > 0 {
+		switch {
+		case response.Nett.Price < response.Nett.Cost:
+			response.Warnings = append(response.Warnings, model.QuotationMarginWarning{
+				Type:          enum.QuotationMarginWarningBelowCost,
+				MarginPercent: response.Nett.MarginPercent,
+				Message:       fmt.Sprintf("Nett charge %s is below cost %s", documentMoney(response.Nett.Price), documentMoney(response.Nett.Cost)),
+			})
+		case minimumMargin > 0 && response.Nett.MarginPercent < minimumMargin:
+			response.Warnings = append(response.Warnings, model.QuotationMarginWarning{
+				Type:          enum.QuotationMarginWarningBelowMinimum,
+				MarginPercent: response.Nett.MarginPercent,
+				Message:       fmt.Sprintf("Nett margin %s%% is below the minimum of %s%%", documentQuantity(response.Nett.MarginPercent), documentQuantity(minimumMargin)),
+			})
+		}
+	}
+
+	return response, nil
+}
+
+// Helper functions
+
+// quotationMargin compares a cost with the price charged for it
+func quotationMargin(cost database.Money, price database.Money) model.QuotationMargin {
+	profit := price - cost
+
+	margin := model.QuotationMargin{
+		Cost:   cost,
+		Price:  price,
+		Profit: profit,
+	}
+	if cost != 0 {
+		margin.MarkupPercent = roundPercent(profit.Float64() / cost.Float64() * 100)
+	}
+	if price != 0 {
+		margin.MarginPercent = roundPercent(profit.Float64() / price.Float64() * 100)
+	}
+
+	return margin
+}
+
+func quotationLineMarginWarning(line model.QuotationLineAnalysis, area string, minimumMargin float64) *model.QuotationMarginWarning {
+	if line.Quantity <= 0 {
+		return nil
+	}
+
+	warning := &model.QuotationMarginWarning{
+		Path:          line.Path,
+		Area:          area,
+		Item:          line.Name,
+		MarginPercent: line.MarginPercent,
+	}
+
+	switch {
+	case line.CostPerUnit == 0:
+		warning.Type = enum.QuotationMarginWarningMissingCost
+		warning.MarginPercent = 0
+		warning.Message = fmt.Sprintf("%s has no cost recorded", line.Name)
+	case line.Price < line.Cost:
+		warning.Type = enum.QuotationMarginWarningBelowCost
+		warning.Message = fmt.Sprintf("%s is priced at %s per %s, below its cost of %s", line.Name, documentMoney(line.PricePerUnit), line.Unit, documentMoney(line.CostPerUnit))
+	case minimumMargin > 0 && line.MarginPercent < minimumMargin:
+		warning.Type = enum.QuotationMarginWarningBelowMinimum
+		warning.Message = fmt.Sprintf("%s has a margin of %s%%, below the minimum of %s%%", line.Name, documentQuantity(line.MarginPercent), documentQuantity(minimumMargin))
+	default:
+		return nil
+	}
+
+	return warning
+}
+
+// quotationCostResolver fills in the cost of quotation lines. Lines already on the quotation keep the
+// cost recorded when they were first quoted; new lines take the current catalogue cost.
+type quotationCostResolver struct {
+	previous  map[primitive.ObjectID]database.Money
+	catalogue *orderInitCollector
+}
+
+// quotationSnapshotCosts records the cost per unit of every line. previous holds the area materials
+// already stored on the quotation, or nil for a new quotation.
+func quotationSnapshotCosts(areaMaterials []database.SystemAreaMaterial, previous []database.SystemAreaMaterial, systemContext *model.SystemContext) error {
+	resolver := &quotationCostResolver{
+		previous: make(map[primitive.ObjectID]database.Money),
+		catalogue: &orderInitCollector{
+			materialCache: make(map[primitive.ObjectID]*database.Material),
+			systemContext: systemContext,
+		},
+	}
+
+	for _, areaMaterial := range previous {
+		for _, detail := range areaMaterial.Materials {
+			resolver.remember(detail)
+		}
+	}
+
+	for i := range areaMaterials {
+		for j := range areaMaterials[i].Materials {
+			if err := resolver.snapshot(&areaMaterials[i].Materials[j]); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (r *quotationCostResolver) remember(detail database.SystemAreaMaterialDetail) {
+	if len(detail.Template) > 0 {
+		for _, child := range detail.Template {
+			r.remember(child)
+		}
+		return
+	}
+
+	// Lines saved before costs were recorded take the catalogue cost
+	if detail.Material != nil && detail.CostPerUnit != 0 {
+		r.previous[*detail.Material] = detail.CostPerUnit
+	}
+}
+
+// snapshot sets the cost of a line. Template costs add up their children per unit of the parent,
+// the same way template prices do.
+func (r *quotationCostResolver) snapshot(detail *database.SystemAreaMaterialDetail) error {
+	if len(detail.Template) > 0 {
+		total := newMoneyTotal(enum.MoneyRoundingDocument)
+		for i := range detail.Template {
+			if err := r.snapshot(&detail.Template[i]); err != nil {
+				return err
+			}
+			total.addLine(detail.Template[i].CostPerUnit, detail.Template[i].Quantity)
+		}
+		detail.CostPerUnit = total.value()
+		return nil
+	}
+
+	// Free-text lines keep the cost entered by the estimator
+	if detail.Material == nil {
+		return nil
+	}
+
+	if cost, exists := r.previous[*detail.Material]; exists {
+		detail.CostPerUnit = cost
+		return nil
+	}
+
+	material, err := r.catalogue.getMaterial(*detail.Material)
+	if err != nil {
+		return err
+	}
+	if material == nil {
+		detail.CostPerUnit = 0
+		return nil
+	}
+
+	// Catalogue templates without snapshotted children cost the sum of their components
+	if material.Type == enum.MaterialTypeTemplate && len(material.Template) > 0 {
+		total := newMoneyTotal(enum.MoneyRoundingDocument)
+		for _, templateItem := range material.Template {
+			component, err := r.catalogue.getMaterial(templateItem.Material)
+			if err != nil {
+				return err
+			}
+			if component != nil {
+				total.addLine(component.CostPerUnit, templateItem.DefaultQuantity)
+			}
+		}
+		detail.CostPerUnit = total.value()
+		return nil
+	}
+
+	detail.CostPerUnit = material.CostPerUnit
+	return nil
+}
@@ -0,0 +1,445 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.uber.org/zap"
+	"renotech.com.my/internal/database"
+	"renotech.com.my/internal/enum"
+	"renotech.com.my/internal/model"
+	"renotech.com.my/internal/utils"
+)
+
+// quotationImportColumns maps normalised bill of quantities headers to the columns they fill
+var quotationImportColumns = map[string]string{
+	"area":           "area",
+	"location":       "area",
+	"section":        "area",
+	"zone":           "area",
+	"room":           "area",
+	"item":           "item",
+	"items":          "item",
+	"item name":      "item",
+	"name":           "item",
+	"material":       "item",
+	"description":    "description",
+	"desc":           "description",
+	"details":        "description",
+	"specification":  "description",
+	"unit":           "unit",
+	"uom":            "unit",
+	"quantity":       "quantity",
+	"qty":            "quantity",
+	"unit price":     "unitPrice",
+	"price":          "unitPrice",
+	"rate":           "unitPrice",
+	"unit rate":      "unitPrice",
+	"price per unit": "unitPrice",
+}
+
+const (
+	// quotationImportMatchScore is the lowest similarity at which an item is linked to a catalogue material
+	quotationImportMatchScore = 0.75
+	// quotationImportMaxRows limits the item rows read from one file
+	quotationImportMaxRows = 2000
+	// quotationImportDefaultArea is used for items listed before any area is named
+	quotationImportDefaultArea = "General"
+)
+
+// quotationImportLine is a valid bill of quantities row ready to be added to the quotation
+type quotationImportLine struct {
+	area   string
+	detail database.SystemAreaMaterialDetail
+}
+
+func quotationImportValidation(input *model.QuotationImportRequest, content []byte) error {
+	extension := strings.ToLower(filepath.Ext(input.FileName))
+	if extension != ".csv" && extension != ".xlsx" {
+		return utils.SystemError(
+			enum.ErrorCodeValidation,
+			"Only .csv and .xlsx files can be imported",
+			map[string]interface{}{"filename": input.FileName},
+		)
+	}
+
+	if len(content) == 0 {
+		return utils.SystemError(enum.ErrorCodeValidation, "File is empty", nil)
+	}
+
+	input.Name = strings.TrimSpace(input.Name)
+	if input.Name == "" {
+		input.Name = strings.TrimSpace(strings.TrimSuffix(filepath.Base(input.FileName), filepath.Ext(input.FileName)))
+	}
+	if input.Name == "" {
+		input.Name = "Imported quotation"
+	}
+
+	return nil
+}
+
+// QuotationImport creates a draft quotation from a CSV or XLSX bill of quantities. Item names are
+// matched against the company material catalogue; rows that cannot be read are left out and reported.
+func QuotationImport(input *model.QuotationImportRequest, content []byte, systemContext *model.SystemContext) (*model.QuotationImportResponse, error) {
+	// Validate input
+	if err := quotationImportValidation(input, content); err != nil {
+		return nil, err
+	}
+
+	// One row more than the item limit leaves room for the header
+	rows, err := spreadsheetReadRows(input.FileName, content, quotationImportMaxRows+1)
+	if err != nil {
+		return nil, utils.SystemError(
+			enum.ErrorCodeValidation,
+			"Failed to read file",
+			map[string]interface{}{"details": err.Error()},
+		)
+	}
+
+	columns, err := quotationImportHeader(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	catalogue, err := quotationImportCatalogue(systemContext)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &model.QuotationImportResponse{Rows: []model.QuotationImportRow{}}
+	lines := []quotationImportLine{}
+	area := quotationImportDefaultArea
+
+	for _, row := range rows[1:] {
+		cell := func(column string) string {
+			index, exists := columns[column]
+			if !exists || index >= len(row.Cells) {
+				return ""
+			}
+			return strings.TrimSpace(row.Cells[index])
+		}
+
+		// Rows with a blank area belong to the area above them, as bills of quantities usually only name it once
+		if name := cell("area"); name != "" {
+			area = name
+		}
+
+		report, line := quotationImportRow(row.Number, area, cell, catalogue)
+		response.Rows = append(response.Rows, report)
+		if line != nil {
+			lines = append(lines, *line)
+		}
+	}
+
+	response.Summary = quotationImportSummary(response.Rows)
+
+	if len(lines) == 0 {
+		return nil, utils.SystemError(
+			enum.ErrorCodeValidation,
+			"File has no rows that can be imported",
+			map[string]interface{}{"rows": response.Rows},
+		)
+	}
+
+	result, err := QuotationCreate(&database.Quotation{
+		Name:           input.Name,
+		Folder:         input.Folder,
+		AreaMaterials:  quotationImportAreaMaterials(lines),
+		RevisionReason: fmt.Sprintf("Imported from %s", filepath.Base(input.FileName)),
+	}, systemContext)
+	if err != nil {
+		return nil, err
+	}
+
+	response.Quotation = result.Quotation
+	return response, nil
+}
+
+// Helper functions
+
+// quotationImportHeader takes the first row as the header and finds the cell index of each recognised column
+func quotationImportHeader(rows []spreadsheetRow) (map[string]int, error) {
+	if len(rows) == 0 {
+		return nil, utils.SystemError(enum.ErrorCodeValidation, "File has no rows", nil)
+	}
+
+	header := rows[0]
+	columns := make(map[string]int)
+	for index, name := range header.Cells {
+		column, exists := quotationImportColumns[quotationImportNormalise(name)]
+		if !exists {
+			continue
+		}
+		if _, duplicate := columns[column]; !duplicate {
+			columns[column] = index
+		}
+	}
+
+	for _, required := range []string{"item", "quantity"} {
+		if _, exists := columns[required]; !exists {
+			return nil, utils.SystemError(
+				enum.ErrorCodeValidation,
+				"File must have item and quantity columns",
+				map[string]interface{}{
+					"row":     header.Number,
+					"headers": header.Cells,
+					"columns": []string{"area", "item", "description", "unit", "quantity", "unit price"},
+				},
+			)
+		}
+	}
+
+	return columns, nil
+}
+
+// quotationImportRow reads one item row. The line is nil when the row cannot be imported.
+func quotationImportRow(number int, area string, cell func(string) string, catalogue []database.Material) (model.QuotationImportRow, *quotationImportLine) {
+	report := model.QuotationImportRow{
+		Row:      number,
+		Area:     area,
+		Item:     cell("item"),
+		Messages: []string{},
+	}
+
+	if report.Item == "" {
+		report.Messages = append(report.Messages, "Item is required")
+	}
+
+	quantity, err := strconv.ParseFloat(quotationImportCleanNumber(cell("quantity")), 64)
+	switch {
+	case cell("quantity") == "":
+		report.Messages = append(report.Messages, "Quantity is required")
+	case err != nil || math.IsInf(quantity, 0) || math.IsNaN(quantity):
+		report.Messages = append(report.Messages, fmt.Sprintf("Quantity %q is not a number", cell("quantity")))
+	case quantity <= 0:
+		report.Messages = append(report.Messages, "Quantity must be greater than 0")
+	}
+
+	var price database.Money
+	hasPrice := cell("unitPrice") != ""
+	if hasPrice {
+		price, err = database.ParseDecimalMoney(quotationImportCleanNumber(cell("unitPrice")))
+		switch {
+		case err != nil:
+			report.Messages = append(report.Messages, fmt.Sprintf("Unit price %q is not an amount", cell("unitPrice")))
+		case price < 0:
+			report.Messages = append(report.Messages, "Unit price cannot be negative")
+		}
+	}
+
+	if len(report.Messages) > 0 {
+		report.Status = enum.QuotationImportRowInvalid
+		return report, nil
+	}
+
+	detail := database.SystemAreaMaterialDetail{
+		Template:     []database.SystemAreaMaterialDetail{},
+		Name:         report.Item,
+		Unit:         cell("unit"),
+		PricePerUnit: price,
+		Quantity:     quantity,
+		Description:  cell("description"),
+	}
+
+	material, score := quotationImportMatch(report.Item, catalogue)
+	if material == nil {
+		report.Status = enum.QuotationImportRowUnmatched
+		report.Messages = append(report.Messages, "No matching material in the catalogue, imported as a free-text item")
+		if !hasPrice {
+			report.Messages = append(report.Messages, "No unit price given, priced at 0")
+		}
+		return report, &quotationImportLine{area: area, detail: detail}
+	}
+
+	report.Status = enum.QuotationImportRowMatched
+	report.Material = material.ID
+	report.MaterialName = material.Name
+	report.Score = roundPercent(score)
+
+	// The bill of quantities wording is kept, the catalogue fills in what the row leaves out
+	detail.Material = material.ID
+	detail.Type = material.Type
+	detail.Brand = material.Brand
+	if detail.Unit == "" {
+		detail.Unit = material.Unit
+	} else if material.Unit != "" && !strings.EqualFold(detail.Unit, material.Unit) {
+		report.Messages = append(report.Messages, fmt.Sprintf("Unit %s differs from the catalogue unit %s", detail.Unit, material.Unit))
+	}
+	if detail.Description == "" {
+		detail.Description = material.Description
+	}
+	if !hasPrice {
+		detail.PricePerUnit = material.PricePerUnit
+		report.Messages = append(report.Messages, "No unit price given, catalogue price used")
+	}
+
+	return report, &quotationImportLine{area: area, detail: detail}
+}
+
+// quotationImportAreaMaterials groups lines by area in the order each area first appears
+func quotationImportAreaMaterials(lines []quotationImportLine) []database.SystemAreaMaterial {
+	areaMaterials := []database.SystemAreaMaterial{}
+	areaIndex := make(map[string]int)
+
+	for _, line := range lines {
+		key := strings.ToLower(line.area)
+		index, exists := areaIndex[key]
+		if !exists {
+			index = len(areaMaterials)
+			areaIndex[key] = index
+			areaMaterials = append(areaMaterials, database.SystemAreaMaterial{
+				Area:      database.SystemArea{Name: line.area},
+				Materials: []database.SystemAreaMaterialDetail{},
+			})
+		}
+		areaMaterials[index].Materials = append(areaMaterials[index].Materials, line.detail)
+	}
+
+	return areaMaterials
+}
+
+func quotationImportSummary(rows []model.QuotationImportRow) model.QuotationImportCount {
+	summary := model.QuotationImportCount{Total: len(rows)}
+	for _, row := range rows {
+		switch row.Status {
+		case enum.QuotationImportRowMatched:
+			summary.Matched++
+		case enum.QuotationImportRowUnmatched:
+			summary.Unmatched++
+		case enum.QuotationImportRowInvalid:
+			summary.Invalid++
+		}
+	}
+	return summary
+}
+
+// quotationImportCatalogue loads the active materials that imported items can be matched against
+func quotationImportCatalogue(systemContext *model.SystemContext) ([]database.Material, error) {
+	filter := bson.M{
+		"company":   systemContext.User.Company,
+		"status":    enum.MaterialStatusActive,
+		"isDeleted": false,
+	}
+
+	opts := options.Find().SetProjection(bson.M{
+		"name":              1,
+		"clientDisplayName": 1,
+		"type":              1,
+		"brand":             1,
+		"unit":              1,
+		"pricePerUnit":      1,
+		"description":       1,
+	})
+
+	cursor, err := systemContext.MongoDB.Collection("material").Find(context.Background(), filter, opts)
+	if err != nil {
+		systemContext.Logger.Error("service.quotationImportCatalogue", zap.Error(err))
+		return nil, utils.SystemError(enum.ErrorCodeInternal, "Failed to load materials", nil)
+	}
+	defer cursor.Close(context.Background())
+
+	var materials []database.Material
+	if err := cursor.All(context.Background(), &materials); err != nil {
+		systemContext.Logger.Error("service.quotationImportCatalogue", zap.Error(err))
+		return nil, utils.SystemError(enum.ErrorCodeInternal, "Failed to decode materials", nil)
+	}
+
+	return materials, nil
+}
+
+// quotationImportMatch returns the catalogue material most similar to an item name by its name or
+// client display name, or nil when none reaches quotationImportMatchScore
+func quotationImportMatch(item string, catalogue []database.Material) (*database.Material, float64) {
+	target := quotationImportNormalise(item)
+	if target == "" {
+		return nil, 0
+	}
+
+	var best *database.Material
+	bestScore := 0.0
+	for i := range catalogue {
+		for _, name := range []string{catalogue[i].Name, catalogue[i].ClientDisplayName} {
+			score := quotationImportSimilarity(target, quotationImportNormalise(name))
+			if score > bestScore {
+				best = &catalogue[i]
+				bestScore = score
+			}
+		}
+	}
+
+	if bestScore < quotationImportMatchScore {
+		return nil, 0
+	}
+	return best, bestScore
+}
+
+// quotationImportSimilarity is the Dice coefficient of the character pairs of two normalised names,
+// which tolerates reordered words, plurals and small typos
+func quotationImportSimilarity(a string, b string) float64 {
+	if a == "" || b == "" {
+		return 0
+	}
+	if a == b {
+		return 1
+	}
+
+	pairsA := quotationImportPairs(a)
+	pairsB := quotationImportPairs(b)
+	if len(pairsA) == 0 || len(pairsB) == 0 {
+		return 0
+	}
+
+	counts := make(map[string]int)
+	for _, pair := range pairsA {
+		counts[pair]++
+	}
+
+	shared := 0
+	for _, pair := range pairsB {
+		if counts[pair] > 0 {
+			counts[pair]--
+			shared++
+		}
+	}
+
+	return float64(2*shared) / float64(len(pairsA)+len(pairsB))
+}
+
+// quotationImportPairs lists the adjacent character pairs within each word
+func quotationImportPairs(value string) []string {
+	pairs := []string{}
+	for _, word := range strings.Fields(value) {
+		runes := []rune(word)
+		if len(runes) == 1 {
+			pairs = append(pairs, word)
+			continue
+		}
+		for i := 0; i < len(runes)-1; i++ {
+			pairs = append(pairs, string(runes[i:i+2]))
+		}
+	}
+	return pairs
+}
+
+// quotationImportNormalise lowercases a name and reduces punctuation and repeated spaces to single spaces
+func quotationImportNormalise(value string) string {
+	return strings.Join(strings.FieldsFunc(strings.ToLower(value), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}), " ")
+}
+
+// quotationImportCleanNumber drops the currency prefix and thousands separators spreadsheets often keep
+func quotationImportCleanNumber(value string) string {
+	value = strings.TrimSpace(value)
+	if len(value) >= 2 && strings.EqualFold(value[:2], "RM") {
+		value = value[2:]
+	}
+	return strings.NewReplacer(",", "", " ", "").Replace(value)
+}
@@ -0,0 +1,612 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.uber.org/zap"
+	"renotech.com.my/internal/database"
+	"renotech.com.my/internal/enum"
+	"renotech.com.my/internal/model"
+	"renotech.com.my/internal/utils"
+)
+
+var quotationRevisionIndex = &indexOnce{}
+
+// Tenant services
+
+// QuotationRevisionList lists the revisions of a quotation, oldest first, without their snapshots
+func QuotationRevisionList(quotationID primitive.ObjectID, systemContext *model.SystemContext) ([]model.QuotationRevisionSummary, error) {
+	quotation, err := QuotationGetByID(quotationID, systemContext)
+	if err != nil {
+		return nil, err
+	}
+
+	collection := systemContext.MongoDB.Collection("quotation_revision")
+
+	filter := bson.M{
+		"quotation": quotationID,
+		"company":   systemContext.User.Company,
+	}
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "revision", Value: 1}}).
+		SetProjection(bson.M{
+			"revision":                 1,
+			"label":                    1,
+			"reason":                   1,
+			"snapshot.totalNettCharge": 1,
+			"createdAt":                1,
+			"createdBy":                1,
+			"createdByName":            1,
+		})
+
+	cursor, err := collection.Find(context.Background(), filter, opts)
+	if err != nil {
+		systemContext.Logger.Error("service.QuotationRevisionList", zap.Error(err))
+		return nil, utils.SystemError(enum.ErrorCodeInternal, "Failed to retrieve quotation revisions", nil)
+	}
+	defer cursor.Close(context.Background())
+
+	var revisions []database.QuotationRevision
+	if err = cursor.All(context.Background(), &revisions); err != nil {
+		systemContext.Logger.Error("service.QuotationRevisionList", zap.Error(err))
+		return nil, utils.SystemError(enum.ErrorCodeInternal, "Failed to decode quotation revisions", nil)
+	}
+
+	summaries := make([]model.QuotationRevisionSummary, len(revisions))
+	for i := range revisions {
+		summaries[i] = quotationRevisionSummary(&revisions[i], quotation.Revision)
+	}
+
+	return summaries, nil
+}
+
+// QuotationRevisionGet returns one revision with its full snapshot
+func QuotationRevisionGet(quotationID primitive.ObjectID, revision int, systemContext *model.SystemContext) (*database.QuotationRevision, error) {
+	collection := systemContext.MongoDB.Collection("quotation_revision")
+
+	filter := bson.M{
+		"quotation": quotationID,
+		"company":   systemContext.User.Company,
+		"revision":  revision,
+	}
+
+	var doc database.QuotationRevision
+	err := collection.FindOne(context.Background(), filter).Decode(&doc)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, utils.SystemError(
+				enum.ErrorCodeNotFound,
+				"Quotation revision not found",
+				map[string]interface{}{"revision": revision},
+			)
+		}
+		systemContext.Logger.Error("service.QuotationRevisionGet", zap.Error(err))
+		return nil, utils.SystemError(enum.ErrorCodeInternal, "Failed to retrieve quotation revision", nil)
+	}
+
+	return &doc, nil
+}
+
+// QuotationRevisionDiff compares two revisions of a quotation. A to revision of 0 compares against
+// the current revision.
+func QuotationRevisionDiff(quotationID primitive.ObjectID, from int, to int, systemContext *model.SystemContext) (*model.QuotationRevisionDiffResponse, error) {
+	quotation, err := QuotationGetByID(quotationID, systemContext)
+	if err != nil {
+		return nil, err
+	}
+
+	if to == 0 {
+		to = quotation.Revision
+	}
+
+	if from <= 0 || from == to {
+		return nil, utils.SystemError(
+			enum.ErrorCodeValidation,
+			"Select two different revisions to compare",
+			map[string]interface{}{"from": from, "to": to},
+		)
+	}
+
+	fromRevision, err := QuotationRevisionGet(quotationID, from, systemContext)
+	if err != nil {
+		return nil, err
+	}
+
+	toRevision, err := QuotationRevisionGet(quotationID, to, systemContext)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &model.QuotationRevisionDiffResponse{
+		Quotation:   quotationID,
+		From:        quotationRevisionSummary(fromRevision, quotation.Revision),
+		To:          quotationRevisionSummary(toRevision, quotation.Revision),
+		Fields:      quotationFieldChanges(&fromRevision.Snapshot, &toRevision.Snapshot),
+		Areas:       quotationAreaChanges(fromRevision.Snapshot.AreaMaterials, toRevision.Snapshot.AreaMaterials),
+		Adjustments: quotationAdjustmentChanges(&fromRevision.Snapshot, &toRevision.Snapshot),
+		Totals:      []model.QuotationRevisionTotalChange{},
+	}
+
+	totals := []struct {
+		field string
+		from  database.Money
+		to    database.Money
+	}{
+		{"totalCharge", fromRevision.Snapshot.TotalCharge, toRevision.Snapshot.TotalCharge},
+		{"totalDiscount", fromRevision.Snapshot.TotalDiscount, toRevision.Snapshot.TotalDiscount},
+		{"totalAdditionalCharge", fromRevision.Snapshot.TotalAdditionalCharge, toRevision.Snapshot.TotalAdditionalCharge},
+		{"totalNettCharge", fromRevision.Snapshot.TotalNettCharge, toRevision.Snapshot.TotalNettCharge},
+	}
+	for _, total := range totals {
+		response.Totals = append(response.Totals, model.QuotationRevisionTotalChange{
+			Field: total.field,
+			From:  total.from,
+			To:    total.to,
+			Delta: total.to - total.from,
+		})
+	}
+
+	return response, nil
+}
+
+// QuotationRevisionRestore saves the content of an earlier revision as a new revision. The quotation
+// keeps its current name, folder and number.
+func QuotationRevisionRestore(quotationID primitive.ObjectID, revision int, input *model.QuotationRevisionRestoreRequest, systemContext *model.SystemContext) (*database.Quotation, error) {
+	quotation, err := QuotationGetByID(quotationID, systemContext)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := validateQuotationEditable(quotation); err != nil {
+		return nil, err
+	}
+
+	if revision == quotation.Revision {
+		return nil, utils.SystemError(
+			enum.ErrorCodeValidation,
+			"Revision is already the current revision",
+			map[string]interface{}{"revision": revision},
+		)
+	}
+
+	source, err := QuotationRevisionGet(quotationID, revision, systemContext)
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot := source.Snapshot
+	fields := bson.M{
+		"client":                snapshot.Client,
+		"budget":                snapshot.Budget,
+		"address":               snapshot.Address,
+		"expiredAt":             snapshot.ExpiredAt,
+		"description":           snapshot.Description,
+		"remark":                snapshot.Remark,
+		"areaMaterials":         snapshot.AreaMaterials,
+		"discounts":             snapshot.Discounts,
+		"additionalCharges":     snapshot.AdditionalCharges,
+		"media":                 snapshot.Media,
+		"totalCharge":           snapshot.TotalCharge,
+		"totalDiscount":         snapshot.TotalDiscount,
+		"totalAdditionalCharge": snapshot.TotalAdditionalCharge,
+		"totalNettCharge":       snapshot.TotalNettCharge,
+		"updatedAt":             time.Now(),
+		"updatedBy":             systemContext.User.ID,
+	}
+
+	reason := fmt.Sprintf("Restored from revision %s", source.Label)
+	if extra := strings.TrimSpace(input.Reason); extra != "" {
+		reason += ": " + extra
+	}
+
+	return quotationSaveRevision(quotation, fields, reason, systemContext)
+}
+
+// Helper functions
+
+// quotationSaveRevision applies a change to a quotation as its next revision and stores the snapshot.
+// The update only applies on top of the revision it was made from, so concurrent saves cannot
+// overwrite each other.
+func quotationSaveRevision(current *database.Quotation, fields bson.M, reason string, systemContext *model.SystemContext) (*database.Quotation, error) {
+	collection := systemContext.MongoDB.Collection("quotation")
+
+	revision := current.Revision + 1
+	reason = strings.TrimSpace(reason)
+
+	fields["revision"] = revision
+	fields["revisionReason"] = reason
+
+	description := fmt.Sprintf("Revision %s saved", quotationRevisionLabel(revision))
+	if reason != "" {
+		description += ": " + reason
+	}
+
+	filter := bson.M{
+		"_id":       current.ID,
+		"company":   systemContext.User.Company,
+		"revision":  current.Revision,
+		"status":    current.Status,
+		"isDeleted": false,
+	}
+
+	update := bson.M{
+		"$set": fields,
+		"$push": bson.M{
+			"actionLogs": newSystemActionLog(description, systemContext),
+		},
+	}
+
+	result, err := collection.UpdateOne(context.Background(), filter, update)
+	if err != nil {
+		systemContext.Logger.Error("service.quotationSaveRevision", zap.Error(err))
+		return nil, utils.SystemError(enum.ErrorCodeInternal, "Failed to update quotation", nil)
+	}
+
+	if result.MatchedCount == 0 {
+		return nil, utils.SystemError(enum.ErrorCodeValidation, "Quotation was changed by another request, please reload", nil)
+	}
+
+	var doc database.Quotation
+	err = collection.FindOne(context.Background(), bson.M{"_id": current.ID}).Decode(&doc)
+	if err != nil {
+		return nil, utils.SystemError(enum.ErrorCodeInternal, "Failed to retrieve updated quotation", nil)
+	}
+
+	if err := quotationRevisionCreate(&doc, systemContext); err != nil {
+		return nil, err
+	}
+
+	return &doc, nil
+}
+
+// quotationRevisionCreate stores the snapshot of a quotation's current revision
+func quotationRevisionCreate(quotation *database.Quotation, systemContext *model.SystemContext) error {
+	collection := systemContext.MongoDB.Collection("quotation_revision")
+	ensureQuotationRevisionIndex(collection, systemContext)
+
+	_, err := collection.InsertOne(context.Background(), newQuotationRevision(quotation, *systemContext.User.ID, systemContext.User.Username, time.Now()))
+	if err != nil {
+		systemContext.Logger.Error("service.quotationRevisionCreate", zap.Error(err))
+		return utils.SystemError(enum.ErrorCodeInternal, "Failed to save quotation revision", nil)
+	}
+
+	return nil
+}
+
+func newQuotationRevision(quotation *database.Quotation, createdBy primitive.ObjectID, createdByName string, createdAt time.Time) *database.QuotationRevision {
+	snapshot := *quotation
+	snapshot.ActionLogs = nil
+
+	return &database.QuotationRevision{
+		Quotation:     *quotation.ID,
+		Company:       quotation.Company,
+		Revision:      quotation.Revision,
+		Label:         quotationRevisionLabel(quotation.Revision),
+		Reason:        quotation.RevisionReason,
+		Snapshot:      snapshot,
+		CreatedAt:     createdAt,
+		CreatedBy:     createdBy,
+		CreatedByName: createdByName,
+	}
+}
+
+// ensureQuotationRevisionIndex makes revision numbers unique per quotation
+func ensureQuotationRevisionIndex(collection *mongo.Collection, systemContext *model.SystemContext) {
+	quotationRevisionIndex.Do(func() error {
+		_, err := collection.Indexes().CreateOne(context.Background(), mongo.IndexModel{
+			Keys:    bson.D{{Key: "quotation", Value: 1}, {Key: "revision", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		})
+		if err != nil {
+			systemContext.Logger.Error("service.ensureQuotationRevisionIndex", zap.Error(err))
+		}
+		return err
+	})
+}
+
+func quotationRevisionReason(reason string, fallback string) string {
+	if reason = strings.TrimSpace(reason); reason != "" {
+		return reason
+	}
+	return fallback
+}
+
+// quotationRevisionLabel turns a revision number into its printable label: A, B, ... Z, AA, AB
+func quotationRevisionLabel(revision int) string {
+	label := ""
+	for revision > 0 {
+		revision--
+		label = string(rune('A'+revision%26)) + label
+		revision /= 26
+	}
+	return label
+}
+
+func quotationRevisionSummary(revision *database.QuotationRevision, currentRevision int) model.QuotationRevisionSummary {
+	return model.QuotationRevisionSummary{
+		Revision:        revision.Revision,
+		Label:           revision.Label,
+		Reason:          revision.Reason,
+		TotalNettCharge: revision.Snapshot.TotalNettCharge,
+		IsCurrent:       revision.Revision == currentRevision,
+		CreatedAt:       revision.CreatedAt,
+		CreatedBy:       revision.CreatedBy,
+		CreatedByName:   revision.CreatedByName,
+	}
+}
+
+// quotationFieldChanges lists the changed header fields of a quotation, formatted as printed
+func quotationFieldChanges(from *database.Quotation, to *database.Quotation) []model.QuotationRevisionFieldChange {
+	changes := []model.QuotationRevisionFieldChange{}
+
+	fields := []struct {
+		field string
+		from  string
+		to    string
+	}{
+		{"clientName", from.Client.Name, to.Client.Name},
+		{"clientContact", from.Client.Contact, to.Client.Contact},
+		{"clientEmail", from.Client.Email, to.Client.Email},
+		{"address", quotationAddressText(from.Address), quotationAddressText(to.Address)},
+		{"budget", documentMoney(from.Budget), documentMoney(to.Budget)},
+		{"expiredAt", documentDate(from.ExpiredAt), documentDate(to.ExpiredAt)},
+		{"description", from.Description, to.Description},
+		{"remark", from.Remark, to.Remark},
+	}
+	for _, field := range fields {
+		if field.from != field.to {
+			changes = append(changes, model.QuotationRevisionFieldChange{Field: field.field, From: field.from, To: field.to})
+		}
+	}
+
+	return changes
+}
+
+func quotationAddressText(address database.SystemAddress) string {
+	parts := []string{}
+	for _, part := range []string{address.Line1, address.Line2, address.Line3, address.Postcode, address.City, address.State} {
+		if part = strings.TrimSpace(part); part != "" {
+			parts = append(parts, part)
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// quotationAreaChanges matches areas by name and lists those added, removed or with changed lines
+func quotationAreaChanges(from []database.SystemAreaMaterial, to []database.SystemAreaMaterial) []model.QuotationRevisionAreaChange {
+	changes := []model.QuotationRevisionAreaChange{}
+
+	fromAreas := make(map[string]database.SystemAreaMaterial)
+	for _, area := range from {
+		fromAreas[strings.TrimSpace(area.Area.Name)] = area
+	}
+
+	seen := make(map[string]bool)
+	for _, toArea := range to {
+		name := strings.TrimSpace(toArea.Area.Name)
+		seen[name] = true
+
+		fromArea, exists := fromAreas[name]
+		if !exists {
+			changes = append(changes, model.QuotationRevisionAreaChange{
+				Area:       name,
+				Change:     "added",
+				ToSubTotal: toArea.SubTotal,
+				Delta:      toArea.SubTotal,
+				Lines:      quotationLineChanges(nil, toArea.Materials),
+			})
+			continue
+		}
+
+		lines := quotationLineChanges(fromArea.Materials, toArea.Materials)
+		if len(lines) == 0 && fromArea.SubTotal == toArea.SubTotal {
+			continue
+		}
+
+		changes = append(changes, model.QuotationRevisionAreaChange{
+			Area:         name,
+			Change:       "changed",
+			FromSubTotal: fromArea.SubTotal,
+			ToSubTotal:   toArea.SubTotal,
+			Delta:        toArea.SubTotal - fromArea.SubTotal,
+			Lines:        lines,
+		})
+	}
+
+	for _, fromArea := range from {
+		name := strings.TrimSpace(fromArea.Area.Name)
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+
+		changes = append(changes, model.QuotationRevisionAreaChange{
+			Area:         name,
+			Change:       "removed",
+			FromSubTotal: fromArea.SubTotal,
+			Delta:        -fromArea.SubTotal,
+			Lines:        quotationLineChanges(fromArea.Materials, nil),
+		})
+	}
+
+	return changes
+}
+
+// quotationLineChanges matches the lines of an area by material, or by name and unit for free-text
+// lines. Repeated lines are matched in order of appearance.
+func quotationLineChanges(from []database.SystemAreaMaterialDetail, to []database.SystemAreaMaterialDetail) []model.QuotationRevisionLineChange {
+	changes := []model.QuotationRevisionLineChange{}
+
+	fromKeys := quotationLineKeys(from)
+	fromLines := make(map[string]database.SystemAreaMaterialDetail)
+	for i, line := range from {
+		fromLines[fromKeys[i]] = line
+	}
+
+	seen := make(map[string]bool)
+	for i, toKey := range quotationLineKeys(to) {
+		toLine := to[i]
+		seen[toKey] = true
+
+		fromLine, exists := fromLines[toKey]
+		if !exists {
+			changes = append(changes, quotationLineChange("added", nil, &toLine))
+			continue
+		}
+
+		if math.Abs(fromLine.Quantity-toLine.Quantity) > quantityTolerance ||
+			fromLine.PricePerUnit != toLine.PricePerUnit ||
+			fromLine.SubTotal != toLine.SubTotal {
+			changes = append(changes, quotationLineChange("changed", &fromLine, &toLine))
+		}
+	}
+
+	for i, fromKey := range fromKeys {
+		if !seen[fromKey] {
+			fromLine := from[i]
+			changes = append(changes, quotationLineChange("removed", &fromLine, nil))
+		}
+	}
+
+	return changes
+}
+
+func quotationLineKeys(lines []database.SystemAreaMaterialDetail) []string {
+	keys := make([]string, len(lines))
+	occurrences := make(map[string]int)
+
+	for i, line := range lines {
+		key := strings.ToLower(strings.TrimSpace(line.Name) + "|" + strings.TrimSpace(line.Unit))
+		if line.Material != nil {
+			key = line.Material.Hex()
+		}
+		occurrences[key]++
+		keys[i] = fmt.Sprintf("%s#%d", key, occurrences[key])
+	}
+
+	return keys
+}
+
+func quotationLineChange(change string, from *database.SystemAreaMaterialDetail, to *database.SystemAreaMaterialDetail) model.QuotationRevisionLineChange {
+	line := from
+	if to != nil {
+		line = to
+	}
+
+	result := model.QuotationRevisionLineChange{
+		Item:     line.Name,
+		Unit:     line.Unit,
+		Material: line.Material,
+		Change:   change,
+	}
+
+	if from != nil {
+		result.FromQuantity = from.Quantity
+		result.FromPricePerUnit = from.PricePerUnit
+		result.FromSubTotal = from.SubTotal
+	}
+	if to != nil {
+		result.ToQuantity = to.Quantity
+		result.ToPricePerUnit = to.PricePerUnit
+		result.ToSubTotal = to.SubTotal
+	}
+	result.Delta = result.ToSubTotal - result.FromSubTotal
+
+	return result
+}
+
+// quotationAdjustmentChanges lists discounts and additional charges added, removed or changed, matched by name
+func quotationAdjustmentChanges(from *database.Quotation, to *database.Quotation) []model.QuotationRevisionAdjustmentChange {
+	type adjustment struct {
+		name   string
+		kind   string
+		value  database.Money
+		method string
+	}
+
+	collect := func(quotation *database.Quotation) []adjustment {
+		adjustments := []adjustment{}
+		for _, discount := range quotation.Discounts {
+			adjustments = append(adjustments, adjustment{discount.Name, "discount", discount.Value, string(discount.Type)})
+		}
+		for _, charge := range quotation.AdditionalCharges {
+			adjustments = append(adjustments, adjustment{charge.Name, "additionalCharge", charge.Value, string(charge.Type)})
+		}
+		return adjustments
+	}
+
+	keys := func(adjustments []adjustment) []string {
+		result := make([]string, len(adjustments))
+		occurrences := make(map[string]int)
+		for i, item := range adjustments {
+			key := item.kind + "|" + strings.ToLower(strings.TrimSpace(item.name))
+			occurrences[key]++
+			result[i] = fmt.Sprintf("%s#%d", key, occurrences[key])
+		}
+		return result
+	}
+
+	fromAdjustments := collect(from)
+	fromKeys := keys(fromAdjustments)
+	fromIndex := make(map[string]adjustment)
+	for i, item := range fromAdjustments {
+		fromIndex[fromKeys[i]] = item
+	}
+
+	changes := []model.QuotationRevisionAdjustmentChange{}
+	seen := make(map[string]bool)
+
+	toAdjustments := collect(to)
+	for i, key := range keys(toAdjustments) {
+		toItem := toAdjustments[i]
+		seen[key] = true
+
+		fromItem, exists := fromIndex[key]
+		if !exists {
+			changes = append(changes, model.QuotationRevisionAdjustmentChange{
+				Kind:    toItem.kind,
+				Name:    toItem.name,
+				Change:  "added",
+				ToType:  toItem.method,
+				ToValue: toItem.value,
+			})
+			continue
+		}
+
+		if fromItem.method != toItem.method || fromItem.value != toItem.value {
+			changes = append(changes, model.QuotationRevisionAdjustmentChange{
+				Kind:      toItem.kind,
+				Name:      toItem.name,
+				Change:    "changed",
+				FromType:  fromItem.method,
+				ToType:    toItem.method,
+				FromValue: fromItem.value,
+				ToValue:   toItem.value,
+			})
+		}
+	}
+
+	for i, key := range fromKeys {
+		if !seen[key] {
+			fromItem := fromAdjustments[i]
+			changes = append(changes, model.QuotationRevisionAdjustmentChange{
+				Kind:      fromItem.kind,
+				Name:      fromItem.name,
+				Change:    "removed",
+				FromType:  fromItem.method,
+				FromValue: fromItem.value,
+			})
+		}
+	}
+
+	return changes
+}
@@ -0,0 +1,594 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"html"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.uber.org/zap"
+	"renotech.com.my/internal/database"
+	"renotech.com.my/internal/enum"
+	"renotech.com.my/internal/model"
+	"renotech.com.my/internal/utils"
+)
+
+const (
+	quotationSignatureDataPrefix = "data:image/png;base64,"
+	quotationSignatureMaxBytes   = 512 * 1024
+
+	// Limits on change requests sent through a share link, which needs no login
+	quotationChangeRequestMaxLength      = 2000
+	quotationChangeRequestMaxOpen        = 10 // per revision; staff clear them by sending a new revision
+	quotationChangeRequestInterval       = time.Minute
+	quotationChangeRequestNotifyInterval = time.Hour
+)
+
+// Tenant services
+
+// QuotationShareCreate issues a client link to a quotation. Sharing a draft sends it.
+func QuotationShareCreate(quotationID primitive.ObjectID, input *model.DocumentShareCreateRequest, systemContext *model.SystemContext) (*model.DocumentShareResponse, error) {
+	quotation, err := QuotationGetByID(quotationID, systemContext)
+	if err != nil {
+		return nil, err
+	}
+
+	switch quotation.Status {
+	case enum.QuotationStatusDraft:
+		if _, err := quotationTransitionStatus(quotation, enum.QuotationStatusSent, "Shared with client", systemContext); err != nil {
+			return nil, err
+		}
+	case enum.QuotationStatusSent, enum.QuotationStatusAccepted:
+	default:
+		return nil, utils.SystemError(
+			enum.ErrorCodeValidation,
+			fmt.Sprintf("Cannot share a %s quotation, move it back to draft first", quotation.Status),
+			map[string]interface{}{"status": quotation.Status},
+		)
+	}
+
+	share, err := documentShareCreate(enum.DocumentShareTypeQuotation, quotationID, input, "/client/quotation", systemContext)
+	if err != nil {
+		return nil, err
+	}
+
+	appendQuotationActionLog(quotationID, fmt.Sprintf("Client link created, expires %s", documentDate(share.ExpiresAt)), systemContext)
+
+	return share, nil
+}
+
+func QuotationShareRevoke(quotationID primitive.ObjectID, systemContext *model.SystemContext) error {
+	if _, err := QuotationGetByID(quotationID, systemContext); err != nil {
+		return err
+	}
+
+	revoked, err := documentShareRevokeAll(enum.DocumentShareTypeQuotation, quotationID, systemContext)
+	if err != nil {
+		return err
+	}
+
+	if revoked > 0 {
+		appendQuotationActionLog(quotationID, "Client link revoked", systemContext)
+	}
+
+	return nil
+}
+
+// Public services, authorised by share token instead of JWT
+func QuotationClientGet(token string, systemContext *model.SystemContext) (*model.QuotationClientView, error) {
+	share, quotation, err := quotationClientResolve(token, true, systemContext)
+	if err != nil {
+		return nil, err
+	}
+
+	company, err := CompanyTenantGet(systemContext)
+	if err != nil {
+		return nil, err
+	}
+
+	return quotationClientView(quotation, company, share), nil
+}
+
+func QuotationClientPreview(token string, systemContext *model.SystemContext) (string, error) {
+	_, quotation, err := quotationClientResolve(token, false, systemContext)
+	if err != nil {
+		return "", err
+	}
+
+	company, err := CompanyTenantGet(systemContext)
+	if err != nil {
+		return "", err
+	}
+
+	return DocumentTemplatePreview("quotation", quotationDocumentData(quotation, company, systemContext), systemContext.User.Company, systemContext)
+}
+
+func quotationClientRespondValidation(input *model.QuotationClientRespondRequest, quotation *database.Quotation) error {
+	if !quotationClientCanRespond(quotation) {
+		return utils.SystemError(
+			enum.ErrorCodeValidation,
+			"Quotation is no longer awaiting your response",
+			map[string]interface{}{"status": quotation.Status},
+		)
+	}
+
+	if strings.TrimSpace(input.Name) == "" {
+		return utils.SystemError(enum.ErrorCodeValidation, "Name is required", nil)
+	}
+
+	switch input.Action {
+	case enum.QuotationClientActionAccept:
+		if strings.TrimSpace(input.Signature) == "" {
+			return utils.SystemError(enum.ErrorCodeValidation, "Signature is required when accepting a quotation", nil)
+		}
+	case enum.QuotationClientActionRequestChanges:
+		if strings.TrimSpace(input.Message) == "" {
+			return utils.SystemError(enum.ErrorCodeValidation, "Message is required when requesting changes", nil)
+		}
+		if len([]rune(strings.TrimSpace(input.Message))) > quotationChangeRequestMaxLength {
+			return utils.SystemError(
+				enum.ErrorCodeValidation,
+				fmt.Sprintf("Message cannot be longer than %d characters", quotationChangeRequestMaxLength),
+				nil,
+			)
+		}
+	default:
+		return utils.SystemError(
+			enum.ErrorCodeValidation,
+			"Invalid response action",
+			map[string]interface{}{"action": input.Action},
+		)
+	}
+
+	return nil
+}
+
+// QuotationClientRespond records the client's acceptance or change request. Accepting stores the
+// signature as media and moves the quotation to accepted; the acceptance cannot be changed afterwards.
+func QuotationClientRespond(token string, input *model.QuotationClientRespondRequest, clientIP string, systemContext *model.SystemContext) (*model.QuotationClientView, error) {
+	share, quotation, err := quotationClientResolve(token, false, systemContext)
+	if err != nil {
+		return nil, err
+	}
+
+	// Validate input
+	if err := quotationClientRespondValidation(input, quotation); err != nil {
+		return nil, err
+	}
+
+	name := strings.TrimSpace(input.Name)
+	systemContext.User.Username = "Client: " + name
+
+	// Change requests following closely on another are recorded but do not email staff again
+	notify := input.Action != enum.QuotationClientActionRequestChanges || !quotationChangeRequestedSince(quotation, time.Now().Add(-quotationChangeRequestNotifyInterval))
+
+	switch input.Action {
+	case enum.QuotationClientActionAccept:
+		quotation, err = quotationClientAccept(quotation, share, name, input.Signature, clientIP, systemContext)
+	default:
+		quotation, err = quotationClientRequestChanges(quotation, share, name, strings.TrimSpace(input.Message), clientIP, systemContext)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if notify {
+		notifyQuotationClientResponse(quotation, input.Action, name, strings.TrimSpace(input.Message), systemContext)
+	}
+
+	company, err := CompanyTenantGet(systemContext)
+	if err != nil {
+		return nil, err
+	}
+
+	return quotationClientView(quotation, company, share), nil
+}
+
+// Helper functions
+func quotationClientResolve(token string, countView bool, systemContext *model.SystemContext) (*database.DocumentShare, *database.Quotation, error) {
+	share, err := documentShareResolve(token, enum.DocumentShareTypeQuotation, "Client", countView, systemContext)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	quotation, err := QuotationGetByID(share.Document, systemContext)
+	if err != nil {
+		return nil, nil, utils.SystemError(enum.ErrorCodeUnauthorized, "Invalid or expired link", nil)
+	}
+
+	return share, quotation, nil
+}
+
+// quotationClientCanRespond reports whether the client may still accept or request changes
+func quotationClientCanRespond(quotation *database.Quotation) bool {
+	if quotation.Status != enum.QuotationStatusSent {
+		return false
+	}
+	return quotation.ExpiredAt.IsZero() || quotation.ExpiredAt.After(time.Now())
+}
+
+func quotationClientAccept(quotation *database.Quotation, share *database.DocumentShare, name string, signature string, clientIP string, systemContext *model.SystemContext) (*database.Quotation, error) {
+	if err := validateQuotationStatusTransition(quotation.Status, enum.QuotationStatusAccepted); err != nil {
+		return nil, err
+	}
+
+	media, err := quotationSignatureSave(quotation, signature, systemContext)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	acceptance := database.QuotationAcceptance{
+		Name:            name,
+		IPAddress:       clientIP,
+		Signature:       media.Path,
+		Revision:        quotation.Revision,
+		TotalNettCharge: quotation.TotalNettCharge,
+		Share:           *share.ID,
+		AcceptedAt:      now,
+	}
+
+	description := fmt.Sprintf("Status changed from %s to %s: accepted online, revision %s, %s", quotation.Status, enum.QuotationStatusAccepted, quotationRevisionLabel(quotation.Revision), documentMoney(quotation.TotalNettCharge))
+
+	// Only the revision the client saw can be accepted, and only once
+	filter := bson.M{
+		"_id":        quotation.ID,
+		"company":    systemContext.User.Company,
+		"status":     quotation.Status,
+		"revision":   quotation.Revision,
+		"acceptance": bson.M{"$exists": false},
+		"isDeleted":  false,
+	}
+
+	update := bson.M{
+		"$set": bson.M{
+			"status":     enum.QuotationStatusAccepted,
+			"acceptedAt": now,
+			"acceptance": acceptance,
+			"updatedAt":  now,
+		},
+		"$push": bson.M{
+			"actionLogs": newSystemActionLog(description, systemContext),
+		},
+	}
+
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+
+	var doc database.Quotation
+	err = systemContext.MongoDB.Collection("quotation").FindOneAndUpdate(context.Background(), filter, update, opts).Decode(&doc)
+	if err != nil {
+		// The signature is not referenced by anything unless the acceptance was recorded
+		if media.ID != nil {
+			if deleteErr := MediaDelete(*media.ID, systemContext); deleteErr != nil {
+				systemContext.Logger.Error("service.quotationClientAccept signature", zap.Error(deleteErr))
+			}
+		}
+
+		if err == mongo.ErrNoDocuments {
+			return nil, utils.SystemError(enum.ErrorCodeValidation, "Quotation has changed since you opened it, please reload", nil)
+		}
+		systemContext.Logger.Error("service.quotationClientAccept", zap.Error(err))
+		return nil, utils.SystemError(enum.ErrorCodeInternal, "Failed to record acceptance", nil)
+	}
+
+	return &doc, nil
+}
+
+func quotationClientRequestChanges(quotation *database.Quotation, share *database.DocumentShare, name string, message string, clientIP string, systemContext *model.SystemContext) (*database.Quotation, error) {
+	now := time.Now()
+
+	if quotationChangeRequestedSince(quotation, now.Add(-quotationChangeRequestInterval)) {
+		return nil, utils.SystemError(enum.ErrorCodeValidation, "Your previous request was just received, please wait a moment before sending another", nil)
+	}
+
+	changeRequest := database.QuotationChangeRequest{
+		Name:        name,
+		Message:     message,
+		IPAddress:   clientIP,
+		Revision:    quotation.Revision,
+		Share:       *share.ID,
+		RequestedAt: now,
+	}
+
+	// Checked again in the filter so parallel requests cannot get around the limits
+	filter := bson.M{
+		"_id":       quotation.ID,
+		"company":   systemContext.User.Company,
+		"status":    enum.QuotationStatusSent,
+		"isDeleted": false,
+		"changeRequests": bson.M{"$not": bson.M{"$elemMatch": bson.M{
+			"requestedAt": bson.M{"$gt": now.Add(-quotationChangeRequestInterval)},
+		}}},
+		"$expr": bson.M{"$lt": bson.A{
+			bson.M{"$size": bson.M{"$filter": bson.M{
+				"input": bson.M{"$ifNull": bson.A{"$changeRequests", bson.A{}}},
+				"cond":  bson.M{"$eq": bson.A{"$$this.revision", quotation.Revision}},
+			}}},
+			quotationChangeRequestMaxOpen,
+		}},
+	}
+
+	update := bson.M{
+		"$set": bson.M{
+			"updatedAt": time.Now(),
+		},
+		"$push": bson.M{
+			"changeRequests": changeRequest,
+			"actionLogs":     newSystemActionLog("Client requested changes: "+message, systemContext),
+		},
+	}
+
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+
+	var doc database.Quotation
+	err := systemContext.MongoDB.Collection("quotation").FindOneAndUpdate(context.Background(), filter, update, opts).Decode(&doc)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			if current, getErr := QuotationGetByID(*quotation.ID, systemContext); getErr == nil && current.Status == enum.QuotationStatusSent {
+				quotation = current
+			}
+			if quotationChangeRequestedSince(quotation, now.Add(-quotationChangeRequestInterval)) {
+				return nil, utils.SystemError(enum.ErrorCodeValidation, "Your previous request was just received, please wait a moment before sending another", nil)
+			}
+			if quotationChangeRequestCount(quotation) >= quotationChangeRequestMaxOpen {
+				return nil, utils.SystemError(
+					enum.ErrorCodeValidation,
+					"Too many change requests have been sent for this quotation, please wait for a revised quotation",
+					map[string]interface{}{"limit": quotationChangeRequestMaxOpen},
+				)
+			}
+			return nil, utils.SystemError(enum.ErrorCodeValidation, "Quotation is no longer awaiting your response", nil)
+		}
+		systemContext.Logger.Error("service.quotationClientRequestChanges", zap.Error(err))
+		return nil, utils.SystemError(enum.ErrorCodeInternal, "Failed to record change request", nil)
+	}
+
+	return &doc, nil
+}
+
+// quotationChangeRequestedSince reports whether the client has requested changes after the given time
+func quotationChangeRequestedSince(quotation *database.Quotation, since time.Time) bool {
+	for _, changeRequest := range quotation.ChangeRequests {
+		if changeRequest.RequestedAt.After(since) {
+			return true
+		}
+	}
+	return false
+}
+
+// quotationChangeRequestCount is the number of change requests made against the current revision
+func quotationChangeRequestCount(quotation *database.Quotation) int {
+	count := 0
+	for _, changeRequest := range quotation.ChangeRequests {
+		if changeRequest.Revision == quotation.Revision {
+			count++
+		}
+	}
+	return count
+}
+
+// quotationSignatureSave stores a drawn signature sent as a PNG data URL and records it as media
+func quotationSignatureSave(quotation *database.Quotation, signature string, systemContext *model.SystemContext) (*database.Media, error) {
+	invalidErr := utils.SystemError(enum.ErrorCodeValidation, "Signature must be a PNG image", nil)
+
+	if !strings.HasPrefix(signature, quotationSignatureDataPrefix) {
+		return nil, invalidErr
+	}
+
+	data, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(signature, quotationSignatureDataPrefix))
+	if err != nil {
+		return nil, invalidErr
+	}
+
+	if len(data) > quotationSignatureMaxBytes {
+		return nil, utils.SystemError(
+			enum.ErrorCodeTooLarge,
+			fmt.Sprintf("Signature size (%d bytes) exceeds maximum allowed size (%d bytes)", len(data), quotationSignatureMaxBytes),
+			nil,
+		)
+	}
+
+	if _, err := png.DecodeConfig(bytes.NewReader(data)); err != nil {
+		return nil, invalidErr
+	}
+
+	uploadDir := utils.GetEnvString("UPLOAD_DIR", "./assets/client")
+	if err := os.MkdirAll(uploadDir, 0755); err != nil {
+		systemContext.Logger.Error("service.quotationSignatureSave", zap.Error(err))
+		return nil, utils.SystemError(enum.ErrorCodeInternal, "Failed to save signature", nil)
+	}
+
+	originalName := fmt.Sprintf("signature_%s.png", quotation.QuotationNumber)
+	fileName := fmt.Sprintf("%d_%s", time.Now().UnixMilli(), utils.SanitizeFilename(originalName))
+	filePath := strings.ReplaceAll(filepath.Join(uploadDir, fileName), "\\", "/")
+	filePath = strings.TrimPrefix(filePath, "./")
+
+	if err := os.WriteFile(filePath, data, 0644); err != nil {
+		systemContext.Logger.Error("service.quotationSignatureSave", zap.Error(err))
+		return nil, utils.SystemError(enum.ErrorCodeInternal, "Failed to save signature", nil)
+	}
+
+	media, err := MediaCreate(&database.Media{
+		Name:      fileName,
+		Extension: ".png",
+		Path:      filePath,
+		FileName:  originalName,
+		Company:   *systemContext.User.Company,
+	}, systemContext)
+	if err != nil {
+		systemContext.Logger.Error("service.quotationSignatureSave", zap.Error(err))
+		if removeErr := os.Remove(filePath); removeErr != nil {
+			systemContext.Logger.Error("service.quotationSignatureSave", zap.Error(removeErr))
+		}
+		return nil, utils.SystemError(enum.ErrorCodeInternal, "Failed to save signature", nil)
+	}
+
+	return media, nil
+}
+
+func quotationClientView(quotation *database.Quotation, company *database.Company, share *database.DocumentShare) *model.QuotationClientView {
+	return &model.QuotationClientView{
+		QuotationNumber:       quotation.QuotationNumber,
+		Name:                  quotation.Name,
+		CompanyName:           company.Name,
+		Client:                quotation.Client,
+		Address:               quotation.Address,
+		ExpiredAt:             quotation.ExpiredAt,
+		Description:           quotation.Description,
+		Remark:                quotation.Remark,
+		Revision:              quotation.Revision,
+		RevisionLabel:         quotationRevisionLabel(quotation.Revision),
+		AreaMaterials:         quotationClientAreaMaterials(quotation.AreaMaterials),
+		Discounts:             quotation.Discounts,
+		AdditionalCharges:     quotation.AdditionalCharges,
+		TotalCharge:           quotation.TotalCharge,
+		TotalDiscount:         quotation.TotalDiscount,
+		TotalAdditionalCharge: quotation.TotalAdditionalCharge,
+		TotalNettCharge:       quotation.TotalNettCharge,
+		TermConditions:        company.TermCondition,
+		Status:                quotation.Status,
+		Acceptance:            quotation.Acceptance,
+		CanRespond:            quotationClientCanRespond(quotation),
+		LinkExpiresAt:         share.ExpiresAt,
+	}
+}
+
+// quotationClientAreaMaterials copies area materials without the internal line costs
+func quotationClientAreaMaterials(areaMaterials []database.SystemAreaMaterial) []database.SystemAreaMaterial {
+	result := make([]database.SystemAreaMaterial, len(areaMaterials))
+	for i, areaMaterial := range areaMaterials {
+		result[i] = areaMaterial
+		result[i].Materials = quotationClientMaterials(areaMaterial.Materials)
+	}
+	return result
+}
+
+func quotationClientMaterials(materials []database.SystemAreaMaterialDetail) []database.SystemAreaMaterialDetail {
+	result := make([]database.SystemAreaMaterialDetail, len(materials))
+	for i, material := range materials {
+		result[i] = material
+		result[i].CostPerUnit = 0
+		result[i].Template = quotationClientMaterials(material.Template)
+	}
+	return result
+}
+
+// appendQuotationActionLog adds an entry to a quotation's action log, logging rather than returning failures
+func appendQuotationActionLog(quotationID primitive.ObjectID, description string, systemContext *model.SystemContext) {
+	_, err := systemContext.MongoDB.Collection("quotation").UpdateOne(
+		context.Background(),
+		bson.M{"_id": quotationID, "company": systemContext.User.Company},
+		bson.M{"$push": bson.M{"actionLogs": newSystemActionLog(description, systemContext)}},
+	)
+	if err != nil {
+		systemContext.Logger.Error("service.appendQuotationActionLog", zap.Error(err))
+	}
+}
+
+// QuotationClientResponseNotifier is called when a client accepts or requests changes to a quotation.
+// The default emails the quotation's creator; replace it to route notifications elsewhere.
+var QuotationClientResponseNotifier = quotationClientResponseEmailNotifier
+
+// notifyQuotationClientResponse runs the notifier in the background so a mail failure never fails the response
+func notifyQuotationClientResponse(quotation *database.Quotation, action enum.QuotationClientAction, name string, message string, systemContext *model.SystemContext) {
+	if QuotationClientResponseNotifier == nil {
+		return
+	}
+
+	var creator database.User
+	err := systemContext.MongoDB.Collection("user").FindOne(context.Background(), bson.M{
+		"_id":       quotation.CreatedBy,
+		"isDeleted": false,
+	}).Decode(&creator)
+	if err != nil {
+		systemContext.Logger.Warn("service.notifyQuotationClientResponse creator lookup", zap.Error(err))
+		return
+	}
+
+	notifier := QuotationClientResponseNotifier
+	logger := systemContext.Logger
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				logger.Error("service.notifyQuotationClientResponse", zap.Any("panic", r))
+			}
+		}()
+		notifier(quotation, &creator, action, name, message, logger)
+	}()
+}
+
+func quotationClientResponseEmailNotifier(quotation *database.Quotation, creator *database.User, action enum.QuotationClientAction, name string, message string, logger *zap.Logger) {
+	if strings.TrimSpace(creator.Email) == "" {
+		return
+	}
+
+	reference := quotationReference(quotation)
+	quotationLink := fmt.Sprintf("%s/quotation/%s",
+		utils.GetEnvString("FRONTEND_URL", "https://app.renotech.space"),
+		quotation.ID.Hex(),
+	)
+
+	subject := fmt.Sprintf("Quotation %s accepted by %s", reference, name)
+	htmlBody := fmt.Sprintf(
+		"<p>Hi %s,</p><p>%s has accepted quotation <strong>%s</strong> (revision %s, %s).</p><p><a href=\"%s\">View quotation</a></p>",
+		html.EscapeString(creator.Username),
+		html.EscapeString(name),
+		html.EscapeString(reference),
+		quotationRevisionLabel(quotation.Revision),
+		documentMoney(quotation.TotalNettCharge),
+		quotationLink,
+	)
+	textBody := fmt.Sprintf(
+		"Hi %s,\n\n%s has accepted quotation %s (revision %s, %s).\n\nView quotation: %s\n",
+		creator.Username,
+		name,
+		reference,
+		quotationRevisionLabel(quotation.Revision),
+		documentMoney(quotation.TotalNettCharge),
+		quotationLink,
+	)
+
+	if action == enum.QuotationClientActionRequestChanges {
+		subject = fmt.Sprintf("Changes requested on quotation %s", reference)
+		htmlBody = fmt.Sprintf(
+			"<p>Hi %s,</p><p>%s has requested changes to quotation <strong>%s</strong>:</p><p>%s</p><p><a href=\"%s\">View quotation</a></p>",
+			html.EscapeString(creator.Username),
+			html.EscapeString(name),
+			html.EscapeString(reference),
+			documentMultiline(message),
+			quotationLink,
+		)
+		textBody = fmt.Sprintf(
+			"Hi %s,\n\n%s has requested changes to quotation %s:\n\n%s\n\nView quotation: %s\n",
+			creator.Username,
+			name,
+			reference,
+			message,
+			quotationLink,
+		)
+	}
+
+	err := utils.SendMultipartEmail(&utils.EmailMessage{
+		To:       []string{creator.Email},
+		Subject:  subject,
+		HTMLBody: htmlBody,
+		TextBody: textBody,
+	})
+	if err != nil {
+		logger.Error("service.quotationClientResponseEmailNotifier",
+			zap.String("quotationID", quotation.ID.Hex()),
+			zap.String("userEmail", creator.Email),
+			zap.Error(err),
+		)
+	}
+}
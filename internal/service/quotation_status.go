@@ -0,0 +1,235 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.uber.org/zap"
+	"renotech.com.my/internal/database"
+	"renotech.com.my/internal/enum"
+	"renotech.com.my/internal/model"
+	"renotech.com.my/internal/utils"
+)
+
+// quotationStatusTransitions lists the statuses a user may move each quotation status to.
+// Quotations only become expired through the expiry sweeper.
+var quotationStatusTransitions = map[enum.QuotationStatus][]enum.QuotationStatus{
+	enum.QuotationStatusDraft:    {enum.QuotationStatusSent},
+	enum.QuotationStatusSent:     {enum.QuotationStatusAccepted, enum.QuotationStatusRejected, enum.QuotationStatusDraft},
+	enum.QuotationStatusRejected: {enum.QuotationStatusDraft},
+	enum.QuotationStatusExpired:  {enum.QuotationStatusAccepted, enum.QuotationStatusDraft},
+	enum.QuotationStatusAccepted: {},
+}
+
+// quotationExpirySweepInterval is how often sent quotations are checked for expiry
+const quotationExpirySweepInterval = 15 * time.Minute
+
+// Tenant services
+
+// QuotationStatusUpdate moves a quotation through its sales pipeline
+func QuotationStatusUpdate(quotationID primitive.ObjectID, input *model.QuotationStatusUpdateRequest, systemContext *model.SystemContext) (*database.Quotation, error) {
+	quotation, err := QuotationGetByID(quotationID, systemContext)
+	if err != nil {
+		return nil, err
+	}
+
+	return quotationTransitionStatus(quotation, input.Status, input.Remark, systemContext)
+}
+
+// Helper functions
+
+// quotationTransitionStatus validates and applies a user status change
+func quotationTransitionStatus(quotation *database.Quotation, status enum.QuotationStatus, remark string, systemContext *model.SystemContext) (*database.Quotation, error) {
+	if err := validateQuotationStatusTransition(quotation.Status, status); err != nil {
+		return nil, err
+	}
+
+	// A quotation cannot be sent with an expiry date that has already passed
+	if status == enum.QuotationStatusSent && !quotation.ExpiredAt.IsZero() && quotation.ExpiredAt.Before(time.Now()) {
+		return nil, utils.SystemError(
+			enum.ErrorCodeValidation,
+			"Quotation expiry date has passed, update it before sending",
+			map[string]interface{}{"expiredAt": quotation.ExpiredAt},
+		)
+	}
+
+	return quotationSetStatus(quotation, status, remark, systemContext)
+}
+
+// quotationSetStatus records a status change and its timestamp without checking the transition.
+// The change only applies if the status has not been changed since the quotation was read.
+func quotationSetStatus(quotation *database.Quotation, status enum.QuotationStatus, remark string, systemContext *model.SystemContext) (*database.Quotation, error) {
+	description := fmt.Sprintf("Status changed from %s to %s", quotation.Status, status)
+	if strings.TrimSpace(remark) != "" {
+		description += ": " + strings.TrimSpace(remark)
+	}
+
+	now := time.Now()
+	set := bson.M{
+		"status":    status,
+		"updatedAt": now,
+		"updatedBy": systemContext.User.ID,
+	}
+	if field := quotationStatusTimeField(status); field != "" {
+		set[field] = now
+	}
+
+	collection := systemContext.MongoDB.Collection("quotation")
+
+	filter := bson.M{
+		"_id":       quotation.ID,
+		"company":   systemContext.User.Company,
+		"status":    quotation.Status,
+		"isDeleted": false,
+	}
+
+	update := bson.M{
+		"$set": set,
+		"$push": bson.M{
+			"actionLogs": newSystemActionLog(description, systemContext),
+		},
+	}
+
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+
+	var doc database.Quotation
+	err := collection.FindOneAndUpdate(context.Background(), filter, update, opts).Decode(&doc)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, utils.SystemError(
+				enum.ErrorCodeValidation,
+				"Quotation status was changed by another request, please reload",
+				map[string]interface{}{"currentStatus": quotation.Status},
+			)
+		}
+		systemContext.Logger.Error("service.quotationSetStatus", zap.Error(err))
+		return nil, utils.SystemError(enum.ErrorCodeInternal, "Failed to update quotation status", nil)
+	}
+
+	return &doc, nil
+}
+
+// quotationStatusTimeField is the field holding the time a quotation last entered the status
+func quotationStatusTimeField(status enum.QuotationStatus) string {
+	switch status {
+	case enum.QuotationStatusSent:
+		return "sentAt"
+	case enum.QuotationStatusAccepted:
+		return "acceptedAt"
+	case enum.QuotationStatusRejected:
+		return "rejectedAt"
+	case enum.QuotationStatusExpired:
+		return "lapsedAt"
+	}
+	return ""
+}
+
+// validateQuotationEditable only allows the content of draft quotations to change, so what the client
+// received stays on record
+func validateQuotationEditable(quotation *database.Quotation) error {
+	if quotation.Status != enum.QuotationStatusDraft {
+		return utils.SystemError(
+			enum.ErrorCodeValidation,
+			"Only draft quotations can be changed, move the quotation back to draft first",
+			map[string]interface{}{"status": quotation.Status},
+		)
+	}
+	return nil
+}
+
+func validateQuotationStatusTransition(from enum.QuotationStatus, to enum.QuotationStatus) error {
+	if _, exists := quotationStatusTransitions[to]; !exists {
+		return utils.SystemError(
+			enum.ErrorCodeValidation,
+			"Invalid quotation status",
+			map[string]interface{}{"status": to},
+		)
+	}
+
+	allowed := quotationStatusTransitions[from]
+	for _, status := range allowed {
+		if status == to {
+			return nil
+		}
+	}
+
+	if allowed == nil {
+		allowed = []enum.QuotationStatus{}
+	}
+
+	return utils.SystemError(
+		enum.ErrorCodeValidation,
+		"Quotation status transition not allowed",
+		map[string]interface{}{
+			"from":    from,
+			"to":      to,
+			"allowed": allowed,
+		},
+	)
+}
+
+// Background jobs
+
+// QuotationExpirySweeperStart marks sent quotations past their expiry date as expired, once at start
+// and then every quotationExpirySweepInterval until the context is cancelled.
+func QuotationExpirySweeperStart(ctx context.Context, db *mongo.Database, logger *zap.Logger) {
+	go func() {
+		ticker := time.NewTicker(quotationExpirySweepInterval)
+		defer ticker.Stop()
+
+		for {
+			if _, err := QuotationExpirySweep(db, logger); err != nil {
+				logger.Error("service.QuotationExpirySweeperStart", zap.Error(err))
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// QuotationExpirySweep marks sent quotations whose expiry date has passed as expired across all
+// companies and returns how many were changed.
+func QuotationExpirySweep(db *mongo.Database, logger *zap.Logger) (int64, error) {
+	now := time.Now()
+
+	filter := bson.M{
+		"status":    enum.QuotationStatusSent,
+		"expiredAt": bson.M{"$gt": time.Time{}, "$lt": now},
+		"isDeleted": false,
+	}
+
+	update := bson.M{
+		"$set": bson.M{
+			"status":   enum.QuotationStatusExpired,
+			"lapsedAt": now,
+		},
+		"$push": bson.M{
+			"actionLogs": database.SystemActionLog{
+				Description: fmt.Sprintf("Status changed from %s to %s: expiry date passed", enum.QuotationStatusSent, enum.QuotationStatusExpired),
+				Time:        now,
+				ByName:      "system",
+			},
+		},
+	}
+
+	result, err := db.Collection("quotation").UpdateMany(context.Background(), filter, update)
+	if err != nil {
+		return 0, err
+	}
+
+	if result.ModifiedCount > 0 {
+		logger.Info("Quotations expired", zap.Int64("quotations", result.ModifiedCount))
+	}
+
+	return result.ModifiedCount, nil
+}
@@ -0,0 +1,321 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.uber.org/zap"
+	"renotech.com.my/internal/database"
+	"renotech.com.my/internal/enum"
+	"renotech.com.my/internal/model"
+	"renotech.com.my/internal/utils"
+)
+
+// Tenant services
+func siteDiaryCreateValidation(input *model.SiteDiaryCreateRequest, systemContext *model.SystemContext) error {
+	project, err := ProjectGetByID(input.Project, systemContext)
+	if err != nil {
+		return err
+	}
+
+	return validateSiteDiaryFields(project, input.Date, input.Weather, input.Manpower, input.WorkDone, input.Media, systemContext)
+}
+
+func SiteDiaryCreate(input *model.SiteDiaryCreateRequest, systemContext *model.SystemContext) (*database.SiteDiary, error) {
+	// Validate input
+	if err := siteDiaryCreateValidation(input, systemContext); err != nil {
+		return nil, err
+	}
+
+	diary := &database.SiteDiary{
+		Project:       input.Project,
+		Company:       systemContext.User.Company,
+		Date:          input.Date,
+		Weather:       input.Weather,
+		Manpower:      input.Manpower,
+		WorkDone:      normalizeSiteDiaryWork(input.WorkDone),
+		Issues:        trimSiteDiaryIssues(input.Issues),
+		Media:         input.Media,
+		Remark:        input.Remark,
+		CreatedAt:     time.Now(),
+		CreatedBy:     *systemContext.User.ID,
+		CreatedByName: systemContext.User.Username,
+		UpdatedAt:     time.Now(),
+		UpdatedBy:     systemContext.User.ID,
+		IsDeleted:     false,
+	}
+
+	if diary.Media == nil {
+		diary.Media = []database.SystemMedia{}
+	}
+
+	collection := systemContext.MongoDB.Collection("site_diary")
+	result, err := collection.InsertOne(context.Background(), diary)
+	if err != nil {
+		systemContext.Logger.Error("service.SiteDiaryCreate", zap.Error(err))
+		return nil, utils.SystemError(enum.ErrorCodeInternal, "Failed to create site diary", nil)
+	}
+
+	return SiteDiaryGetByID(result.InsertedID.(primitive.ObjectID), systemContext)
+}
+
+func siteDiaryUpdateValidation(input *model.SiteDiaryUpdateRequest, systemContext *model.SystemContext) error {
+	existing, err := SiteDiaryGetByID(input.ID, systemContext)
+	if err != nil {
+		return err
+	}
+
+	project, err := ProjectGetByID(existing.Project, systemContext)
+	if err != nil {
+		return err
+	}
+
+	return validateSiteDiaryFields(project, input.Date, input.Weather, input.Manpower, input.WorkDone, input.Media, systemContext)
+}
+
+func SiteDiaryUpdate(input *model.SiteDiaryUpdateRequest, systemContext *model.SystemContext) (*database.SiteDiary, error) {
+	// Validate input
+	if err := siteDiaryUpdateValidation(input, systemContext); err != nil {
+		return nil, err
+	}
+
+	media := input.Media
+	if media == nil {
+		media = []database.SystemMedia{}
+	}
+
+	collection := systemContext.MongoDB.Collection("site_diary")
+
+	filter := bson.M{
+		"_id":       input.ID,
+		"company":   systemContext.User.Company,
+		"isDeleted": false,
+	}
+
+	update := bson.M{
+		"$set": bson.M{
+			"date":      input.Date,
+			"weather":   input.Weather,
+			"manpower":  input.Manpower,
+			"workDone":  normalizeSiteDiaryWork(input.WorkDone),
+			"issues":    trimSiteDiaryIssues(input.Issues),
+			"media":     media,
+			"remark":    input.Remark,
+			"updatedAt": time.Now(),
+			"updatedBy": systemContext.User.ID,
+		},
+	}
+
+	_, err := collection.UpdateOne(context.Background(), filter, update)
+	if err != nil {
+		return nil, utils.SystemError(enum.ErrorCodeInternal, "Failed to update site diary", nil)
+	}
+
+	return SiteDiaryGetByID(input.ID, systemContext)
+}
+
+func SiteDiaryGetByID(diaryID primitive.ObjectID, systemContext *model.SystemContext) (*database.SiteDiary, error) {
+	collection := systemContext.MongoDB.Collection("site_diary")
+
+	filter := bson.M{
+		"_id":       diaryID,
+		"company":   systemContext.User.Company,
+		"isDeleted": false,
+	}
+
+	var doc database.SiteDiary
+	err := collection.FindOne(context.Background(), filter).Decode(&doc)
+	if err != nil {
+		return nil, utils.SystemError(enum.ErrorCodeNotFound, "Site diary not found", nil)
+	}
+
+	return &doc, nil
+}
+
+func SiteDiaryDelete(diaryID primitive.ObjectID, systemContext *model.SystemContext) error {
+	collection := systemContext.MongoDB.Collection("site_diary")
+
+	filter := bson.M{
+		"_id":       diaryID,
+		"company":   systemContext.User.Company,
+		"isDeleted": false,
+	}
+
+	update := bson.M{
+		"$set": bson.M{
+			"isDeleted": true,
+			"updatedAt": time.Now(),
+			"updatedBy": systemContext.User.ID,
+		},
+	}
+
+	result, err := collection.UpdateOne(context.Background(), filter, update)
+	if err != nil {
+		return utils.SystemError(enum.ErrorCodeInternal, "Failed to delete site diary", nil)
+	}
+
+	if result.MatchedCount == 0 {
+		return utils.SystemError(enum.ErrorCodeNotFound, "Site diary not found or access denied", nil)
+	}
+
+	return nil
+}
+
+// SiteDiaryFeed returns a project's diary entries newest first, paged by cursor so new
+// entries added while scrolling do not shift the pages
+func SiteDiaryFeed(input model.SiteDiaryFeedRequest, systemContext *model.SystemContext) (*model.SiteDiaryFeedResponse, error) {
+	if _, err := ProjectGetByID(input.Project, systemContext); err != nil {
+		return nil, err
+	}
+
+	collection := systemContext.MongoDB.Collection("site_diary")
+
+	// Build base filter
+	filter := bson.M{"isDeleted": false, "company": systemContext.User.Company, "project": input.Project}
+
+	// Add field-specific filters
+	if strings.TrimSpace(input.Area) != "" {
+		filter["workDone.area.name"] = strings.TrimSpace(input.Area)
+	}
+
+	// Add date range filter
+	if input.DateFrom != nil || input.DateTo != nil {
+		dateFilter := bson.M{}
+		if input.DateFrom != nil {
+			dateFilter["$gte"] = input.DateFrom
+		}
+		if input.DateTo != nil {
+			dateFilter["$lte"] = input.DateTo
+		}
+		filter["date"] = dateFilter
+	}
+
+	// Continue after the last entry of the previous page
+	if input.Cursor != nil {
+		filter = bson.M{
+			"$and": []bson.M{
+				filter,
+				{"$or": []bson.M{
+					{"date": bson.M{"$lt": input.Cursor.Date}},
+					{"date": input.Cursor.Date, "_id": bson.M{"$lt": input.Cursor.ID}},
+				}},
+			},
+		}
+	}
+
+	limit := input.Limit
+	if limit <= 0 {
+		limit = 20
+	}
+	if limit > 100 {
+		limit = 100 // Maximum limit
+	}
+
+	// Fetch one extra entry to know whether there is another page
+	findOptions := options.Find().
+		SetLimit(int64(limit + 1)).
+		SetSort(bson.D{{Key: "date", Value: -1}, {Key: "_id", Value: -1}})
+
+	cursor, err := collection.Find(context.Background(), filter, findOptions)
+	if err != nil {
+		systemContext.Logger.Error("service.SiteDiaryFeed", zap.Error(err))
+		return nil, utils.SystemError(enum.ErrorCodeInternal, "Failed to retrieve site diary", nil)
+	}
+	defer cursor.Close(context.Background())
+
+	var entries []database.SiteDiary
+	if err = cursor.All(context.Background(), &entries); err != nil {
+		systemContext.Logger.Error("service.SiteDiaryFeed", zap.Error(err))
+		return nil, utils.SystemError(enum.ErrorCodeInternal, "Failed to decode site diary", nil)
+	}
+
+	response := &model.SiteDiaryFeedResponse{Data: []database.SiteDiary{}}
+	if len(entries) > limit {
+		entries = entries[:limit]
+		last := entries[limit-1]
+		response.NextCursor = &model.SiteDiaryCursor{Date: last.Date, ID: *last.ID}
+	}
+	if entries != nil {
+		response.Data = entries
+	}
+
+	return response, nil
+}
+
+// Helper functions
+func validateSiteDiaryFields(project *database.Project, date time.Time, weather enum.SiteDiaryWeather, manpower int, workDone []database.SiteDiaryWork, media []database.SystemMedia, systemContext *model.SystemContext) error {
+	if date.After(time.Now().Add(24 * time.Hour)) {
+		return utils.SystemError(enum.ErrorCodeValidation, "Diary date cannot be in the future", nil)
+	}
+
+	switch weather {
+	case "", enum.SiteDiaryWeatherSunny, enum.SiteDiaryWeatherCloudy, enum.SiteDiaryWeatherRainy, enum.SiteDiaryWeatherStormy:
+	default:
+		return utils.SystemError(
+			enum.ErrorCodeValidation,
+			"Invalid weather",
+			map[string]interface{}{"weather": weather},
+		)
+	}
+
+	if manpower < 0 {
+		return utils.SystemError(
+			enum.ErrorCodeValidation,
+			"Manpower cannot be negative",
+			map[string]interface{}{"manpower": manpower},
+		)
+	}
+
+	projectAreas := make(map[string]bool)
+	for _, areaMaterial := range project.AreaMaterials {
+		projectAreas[strings.TrimSpace(areaMaterial.Area.Name)] = true
+	}
+
+	mediaPaths := []string{}
+	for _, item := range media {
+		mediaPaths = append(mediaPaths, item.Path)
+	}
+
+	for i, work := range workDone {
+		area := strings.TrimSpace(work.Area.Name)
+		if area == "" || !projectAreas[area] {
+			return utils.SystemError(
+				enum.ErrorCodeValidation,
+				"Area not found in project",
+				map[string]interface{}{"workIndex": i, "area": work.Area.Name},
+			)
+		}
+
+		for _, item := range work.Media {
+			mediaPaths = append(mediaPaths, item.Path)
+		}
+	}
+
+	return ValidateMediaPaths(mediaPaths, systemContext)
+}
+
+func normalizeSiteDiaryWork(workDone []database.SiteDiaryWork) []database.SiteDiaryWork {
+	result := make([]database.SiteDiaryWork, len(workDone))
+	for i, work := range workDone {
+		work.Area.Name = strings.TrimSpace(work.Area.Name)
+		if work.Media == nil {
+			work.Media = []database.SystemMedia{}
+		}
+		result[i] = work
+	}
+	return result
+}
+
+func trimSiteDiaryIssues(issues []string) []string {
+	result := []string{}
+	for _, issue := range issues {
+		if trimmed := strings.TrimSpace(issue); trimmed != "" {
+			result = append(result, trimmed)
+		}
+	}
+	return result
+}
@@ -0,0 +1,322 @@
+package service
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Spreadsheet reading for imports. XLSX files are read directly from their zip and XML parts, so no
+// external converter is needed. Only the first worksheet is read and cells are returned as text.
+
+// spreadsheetMaxPartSize caps how much of one XLSX part is decompressed
+const spreadsheetMaxPartSize = 32 << 20
+
+// spreadsheetMaxColumns is the widest row read, cells beyond it are ignored
+const spreadsheetMaxColumns = 64
+
+// spreadsheetRow is one non-blank row with its row number in the file, starting at 1
+type spreadsheetRow struct {
+	Number int
+	Cells  []string
+}
+
+// spreadsheetReadRows returns the non-blank rows of a CSV or XLSX file, chosen by the file extension.
+// Reading stops with an error once the file has more than maxRows of them.
+func spreadsheetReadRows(fileName string, content []byte, maxRows int) ([]spreadsheetRow, error) {
+	switch strings.ToLower(filepath.Ext(fileName)) {
+	case ".csv":
+		return spreadsheetReadCSV(content, maxRows)
+	case ".xlsx":
+		return spreadsheetReadXLSX(content, maxRows)
+	default:
+		return nil, fmt.Errorf("unsupported file type %q, use .csv or .xlsx", filepath.Ext(fileName))
+	}
+}
+
+func spreadsheetReadCSV(content []byte, maxRows int) ([]spreadsheetRow, error) {
+	// Excel writes a byte order mark at the start of UTF-8 CSV files
+	content = bytes.TrimPrefix(content, []byte("\xef\xbb\xbf"))
+	if !utf8.Valid(content) {
+		return nil, fmt.Errorf("file is not UTF-8 text")
+	}
+
+	reader := csv.NewReader(bytes.NewReader(content))
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+	reader.TrimLeadingSpace = true
+	reader.ReuseRecord = true
+
+	rows := []spreadsheetRow{}
+	for {
+		cells, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if spreadsheetRowIsBlank(cells) {
+			continue
+		}
+		if len(rows) >= maxRows {
+			return nil, spreadsheetRowLimitError(maxRows)
+		}
+
+		if len(cells) > spreadsheetMaxColumns {
+			cells = cells[:spreadsheetMaxColumns]
+		}
+		line, _ := reader.FieldPos(0)
+		rows = append(rows, spreadsheetRow{Number: line, Cells: append([]string(nil), cells...)})
+	}
+
+	return rows, nil
+}
+
+type xlsxWorkbook struct {
+	Sheets []struct {
+		RelationID string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+	} `xml:"sheets>sheet"`
+}
+
+type xlsxRelationships struct {
+	Relationships []struct {
+		ID     string `xml:"Id,attr"`
+		Target string `xml:"Target,attr"`
+	} `xml:"Relationship"`
+}
+
+type xlsxSharedStrings struct {
+	Items []xlsxText `xml:"si"`
+}
+
+// xlsxText is a shared or inline string, either plain or made of rich text runs
+type xlsxText struct {
+	Text string `xml:"t"`
+	Runs []struct {
+		Text string `xml:"t"`
+	} `xml:"r"`
+}
+
+func (t xlsxText) String() string {
+	if len(t.Runs) == 0 {
+		return t.Text
+	}
+
+	var text strings.Builder
+	for _, run := range t.Runs {
+		text.WriteString(run.Text)
+	}
+	return text.String()
+}
+
+// xlsxRow is one row of a worksheet, decoded one at a time as the sheet is streamed
+type xlsxRow struct {
+	Number int `xml:"r,attr"`
+	Cells  []struct {
+		Ref    string   `xml:"r,attr"`
+		Type   string   `xml:"t,attr"`
+		Value  string   `xml:"v"`
+		Inline xlsxText `xml:"is"`
+	} `xml:"c"`
+}
+
+func spreadsheetReadXLSX(content []byte, maxRows int) ([]spreadsheetRow, error) {
+	archive, err := zip.NewReader(bytes.NewReader(content), int64(len(content)))
+	if err != nil {
+		return nil, fmt.Errorf("file is not a valid XLSX workbook")
+	}
+
+	parts := make(map[string]*zip.File)
+	for _, file := range archive.File {
+		parts[file.Name] = file
+	}
+
+	var sharedStrings xlsxSharedStrings
+	if part, exists := parts["xl/sharedStrings.xml"]; exists {
+		if err := xlsxDecodePart(part, &sharedStrings); err != nil {
+			return nil, err
+		}
+	}
+
+	sheetPath, err := xlsxFirstSheetPath(parts)
+	if err != nil {
+		return nil, err
+	}
+
+	reader, err := parts[sheetPath].Open()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s", sheetPath)
+	}
+	defer reader.Close()
+
+	// The sheet is streamed row by row so the row limit applies before anything else is kept
+	decoder := xml.NewDecoder(io.LimitReader(reader, spreadsheetMaxPartSize))
+	rows := []spreadsheetRow{}
+	previous := 0
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s", sheetPath)
+		}
+
+		start, ok := token.(xml.StartElement)
+		if !ok || start.Name.Local != "row" {
+			continue
+		}
+
+		var row xlsxRow
+		if err := decoder.DecodeElement(&row, &start); err != nil {
+			return nil, fmt.Errorf("failed to read %s", sheetPath)
+		}
+
+		// The row number is optional in the file format, rows without one follow the previous row
+		number := row.Number
+		if number <= 0 {
+			number = previous + 1
+		}
+		previous = number
+
+		cells := xlsxRowCells(row, sharedStrings)
+		if spreadsheetRowIsBlank(cells) {
+			continue
+		}
+		if len(rows) >= maxRows {
+			return nil, spreadsheetRowLimitError(maxRows)
+		}
+		rows = append(rows, spreadsheetRow{Number: number, Cells: cells})
+	}
+
+	return rows, nil
+}
+
+// xlsxRowCells places the cell values of a row by column, up to spreadsheetMaxColumns
+func xlsxRowCells(row xlsxRow, sharedStrings xlsxSharedStrings) []string {
+	values := []string{}
+	for i, cell := range row.Cells {
+		column := xlsxColumnIndex(cell.Ref)
+		if column < 0 {
+			column = i
+		}
+		if column >= spreadsheetMaxColumns {
+			continue
+		}
+
+		var value string
+		switch cell.Type {
+		case "s":
+			index, err := strconv.Atoi(strings.TrimSpace(cell.Value))
+			if err == nil && index >= 0 && index < len(sharedStrings.Items) {
+				value = sharedStrings.Items[index].String()
+			}
+		case "inlineStr":
+			value = cell.Inline.String()
+		default:
+			value = cell.Value
+		}
+
+		for len(values) <= column {
+			values = append(values, "")
+		}
+		values[column] = value
+	}
+	return values
+}
+
+// xlsxFirstSheetPath finds the part holding the first worksheet listed in the workbook
+func xlsxFirstSheetPath(parts map[string]*zip.File) (string, error) {
+	fallback := "xl/worksheets/sheet1.xml"
+
+	var workbook xlsxWorkbook
+	var relationships xlsxRelationships
+	workbookPart, hasWorkbook := parts["xl/workbook.xml"]
+	relationshipsPart, hasRelationships := parts["xl/_rels/workbook.xml.rels"]
+	if hasWorkbook && hasRelationships {
+		if err := xlsxDecodePart(workbookPart, &workbook); err != nil {
+			return "", err
+		}
+		if err := xlsxDecodePart(relationshipsPart, &relationships); err != nil {
+			return "", err
+		}
+
+		if len(workbook.Sheets) > 0 {
+			for _, relationship := range relationships.Relationships {
+				if relationship.ID != workbook.Sheets[0].RelationID {
+					continue
+				}
+
+				target := relationship.Target
+				if strings.HasPrefix(target, "/") {
+					target = strings.TrimPrefix(target, "/")
+				} else {
+					target = path.Join("xl", target)
+				}
+				if _, exists := parts[target]; exists {
+					return target, nil
+				}
+			}
+		}
+	}
+
+	if _, exists := parts[fallback]; exists {
+		return fallback, nil
+	}
+	return "", fmt.Errorf("workbook has no worksheet")
+}
+
+func xlsxDecodePart(part *zip.File, target interface{}) error {
+	reader, err := part.Open()
+	if err != nil {
+		return fmt.Errorf("failed to open %s", part.Name)
+	}
+	defer reader.Close()
+
+	if err := xml.NewDecoder(io.LimitReader(reader, spreadsheetMaxPartSize)).Decode(target); err != nil {
+		return fmt.Errorf("failed to read %s", part.Name)
+	}
+	return nil
+}
+
+func spreadsheetRowIsBlank(cells []string) bool {
+	for _, cell := range cells {
+		if strings.TrimSpace(cell) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+func spreadsheetRowLimitError(maxRows int) error {
+	return fmt.Errorf("file has more than %d rows, split it into smaller files", maxRows)
+}
+
+// xlsxColumnIndex converts the column letters of a cell reference such as "AB12" to a zero-based index
+func xlsxColumnIndex(ref string) int {
+	column := 0
+	letters := 0
+	for _, char := range strings.ToUpper(ref) {
+		if char < 'A' || char > 'Z' {
+			break
+		}
+		if column <= spreadsheetMaxColumns {
+			column = column*26 + int(char-'A'+1)
+		}
+		letters++
+	}
+
+	if letters == 0 {
+		return -1
+	}
+	return column - 1
+}
@@ -0,0 +1,712 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"math"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.uber.org/zap"
+	"renotech.com.my/internal/database"
+	"renotech.com.my/internal/enum"
+	"renotech.com.my/internal/model"
+	"renotech.com.my/internal/utils"
+)
+
+// variationOrderStatusTransitions lists the statuses each variation order status may move to.
+// Approved and cancelled are final; an approved VO is reversed by issuing another VO.
+var variationOrderStatusTransitions = map[enum.VariationOrderStatus][]enum.VariationOrderStatus{
+	enum.VariationOrderStatusDraft:     {enum.VariationOrderStatusSubmitted, enum.VariationOrderStatusCancelled},
+	enum.VariationOrderStatusSubmitted: {enum.VariationOrderStatusApproved, enum.VariationOrderStatusRejected, enum.VariationOrderStatusDraft},
+	enum.VariationOrderStatusRejected:  {enum.VariationOrderStatusDraft, enum.VariationOrderStatusCancelled},
+	enum.VariationOrderStatusApproved:  {},
+	enum.VariationOrderStatusCancelled: {},
+}
+
+// Tenant services
+func variationOrderCreateValidation(input *model.VariationOrderCreateRequest, systemContext *model.SystemContext) error {
+	project, err := ProjectGetByID(input.Project, systemContext)
+	if err != nil {
+		return err
+	}
+
+	if strings.TrimSpace(input.Title) == "" {
+		return utils.SystemError(enum.ErrorCodeValidation, "Title is required", nil)
+	}
+
+	return validateVariationOrderLines(project, input.Lines, systemContext)
+}
+
+func VariationOrderCreate(input *model.VariationOrderCreateRequest, systemContext *model.SystemContext) (*database.VariationOrder, error) {
+	// Validate input
+	if err := variationOrderCreateValidation(input, systemContext); err != nil {
+		return nil, err
+	}
+
+	voNumber, err := DocumentNumberGenerate(enum.DocumentNumberTypeVariationOrder, systemContext)
+	if err != nil {
+		return nil, err
+	}
+
+	lines, totalAddition, totalOmission, netAmount := calculateVariationOrderTotals(input.Lines, companyMoneyRounding(systemContext))
+
+	variationOrder := &database.VariationOrder{
+		Project:       input.Project,
+		Company:       systemContext.User.Company,
+		VONumber:      voNumber,
+		Title:         strings.TrimSpace(input.Title),
+		Description:   input.Description,
+		Reason:        input.Reason,
+		Lines:         lines,
+		TotalAddition: totalAddition,
+		TotalOmission: totalOmission,
+		NetAmount:     netAmount,
+		Status:        enum.VariationOrderStatusDraft,
+		ActionLogs:    []database.SystemActionLog{newSystemActionLog("Variation order created", systemContext)},
+		CreatedAt:     time.Now(),
+		CreatedBy:     *systemContext.User.ID,
+		UpdatedAt:     time.Now(),
+		UpdatedBy:     systemContext.User.ID,
+		IsDeleted:     false,
+	}
+
+	collection := systemContext.MongoDB.Collection("variation_order")
+	result, err := collection.InsertOne(context.Background(), variationOrder)
+	if err != nil {
+		systemContext.Logger.Error("service.VariationOrderCreate", zap.Error(err))
+		return nil, utils.SystemError(enum.ErrorCodeInternal, "Failed to create variation order", nil)
+	}
+
+	return VariationOrderGetByID(result.InsertedID.(primitive.ObjectID), systemContext)
+}
+
+func variationOrderUpdateValidation(input *model.VariationOrderUpdateRequest, systemContext *model.SystemContext) (*database.VariationOrder, error) {
+	variationOrder, err := VariationOrderGetByID(input.ID, systemContext)
+	if err != nil {
+		return nil, err
+	}
+
+	if variationOrder.Status != enum.VariationOrderStatusDraft {
+		return nil, utils.SystemError(
+			enum.ErrorCodeValidation,
+			"Only draft variation orders can be edited",
+			map[string]interface{}{"status": variationOrder.Status},
+		)
+	}
+
+	project, err := ProjectGetByID(variationOrder.Project, systemContext)
+	if err != nil {
+		return nil, err
+	}
+
+	if strings.TrimSpace(input.Title) == "" {
+		return nil, utils.SystemError(enum.ErrorCodeValidation, "Title is required", nil)
+	}
+
+	if err := validateVariationOrderLines(project, input.Lines, systemContext); err != nil {
+		return nil, err
+	}
+
+	return variationOrder, nil
+}
+
+func VariationOrderUpdate(input *model.VariationOrderUpdateRequest, systemContext *model.SystemContext) (*database.VariationOrder, error) {
+	// Validate input
+	if _, err := variationOrderUpdateValidation(input, systemContext); err != nil {
+		return nil, err
+	}
+
+	lines, totalAddition, totalOmission, netAmount := calculateVariationOrderTotals(input.Lines, companyMoneyRounding(systemContext))
+
+	collection := systemContext.MongoDB.Collection("variation_order")
+
+	// Only update while still a draft, in case the status changed in the meantime
+	filter := bson.M{
+		"_id":       input.ID,
+		"company":   systemContext.User.Company,
+		"status":    enum.VariationOrderStatusDraft,
+		"isDeleted": false,
+	}
+
+	update := bson.M{
+		"$set": bson.M{
+			"title":         strings.TrimSpace(input.Title),
+			"description":   input.Description,
+			"reason":        input.Reason,
+			"lines":         lines,
+			"totalAddition": totalAddition,
+			"totalOmission": totalOmission,
+			"netAmount":     netAmount,
+			"updatedAt":     time.Now(),
+			"updatedBy":     systemContext.User.ID,
+		},
+		"$push": bson.M{
+			"actionLogs": newSystemActionLog("Variation order updated", systemContext),
+		},
+	}
+
+	result, err := collection.UpdateOne(context.Background(), filter, update)
+	if err != nil {
+		return nil, utils.SystemError(enum.ErrorCodeInternal, "Failed to update variation order", nil)
+	}
+
+	if result.MatchedCount == 0 {
+		return nil, utils.SystemError(enum.ErrorCodeValidation, "Only draft variation orders can be edited", nil)
+	}
+
+	return VariationOrderGetByID(input.ID, systemContext)
+}
+
+func VariationOrderGetByID(variationOrderID primitive.ObjectID, systemContext *model.SystemContext) (*database.VariationOrder, error) {
+	collection := systemContext.MongoDB.Collection("variation_order")
+
+	filter := bson.M{
+		"_id":       variationOrderID,
+		"company":   systemContext.User.Company,
+		"isDeleted": false,
+	}
+
+	var doc database.VariationOrder
+	err := collection.FindOne(context.Background(), filter).Decode(&doc)
+	if err != nil {
+		return nil, utils.SystemError(enum.ErrorCodeNotFound, "Variation order not found", nil)
+	}
+
+	return &doc, nil
+}
+
+func VariationOrderList(input model.VariationOrderListRequest, systemContext *model.SystemContext) (*model.VariationOrderListResponse, error) {
+	collection := systemContext.MongoDB.Collection("variation_order")
+
+	// Build base filter
+	filter := bson.M{"isDeleted": false, "company": systemContext.User.Company}
+
+	// Add field-specific filters
+	if input.Project != nil {
+		filter["project"] = input.Project
+	}
+	if input.Status != "" {
+		filter["status"] = input.Status
+	}
+
+	// Add global search filter
+	if strings.TrimSpace(input.Search) != "" {
+		searchRegex := primitive.Regex{Pattern: input.Search, Options: "i"}
+		filter["$or"] = []bson.M{
+			{"voNumber": searchRegex},
+			{"title": searchRegex},
+			{"description": searchRegex},
+		}
+	}
+
+	// Get total count
+	total, err := collection.CountDocuments(context.Background(), filter)
+	if err != nil {
+		systemContext.Logger.Error("service.VariationOrderList", zap.Error(err))
+		return nil, utils.SystemError(enum.ErrorCodeInternal, "Failed to count variation orders", nil)
+	}
+
+	// Set default pagination values
+	page := input.Page
+	if page <= 0 {
+		page = 1
+	}
+	limit := input.Limit
+	if limit <= 0 {
+		limit = 10
+	}
+	if limit > 100 {
+		limit = 100 // Maximum limit
+	}
+
+	skip := (page - 1) * limit
+	totalPages := int(math.Ceil(float64(total) / float64(limit)))
+
+	var sortOptions bson.D
+	if len(input.Sort) > 0 {
+		for key, value := range input.Sort {
+			sortOptions = append(sortOptions, bson.E{Key: key, Value: value})
+		}
+	} else {
+		// Default sort by createdAt descending (latest first)
+		sortOptions = bson.D{{Key: "createdAt", Value: -1}}
+	}
+
+	findOptions := options.Find().
+		SetSkip(int64(skip)).
+		SetLimit(int64(limit)).
+		SetSort(sortOptions)
+
+	cursor, err := collection.Find(context.Background(), filter, findOptions)
+	if err != nil {
+		systemContext.Logger.Error("service.VariationOrderList", zap.Error(err))
+		return nil, utils.SystemError(enum.ErrorCodeInternal, "Failed to retrieve variation orders", nil)
+	}
+	defer cursor.Close(context.Background())
+
+	var variationOrders []bson.M
+	if err = cursor.All(context.Background(), &variationOrders); err != nil {
+		systemContext.Logger.Error("service.VariationOrderList", zap.Error(err))
+		return nil, utils.SystemError(enum.ErrorCodeInternal, "Failed to decode variation orders", nil)
+	}
+	moneyDocuments(variationOrders)
+
+	return &model.VariationOrderListResponse{
+		Data:       variationOrders,
+		Page:       page,
+		Limit:      limit,
+		Total:      total,
+		TotalPages: totalPages,
+	}, nil
+}
+
+// VariationOrderStatusUpdate moves a variation order through its approval workflow.
+// Approving a VO recalculates the project's revised contract sum.
+func VariationOrderStatusUpdate(variationOrderID primitive.ObjectID, input *model.VariationOrderStatusUpdateRequest, systemContext *model.SystemContext) (*database.VariationOrder, error) {
+	variationOrder, err := VariationOrderGetByID(variationOrderID, systemContext)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := validateVariationOrderStatusTransition(variationOrder.Status, input.Status); err != nil {
+		return nil, err
+	}
+
+	description := fmt.Sprintf("Status changed from %s to %s", variationOrder.Status, input.Status)
+	if strings.TrimSpace(input.Remark) != "" {
+		description += ": " + strings.TrimSpace(input.Remark)
+	}
+
+	set := bson.M{
+		"status":    input.Status,
+		"updatedAt": time.Now(),
+		"updatedBy": systemContext.User.ID,
+	}
+
+	if input.Status == enum.VariationOrderStatusApproved {
+		approvedBy := strings.TrimSpace(input.ApprovedBy)
+		if approvedBy == "" {
+			approvedBy = systemContext.User.Username
+		}
+		set["approvedAt"] = time.Now()
+		set["approvedBy"] = approvedBy
+	}
+
+	collection := systemContext.MongoDB.Collection("variation_order")
+
+	filter := bson.M{
+		"_id":       variationOrderID,
+		"company":   systemContext.User.Company,
+		"status":    variationOrder.Status,
+		"isDeleted": false,
+	}
+
+	update := bson.M{
+		"$set": set,
+		"$push": bson.M{
+			"actionLogs": newSystemActionLog(description, systemContext),
+		},
+	}
+
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+
+	var doc database.VariationOrder
+	err = collection.FindOneAndUpdate(context.Background(), filter, update, opts).Decode(&doc)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, utils.SystemError(
+				enum.ErrorCodeValidation,
+				"Variation order status was changed by another request, please reload",
+				map[string]interface{}{"currentStatus": variationOrder.Status},
+			)
+		}
+		systemContext.Logger.Error("service.VariationOrderStatusUpdate", zap.Error(err))
+		return nil, utils.SystemError(enum.ErrorCodeInternal, "Failed to update variation order status", nil)
+	}
+
+	if doc.Status == enum.VariationOrderStatusApproved {
+		if err := projectRecalculateVariation(doc.Project, fmt.Sprintf("Variation order %s approved (%s)", doc.VONumber, documentMoney(doc.NetAmount)), systemContext); err != nil {
+			return nil, err
+		}
+	}
+
+	return &doc, nil
+}
+
+// VariationOrderDelete soft deletes a variation order that never took effect
+func VariationOrderDelete(variationOrderID primitive.ObjectID, systemContext *model.SystemContext) error {
+	variationOrder, err := VariationOrderGetByID(variationOrderID, systemContext)
+	if err != nil {
+		return err
+	}
+
+	if variationOrder.Status == enum.VariationOrderStatusApproved || variationOrder.Status == enum.VariationOrderStatusSubmitted {
+		return utils.SystemError(
+			enum.ErrorCodeValidation,
+			"Submitted or approved variation orders cannot be deleted",
+			map[string]interface{}{"status": variationOrder.Status},
+		)
+	}
+
+	collection := systemContext.MongoDB.Collection("variation_order")
+
+	filter := bson.M{
+		"_id":       variationOrderID,
+		"company":   systemContext.User.Company,
+		"status":    variationOrder.Status,
+		"isDeleted": false,
+	}
+
+	update := bson.M{
+		"$set": bson.M{
+			"isDeleted": true,
+			"updatedAt": time.Now(),
+			"updatedBy": systemContext.User.ID,
+		},
+	}
+
+	result, err := collection.UpdateOne(context.Background(), filter, update)
+	if err != nil {
+		return utils.SystemError(enum.ErrorCodeInternal, "Failed to delete variation order", nil)
+	}
+
+	if result.MatchedCount == 0 {
+		return utils.SystemError(enum.ErrorCodeValidation, "Variation order status was changed by another request, please reload", nil)
+	}
+
+	return nil
+}
+
+// VariationOrderGeneratePDF renders a variation order through the company's variation_order document template
+func VariationOrderGeneratePDF(variationOrderID primitive.ObjectID, systemContext *model.SystemContext) ([]byte, string, error) {
+	variationOrder, err := VariationOrderGetByID(variationOrderID, systemContext)
+	if err != nil {
+		return nil, "", err
+	}
+
+	project, err := ProjectGetByID(variationOrder.Project, systemContext)
+	if err != nil {
+		return nil, "", err
+	}
+
+	company, err := CompanyTenantGet(systemContext)
+	if err != nil {
+		return nil, "", err
+	}
+
+	// Client details live on the source quotation; the VO still renders without them
+	quotation, _ := QuotationGetByID(project.Quotation, systemContext)
+
+	previousVariation, err := projectApprovedVariationBefore(variationOrder, systemContext)
+	if err != nil {
+		return nil, "", err
+	}
+
+	data := variationOrderDocumentData(variationOrder, project, quotation, previousVariation, company, systemContext)
+
+	return DocumentTemplateGenerate("variation_order", data, systemContext.User.Company, systemContext)
+}
+
+// Helper functions
+func validateVariationOrderLines(project *database.Project, lines []database.VariationOrderLine, systemContext *model.SystemContext) error {
+	projectAreas := make(map[string]bool)
+	for _, areaMaterial := range project.AreaMaterials {
+		projectAreas[strings.TrimSpace(areaMaterial.Area.Name)] = true
+	}
+
+	materialCollection := systemContext.MongoDB.Collection("material")
+
+	for i, line := range lines {
+		switch line.Type {
+		case enum.VariationLineTypeAddition, enum.VariationLineTypeOmission:
+		default:
+			return utils.SystemError(
+				enum.ErrorCodeValidation,
+				"Invalid variation line type",
+				map[string]interface{}{"lineIndex": i, "type": line.Type},
+			)
+		}
+
+		area := strings.TrimSpace(line.Area.Name)
+		if area == "" {
+			return utils.SystemError(
+				enum.ErrorCodeValidation,
+				"Area is required",
+				map[string]interface{}{"lineIndex": i},
+			)
+		}
+
+		// Work can be added in a new area, but can only be omitted from an existing one
+		if line.Type == enum.VariationLineTypeOmission && !projectAreas[area] {
+			return utils.SystemError(
+				enum.ErrorCodeValidation,
+				"Omitted work must belong to an existing project area",
+				map[string]interface{}{"lineIndex": i, "area": line.Area.Name},
+			)
+		}
+
+		if strings.TrimSpace(line.Item.Name) == "" {
+			return utils.SystemError(
+				enum.ErrorCodeValidation,
+				"Item name is required",
+				map[string]interface{}{"lineIndex": i},
+			)
+		}
+
+		if line.Item.Quantity <= 0 {
+			return utils.SystemError(
+				enum.ErrorCodeValidation,
+				"Quantity must be greater than zero",
+				map[string]interface{}{"lineIndex": i, "quantity": line.Item.Quantity},
+			)
+		}
+
+		if line.Item.PricePerUnit < 0 {
+			return utils.SystemError(
+				enum.ErrorCodeValidation,
+				"Price per unit cannot be negative",
+				map[string]interface{}{"lineIndex": i, "pricePerUnit": line.Item.PricePerUnit},
+			)
+		}
+
+		if err := validateMaterialDetail(line.Item, materialCollection, systemContext); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// calculateVariationOrderTotals prices every line server-side. Line amounts are signed so the
+// net amount can be added straight onto the contract sum.
+func calculateVariationOrderTotals(lines []database.VariationOrderLine, rounding enum.MoneyRounding) ([]database.VariationOrderLine, database.Money, database.Money, database.Money) {
+	additions := newMoneyTotal(rounding)
+	omissions := newMoneyTotal(rounding)
+
+	result := make([]database.VariationOrderLine, len(lines))
+	for i, line := range lines {
+		line.Area.Name = strings.TrimSpace(line.Area.Name)
+
+		if line.Type == enum.VariationLineTypeOmission {
+			line.Item.SubTotal = omissions.addLine(line.Item.PricePerUnit, line.Item.Quantity)
+			line.Amount = -line.Item.SubTotal
+		} else {
+			line.Item.SubTotal = additions.addLine(line.Item.PricePerUnit, line.Item.Quantity)
+			line.Amount = line.Item.SubTotal
+		}
+
+		result[i] = line
+	}
+
+	totalAddition := additions.value()
+	totalOmission := omissions.value()
+
+	return result, totalAddition, totalOmission, totalAddition - totalOmission
+}
+
+func validateVariationOrderStatusTransition(from enum.VariationOrderStatus, to enum.VariationOrderStatus) error {
+	if _, exists := variationOrderStatusTransitions[to]; !exists {
+		return utils.SystemError(
+			enum.ErrorCodeValidation,
+			"Invalid variation order status",
+			map[string]interface{}{"status": to},
+		)
+	}
+
+	allowed := variationOrderStatusTransitions[from]
+	for _, status := range allowed {
+		if status == to {
+			return nil
+		}
+	}
+
+	if allowed == nil {
+		allowed = []enum.VariationOrderStatus{}
+	}
+
+	return utils.SystemError(
+		enum.ErrorCodeValidation,
+		"Variation order status transition not allowed",
+		map[string]interface{}{
+			"from":    from,
+			"to":      to,
+			"allowed": allowed,
+		},
+	)
+}
+
+// projectRecalculateVariation re-sums the project's approved variation orders so the revised
+// contract sum never drifts from the VOs it is made of
+func projectRecalculateVariation(projectID primitive.ObjectID, description string, systemContext *model.SystemContext) error {
+	project, err := ProjectGetByID(projectID, systemContext)
+	if err != nil {
+		return err
+	}
+
+	approvedVariation, err := projectApprovedVariationTotal(projectID, nil, systemContext)
+	if err != nil {
+		return err
+	}
+
+	collection := systemContext.MongoDB.Collection("project")
+
+	filter := bson.M{
+		"_id":       projectID,
+		"company":   systemContext.User.Company,
+		"isDeleted": false,
+	}
+
+	update := bson.M{
+		"$set": bson.M{
+			"approvedVariation":  approvedVariation,
+			"revisedContractSum": project.TotalNettCharge + approvedVariation,
+			"updatedAt":          time.Now(),
+			"updatedBy":          systemContext.User.ID,
+		},
+		"$push": bson.M{
+			"actionLogs": newSystemActionLog(description, systemContext),
+		},
+	}
+
+	_, err = collection.UpdateOne(context.Background(), filter, update)
+	if err != nil {
+		systemContext.Logger.Error("service.projectRecalculateVariation", zap.Error(err))
+		return utils.SystemError(enum.ErrorCodeInternal, "Failed to update project contract sum", nil)
+	}
+
+	return nil
+}
+
+// projectApprovedVariationTotal sums approved VOs of a project, optionally only those approved before a time
+func projectApprovedVariationTotal(projectID primitive.ObjectID, approvedBefore *time.Time, systemContext *model.SystemContext) (database.Money, error) {
+	variationOrders, err := projectApprovedVariationOrders(projectID, approvedBefore, systemContext)
+	if err != nil {
+		return 0, err
+	}
+
+	var total database.Money
+	for _, variationOrder := range variationOrders {
+		total += variationOrder.NetAmount
+	}
+
+	return total, nil
+}
+
+func projectApprovedVariationOrders(projectID primitive.ObjectID, approvedBefore *time.Time, systemContext *model.SystemContext) ([]database.VariationOrder, error) {
+	collection := systemContext.MongoDB.Collection("variation_order")
+
+	filter := bson.M{
+		"project":   projectID,
+		"company":   systemContext.User.Company,
+		"status":    enum.VariationOrderStatusApproved,
+		"isDeleted": false,
+	}
+	if approvedBefore != nil {
+		filter["approvedAt"] = bson.M{"$lt": approvedBefore}
+	}
+
+	cursor, err := collection.Find(context.Background(), filter, options.Find().SetSort(bson.D{{Key: "approvedAt", Value: 1}}))
+	if err != nil {
+		systemContext.Logger.Error("service.projectApprovedVariationOrders", zap.Error(err))
+		return nil, utils.SystemError(enum.ErrorCodeInternal, "Failed to retrieve variation orders", nil)
+	}
+	defer cursor.Close(context.Background())
+
+	var variationOrders []database.VariationOrder
+	if err = cursor.All(context.Background(), &variationOrders); err != nil {
+		systemContext.Logger.Error("service.projectApprovedVariationOrders", zap.Error(err))
+		return nil, utils.SystemError(enum.ErrorCodeInternal, "Failed to decode variation orders", nil)
+	}
+
+	return variationOrders, nil
+}
+
+// projectApprovedVariationBefore is the approved variation total preceding this VO, for the
+// contract sum summary printed on the VO
+func projectApprovedVariationBefore(variationOrder *database.VariationOrder, systemContext *model.SystemContext) (database.Money, error) {
+	before := time.Now()
+	if variationOrder.ApprovedAt != nil {
+		before = *variationOrder.ApprovedAt
+	}
+
+	return projectApprovedVariationTotal(variationOrder.Project, &before, systemContext)
+}
+
+func variationOrderDocumentData(variationOrder *database.VariationOrder, project *database.Project, quotation *database.Quotation, previousVariation database.Money, company *database.Company, systemContext *model.SystemContext) bson.M {
+	lines := make([]interface{}, len(variationOrder.Lines))
+	for i, line := range variationOrder.Lines {
+		typeLabel := "Addition"
+		if line.Type == enum.VariationLineTypeOmission {
+			typeLabel = "Omission"
+		}
+
+		lines[i] = map[string]interface{}{
+			"no":           i + 1,
+			"type":         typeLabel,
+			"area":         html.EscapeString(line.Area.Name),
+			"name":         html.EscapeString(line.Item.Name),
+			"brand":        html.EscapeString(line.Item.Brand),
+			"unit":         html.EscapeString(line.Item.Unit),
+			"description":  documentMultiline(line.Item.Description),
+			"remark":       documentMultiline(line.Item.Remark),
+			"quantity":     documentQuantity(line.Item.Quantity),
+			"pricePerUnit": documentMoney(line.Item.PricePerUnit),
+			"amount":       documentMoney(line.Amount),
+		}
+	}
+
+	approvedAt := ""
+	if variationOrder.ApprovedAt != nil {
+		approvedAt = documentDate(*variationOrder.ApprovedAt)
+	}
+
+	// Contract sum before and after this VO; a VO that is not approved yet shows the effect it would have
+	originalContractSum := project.TotalNettCharge
+	previousContractSum := originalContractSum + previousVariation
+
+	variationData := bson.M{
+		"voNumber":            html.EscapeString(variationOrder.VONumber),
+		"voDate":              documentDate(variationOrder.CreatedAt),
+		"title":               html.EscapeString(variationOrder.Title),
+		"description":         documentMultiline(variationOrder.Description),
+		"reason":              documentMultiline(variationOrder.Reason),
+		"status":              string(variationOrder.Status),
+		"approvedAt":          approvedAt,
+		"approvedBy":          html.EscapeString(variationOrder.ApprovedBy),
+		"projectName":         html.EscapeString(project.Name),
+		"lines":               lines,
+		"totalAddition":       documentMoney(variationOrder.TotalAddition),
+		"totalOmission":       documentMoney(variationOrder.TotalOmission),
+		"netAmount":           documentMoney(variationOrder.NetAmount),
+		"originalContractSum": documentMoney(originalContractSum),
+		"previousVariation":   documentMoney(previousVariation),
+		"previousContractSum": documentMoney(previousContractSum),
+		"revisedContractSum":  documentMoney(previousContractSum + variationOrder.NetAmount),
+		"clientName":          "",
+		"clientContact":       "",
+		"clientEmail":         "",
+		"address":             "",
+		"quotationNumber":     "",
+	}
+
+	if quotation != nil {
+		variationData["clientName"] = html.EscapeString(quotation.Client.Name)
+		variationData["clientContact"] = html.EscapeString(quotation.Client.Contact)
+		variationData["clientEmail"] = html.EscapeString(quotation.Client.Email)
+		variationData["address"] = documentAddress(quotation.Address)
+		variationData["quotationNumber"] = html.EscapeString(quotationReference(quotation))
+	}
+
+	data := documentCompanyData(company, systemContext)
+	for key, value := range variationData {
+		data[key] = value
+	}
+
+	return data
+}
@@ -2,10 +2,16 @@ package utils
 
 import (
 	"bytes"
+	"encoding/base64"
 	"fmt"
 	"html/template"
+	"mime"
+	"mime/multipart"
+	"net/mail"
 	"net/smtp"
+	"net/textproto"
 	"strings"
+	"time"
 
 	"renotech.com.my/internal/enum"
 )
@@ -26,6 +32,24 @@ type EmailData struct {
 	IsHTML  bool
 }
 
+// EmailMessage is a multipart email with an HTML body, plain-text alternative and attachments
+type EmailMessage struct {
+	To          []string
+	Cc          []string
+	Bcc         []string
+	ReplyTo     string
+	Subject     string
+	HTMLBody    string
+	TextBody    string
+	Attachments []EmailAttachment
+}
+
+type EmailAttachment struct {
+	Filename    string
+	ContentType string
+	Content     []byte
+}
+
 func GetEmailConfig() *EmailConfig {
 	return &EmailConfig{
 		SMTPHost:     GetEnvString("SMTP_HOST", "smtp.gmail.com"),
@@ -76,6 +100,154 @@ func SendEmail(emailData *EmailData) error {
 	return nil
 }
 
+// SendMultipartEmail sends a MIME multipart/mixed message. Bcc recipients receive the
+// message but are not listed in the headers.
+func SendMultipartEmail(message *EmailMessage) error {
+	config := GetEmailConfig()
+
+	if config.SMTPHost == "localhost" || config.SMTPUsername == "" {
+		return SystemError(enum.ErrorCodeInternal, "Email configuration not set up", nil)
+	}
+
+	if len(message.To) == 0 {
+		return SystemError(enum.ErrorCodeValidation, "At least one recipient is required", nil)
+	}
+
+	// Validate every address up front so a typo does not fail halfway through SMTP
+	var recipients []string
+	for _, list := range [][]string{message.To, message.Cc, message.Bcc} {
+		for _, address := range list {
+			parsed, err := mail.ParseAddress(address)
+			if err != nil {
+				return SystemError(enum.ErrorCodeValidation, "Invalid email address", map[string]interface{}{
+					"email": address,
+				})
+			}
+			recipients = append(recipients, parsed.Address)
+		}
+	}
+
+	msg, err := buildMultipartEmail(config, message)
+	if err != nil {
+		return SystemError(enum.ErrorCodeInternal, "Failed to build email", map[string]interface{}{
+			"error": err.Error(),
+		})
+	}
+
+	auth := smtp.PlainAuth("", config.SMTPUsername, config.SMTPPassword, config.SMTPHost)
+	smtpAddr := fmt.Sprintf("%s:%d", config.SMTPHost, config.SMTPPort)
+	err = smtp.SendMail(smtpAddr, auth, config.FromEmail, recipients, msg)
+	if err != nil {
+		return SystemError(enum.ErrorCodeInternal, "Failed to send email", map[string]interface{}{
+			"error": err.Error(),
+		})
+	}
+
+	return nil
+}
+
+func buildMultipartEmail(config *EmailConfig, message *EmailMessage) ([]byte, error) {
+	var buffer bytes.Buffer
+
+	from := mail.Address{Name: config.FromName, Address: config.FromEmail}
+	buffer.WriteString(fmt.Sprintf("From: %s\r\n", from.String()))
+	buffer.WriteString(fmt.Sprintf("To: %s\r\n", strings.Join(message.To, ", ")))
+	if len(message.Cc) > 0 {
+		buffer.WriteString(fmt.Sprintf("Cc: %s\r\n", strings.Join(message.Cc, ", ")))
+	}
+	if replyTo, err := mail.ParseAddress(message.ReplyTo); err == nil {
+		buffer.WriteString(fmt.Sprintf("Reply-To: %s\r\n", replyTo.String()))
+	}
+	buffer.WriteString(fmt.Sprintf("Subject: %s\r\n", mime.QEncoding.Encode("UTF-8", message.Subject)))
+	buffer.WriteString(fmt.Sprintf("Date: %s\r\n", time.Now().Format(time.RFC1123Z)))
+	buffer.WriteString("MIME-Version: 1.0\r\n")
+
+	mixed := multipart.NewWriter(&buffer)
+	buffer.WriteString(fmt.Sprintf("Content-Type: multipart/mixed; boundary=%q\r\n\r\n", mixed.Boundary()))
+
+	// Body: plain text first, HTML last so clients prefer it
+	var alternativeBuffer bytes.Buffer
+	alternative := multipart.NewWriter(&alternativeBuffer)
+
+	bodies := []struct {
+		contentType string
+		content     string
+	}{
+		{"text/plain; charset=UTF-8", message.TextBody},
+		{"text/html; charset=UTF-8", message.HTMLBody},
+	}
+	for _, body := range bodies {
+		if body.content == "" {
+			continue
+		}
+
+		part, err := alternative.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {body.contentType},
+			"Content-Transfer-Encoding": {"base64"},
+		})
+		if err != nil {
+			return nil, err
+		}
+		if _, err := part.Write(wrapBase64([]byte(body.content))); err != nil {
+			return nil, err
+		}
+	}
+	if err := alternative.Close(); err != nil {
+		return nil, err
+	}
+
+	bodyPart, err := mixed.CreatePart(textproto.MIMEHeader{
+		"Content-Type": {fmt.Sprintf("multipart/alternative; boundary=%q", alternative.Boundary())},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if _, err := bodyPart.Write(alternativeBuffer.Bytes()); err != nil {
+		return nil, err
+	}
+
+	for _, attachment := range message.Attachments {
+		contentType := attachment.ContentType
+		if contentType == "" {
+			contentType = "application/octet-stream"
+		}
+
+		part, err := mixed.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {mime.FormatMediaType(contentType, map[string]string{"name": attachment.Filename})},
+			"Content-Disposition":       {mime.FormatMediaType("attachment", map[string]string{"filename": attachment.Filename})},
+			"Content-Transfer-Encoding": {"base64"},
+		})
+		if err != nil {
+			return nil, err
+		}
+		if _, err := part.Write(wrapBase64(attachment.Content)); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := mixed.Close(); err != nil {
+		return nil, err
+	}
+
+	return buffer.Bytes(), nil
+}
+
+// wrapBase64 encodes content as base64 split into 76 character lines (RFC 2045)
+func wrapBase64(content []byte) []byte {
+	encoded := base64.StdEncoding.EncodeToString(content)
+
+	var wrapped bytes.Buffer
+	for len(encoded) > 76 {
+		wrapped.WriteString(encoded[:76])
+		wrapped.WriteString("\r\n")
+		encoded = encoded[76:]
+	}
+	wrapped.WriteString(encoded)
+	wrapped.WriteString("\r\n")
+
+	return wrapped.Bytes()
+}
+
 func SendPasswordResetEmail(email, resetToken string) error {
 	resetLink := fmt.Sprintf("%s/auth/reset-password?token=%s&email=%s",
 		GetEnvString("FRONTEND_URL", "https://app.renotech.space"),
@@ -13,6 +13,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"renotech.com.my/internal/controller"
 	"renotech.com.my/internal/middleware"
+	"renotech.com.my/internal/service"
 	"renotech.com.my/internal/utils"
 	"renotech.com.my/logs"
 )
@@ -37,6 +38,11 @@ func init() {
 		log.Fatalf("MongoDB health check failed: %v", err)
 	}
 
+	// Apply pending data migrations
+	if err := service.MigrationRun(utils.MongoGet(), logs.LoggerGet()); err != nil {
+		log.Fatalf("Failed to run migrations: %v", err)
+	}
+
 	log.Println("Application initialized successfully")
 }
 
@@ -69,6 +75,10 @@ func main() {
 		MaxHeaderBytes: 1 << 20, // 1MB
 	}
 
+	// Start background jobs
+	jobCtx, stopJobs := context.WithCancel(context.Background())
+	service.QuotationExpirySweeperStart(jobCtx, utils.MongoGet(), logs.LoggerGet())
+
 	// Start server in a goroutine
 	go func() {
 		log.Printf("Server starting on %s", addr)
@@ -84,6 +94,9 @@ func main() {
 
 	log.Println("Server shutting down...")
 
+	// Stop background jobs
+	stopJobs()
+
 	// Graceful shutdown with timeout
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
@@ -149,6 +162,16 @@ func setupRoutes(router *gin.Engine) {
 	controller.MaterialAPIInit(router)
 	controller.FolderAPIInit(router)
 	controller.QuotationAPIInit(router)
+	controller.ProjectAPIInit(router)
+	controller.ProjectCostAPIInit(router)
+	controller.ProjectTaskAPIInit(router)
+	controller.SiteDiaryAPIInit(router)
+	controller.VariationOrderAPIInit(router)
+	controller.InvoiceAPIInit(router)
+	controller.PaymentAPIInit(router)
+	controller.OrderAPIInit(router)
+	controller.GoodsReceiptAPIInit(router)
+	controller.DocumentNumberAPIInit(router)
 }
 
 // healthCheckHandler provides a health check endpoint